@@ -34,10 +34,13 @@ const Editor = ({ draftId, isExistingDraft = false }) => {
         medium: false,
         devto: false,
     });
-    const [saveStatus, setSaveStatus] = useState('saved'); // 'saved', 'saving', 'unsaved'
+    const [saveStatus, setSaveStatus] = useState('saved'); // 'saved', 'saving', 'unsaved', 'conflict'
     const [draftReady, setDraftReady] = useState(!isExistingDraft);
     const imageInputRef = useRef(null);
     const hasLoadedDraft = useRef(false);
+    // Server version and content this tab last synced with, for If-Match and merges
+    const versionRef = useRef(null);
+    const baseRef = useRef(null);
 
     const editor = useEditor({
         extensions: [
@@ -74,20 +77,42 @@ const Editor = ({ draftId, isExistingDraft = false }) => {
             const publishTargets = Object.entries(selectedPlatforms)
                 .filter(([, enabled]) => enabled)
                 .map(([key]) => key);
+            const headers = { 'Content-Type': 'application/json' };
+            if (versionRef.current !== null) {
+                headers['If-Match'] = `"${versionRef.current}"`;
+            }
             const response = await fetch(`${import.meta.env.VITE_API_URL || 'http://localhost:8080'}/api/drafts/${draftId}`, {
                 method: 'PUT',
-                headers: { 'Content-Type': 'application/json' },
+                headers,
                 body: JSON.stringify({
                     title,
                     content,
                     cover_image: coverImage,
                     tags,
-                    publish_targets: publishTargets
+                    publish_targets: publishTargets,
+                    base_title: baseRef.current?.title,
+                    base_content: baseRef.current?.content,
                 })
             });
+            if (response.status === 409) {
+                // Someone else saved overlapping changes; keep local edits until resolved
+                const conflict = await response.json();
+                console.warn("Draft changed on the server:", conflict);
+                setSaveStatus('conflict');
+                return;
+            }
             if (!response.ok) {
                 throw new Error(`Save failed with status ${response.status}`);
             }
+            const data = await response.json();
+            versionRef.current = data.version;
+            if (data.merged) {
+                setTitle(data.title);
+                editor.commands.setContent(data.content, false);
+                baseRef.current = { title: data.title, content: data.content };
+            } else {
+                baseRef.current = { title, content };
+            }
             setSaveStatus('saved');
         } catch (error) {
             console.error("Auto-save failed:", error);
@@ -113,6 +138,8 @@ const Editor = ({ draftId, isExistingDraft = false }) => {
                     return;
                 }
                 const data = await response.json();
+                versionRef.current = data.version ?? null;
+                baseRef.current = { title: data.title || '', content: data.content || '' };
                 if (data.title) {
                     setTitle(data.title);
                 }
@@ -128,6 +155,9 @@ const Editor = ({ draftId, isExistingDraft = false }) => {
                         devto: data.publish_targets.includes('devto'),
                     });
                 }
+                if (Array.isArray(data.tags)) {
+                    setTags(data.tags);
+                }
                 setIsEditorEmpty(editor.isEmpty);
                 setSaveStatus('saved');
                 hasLoadedDraft.current = true;
@@ -303,9 +333,9 @@ const Editor = ({ draftId, isExistingDraft = false }) => {
                             <div className="flex items-center gap-2 rounded-full border border-gray-200/60 bg-white/85 px-3 py-1.5 text-xs text-gray-600">
                                 {saveStatus === 'saving' && <Loader2 className="w-3 h-3 animate-spin text-gray-400" />}
                                 {saveStatus === 'saved' && <CheckCircle className="w-3 h-3 text-brand" />}
-                                {saveStatus === 'unsaved' && <div className="w-3 h-3 rounded-full bg-brand/40 animate-pulse"></div>}
+                                {(saveStatus === 'unsaved' || saveStatus === 'conflict') && <div className="w-3 h-3 rounded-full bg-brand/40 animate-pulse"></div>}
                                 <span className="text-xs font-medium">
-                                    {saveStatus === 'saving' ? 'Saving...' : saveStatus === 'saved' ? 'Saved' : 'Unsaved changes'}
+                                    {saveStatus === 'saving' ? 'Saving...' : saveStatus === 'saved' ? 'Saved' : saveStatus === 'conflict' ? 'Edited elsewhere, reload to sync' : 'Unsaved changes'}
                                 </span>
                             </div>
                             <div className="flex gap-3">
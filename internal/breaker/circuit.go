@@ -1,14 +1,23 @@
 package breaker
 
 import (
+	"context"
 	"errors"
-	"postificus/internal/metrics"
+	"fmt"
+	"log"
+	"sort"
 	"sync"
 	"time"
+
+	"postificus/internal/metrics"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
 var (
-	ErrCircuitOpen = errors.New("circuit breaker is open")
+	ErrCircuitOpen    = errors.New("circuit breaker is open")
+	ErrUnknownBreaker = errors.New("unknown circuit breaker")
 )
 
 type State int
@@ -19,86 +28,479 @@ const (
 	StateHalfOpen
 )
 
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func parseState(s string) State {
+	switch s {
+	case "open":
+		return StateOpen
+	case "half_open":
+		return StateHalfOpen
+	default:
+		return StateClosed
+	}
+}
+
+// Settings decide when a breaker opens and how it recovers
 type Settings struct {
-	FailureThreshold int
-	ResetTimeout     time.Duration
+	Window       time.Duration    // The failure rate is measured over calls this recent
+	MinCalls     int              // Calls the window needs before the rate can open the breaker
+	FailureRate  float64          // Share of failed calls (0-1) that opens the breaker
+	OpenFor      time.Duration    // How long an open breaker refuses calls before letting a probe through
+	ProbeTimeout time.Duration    // How long a probe may run before another call may probe instead
+	Ignore       func(error) bool // Errors that say nothing about the service's health; they don't count
+}
+
+// DefaultSettings open a breaker once half of at least 3 calls in 5 minutes failed
+var DefaultSettings = Settings{
+	Window:       5 * time.Minute,
+	MinCalls:     3,
+	FailureRate:  0.5,
+	OpenFor:      time.Minute,
+	ProbeTimeout: 10 * time.Minute, // Browser publishing can take minutes
+}
+
+// outcome is what a call did to a breaker
+type outcome string
+
+const (
+	succeeded outcome = "success"
+	failed    outcome = "failure"
+	ignored   outcome = "ignored"
+)
+
+// Snapshot is a breaker's state as the admin API shows it
+type Snapshot struct {
+	Name        string     `json:"name"`
+	State       State      `json:"state"`
+	Calls       int        `json:"calls"`    // In the current window
+	Failures    int        `json:"failures"` // In the current window
+	FailureRate float64    `json:"failure_rate"`
+	OpenedAt    *time.Time `json:"opened_at,omitempty"`
+	ProbeAt     *time.Time `json:"probe_at,omitempty"` // When an open breaker lets a probe through
 }
 
+// backend keeps breaker state, in process memory or in Redis
+type backend interface {
+	// allow decides whether a call may go ahead; probe marks the one call a half-open breaker lets through
+	allow(ctx context.Context, name string, s Settings, now time.Time) (ok bool, probe bool, state State, err error)
+	record(ctx context.Context, name string, s Settings, now time.Time, result outcome, probe bool) (State, error)
+	snapshot(ctx context.Context, name string, s Settings, now time.Time) (Snapshot, error)
+	reset(ctx context.Context, name string) error
+}
+
+// CircuitBreaker stops calling a service whose calls keep failing. It opens when
+// the failure rate over a sliding window crosses the threshold, refuses calls for
+// OpenFor, then lets exactly one probe through: the probe's success closes it
+// and its failure opens it again. With a Redis client the state is shared by
+// every process using the same name; without one (or while Redis can't be
+// reached) each process keeps its own.
 type CircuitBreaker struct {
-	mu           sync.Mutex
-	name         string
-	state        State
-	failures     int
-	failureLimit int
-	resetTimeout time.Duration
-	lastFailure  time.Time
+	name     string
+	settings Settings
+	shared   backend // nil without Redis
+	local    backend
+	now      func() time.Time
 }
 
-func NewCircuitBreakerWithName(name string, failureLimit int, resetTimeout time.Duration) *CircuitBreaker {
-	metrics.CircuitBreakerState.WithLabelValues(name).Set(0) // Closed
-	return &CircuitBreaker{
-		name:         name,
-		state:        StateClosed,
-		failureLimit: failureLimit,
-		resetTimeout: resetTimeout,
+func New(name string, settings Settings, client *redis.Client) *CircuitBreaker {
+	cb := &CircuitBreaker{name: name, settings: settings, local: newMemoryBackend(), now: time.Now}
+	if client != nil {
+		cb.shared = &redisBackend{client: client}
 	}
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(StateClosed))
+	return cb
 }
 
-func (cb *CircuitBreaker) Execute(fn func() error) error {
-	if !cb.Allow() {
-		return ErrCircuitOpen
+func (cb *CircuitBreaker) Name() string { return cb.name }
+
+// Execute runs fn unless the breaker is open, and records how it went.
+// Errors the settings ignore are returned without counting either way.
+// A nil breaker runs fn unguarded.
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
+	if cb == nil {
+		return fn()
+	}
+	ok, probe := cb.allow(ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", cb.name, ErrCircuitOpen)
+	}
+	if probe {
+		log.Printf("🔌 Circuit breaker %s is half-open; probing", cb.name)
 	}
 
 	err := fn()
+	result := succeeded
+	switch {
+	case err != nil && cb.settings.Ignore != nil && cb.settings.Ignore(err):
+		result = ignored
+	case err != nil:
+		result = failed
+	}
+	cb.record(context.WithoutCancel(ctx), result, probe)
+	return err
+}
+
+func (cb *CircuitBreaker) allow(ctx context.Context) (bool, bool) {
+	now := cb.now()
+	if cb.shared != nil {
+		ok, probe, state, err := cb.shared.allow(ctx, cb.name, cb.settings, now)
+		if err == nil {
+			cb.observe(state)
+			return ok, probe
+		}
+		log.Printf("⚠️ Circuit breaker %s can't reach Redis, using this process's state: %v", cb.name, err)
+	}
+	ok, probe, state, _ := cb.local.allow(ctx, cb.name, cb.settings, now)
+	cb.observe(state)
+	return ok, probe
+}
+
+func (cb *CircuitBreaker) record(ctx context.Context, result outcome, probe bool) {
+	now := cb.now()
+	if cb.shared != nil {
+		state, err := cb.shared.record(ctx, cb.name, cb.settings, now, result, probe)
+		if err == nil {
+			cb.observe(state)
+			return
+		}
+		log.Printf("⚠️ Circuit breaker %s can't reach Redis, using this process's state: %v", cb.name, err)
+	}
+	state, _ := cb.local.record(ctx, cb.name, cb.settings, now, result, probe)
+	cb.observe(state)
+}
+
+// Snapshot returns the breaker's current state
+func (cb *CircuitBreaker) Snapshot(ctx context.Context) (Snapshot, error) {
+	var snap Snapshot
+	var err error
+	if cb.shared != nil {
+		snap, err = cb.shared.snapshot(ctx, cb.name, cb.settings, cb.now())
+	} else {
+		snap, err = cb.local.snapshot(ctx, cb.name, cb.settings, cb.now())
+	}
 	if err != nil {
-		cb.RecordFailure()
-		return err
+		return Snapshot{}, err
 	}
+	cb.observe(snap.State)
+	return snap, nil
+}
 
-	cb.RecordSuccess()
+// Reset closes the breaker and forgets its window
+func (cb *CircuitBreaker) Reset(ctx context.Context) error {
+	if cb.shared != nil {
+		if err := cb.shared.reset(ctx, cb.name); err != nil {
+			return err
+		}
+	}
+	cb.local.reset(ctx, cb.name)
+	log.Printf("🔌 Circuit breaker %s reset", cb.name)
+	cb.observe(StateClosed)
 	return nil
 }
 
-func (cb *CircuitBreaker) Allow() bool {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
+func (cb *CircuitBreaker) observe(state State) {
+	metrics.CircuitBreakerState.WithLabelValues(cb.name).Set(float64(state))
+}
+
+// Group is a set of named breakers with the same settings, such as one per platform
+type Group struct {
+	breakers map[string]*CircuitBreaker
+}
+
+func NewGroup(settings Settings, client *redis.Client, names ...string) *Group {
+	g := &Group{breakers: make(map[string]*CircuitBreaker, len(names))}
+	for _, name := range names {
+		g.breakers[name] = New(name, settings, client)
+	}
+	return g
+}
 
-	if cb.state == StateOpen {
-		if time.Since(cb.lastFailure) > cb.resetTimeout {
-			cb.state = StateHalfOpen
-			metrics.CircuitBreakerState.WithLabelValues(cb.name).Set(2) // HalfOpen
-			return true
+// Get returns the named breaker, or nil (which guards nothing) if the group has none by that name
+func (g *Group) Get(name string) *CircuitBreaker {
+	if g == nil {
+		return nil
+	}
+	return g.breakers[name]
+}
+
+// Snapshots returns the state of every breaker, by name
+func (g *Group) Snapshots(ctx context.Context) ([]Snapshot, error) {
+	snaps := make([]Snapshot, 0, len(g.breakers))
+	for _, cb := range g.breakers {
+		snap, err := cb.Snapshot(ctx)
+		if err != nil {
+			return nil, err
 		}
-		return false
+		snaps = append(snaps, snap)
 	}
-	return true
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Name < snaps[j].Name })
+	return snaps, nil
 }
 
-func (cb *CircuitBreaker) RecordFailure() {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
+// Reset closes the named breaker
+func (g *Group) Reset(ctx context.Context, name string) error {
+	cb, ok := g.breakers[name]
+	if !ok {
+		return ErrUnknownBreaker
+	}
+	return cb.Reset(ctx)
+}
 
-	cb.failures++
-	cb.lastFailure = time.Now()
+// memoryBackend keeps state in process memory
+type memoryBackend struct {
+	mu     sync.Mutex
+	states map[string]*memoryState
+}
 
-	if cb.state == StateClosed && cb.failures >= cb.failureLimit {
-		cb.state = StateOpen
-		metrics.CircuitBreakerState.WithLabelValues(cb.name).Set(1) // Open
-	} else if cb.state == StateHalfOpen {
-		cb.state = StateOpen                                        // Fail fast in half-open
-		metrics.CircuitBreakerState.WithLabelValues(cb.name).Set(1) // Open
+type memoryState struct {
+	state      State
+	openedAt   time.Time
+	probeUntil time.Time
+	calls      []call // Oldest first
+}
+
+type call struct {
+	at     time.Time
+	failed bool
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{states: make(map[string]*memoryState)}
+}
+
+func (m *memoryBackend) get(name string) *memoryState {
+	st, ok := m.states[name]
+	if !ok {
+		st = &memoryState{}
+		m.states[name] = st
+	}
+	return st
+}
+
+func (m *memoryBackend) allow(ctx context.Context, name string, s Settings, now time.Time) (bool, bool, State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.get(name)
+	switch {
+	case st.state == StateClosed:
+		return true, false, StateClosed, nil
+	case st.state == StateOpen && now.Before(st.openedAt.Add(s.OpenFor)):
+		return false, false, StateOpen, nil
+	case now.Before(st.probeUntil):
+		return false, false, StateHalfOpen, nil // Another call is probing
+	}
+	st.state = StateHalfOpen
+	st.probeUntil = now.Add(s.ProbeTimeout)
+	return true, true, StateHalfOpen, nil
+}
+
+func (m *memoryBackend) record(ctx context.Context, name string, s Settings, now time.Time, result outcome, probe bool) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.get(name)
+	if probe {
+		switch result {
+		case succeeded:
+			*st = memoryState{}
+		case failed:
+			*st = memoryState{state: StateOpen, openedAt: now}
+		default:
+			st.probeUntil = time.Time{} // Let the next call probe instead
+		}
+		return st.state, nil
 	}
+	// Calls let through before the breaker opened don't count
+	if st.state != StateClosed || result == ignored {
+		return st.state, nil
+	}
+
+	st.calls = append(st.calls, call{at: now, failed: result == failed})
+	st.calls = recent(st.calls, now, s.Window)
+	calls, failures := count(st.calls)
+	if calls >= s.MinCalls && failures > 0 && float64(failures) >= float64(calls)*s.FailureRate {
+		*st = memoryState{state: StateOpen, openedAt: now}
+	}
+	return st.state, nil
 }
 
-func (cb *CircuitBreaker) RecordSuccess() {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
+func (m *memoryBackend) snapshot(ctx context.Context, name string, s Settings, now time.Time) (Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.get(name)
+	st.calls = recent(st.calls, now, s.Window)
+	calls, failures := count(st.calls)
+	return newSnapshot(name, s, st.state, calls, failures, st.openedAt), nil
+}
+
+func (m *memoryBackend) reset(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, name)
+	return nil
+}
 
-	if cb.state == StateHalfOpen {
-		cb.state = StateClosed
-		cb.failures = 0
-		metrics.CircuitBreakerState.WithLabelValues(cb.name).Set(0) // Closed
-	} else if cb.state == StateClosed {
-		cb.failures = 0
+// recent drops the calls that left the window
+func recent(calls []call, now time.Time, window time.Duration) []call {
+	i := 0
+	for i < len(calls) && !calls[i].at.After(now.Add(-window)) {
+		i++
 	}
+	return calls[i:]
+}
+
+func count(calls []call) (total int, failures int) {
+	for _, c := range calls {
+		if c.failed {
+			failures++
+		}
+	}
+	return len(calls), failures
+}
+
+func newSnapshot(name string, s Settings, state State, calls, failures int, openedAt time.Time) Snapshot {
+	snap := Snapshot{Name: name, State: state, Calls: calls, Failures: failures}
+	if calls > 0 {
+		snap.FailureRate = float64(failures) / float64(calls)
+	}
+	if state != StateClosed && !openedAt.IsZero() {
+		probeAt := openedAt.Add(s.OpenFor)
+		snap.OpenedAt, snap.ProbeAt = &openedAt, &probeAt
+	}
+	return snap
+}
+
+// redisBackend keeps state in Redis, where every process sees it: a hash per
+// breaker (state, opened_at, probe_until) and sorted sets of the window's calls
+type redisBackend struct {
+	client *redis.Client
+}
+
+func stateKey(name string) string    { return "breaker:" + name }
+func callsKey(name string) string    { return "breaker:" + name + ":calls" }
+func failuresKey(name string) string { return "breaker:" + name + ":failures" }
+
+// allowScript mirrors memoryBackend.allow. ARGV: now, open for and probe timeout (millis).
+var allowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local state = redis.call('HGET', KEYS[1], 'state') or 'closed'
+if state == 'closed' then
+	return {1, 0, state}
+end
+if state == 'open' and now < tonumber(redis.call('HGET', KEYS[1], 'opened_at')) + tonumber(ARGV[2]) then
+	return {0, 0, state}
+end
+if now < tonumber(redis.call('HGET', KEYS[1], 'probe_until') or '0') then
+	return {0, 0, 'half_open'}
+end
+redis.call('HSET', KEYS[1], 'state', 'half_open', 'probe_until', now + tonumber(ARGV[3]))
+return {1, 1, 'half_open'}
+`)
+
+// recordScript mirrors memoryBackend.record. ARGV: now, outcome, probe (1/0),
+// window (millis), minimum calls, failure rate and a unique ID for the call.
+var recordScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local outcome = ARGV[2]
+if ARGV[3] == '1' then
+	if outcome == 'success' then
+		redis.call('DEL', KEYS[1], KEYS[2], KEYS[3])
+		return 'closed'
+	elseif outcome == 'failure' then
+		redis.call('DEL', KEYS[2], KEYS[3])
+		redis.call('HSET', KEYS[1], 'state', 'open', 'opened_at', now, 'probe_until', 0)
+		return 'open'
+	end
+	redis.call('HSET', KEYS[1], 'probe_until', 0)
+	return redis.call('HGET', KEYS[1], 'state') or 'closed'
+end
+local state = redis.call('HGET', KEYS[1], 'state') or 'closed'
+if state ~= 'closed' or outcome == 'ignored' then
+	return state
+end
+local window = tonumber(ARGV[4])
+redis.call('ZADD', KEYS[2], now, ARGV[7])
+if outcome == 'failure' then
+	redis.call('ZADD', KEYS[3], now, ARGV[7])
+end
+for i = 2, 3 do
+	redis.call('ZREMRANGEBYSCORE', KEYS[i], '-inf', now - window)
+	redis.call('PEXPIRE', KEYS[i], window)
+end
+local calls = redis.call('ZCARD', KEYS[2])
+local failures = redis.call('ZCARD', KEYS[3])
+if calls >= tonumber(ARGV[5]) and failures > 0 and failures >= calls * tonumber(ARGV[6]) then
+	redis.call('DEL', KEYS[2], KEYS[3])
+	redis.call('HSET', KEYS[1], 'state', 'open', 'opened_at', now, 'probe_until', 0)
+	return 'open'
+end
+return 'closed'
+`)
+
+func (r *redisBackend) keys(name string) []string {
+	return []string{stateKey(name), callsKey(name), failuresKey(name)}
+}
+
+func (r *redisBackend) allow(ctx context.Context, name string, s Settings, now time.Time) (bool, bool, State, error) {
+	res, err := allowScript.Run(ctx, r.client, []string{stateKey(name)}, now.UnixMilli(), s.OpenFor.Milliseconds(), s.ProbeTimeout.Milliseconds()).Slice()
+	if err != nil {
+		return false, false, StateClosed, err
+	}
+	if len(res) != 3 {
+		return false, false, StateClosed, fmt.Errorf("unexpected breaker reply %v", res)
+	}
+	ok, _ := res[0].(int64)
+	probe, _ := res[1].(int64)
+	state, _ := res[2].(string)
+	return ok == 1, probe == 1, parseState(state), nil
+}
+
+func (r *redisBackend) record(ctx context.Context, name string, s Settings, now time.Time, result outcome, probe bool) (State, error) {
+	isProbe := 0
+	if probe {
+		isProbe = 1
+	}
+	state, err := recordScript.Run(ctx, r.client, r.keys(name), now.UnixMilli(), string(result), isProbe,
+		s.Window.Milliseconds(), s.MinCalls, s.FailureRate, uuid.NewString()).Text()
+	if err != nil {
+		return StateClosed, err
+	}
+	return parseState(state), nil
+}
+
+func (r *redisBackend) snapshot(ctx context.Context, name string, s Settings, now time.Time) (Snapshot, error) {
+	since := fmt.Sprintf("(%d", now.Add(-s.Window).UnixMilli())
+	pipe := r.client.Pipeline()
+	fields := pipe.HMGet(ctx, stateKey(name), "state", "opened_at")
+	calls := pipe.ZCount(ctx, callsKey(name), since, "+inf")
+	failures := pipe.ZCount(ctx, failuresKey(name), since, "+inf")
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Snapshot{}, err
+	}
+	values := fields.Val()
+	state, _ := values[0].(string)
+	var openedAt time.Time
+	if raw, ok := values[1].(string); ok {
+		var ms int64
+		fmt.Sscan(raw, &ms)
+		openedAt = time.UnixMilli(ms)
+	}
+	return newSnapshot(name, s, parseState(state), int(calls.Val()), int(failures.Val()), openedAt), nil
+}
+
+func (r *redisBackend) reset(ctx context.Context, name string) error {
+	return r.client.Del(ctx, r.keys(name)...).Err()
 }
@@ -0,0 +1,216 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errDown         = errors.New("platform down")
+	errNoAccount    = errors.New("credentials missing")
+	testSettings    = Settings{Window: time.Minute, MinCalls: 4, FailureRate: 0.5, OpenFor: 30 * time.Second, ProbeTimeout: 5 * time.Minute, Ignore: ignoreNoAccount}
+	ignoreNoAccount = func(err error) bool { return errors.Is(err, errNoAccount) }
+)
+
+// testClock is a settable clock for breakers
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// testBreakers returns a Redis-backed and a process-local breaker
+func testBreakers(t *testing.T) map[string]*CircuitBreaker {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]*CircuitBreaker{
+		"redis": New("devto", testSettings, client),
+		"local": New("devto", testSettings, nil),
+	}
+}
+
+// clockOf puts the breaker on a clock the test moves
+func clockOf(cb *CircuitBreaker) *testClock {
+	clock := &testClock{now: time.Unix(1_700_000_000, 0)}
+	cb.now = clock.Now
+	return clock
+}
+
+// run executes a call that returns err and reports whether it ran
+func run(cb *CircuitBreaker, err error) bool {
+	ran := false
+	cb.Execute(context.Background(), func() error {
+		ran = true
+		return err
+	})
+	return ran
+}
+
+func TestBreaker_OpensOnFailureRate(t *testing.T) {
+	for name, cb := range testBreakers(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := clockOf(cb)
+			require.True(t, run(cb, errDown))
+			require.True(t, run(cb, nil), "too few calls to judge")
+			require.True(t, run(cb, nil))
+			require.True(t, run(cb, nil))
+			require.True(t, run(cb, errDown))
+			require.True(t, run(cb, errDown), "2 of 5 failed, under the rate")
+
+			err := cb.Execute(context.Background(), func() error { return nil })
+			require.ErrorIs(t, err, ErrCircuitOpen, "3 of 6 failed")
+			snap, err := cb.Snapshot(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, StateOpen, snap.State)
+			require.NotNil(t, snap.ProbeAt)
+			assert.Equal(t, clock.Now().Add(testSettings.OpenFor), *snap.ProbeAt)
+		})
+	}
+}
+
+func TestBreaker_FailuresLeaveTheWindow(t *testing.T) {
+	for name, cb := range testBreakers(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := clockOf(cb)
+			for range 3 {
+				require.True(t, run(cb, errDown))
+			}
+			clock.Advance(2 * time.Minute)
+			for range 3 {
+				require.True(t, run(cb, nil))
+			}
+			require.True(t, run(cb, errDown))
+			assert.True(t, run(cb, nil), "1 of 4 recent calls failed")
+
+			snap, err := cb.Snapshot(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, StateClosed, snap.State)
+			assert.Equal(t, 5, snap.Calls)
+			assert.Equal(t, 1, snap.Failures)
+			assert.InDelta(t, 0.2, snap.FailureRate, 0.001)
+		})
+	}
+}
+
+func TestBreaker_HalfOpenLetsOneProbeThrough(t *testing.T) {
+	for name, cb := range testBreakers(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := clockOf(cb)
+			for range 4 {
+				run(cb, errDown)
+			}
+			require.False(t, run(cb, nil))
+
+			// A failed probe opens the breaker again; calls during the probe are refused
+			clock.Advance(testSettings.OpenFor)
+			probed := cb.Execute(context.Background(), func() error {
+				assert.False(t, run(cb, nil), "one probe at a time")
+				return errDown
+			})
+			require.ErrorIs(t, probed, errDown)
+			assert.False(t, run(cb, nil))
+
+			// A probe abandoned past its timeout lets another call probe
+			clock.Advance(testSettings.OpenFor)
+			cb.Execute(context.Background(), func() error {
+				clock.Advance(testSettings.ProbeTimeout)
+				assert.True(t, run(cb, nil), "the second probe closes the breaker")
+				return nil
+			})
+
+			snap, err := cb.Snapshot(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, StateClosed, snap.State)
+			assert.Nil(t, snap.OpenedAt)
+			assert.Zero(t, snap.Calls, "closing starts a new window")
+		})
+	}
+}
+
+func TestBreaker_IgnoredErrors(t *testing.T) {
+	for name, cb := range testBreakers(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := clockOf(cb)
+			for range 10 {
+				require.True(t, run(cb, errNoAccount))
+			}
+			snap, err := cb.Snapshot(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, StateClosed, snap.State)
+			assert.Zero(t, snap.Calls)
+
+			// An ignored probe neither closes nor reopens; the next call probes
+			for range 4 {
+				run(cb, errDown)
+			}
+			clock.Advance(testSettings.OpenFor)
+			require.True(t, run(cb, errNoAccount))
+			snap, err = cb.Snapshot(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, StateHalfOpen, snap.State)
+			assert.True(t, run(cb, nil))
+			assert.True(t, run(cb, errDown), "closed again")
+		})
+	}
+}
+
+func TestBreaker_SharedThroughRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	worker := NewGroup(testSettings, client, "medium", "devto")
+	api := NewGroup(testSettings, client, "medium", "devto")
+	for range 4 {
+		run(worker.Get("medium"), errDown)
+	}
+
+	snaps, err := api.Snapshots(ctx)
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	assert.Equal(t, "devto", snaps[0].Name)
+	assert.Equal(t, StateClosed, snaps[0].State)
+	assert.Equal(t, "medium", snaps[1].Name)
+	assert.Equal(t, StateOpen, snaps[1].State)
+
+	require.NoError(t, api.Reset(ctx, "medium"))
+	assert.True(t, run(worker.Get("medium"), nil), "reset in one process, closed in all")
+	assert.ErrorIs(t, api.Reset(ctx, "linkedin"), ErrUnknownBreaker)
+	assert.Nil(t, api.Get("linkedin"))
+	assert.True(t, run(api.Get("linkedin"), errDown), "a nil breaker guards nothing")
+}
+
+func TestBreaker_FallsBackWhenRedisIsDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	mr.Close()
+
+	cb := New("devto", testSettings, client)
+	for range 4 {
+		require.True(t, run(cb, errDown))
+	}
+	assert.False(t, run(cb, nil))
+}
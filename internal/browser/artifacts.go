@@ -0,0 +1,266 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"postificus/internal/storage"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	// captureTimeout bounds taking the screenshot and DOM snapshot of a failed run
+	captureTimeout = 15 * time.Second
+	// uploadTimeout bounds saving a failed run's artifacts
+	uploadTimeout = 30 * time.Second
+	// localArtifactsDir keeps the artifacts when object storage isn't configured
+	localArtifactsDir = "failure-artifacts"
+	// maxConsoleLines keeps a chatty page from filling the worker's memory
+	maxConsoleLines = 500
+)
+
+// Artifacts is the debugging trail of a browser automation run: its step
+// timeline and console output as it goes, and a screenshot and DOM snapshot
+// when it fails. A failed run saves them all under its key, to the private
+// storage.ArtifactsBucket when one is configured and to failure-artifacts/
+// otherwise.
+type Artifacts struct {
+	key string
+
+	mu      sync.Mutex
+	started time.Time
+	steps   []traceStep
+	console []string
+	url     string
+}
+
+// traceStep is one entry of the timeline
+type traceStep struct {
+	Step       string `json:"step"`
+	AtMs       int64  `json:"at_ms"` // Since the run started
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// NewArtifacts collects a run's artifacts for saving under key, e.g. "publish/<job>/attempt-2"
+func NewArtifacts(key string) *Artifacts {
+	return &Artifacts{key: strings.Trim(key, "/")}
+}
+
+type artifactsKey struct{}
+
+// WithArtifacts has the automation functions run with ctx record into a.
+// Runs without one still save their artifacts, under a key of their own.
+func WithArtifacts(ctx context.Context, a *Artifacts) context.Context {
+	return context.WithValue(ctx, artifactsKey{}, a)
+}
+
+func artifactsFrom(ctx context.Context) *Artifacts {
+	a, _ := ctx.Value(artifactsKey{}).(*Artifacts)
+	return a
+}
+
+// URL is where a failed run's artifacts were saved: s3://bucket/key/, or a
+// file:// directory without object storage. It is "" until then.
+func (a *Artifacts) URL() string {
+	if a == nil {
+		return ""
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.url
+}
+
+// step adds a finished step to the timeline
+func (a *Artifacts) step(name string, start time.Time, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry := traceStep{Step: name, AtMs: start.Sub(a.started).Milliseconds(), DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	a.steps = append(a.steps, entry)
+}
+
+func (a *Artifacts) log(line string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.console) < maxConsoleLines {
+		a.console = append(a.console, line)
+	}
+}
+
+// traced adds the step to the timeline of the run ctx belongs to; defer it at
+// the start of the step: defer traced(page.GetContext(), "click", time.Now(), &err)
+func traced(ctx context.Context, name string, start time.Time, err *error) {
+	if a := artifactsFrom(ctx); a != nil {
+		a.step(name, start, *err)
+	}
+}
+
+// run is one automation run being recorded
+type run struct {
+	name      string
+	artifacts *Artifacts
+	page      *rod.Page
+	stop      context.CancelFunc
+}
+
+// startRun binds the tab to ctx and starts recording the run's timeline and
+// console output; defer the run's finish with the error the run returns.
+func startRun(ctx context.Context, tab *rod.Page, name string) (*rod.Page, *run) {
+	a := artifactsFrom(ctx)
+	if a == nil {
+		a = NewArtifacts(fmt.Sprintf("runs/%s/%s", name, time.Now().UTC().Format("20060102-150405.000")))
+		ctx = WithArtifacts(ctx, a)
+	}
+	a.mu.Lock()
+	a.started, a.steps, a.console = time.Now(), nil, nil
+	a.mu.Unlock()
+
+	// Every call on page fails once ctx is cancelled, so shutdown stops the run
+	page := tab.Context(ctx)
+
+	listen, stop := context.WithCancel(ctx)
+	go page.Context(listen).EachEvent(func(e *proto.RuntimeConsoleAPICalled) {
+		a.log(consoleLine(e))
+	}, func(e *proto.RuntimeExceptionThrown) {
+		a.log(exceptionLine(e))
+	})()
+
+	return page, &run{name: name, artifacts: a, page: page, stop: stop}
+}
+
+// finish stops recording; if the run failed, it captures the page and saves the artifacts.
+// Saving is best effort: a failure to is logged, never returned.
+func (r *run) finish(err error) {
+	r.stop()
+	if err == nil || errors.Is(err, context.Canceled) {
+		// A cancelled run was interrupted, not broken
+		return
+	}
+
+	// The job's context may be what ran out; capturing gets time of its own
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.page.GetContext()), captureTimeout)
+	defer cancel()
+	page := r.page.Context(ctx)
+
+	files := make(map[string]artifactFile)
+	if shot, shotErr := page.Screenshot(true, nil); shotErr == nil {
+		files["screenshot.png"] = artifactFile{shot, "image/png"}
+	} else {
+		log.Printf("⚠️ Couldn't capture a screenshot of the failed %s run: %v", r.name, shotErr)
+	}
+	if html, htmlErr := page.HTML(); htmlErr == nil {
+		files["dom.html"] = artifactFile{[]byte(html), "text/html; charset=utf-8"}
+	} else {
+		log.Printf("⚠️ Couldn't capture the DOM of the failed %s run: %v", r.name, htmlErr)
+	}
+	pageURL := ""
+	if info, infoErr := page.Info(); infoErr == nil {
+		pageURL = info.URL
+	}
+
+	a := r.artifacts
+	a.mu.Lock()
+	timeline, _ := json.MarshalIndent(map[string]interface{}{
+		"run":      r.name,
+		"started":  a.started,
+		"failed":   time.Now(),
+		"page_url": pageURL,
+		"error":    err.Error(),
+		"steps":    a.steps,
+	}, "", "  ")
+	files["timeline.json"] = artifactFile{timeline, "application/json"}
+	files["console.log"] = artifactFile{[]byte(strings.Join(a.console, "\n")), "text/plain; charset=utf-8"}
+	a.mu.Unlock()
+
+	uploadCtx, cancelUpload := context.WithTimeout(context.WithoutCancel(r.page.GetContext()), uploadTimeout)
+	defer cancelUpload()
+	url, saveErr := saveArtifacts(uploadCtx, a.key, files)
+	if saveErr != nil {
+		log.Printf("⚠️ Failed to save the artifacts of the failed %s run: %v", r.name, saveErr)
+		return
+	}
+	a.mu.Lock()
+	a.url = url
+	a.mu.Unlock()
+	log.Printf("🧾 Saved the artifacts of the failed %s run to %s", r.name, url)
+}
+
+type artifactFile struct {
+	data        []byte
+	contentType string
+}
+
+// ArtifactsUploaded reports whether failed runs' artifacts go to the artifacts
+// bucket. Otherwise they only land in failure-artifacts/ on this machine's disk.
+func ArtifactsUploaded() bool {
+	return storage.S3Client != nil && storage.ArtifactsBucket != ""
+}
+
+// saveArtifacts uploads the files under key and returns where they are
+func saveArtifacts(ctx context.Context, key string, files map[string]artifactFile) (string, error) {
+	// Never the public uploads bucket: the page may show the account's session
+	if !ArtifactsUploaded() {
+		return saveArtifactsLocally(key, files)
+	}
+	for name, file := range files {
+		if _, err := storage.PutArtifact(ctx, key+"/"+name, file.data, file.contentType); err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return fmt.Sprintf("s3://%s/%s/", storage.ArtifactsBucket, key), nil
+}
+
+// saveArtifactsLocally is the fallback without an artifacts bucket, for local development
+func saveArtifactsLocally(key string, files map[string]artifactFile) (string, error) {
+	dir, err := filepath.Abs(filepath.Join(localArtifactsDir, filepath.FromSlash(key)))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	for name, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), file.data, 0o644); err != nil {
+			return "", err
+		}
+	}
+	return "file://" + filepath.ToSlash(dir) + "/", nil
+}
+
+// consoleLine renders a console call the way DevTools prints it
+func consoleLine(e *proto.RuntimeConsoleAPICalled) string {
+	args := make([]string, 0, len(e.Args))
+	for _, arg := range e.Args {
+		switch {
+		case !arg.Value.Nil():
+			args = append(args, arg.Value.String())
+		case arg.Description != "":
+			args = append(args, arg.Description)
+		default:
+			args = append(args, string(arg.Type))
+		}
+	}
+	return fmt.Sprintf("%s [%s] %s", time.UnixMilli(int64(e.Timestamp)).UTC().Format(time.RFC3339Nano), e.Type, strings.Join(args, " "))
+}
+
+// exceptionLine renders an uncaught exception of the page
+func exceptionLine(e *proto.RuntimeExceptionThrown) string {
+	text := e.ExceptionDetails.Text
+	if e.ExceptionDetails.Exception != nil && e.ExceptionDetails.Exception.Description != "" {
+		text = e.ExceptionDetails.Exception.Description
+	}
+	return fmt.Sprintf("%s [exception] %s", time.UnixMilli(int64(e.Timestamp)).UTC().Format(time.RFC3339Nano), text)
+}
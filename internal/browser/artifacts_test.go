@@ -0,0 +1,80 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"postificus/internal/storage"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifacts_RecordTheTimeline(t *testing.T) {
+	a := NewArtifacts("/publish/job-1/attempt-2/")
+	a.started = time.Now()
+	ctx := WithArtifacts(context.Background(), a)
+
+	step := func(name string, err error) {
+		defer traced(ctx, name, time.Now(), &err)
+	}
+	step("navigate to https://dev.to/new", nil)
+	step("find #article_tags", ErrSelectorNotFound)
+	traced(context.Background(), "untraced", time.Now(), new(error))
+
+	require.Len(t, a.steps, 2)
+	assert.Equal(t, "navigate to https://dev.to/new", a.steps[0].Step)
+	assert.Empty(t, a.steps[0].Error)
+	assert.Equal(t, "selector not found", a.steps[1].Error)
+	assert.Equal(t, "publish/job-1/attempt-2", a.key)
+	assert.Empty(t, a.URL(), "nothing saved yet")
+	assert.Empty(t, (*Artifacts)(nil).URL())
+}
+
+func TestSaveArtifacts_FallsBackToDisk(t *testing.T) {
+	t.Chdir(t.TempDir())
+	url, err := saveArtifacts(context.Background(), "publish/job-1/attempt-1", map[string]artifactFile{
+		"console.log": {[]byte("[error] boom"), "text/plain"},
+		"dom.html":    {[]byte("<html></html>"), "text/html"},
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(url, "file://"), url)
+
+	dir := filepath.FromSlash(strings.TrimPrefix(url, "file://"))
+	console, err := os.ReadFile(filepath.Join(dir, "console.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "[error] boom", string(console))
+	assert.FileExists(t, filepath.Join(dir, "dom.html"))
+	assert.True(t, strings.HasSuffix(filepath.ToSlash(dir), "failure-artifacts/publish/job-1/attempt-1/"))
+}
+
+func TestSaveArtifacts_NeverUsesTheUploadsBucket(t *testing.T) {
+	t.Chdir(t.TempDir())
+	client, err := minio.New("localhost:9000", &minio.Options{Creds: credentials.NewStaticV4("key", "secret", "")})
+	require.NoError(t, err)
+	restore := storage.S3Client
+	storage.S3Client, storage.S3Bucket, storage.ArtifactsBucket = client, "postificus-uploads", ""
+	t.Cleanup(func() { storage.S3Client, storage.S3Bucket = restore, "" })
+
+	url, err := saveArtifacts(context.Background(), "publish/job-1/attempt-1", map[string]artifactFile{
+		"dom.html": {[]byte("<html></html>"), "text/html"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(url, "file://"), "without ARTIFACTS_BUCKET artifacts stay on disk, got %s", url)
+}
+
+func TestRunFinish_SkipsSuccessAndCancellation(t *testing.T) {
+	for _, err := range []error{nil, context.Canceled, errors.Join(errors.New("navigate"), context.Canceled)} {
+		a := NewArtifacts("runs/x")
+		r := &run{name: "x", artifacts: a, stop: func() {}}
+		r.finish(err) // Would need a page if it tried to capture
+		assert.Empty(t, a.URL())
+	}
+}
@@ -3,16 +3,10 @@ package browser
 import (
 	"os"
 	"path/filepath"
-	"sync"
 
-	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 )
 
-var Browser *rod.Browser
-var browserOnce sync.Once
-var browserInitErr error
-
 // GetHardenedLauncher returns a launcher with anti-detection flags pre-configured
 func GetHardenedLauncher(headless bool, useFirefox bool) *launcher.Launcher {
 	l := launcher.New()
@@ -68,39 +62,3 @@ func GetHardenedLauncher(headless bool, useFirefox bool) *launcher.Launcher {
 
 	return l
 }
-
-// InitBrowser launches a global browser instance.
-func InitBrowser() error {
-	// Check if running in production (Render sets PORT, Docker sets APP_ENV)
-	isProduction := os.Getenv("PORT") != "" || os.Getenv("APP_ENV") == "production"
-
-	headless := isProduction
-	// Allow manual override via env var
-	if val := os.Getenv("BROWSER_HEADLESS"); val != "" {
-		headless = (val == "true" || val == "1")
-	}
-
-	// 1. Create a "Clean" Launcher using shared logic (Default to Chrome for background tasks)
-	l := GetHardenedLauncher(headless, false)
-
-	// 3. Launch
-	u := l.MustLaunch()
-
-	Browser = rod.New().ControlURL(u).MustConnect()
-	return nil
-}
-
-// CloseBrowser closes the global browser instance.
-func CloseBrowser() {
-	if Browser != nil {
-		Browser.MustClose()
-	}
-}
-
-// EnsureBrowser initializes the shared browser exactly once.
-func EnsureBrowser() error {
-	browserOnce.Do(func() {
-		browserInitErr = InitBrowser()
-	})
-	return browserInitErr
-}
@@ -1,6 +1,8 @@
 package browser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"regexp"
@@ -8,31 +10,63 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 )
 
+const (
+	// loginTimeout is how long the user gets to log in
+	loginTimeout = 3 * time.Minute
+	// profileTimeout bounds scraping the account name once logged in
+	profileTimeout = 10 * time.Second
+)
+
+var mediumHandle = regexp.MustCompile(`@([\w\.\-]+)`)
+
+// openLoginWindow launches a visible browser on the platform's login page
+func openLoginWindow(url string) (*instance, *rod.Page, error) {
+	inst, err := launchInstance(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, err := inst.browser.Page(proto.TargetCreateTarget{URL: url})
+	if err != nil {
+		inst.close()
+		return nil, nil, fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	return inst, page, nil
+}
+
+// loginWaitError explains why waiting for a login ended: the user took too long, or the request went away
+func loginWaitError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("login timeout: user took too long: %w", ErrTimeout)
+	}
+	return ctx.Err()
+}
+
 // WaitForMediumLogin launches a visible browser window and waits for the user to log in.
 // It returns the uid, sid, xsrf, and username/name once detected.
-func WaitForMediumLogin() (string, string, string, string, error) {
+func WaitForMediumLogin(ctx context.Context) (string, string, string, string, error) {
 	log.Println("🚀 Launching validation browser for Medium login...")
 
-	u := GetHardenedLauncher(false, false).MustLaunch()
-
-	browser := rod.New().ControlURL(u).MustConnect()
-	defer browser.MustClose()
-
-	page := browser.MustPage("https://medium.com/m/signin")
+	inst, page, err := openLoginWindow("https://medium.com/m/signin")
+	if err != nil {
+		return "", "", "", "", err
+	}
+	defer inst.close()
 
 	log.Println("⏳ Waiting for user to log in...")
 	fmt.Println("👉 Please log in to Medium in the opened window.")
 
-	timeout := time.After(3 * time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-timeout:
-			return "", "", "", "", fmt.Errorf("login timeout: user took too long")
+		case <-ctx.Done():
+			return "", "", "", "", loginWaitError(ctx)
 		case <-ticker.C:
 			// 1. Check if browser is alive
 			info, err := page.Info()
@@ -66,47 +100,10 @@ func WaitForMediumLogin() (string, string, string, string, error) {
 			if uid != "" && sid != "" && xsrf != "" {
 				log.Println("✅ Login detected! Fetching account info...")
 
-				// Scraping Logic with Timeout
-				username := "Medium User"
-
-				// We fork a cleanup context for the scraping actions to ensure we don't hang
-				err := rod.Try(func() {
-					// Navigate to /me which redirects to /@username
-					page.Timeout(10 * time.Second).MustNavigate("https://medium.com/me")
-					page.Timeout(10 * time.Second).MustWaitLoad()
-
-					// 1. Get Handle from URL
-					// URL will be like https://medium.com/@myhandle
-					// or https://medium.com/@myhandle/
-					finalURL := page.MustInfo().URL
-					if len(finalURL) > 20 && finalURL[len(finalURL)-1] == '/' {
-						finalURL = finalURL[:len(finalURL)-1]
-					}
-
-					// Extract handle
-					// Simple implementation: split by '@'
-					parts := regexp.MustCompile(`@([\w\.\-]+)`).FindStringSubmatch(finalURL)
-					handle := ""
-					if len(parts) > 1 {
-						handle = parts[1]
-					}
-
-					// 2. Get Name from Title
-					// Title is usually "My Name – Medium"
-					title := page.MustInfo().Title
-					name := strings.TrimSuffix(title, " – Medium")
-
-					if name != "" && handle != "" {
-						username = fmt.Sprintf("%s (@%s)", name, handle)
-					} else if handle != "" {
-						username = "@" + handle
-					} else if name != "" {
-						username = name
-					}
-				})
-
+				username, err := scrapeMediumUsername(page.Context(ctx))
 				if err != nil {
 					log.Printf("⚠️ Failed to scrape Medium username: %v", err)
+					username = "Medium User"
 				}
 
 				return uid, sid, xsrf, username, nil
@@ -115,29 +112,69 @@ func WaitForMediumLogin() (string, string, string, string, error) {
 	}
 }
 
+// scrapeMediumUsername reads "Name (@handle)" off the user's profile page
+func scrapeMediumUsername(page *rod.Page) (string, error) {
+	page = page.Timeout(profileTimeout)
+	defer page.CancelTimeout()
+
+	// Navigate to /me which redirects to /@username
+	if err := page.Navigate("https://medium.com/me"); err != nil {
+		return "", stepError("open the profile", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return "", stepError("load the profile", err)
+	}
+	info, err := page.Info()
+	if err != nil {
+		return "", stepError("read the profile", err)
+	}
+
+	// 1. Get Handle from URL
+	// URL will be like https://medium.com/@myhandle
+	// or https://medium.com/@myhandle/
+	handle := ""
+	if parts := mediumHandle.FindStringSubmatch(strings.TrimSuffix(info.URL, "/")); len(parts) > 1 {
+		handle = parts[1]
+	}
+
+	// 2. Get Name from Title
+	// Title is usually "My Name – Medium"
+	name := strings.TrimSuffix(info.Title, " – Medium")
+
+	switch {
+	case name != "" && handle != "":
+		return fmt.Sprintf("%s (@%s)", name, handle), nil
+	case handle != "":
+		return "@" + handle, nil
+	case name != "":
+		return name, nil
+	}
+	return "", fmt.Errorf("profile page has no name: %w", ErrSelectorNotFound)
+}
+
 // WaitForDevToLogin launches a visible browser window and waits for the user to log in to Dev.to.
 // It returns the remember_user_token cookie and username once detected.
-func WaitForDevToLogin() (string, string, error) {
+func WaitForDevToLogin(ctx context.Context) (string, string, error) {
 	log.Println("🚀 Launching validation browser for Dev.to login...")
 
-	u := GetHardenedLauncher(false, false).MustLaunch()
-
-	browser := rod.New().ControlURL(u).MustConnect()
-	defer browser.MustClose()
-
-	page := browser.MustPage("https://dev.to/enter")
+	inst, page, err := openLoginWindow("https://dev.to/enter")
+	if err != nil {
+		return "", "", err
+	}
+	defer inst.close()
 
 	log.Println("⏳ Waiting for user to log in...")
 	fmt.Println("👉 Please log in to Dev.to in the opened window.")
 
-	timeout := time.After(3 * time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-timeout:
-			return "", "", fmt.Errorf("login timeout: user took too long")
+		case <-ctx.Done():
+			return "", "", loginWaitError(ctx)
 		case <-ticker.C:
 			// 1. Check browser
 			_, err := page.Info()
@@ -155,40 +192,10 @@ func WaitForDevToLogin() (string, string, error) {
 				if c.Name == "remember_user_token" && c.Value != "" {
 					log.Println("✅ Dev.to Login detected! Fetching account info...")
 
-					username := "Dev.to User"
-
-					// Scraping with Timeout
-					err := rod.Try(func() {
-						page.Timeout(10 * time.Second).MustNavigate("https://dev.to/settings")
-						page.Timeout(10 * time.Second).MustWaitLoad()
-
-						// Try explicit inputs
-						// user_name is the "Name" field
-						// user_username is the "Username" field
-
-						var name, handle string
-
-						// Use Race to find elements or timeout
-						page.Race().
-							Element("input[name='user[name]']").MustHandle(func(e *rod.Element) {
-							name = e.MustProperty("value").String()
-						}).
-							Element("input[name='user[username]']").MustHandle(func(e *rod.Element) {
-							handle = e.MustProperty("value").String()
-						}).
-							MustDo()
-
-						if name != "" && handle != "" {
-							username = fmt.Sprintf("%s (@%s)", name, handle)
-						} else if name != "" {
-							username = name
-						} else if handle != "" {
-							username = "@" + handle
-						}
-					})
-
+					username, err := scrapeDevtoUsername(page.Context(ctx))
 					if err != nil {
 						log.Printf("⚠️ Failed to scrape Dev.to username: %v", err)
+						username = "Dev.to User"
 					}
 
 					return c.Value, username, nil
@@ -197,3 +204,34 @@ func WaitForDevToLogin() (string, string, error) {
 		}
 	}
 }
+
+// scrapeDevtoUsername reads "Name (@handle)" off the settings page
+func scrapeDevtoUsername(page *rod.Page) (string, error) {
+	if err := navigate(page, "https://dev.to/settings"); err != nil {
+		return "", err
+	}
+
+	// Try explicit inputs
+	// user[name] is the "Name" field
+	// user[username] is the "Username" field
+	var name, handle string
+	for field, value := range map[string]*string{"input[name='user[name]']": &name, "input[name='user[username]']": &handle} {
+		input, err := element(page, field)
+		if err != nil {
+			continue
+		}
+		if prop, err := input.Property("value"); err == nil {
+			*value = prop.String()
+		}
+	}
+
+	switch {
+	case name != "" && handle != "":
+		return fmt.Sprintf("%s (@%s)", name, handle), nil
+	case name != "":
+		return name, nil
+	case handle != "":
+		return "@" + handle, nil
+	}
+	return "", fmt.Errorf("settings page has no name: %w", ErrSelectorNotFound)
+}
@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -9,28 +10,38 @@ import (
 	"github.com/go-rod/rod/lib/proto"
 )
 
-// Browser logic moved to browser.go
+// devtoLoginURL is where Dev.to sends requests without a valid session
+const devtoLoginURL = "https://dev.to/enter"
 
 // PostToDevToWithCookie bypasses login by injecting a valid session token.
-func PostToDevToWithCookie(sessionToken, title, content, coverImage string, tags []string) error {
+func PostToDevToWithCookie(ctx context.Context, sessionToken, title, content, coverImage string, meta PostMetadata) (err error) {
+	tags := meta.Tags
 	log.Println("Starting PostToDevToWithCookie...")
 	log.Println("Starting PostToDevToWithCookie...")
-	if err := EnsureBrowser(); err != nil {
+	// 1. LEASE A PRIVATE CONTEXT FROM THE POOL
+	// Instead of launching a new browser, we open a tab in an incognito context
+	// of a pooled one: fast, and no other job's cookies are visible in it.
+	lease, err := DefaultPool().Acquire(ctx)
+	if err != nil {
 		return fmt.Errorf("browser init failed: %w", err)
 	}
-
-	// 1. USE THE SHARED BROWSER
-	// Instead of launching a new browser, we just open a new TAB (Page)
-	// This is instant and uses very little memory.
-	log.Println("Opening new page...")
-	page := Browser.MustPage("https://dev.to/404")
-
-	// CRITICAL: Ensure we close THIS TAB when done, not the whole browser
+	// CRITICAL: Release closes the context with its tab, not the whole browser
 	defer func() {
 		log.Println("Closing page...")
-		page.MustClose()
+		lease.Release()
 	}()
 
+	log.Println("Opening new page...")
+	tab, err := lease.Page("https://dev.to/404")
+	if err != nil {
+		return fmt.Errorf("failed to open page: %w", err)
+	}
+
+	// Every call on page fails once ctx is cancelled, so shutdown stops the typing;
+	// a failed run leaves a screenshot, the DOM and its timeline behind
+	page, run := startRun(ctx, tab, "devto-publish")
+	defer func() { run.finish(err) }()
+
 	// 2. Cookie Injection
 	log.Println("Injecting cookies...")
 	cookie := &proto.NetworkCookieParam{
@@ -49,23 +60,29 @@ func PostToDevToWithCookie(sessionToken, title, content, coverImage string, tags
 
 	// 3. Navigate
 	log.Println("Navigating to editor (https://dev.to/new)...")
-	page.MustNavigate("https://dev.to/new")
-
-	log.Println("Waiting for load...")
-	page.MustWaitLoad()
-
-	if page.MustInfo().URL == "https://dev.to/enter" {
-		return fmt.Errorf("COOKIE EXPIRED: Session token invalid")
+	if err := navigate(page, "https://dev.to/new"); err != nil {
+		return err
+	}
+	if err := checkLanding(page, devtoLoginURL); err != nil {
+		return err
 	}
 
 	log.Println("Session valid! Writing post...")
 
 	// 4. Fill Content
 	log.Println("Waiting for stable...")
-	page.MustWaitStable()
+	if err := waitStable(page); err != nil {
+		return err
+	}
 
 	log.Println("Inputting title...")
-	page.MustElement("textarea[placeholder='New post title here...']").MustInput(title)
+	titleInput, err := element(page, "textarea[placeholder='New post title here...']")
+	if err != nil {
+		return err
+	}
+	if err := titleInput.Input(title); err != nil {
+		return stepError("type the title", err)
+	}
 
 	if coverImage != "" {
 		if err := uploadDevtoCoverImage(page, coverImage); err != nil {
@@ -74,56 +91,71 @@ func PostToDevToWithCookie(sessionToken, title, content, coverImage string, tags
 	}
 
 	log.Println("Inputting content...")
-	page.MustElement("#article_body_markdown").MustInput(content)
+	body, err := element(page, "#article_body_markdown")
+	if err != nil {
+		return err
+	}
+	if err := body.Input(content); err != nil {
+		return stepError("type the body", err)
+	}
 
 	// 5. Handle Tags (THE FIX)
 	// DO NOT PRESS ENTER. It submits the form prematurely.
 	log.Println("Adding tags...")
 
-	// Find input (robust race with wait)
-	var tagInput *rod.Element
-	err := rod.Try(func() {
-		tagInput = page.Race().
-			Element("#article_tags").MustHandle(func(e *rod.Element) {
-			tagInput = e
-		}).
-			Element("input[placeholder*='tags']").MustHandle(func(e *rod.Element) {
-			tagInput = e
-		}).
-			MustDo()
-	})
-
+	// Find input (whichever of the editor's versions is on the page)
+	tagInput, err := element(page, "#article_tags", "input[placeholder*='tags']")
 	if err != nil {
-		log.Println("❌ Failed to find tag input. Capturing screenshot...")
-		page.MustScreenshot("debug_tags_missing.png")
+		log.Println("❌ Failed to find tag input")
 		return fmt.Errorf("could not find tag input: %w", err)
 	}
 
-	// Input tags
-	if len(tags) > 0 {
-		for _, tag := range tags {
-			log.Printf("Adding tag: %s", tag)
-			tagInput.MustInput(tag).MustBlur()
-			// Wait a bit for the tag to be processed
-			time.Sleep(200 * time.Millisecond)
-			// Re-focus for next tag if needed, but usually inputting appends
-			tagInput.MustClick()
+	// Default tag if none provided
+	if len(tags) == 0 {
+		tags = []string{"automation"}
+	}
+	for _, tag := range tags {
+		log.Printf("Adding tag: %s", tag)
+		if err := tagInput.Input(tag); err != nil {
+			return stepError("type tag "+tag, err)
+		}
+		if err := tagInput.Blur(); err != nil {
+			return stepError("confirm tag "+tag, err)
+		}
+		// Wait a bit for the tag to be processed
+		time.Sleep(200 * time.Millisecond)
+		// Re-focus for next tag if needed, but usually inputting appends
+		if err := click(tagInput, "focus the tag input"); err != nil {
+			return err
 		}
-	} else {
-		// Default tag if none provided
-		log.Println("Adding default tag: automation")
-		tagInput.MustInput("automation").MustBlur()
 	}
 
 	// Wait for the UI to settle after blurring (Dev.to does JS processing here)
-	page.MustWaitStable()
+	if err := waitStable(page); err != nil {
+		return err
+	}
+
+	if meta.Series != "" {
+		if err := setDevtoSeries(page, meta.Series); err != nil {
+			// Publishing without the series beats failing the whole post
+			log.Printf("⚠️ Failed to set series: %v", err)
+		}
+	}
 
 	// 6. Publish
 	log.Println("Publishing...")
 	// User provided selector: <button type="button" class="c-btn c-btn--primary mr-2 whitespace-nowrap">Publish</button>
 	// We target the primary button with text "Publish"
-	saveBtn := page.MustElementR("button.c-btn.c-btn--primary", "Publish")
-	saveBtn.MustWaitVisible().MustClick()
+	saveBtn, err := elementR(page, "button.c-btn.c-btn--primary", "Publish")
+	if err != nil {
+		return err
+	}
+	if err := saveBtn.WaitVisible(); err != nil {
+		return stepError("wait for the publish button", err)
+	}
+	if err := click(saveBtn, "click publish"); err != nil {
+		return err
+	}
 
 	// 7. Robust Wait Loop
 	log.Println("Waiting for save to complete...")
@@ -151,13 +183,18 @@ func PostToDevToWithCookie(sessionToken, title, content, coverImage string, tags
 
 		// Check Error
 		if has, el, _ := page.Has(".crayons-toast--error"); has {
-			return fmt.Errorf("❌ Dev.to Error: %s", el.MustText())
+			text, _ := el.Text()
+			return fmt.Errorf("❌ Dev.to Error: %s", text)
 		}
 
-		time.Sleep(500 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
 	}
 
-	return fmt.Errorf("timeout waiting for save confirmation")
+	return fmt.Errorf("waiting for save confirmation: %w", ErrTimeout)
 }
 
 func uploadDevtoCoverImage(page *rod.Page, coverImage string) error {
@@ -186,3 +223,37 @@ func uploadDevtoCoverImage(page *rod.Page, coverImage string) error {
 	time.Sleep(700 * time.Millisecond)
 	return nil
 }
+
+// setDevtoSeries fills the series field in the editor's "Post options" panel
+func setDevtoSeries(page *rod.Page, series string) error {
+	log.Printf("Setting series: %s", series)
+	options, err := elementR(page, "button", "(?i)post options")
+	if err != nil {
+		return err
+	}
+	if err := click(options, "open post options"); err != nil {
+		return err
+	}
+
+	input, err := element(page, "#series, input[name='series'], input[placeholder*='series' i]")
+	if err != nil {
+		return err
+	}
+	if err := input.SelectAllText(); err != nil {
+		return stepError("select the series", err)
+	}
+	if err := input.Input(series); err != nil {
+		return stepError("type the series", err)
+	}
+	if err := input.Blur(); err != nil {
+		return stepError("confirm the series", err)
+	}
+
+	// Close the panel again so the publish button is reachable
+	if has, done, _ := page.HasR("button", "^Done$"); has {
+		if err := click(done, "close post options"); err != nil {
+			return err
+		}
+	}
+	return waitStable(page)
+}
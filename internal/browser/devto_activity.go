@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -23,17 +24,23 @@ type DevtoPost struct {
 
 // FetchDevtoDashboardPosts scrapes the Dev.to dashboard using a session cookie.
 // It returns a best-effort list of the user's posts with basic metadata.
-func FetchDevtoDashboardPosts(sessionToken string, limit int) ([]DevtoPost, error) {
+func FetchDevtoDashboardPosts(ctx context.Context, sessionToken string, limit int) (posts []DevtoPost, err error) {
 	if sessionToken == "" {
 		return nil, fmt.Errorf("devto session token missing")
 	}
 
-	if err := EnsureBrowser(); err != nil {
+	lease, err := DefaultPool().Acquire(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to initialize browser: %w", err)
 	}
+	defer lease.Release()
 
-	page := Browser.MustPage("https://dev.to/404")
-	defer page.MustClose()
+	tab, err := lease.Page("https://dev.to/404")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open page: %w", err)
+	}
+	page, run := startRun(ctx, tab, "devto-dashboard")
+	defer func() { run.finish(err) }()
 
 	cookie := &proto.NetworkCookieParam{
 		Name:     "remember_user_token",
@@ -49,15 +56,17 @@ func FetchDevtoDashboardPosts(sessionToken string, limit int) ([]DevtoPost, erro
 		return nil, fmt.Errorf("failed to set cookie: %w", err)
 	}
 
-	page.MustNavigate("https://dev.to/dashboard")
-	page.MustWaitLoad()
-	page.MustWaitStable()
-
-	if page.MustInfo().URL == "https://dev.to/enter" {
-		return nil, fmt.Errorf("cookie expired: session token invalid")
+	if err := navigate(page, "https://dev.to/dashboard"); err != nil {
+		return nil, err
+	}
+	if err := waitStable(page); err != nil {
+		return nil, err
+	}
+	if err := checkLanding(page, devtoLoginURL); err != nil {
+		return nil, err
 	}
 
-	posts, err := extractDevtoDashboardPosts(page)
+	posts, err = extractDevtoDashboardPosts(page)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +170,7 @@ func firstText(el *rod.Element, selectors []string) string {
 		if err != nil {
 			continue
 		}
-		text := strings.TrimSpace(child.MustText())
+		text := strings.TrimSpace(textOf(child))
 		if text != "" {
 			return text
 		}
@@ -169,6 +178,15 @@ func firstText(el *rod.Element, selectors []string) string {
 	return ""
 }
 
+// textOf is the element's text, or "" when it can't be read (the scrape is best-effort)
+func textOf(el *rod.Element) string {
+	text, err := el.Text()
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
 func firstAttr(el *rod.Element, selectors []string, attr string) string {
 	for _, sel := range selectors {
 		child, err := el.Element(sel)
@@ -185,7 +203,7 @@ func firstAttr(el *rod.Element, selectors []string, attr string) string {
 
 func findDevtoStatus(el *rod.Element) string {
 	if strong, err := el.Element(".js-dashboard-story-details strong"); err == nil {
-		label := strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strong.MustText(), ":")))
+		label := strings.ToLower(strings.TrimSpace(strings.TrimSuffix(textOf(strong), ":")))
 		switch label {
 		case "published":
 			return "published"
@@ -202,7 +220,7 @@ func findDevtoStatus(el *rod.Element) string {
 
 	labels, _ := el.Elements(".crayons-pill, .crayons-tag, .crayons-badge, .dashboard-article__status, .article-status")
 	for _, label := range labels {
-		text := strings.ToLower(strings.TrimSpace(label.MustText()))
+		text := strings.ToLower(strings.TrimSpace(textOf(label)))
 		switch {
 		case strings.Contains(text, "draft"):
 			return "draft"
@@ -224,7 +242,7 @@ func findDevtoTimestamp(el *rod.Element) string {
 		if dt, err := t.Attribute("datetime"); err == nil && dt != nil && strings.TrimSpace(*dt) != "" {
 			return strings.TrimSpace(*dt)
 		}
-		text := strings.TrimSpace(t.MustText())
+		text := strings.TrimSpace(textOf(t))
 		if text != "" {
 			return text
 		}
@@ -234,7 +252,7 @@ func findDevtoTimestamp(el *rod.Element) string {
 		if dt, err := t.Attribute("datetime"); err == nil && dt != nil && strings.TrimSpace(*dt) != "" {
 			return strings.TrimSpace(*dt)
 		}
-		text := strings.TrimSpace(t.MustText())
+		text := strings.TrimSpace(textOf(t))
 		if text != "" {
 			return text
 		}
@@ -250,17 +268,17 @@ func findDevtoStats(el *rod.Element) (*int, *int, string, *int) {
 
 	var reactions *int
 	if span, err := container.Element(`span[title="Reactions"]`); err == nil {
-		text := normalizeWhitespace(span.MustText())
+		text := normalizeWhitespace(textOf(span))
 		reactions = parseCount(text)
 	}
 
 	var comments *int
 	if span, err := container.Element(`span[title="Comments"]`); err == nil {
 		if countEl, err := span.Element(".spec__comments-count"); err == nil {
-			text := normalizeWhitespace(countEl.MustText())
+			text := normalizeWhitespace(textOf(countEl))
 			comments = parseCount(text)
 		} else {
-			text := normalizeWhitespace(span.MustText())
+			text := normalizeWhitespace(textOf(span))
 			comments = parseCount(text)
 		}
 	}
@@ -268,7 +286,7 @@ func findDevtoStats(el *rod.Element) (*int, *int, string, *int) {
 	var viewsLabel string
 	var viewsCount *int
 	if span, err := container.Element(`span[title="Views"]`); err == nil {
-		text := normalizeWhitespace(span.MustText())
+		text := normalizeWhitespace(textOf(span))
 		viewsLabel = text
 		if strings.Contains(text, "<") {
 			viewsCount = nil
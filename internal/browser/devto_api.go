@@ -0,0 +1,152 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DevtoAPIClient publishes through the Forem API with an account API key,
+// which is faster and sturdier than driving the editor with a session cookie.
+type DevtoAPIClient struct {
+	APIKey  string
+	BaseURL string // https://dev.to unless DEVTO_BASE_URL points elsewhere (e.g. a test server)
+	Client  *http.Client
+}
+
+// NewDevtoAPIClient creates a new API client
+func NewDevtoAPIClient(apiKey string) *DevtoAPIClient {
+	baseURL := os.Getenv("DEVTO_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://dev.to"
+	}
+	return &DevtoAPIClient{
+		APIKey:  apiKey,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+type devtoArticle struct {
+	Title        string   `json:"title"`
+	BodyMarkdown string   `json:"body_markdown"`
+	Published    bool     `json:"published"`
+	Tags         []string `json:"tags,omitempty"`
+	Series       string   `json:"series,omitempty"`
+	MainImage    string   `json:"main_image,omitempty"`
+}
+
+// Publish creates a published article and returns its URL
+func (c *DevtoAPIClient) Publish(ctx context.Context, title, content, coverImage string, meta PostMetadata) (string, error) {
+	log.Println("🌐 Starting Dev.to API publish flow...")
+
+	article := devtoArticle{
+		Title:        title,
+		BodyMarkdown: content,
+		Published:    true,
+		Tags:         meta.Tags,
+		Series:       meta.Series,
+	}
+	// The API only takes hosted images; data URLs would need the editor's uploader
+	if strings.HasPrefix(coverImage, "http://") || strings.HasPrefix(coverImage, "https://") {
+		article.MainImage = coverImage
+	}
+
+	body, err := json.Marshal(map[string]devtoArticle{"article": article})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/api/articles", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.forem.api-v1+json")
+	req.Header.Set("api-key", c.APIKey)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var created struct {
+		ID  int64  `json:"id"`
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+	if created.URL == "" {
+		return "", fmt.Errorf("article URL not found in response")
+	}
+
+	log.Printf("✅ Published: %s", created.URL)
+	return created.URL, nil
+}
+
+// RecentArticles lists the account's latest articles, newest first
+func (c *DevtoAPIClient) RecentArticles(ctx context.Context, limit int) ([]RecentPost, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/articles/me/all?per_page=%d", c.BaseURL, limit), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.forem.api-v1+json")
+	req.Header.Set("api-key", c.APIKey)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var articles []struct {
+		Title        string     `json:"title"`
+		URL          string     `json:"url"`
+		BodyMarkdown string     `json:"body_markdown"`
+		Published    bool       `json:"published"`
+		PublishedAt  *time.Time `json:"published_at"`
+	}
+	if err := json.Unmarshal(respBody, &articles); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	posts := make([]RecentPost, 0, len(articles))
+	for _, a := range articles {
+		if !a.Published {
+			continue // Drafts aren't duplicates readers can see
+		}
+		post := RecentPost{Title: a.Title, URL: a.URL, Body: a.BodyMarkdown}
+		if a.PublishedAt != nil {
+			post.PublishedAt = *a.PublishedAt
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
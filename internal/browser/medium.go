@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -8,11 +9,13 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/input"
 	"github.com/go-rod/rod/lib/proto"
-	"github.com/go-rod/stealth"
 )
 
-// Helper: Wait for "Saved" status
-func waitForSaved(page *rod.Page) error {
+// mediumLoginURL is where Medium sends requests without a valid session
+const mediumLoginURL = "https://medium.com/m/signin"
+
+// Helper: Wait for "Saved" status; Medium's firewall refuses the saves of sessions it flagged
+func waitForSaved(ctx context.Context, page *rod.Page) error {
 	log.Print("   ⏳ Syncing: ")
 	// Poll for 15 seconds
 	for i := 0; i < 15; i++ {
@@ -25,50 +28,74 @@ func waitForSaved(page *rod.Page) error {
 		if has, _, _ := page.HasR("span, div, p", "Saving..."); has {
 			log.Print(".") // loading indicator
 		}
-		time.Sleep(1 * time.Second)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
 	}
 	log.Println("❌ Timeout")
-	return fmt.Errorf("sync timeout: stuck on Saving...")
+	return fmt.Errorf("stuck on Saving...: %w", ErrWAFBlocked)
 }
 
-// PostToMediumWithTags publishes to Medium with tags support
-func PostToMediumWithTags(uid, sid, xsrf, title, content string, tags []string, coverImage string) error {
+// PostToMediumWithTags publishes to Medium with tags and subtitle support.
+// It returns the story URL, or "" when the browser fallback couldn't tell.
+func PostToMediumWithTags(ctx context.Context, uid, sid, xsrf, title, content string, meta PostMetadata, coverImage string) (string, error) {
 	log.Println("🎯 Attempting Medium API publish...")
 
 	// Always try API first
 	client := NewMediumAPIClient(uid, sid, xsrf)
-	url, err := client.Publish(title, content, tags)
+	url, err := client.Publish(ctx, title, content, meta)
 
 	if err == nil {
 		log.Printf("✅ Published via API: %s", url)
-		return nil
+		return url, nil
+	}
+
+	// Shutting down; don't start a browser session we'd have to abandon
+	if ctx.Err() != nil {
+		return "", ctx.Err()
 	}
 
 	// API failed, fall back to browser automation (without cover image to avoid file picker hang)
 	log.Printf("⚠️ API failed (%v), falling back to browser automation...", err)
-	return postToMediumBrowser(uid, sid, xsrf, title, content, tags, "")
+	if meta.Subtitle != "" {
+		log.Println("⚠️ Browser fallback does not set the subtitle")
+	}
+	return "", postToMediumBrowser(ctx, uid, sid, xsrf, title, content, meta.Tags, "")
 }
 
 // postToMediumBrowser is the original browser automation implementation (preserved as fallback)
-func postToMediumBrowser(uid, sid, xsrf, title, content string, tags []string, coverImage string) error {
+func postToMediumBrowser(ctx context.Context, uid, sid, xsrf, title, content string, tags []string, coverImage string) (err error) {
 	log.Println("Starting PostToMedium...")
 	log.Println("Starting PostToMedium...")
-	if err := EnsureBrowser(); err != nil {
+	lease, err := DefaultPool().Acquire(ctx)
+	if err != nil {
 		return fmt.Errorf("browser init failed: %w", err)
 	}
-
-	// CRITICAL: Initialize Stealth Page
-	// stealth.MustPage(Browser) creates a page with stealth scripts pre-loaded
-	log.Println("Creating stealth page...")
-	page := stealth.MustPage(Browser)
 	defer func() {
 		log.Println("Closing page...")
-		rod.Try(func() { page.MustClose() })
+		lease.Release()
 	}()
 
+	// CRITICAL: Initialize Stealth Page
+	// lease.StealthPage() creates a page with stealth scripts pre-loaded, in the job's own context
+	log.Println("Creating stealth page...")
+	tab, err := lease.StealthPage()
+	if err != nil {
+		return fmt.Errorf("failed to open page: %w", err)
+	}
+
+	// Every call on page fails once ctx is cancelled, so shutdown stops the typing;
+	// a failed run leaves a screenshot, the DOM and its timeline behind
+	page, run := startRun(ctx, tab, "medium-publish")
+	defer func() { run.finish(err) }()
+
 	// Navigate to domain to ensure cookies are set correctly
 	log.Println("Navigating to medium.com to set cookies...")
-	page.MustNavigate("https://medium.com/")
+	if err := navigate(page, "https://medium.com/"); err != nil {
+		return err
+	}
 
 	// 1. Inject Cookies
 	log.Println("Injecting cookies...")
@@ -77,47 +104,63 @@ func postToMediumBrowser(uid, sid, xsrf, title, content string, tags []string, c
 		{Name: "sid", Value: sid, Domain: ".medium.com", Path: "/", HTTPOnly: true, Secure: true},
 		{Name: "xsrf", Value: xsrf, Domain: ".medium.com", Path: "/", HTTPOnly: true, Secure: true},
 	}
-	page.SetCookies(cookies)
+	if err := page.SetCookies(cookies); err != nil {
+		return fmt.Errorf("failed to set cookies: %w", err)
+	}
 
 	// 2. Navigate
 	log.Println("Navigating to Medium Editor (https://medium.com/new-story)...")
-	page.MustNavigate("https://medium.com/new-story")
+	if err := navigate(page, "https://medium.com/new-story"); err != nil {
+		return err
+	}
+	if err := checkLanding(page, mediumLoginURL); err != nil {
+		return err
+	}
 
 	// 3. Wait for Editor (Robust Selector)
 	log.Println("Waiting for editor...")
 	// We wait for the Title field. If this times out, your Cookies/UA are still blocked.
-	err := page.Timeout(15*time.Second).WaitElementsMoreThan(`[data-testid="editorTitleParagraph"]`, 0)
+	titleElem, err := element(page, `[data-testid="editorTitleParagraph"]`)
 	if err != nil {
-		log.Println("❌ Editor load failed. Capturing screenshot...")
-		page.MustScreenshot("medium_blocked.png")
-		return fmt.Errorf("editor load failed: see medium_blocked.png")
+		log.Println("❌ Editor load failed")
+		if landing := checkLanding(page, mediumLoginURL); landing != nil {
+			return landing
+		}
+		return fmt.Errorf("editor load failed: %w", err)
 	}
 
 	// ---------------------------------------------------------
 	// PART A: Title (The "Human Handshake")
 	// ---------------------------------------------------------
 	log.Println("Writing Title...")
-	titleElem := page.MustElement(`[data-testid="editorTitleParagraph"]`)
-	titleElem.MustClick() // Focus
+	if err := click(titleElem, "focus the title"); err != nil {
+		return err
+	}
 
 	// Use Human Typing for Title
 	// This generates "clean" traffic that looks real to the WAF
-	HumanType(page, title)
+	if err := HumanType(page, title); err != nil {
+		return stepError("type the title", err)
+	}
 
 	// SYNC CHECK 1:
 	// If the title doesn't save, do not proceed. The WAF has already blocked you.
-	if err := waitForSaved(page); err != nil {
-		return fmt.Errorf("WAF BLOCK: Title was typed, but server rejected save")
+	if err := waitForSaved(ctx, page); err != nil {
+		return fmt.Errorf("title was typed, but the server didn't save it: %w", err)
 	}
 
-	page.Keyboard.MustType(input.Enter) // New line
+	if err := page.Keyboard.Type(input.Enter); err != nil { // New line
+		return stepError("start the body", err)
+	}
 
 	if coverImage != "" {
 		if err := insertMediumInlineImage(page, coverImage); err != nil {
 			log.Printf("⚠️ Cover image insert failed: %v", err)
 		} else {
 			time.Sleep(300 * time.Millisecond)
-			page.Keyboard.MustType(input.Enter)
+			if err := page.Keyboard.Type(input.Enter); err != nil {
+				return stepError("start the body", err)
+			}
 		}
 	}
 
@@ -125,7 +168,9 @@ func postToMediumBrowser(uid, sid, xsrf, title, content string, tags []string, c
 	// PART B: Body (Hybrid Approach)
 	// ---------------------------------------------------------
 	log.Println("Writing Body...")
-	page.MustWaitStable()
+	if err := waitStable(page); err != nil {
+		return err
+	}
 
 	// For the body, typing 1000 words humanly takes too long (and might timeout execution).
 	// We use the clipboard method.
@@ -139,22 +184,23 @@ func postToMediumBrowser(uid, sid, xsrf, title, content string, tags []string, c
 	if err != nil {
 		// Fallback
 		log.Println("Clipboard paste failed, using InsertText...")
-		page.MustInsertText(content)
+		if err := page.InsertText(content); err != nil {
+			return stepError("insert the body", err)
+		}
 	} else {
 		time.Sleep(300 * time.Millisecond)
 		// CRITICAL FIX: Use explicit Press/Type/Release for Control+V
-		// Keyboard methods return error, but we ignore for brevity in this snippet
 		log.Println("Pasting content (Ctrl+V)...")
-		page.Keyboard.Press(input.ControlLeft)
-		page.Keyboard.Type(input.Key('v'))
-		page.Keyboard.Release(input.ControlLeft)
+		if err := paste(page); err != nil {
+			return stepError("paste the body", err)
+		}
 	}
 
 	// SYNC CHECK 2 (CRITICAL):
 	// Large paste = Longer save time.
 	// We do NOT click publish until this returns.
-	if err := waitForSaved(page); err != nil {
-		return fmt.Errorf("WAF BLOCK: Body content rejected")
+	if err := waitForSaved(ctx, page); err != nil {
+		return fmt.Errorf("body was pasted, but the server didn't save it: %w", err)
 	}
 
 	// ---------------------------------------------------------
@@ -170,24 +216,24 @@ func postToMediumBrowser(uid, sid, xsrf, title, content string, tags []string, c
 	log.Println("Clicking header 'Publish' button...")
 
 	// Try user-provided selector first, then testid, then text
-	err = rod.Try(func() {
-		page.MustElement(`[data-action="show-prepublish"]`).MustClick()
-	})
+	publish, err := element(page, `[data-action="show-prepublish"]`, `[data-testid="header-publish-button"]`)
 	if err != nil {
-		err = rod.Try(func() {
-			page.MustElement(`[data-testid="header-publish-button"]`).MustClick()
-		})
-		if err != nil {
-			page.MustElementR("button", "Publish").MustClick()
+		if publish, err = elementR(page, "button", "Publish"); err != nil {
+			return err
 		}
 	}
-	page.MustWaitStable()
+	if err := click(publish, "open the publish overlay"); err != nil {
+		return err
+	}
+	if err := waitStable(page); err != nil {
+		return err
+	}
 
 	// Wait for Overlay
 	log.Println("Waiting for publish overlay...")
-	rod.Try(func() {
-		page.Timeout(10 * time.Second).MustElement(`.overlay-dialog`).WaitVisible()
-	})
+	if overlay, err := element(page, `.overlay-dialog`); err == nil {
+		overlay.Timeout(10 * time.Second).WaitVisible()
+	}
 
 	// Give animation a moment to settle
 	time.Sleep(1 * time.Second)
@@ -198,29 +244,7 @@ func postToMediumBrowser(uid, sid, xsrf, title, content string, tags []string, c
 	if len(tags) > 0 {
 		log.Println("Adding tags (topics)...")
 
-		// Use Try to safely check/wait for the input
-		err := rod.Try(func() {
-			tagInput, e := page.Timeout(5 * time.Second).Element(`[data-testid="publishTopicsInput"]`)
-			if e != nil { panic(e) }
-			tagInput.MustWaitVisible()
-
-			for _, tag := range tags {
-				log.Printf("Adding tag: %s", tag)
-				// Click to focus
-				tagInput.MustClick()
-				time.Sleep(200 * time.Millisecond)
-
-				// Type tag
-				tagInput.MustInput(tag)
-				time.Sleep(500 * time.Millisecond) // Wait for dropdown suggestions
-
-				// Press Enter to confirm tag
-				page.Keyboard.MustType(input.Enter)
-				time.Sleep(300 * time.Millisecond)
-			}
-		})
-
-		if err != nil {
+		if err := addMediumTopics(page, tags); err != nil {
 			log.Printf("⚠️ Failed to add tags: %v", err)
 			// Don't error out, try to publish anyway
 		}
@@ -231,44 +255,81 @@ func postToMediumBrowser(uid, sid, xsrf, title, content string, tags []string, c
 	log.Println("Clicking final 'Publish now' button...")
 
 	// Wait for the modal/panel to appear
-	err = rod.Try(func() {
-		el, e := page.Timeout(5 * time.Second).Element(`[data-testid="publishConfirmButton"]`)
-		if e != nil { panic(e) }
-		el.MustClick()
-	})
+	confirm, err := element(page, `[data-testid="publishConfirmButton"]`)
 	if err != nil {
-		err = rod.Try(func() {
-			el, e := page.Timeout(5 * time.Second).ElementR("button", "Publish now")
-			if e != nil { panic(e) }
-			el.MustWaitVisible().MustClick()
-		})
-		if err != nil {
-			log.Printf("⚠️ Could not find publish confirm button: %v", err)
-		}
+		confirm, err = elementR(page, "button", "Publish now")
+	}
+	if err == nil {
+		err = click(confirm, "confirm publishing")
+	}
+	if err != nil {
+		log.Printf("⚠️ Could not find publish confirm button: %v", err)
 	}
 
 	// ---------------------------------------------------------
 	// PART D: Verification
 	// ---------------------------------------------------------
 	log.Println("Verifying redirect...")
-	err = rod.Try(func() {
-		for i := 0; i < 60; i++ {
-			if page.MustInfo().URL != "https://medium.com/new-story" {
-				return
-			}
-			time.Sleep(time.Second)
+	for i := 0; i < 60; i++ {
+		info, err := page.Info()
+		if err != nil {
+			return stepError("read the page", err)
 		}
-		panic("timeout")
-	})
+		if info.URL != "https://medium.com/new-story" {
+			log.Println("✅ Success! URL:", info.URL)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	return fmt.Errorf("waiting for the redirect to the story: %w", ErrTimeout)
+}
 
+// addMediumTopics types the tags into the publish overlay's topics input
+func addMediumTopics(page *rod.Page, tags []string) error {
+	tagInput, err := element(page, `[data-testid="publishTopicsInput"]`)
 	if err != nil {
-		return fmt.Errorf("redirect timeout")
+		return err
+	}
+	if err := tagInput.WaitVisible(); err != nil {
+		return stepError("wait for the topics input", err)
 	}
 
-	log.Println("✅ Success! URL:", page.MustInfo().URL)
+	for _, tag := range tags {
+		log.Printf("Adding tag: %s", tag)
+		// Click to focus
+		if err := click(tagInput, "focus the topics input"); err != nil {
+			return err
+		}
+		time.Sleep(200 * time.Millisecond)
+
+		// Type tag
+		if err := tagInput.Input(tag); err != nil {
+			return stepError("type topic "+tag, err)
+		}
+		time.Sleep(500 * time.Millisecond) // Wait for dropdown suggestions
+
+		// Press Enter to confirm tag
+		if err := page.Keyboard.Type(input.Enter); err != nil {
+			return stepError("confirm topic "+tag, err)
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
 	return nil
 }
 
+// paste presses Ctrl+V
+func paste(page *rod.Page) error {
+	if err := page.Keyboard.Press(input.ControlLeft); err != nil {
+		return err
+	}
+	defer page.Keyboard.Release(input.ControlLeft)
+	return page.Keyboard.Type(input.Key('v'))
+}
+
 func insertMediumInlineImage(page *rod.Page, coverImage string) error {
 	path, cleanup, err := PrepareImageUpload(coverImage)
 	if err != nil {
@@ -281,29 +342,24 @@ func insertMediumInlineImage(page *rod.Page, coverImage string) error {
 	}
 
 	// Open inline menu (+)
-	err = rod.Try(func() {
-		page.MustElement(`[data-testid="editorAddButton"]`).MustClick()
-	})
+	menu, err := element(page, `[data-testid="editorAddButton"]`, `button[data-action="inline-menu"]`)
 	if err != nil {
-		err = rod.Try(func() {
-			page.MustElement(`button[data-action="inline-menu"]`).MustClick()
-		})
+		return fmt.Errorf("inline menu button: %w", err)
 	}
-	if err != nil {
-		return fmt.Errorf("inline menu button not found")
+	if err := click(menu, "open the inline menu"); err != nil {
+		return err
 	}
 
 	// Click image option
-	err = rod.Try(func() {
-		page.MustElement(`button[data-action="inline-menu-image"]`).MustClick()
-	})
+	image, err := element(page, `button[data-action="inline-menu-image"]`)
 	if err != nil {
-		err = rod.Try(func() {
-			page.MustElementR("button", "Add an image").MustClick()
-		})
+		image, err = elementR(page, "button", "Add an image")
 	}
 	if err != nil {
-		return fmt.Errorf("inline image button not found")
+		return fmt.Errorf("inline image button: %w", err)
+	}
+	if err := click(image, "pick an image"); err != nil {
+		return err
 	}
 
 	// Find file input and upload
@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -20,18 +21,24 @@ type MediumPost struct {
 }
 
 // FetchMediumPosts scrapes the Medium stories page using session cookies.
-func FetchMediumPosts(uid, sid, xsrf string, limit int) ([]MediumPost, error) {
+func FetchMediumPosts(ctx context.Context, uid, sid, xsrf string, limit int) (posts []MediumPost, err error) {
 	if uid == "" || sid == "" || xsrf == "" {
 		return nil, fmt.Errorf("medium credentials missing")
 	}
 
-	if err := EnsureBrowser(); err != nil {
+	lease, err := DefaultPool().Acquire(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to initialize browser: %w", err)
 	}
+	defer lease.Release()
 
-	// Use a new page on the shared browser
-	page := Browser.MustPage("about:blank")
-	defer page.MustClose()
+	// Use a new page in the job's own context
+	tab, err := lease.Page("about:blank")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open page: %w", err)
+	}
+	page, run := startRun(ctx, tab, "medium-posts")
+	defer func() { run.finish(err) }()
 
 	// Set Cookies
 	cookies := []*proto.NetworkCookieParam{
@@ -66,17 +73,26 @@ func FetchMediumPosts(uid, sid, xsrf string, limit int) ([]MediumPost, error) {
 	}
 
 	// Navigate to Stories -> Published
-	// https://medium.com/me/stories?tab=posts-published
-	page.MustNavigate("https://medium.com/me/stories?tab=posts-published")
-	page.MustWaitLoad()
+	if err := navigate(page, "https://medium.com/me/stories?tab=posts-published"); err != nil {
+		return nil, err
+	}
+	if err := checkLanding(page, mediumLoginURL); err != nil {
+		return nil, err
+	}
 
 	// Wait for list to load
 	// Prefer table rows, fall back to headings if the layout changes.
-	err := page.Timeout(10*time.Second).WaitElementsMoreThan("table tbody tr", 0)
-	if err != nil {
+	if err := waitMediumRows(page); err != nil {
 		// Might be no stories or not logged in
-		if isMediumLoginURL(page.MustInfo().URL) {
-			return nil, fmt.Errorf("cookie expired or login failed")
+		if ctxErr := page.GetContext().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		info, infoErr := page.Info()
+		if infoErr != nil {
+			return nil, stepError("read the page", infoErr)
+		}
+		if isMediumLoginURL(info.URL) {
+			return nil, fmt.Errorf("redirected to %s: %w", info.URL, ErrSessionExpired)
 		}
 		// If just no stories or layout changed, continue with best-effort extraction.
 	}
@@ -84,11 +100,19 @@ func FetchMediumPosts(uid, sid, xsrf string, limit int) ([]MediumPost, error) {
 	return extractMediumPosts(page, limit)
 }
 
+// waitMediumRows waits up to 10 seconds for the stories table to have rows
+func waitMediumRows(page *rod.Page) (err error) {
+	defer traced(page.GetContext(), "wait for the stories", time.Now(), &err)
+	p := page.Timeout(10 * time.Second)
+	defer p.CancelTimeout()
+	return p.WaitElementsMoreThan("table tbody tr", 0)
+}
+
 func extractMediumPosts(page *rod.Page, limit int) ([]MediumPost, error) {
 	var posts []MediumPost
 	rows, err := page.Elements("table tbody tr")
 	if err == nil && len(rows) > 0 {
-		return extractMediumPostsFromRows(rows, limit), nil
+		return extractMediumPostsFromRows(rows, limit)
 	}
 
 	// Fallback: grab headings if layout changes (best-effort).
@@ -100,7 +124,11 @@ func extractMediumPosts(page *rod.Page, limit int) ([]MediumPost, error) {
 		if limit > 0 && len(posts) >= limit {
 			break
 		}
-		title := strings.TrimSpace(el.MustText())
+		title, err := elementText(el)
+		if err != nil {
+			return nil, err
+		}
+		title = strings.TrimSpace(title)
 		if title == "" {
 			continue
 		}
@@ -125,7 +153,7 @@ func extractMediumPosts(page *rod.Page, limit int) ([]MediumPost, error) {
 	return posts, nil
 }
 
-func extractMediumPostsFromRows(rows []*rod.Element, limit int) []MediumPost {
+func extractMediumPostsFromRows(rows []*rod.Element, limit int) ([]MediumPost, error) {
 	var posts []MediumPost
 
 	for _, row := range rows {
@@ -147,24 +175,22 @@ func extractMediumPostsFromRows(rows []*rod.Element, limit int) []MediumPost {
 			continue
 		}
 
-		title := ""
-		if h2, err := linkEl.Element("h2"); err == nil {
-			title = strings.TrimSpace(h2.MustText())
-		}
-		if title == "" {
-			if h2, err := row.Element("h2"); err == nil {
-				title = strings.TrimSpace(h2.MustText())
-			}
-		}
-		if title == "" {
-			title = strings.TrimSpace(linkEl.MustText())
+		title, err := mediumPostTitle(row, linkEl)
+		if err != nil {
+			return nil, err
 		}
 		if title == "" {
 			continue
 		}
 
-		publishedAt := extractMediumPublishedText(row)
-		claps, responses := extractMediumStats(row)
+		publishedAt, err := extractMediumPublishedText(row)
+		if err != nil {
+			return nil, err
+		}
+		claps, responses, err := extractMediumStats(row)
+		if err != nil {
+			return nil, err
+		}
 
 		posts = append(posts, MediumPost{
 			Title:       title,
@@ -176,7 +202,26 @@ func extractMediumPostsFromRows(rows []*rod.Element, limit int) []MediumPost {
 		})
 	}
 
-	return posts
+	return posts, nil
+}
+
+// mediumPostTitle is the post's heading, or the link's text without one
+func mediumPostTitle(row, linkEl *rod.Element) (string, error) {
+	for _, el := range []*rod.Element{linkEl, row} {
+		h2, err := el.Element("h2")
+		if err != nil {
+			continue
+		}
+		title, err := elementText(h2)
+		if err != nil {
+			return "", err
+		}
+		if title = strings.TrimSpace(title); title != "" {
+			return title, nil
+		}
+	}
+	title, err := elementText(linkEl)
+	return strings.TrimSpace(title), err
 }
 
 func findMediumPostLink(row *rod.Element) *rod.Element {
@@ -219,46 +264,53 @@ func findMediumHeadingLink(el *rod.Element) *rod.Element {
 	return nil
 }
 
-func extractMediumPublishedText(row *rod.Element) string {
+func extractMediumPublishedText(row *rod.Element) (string, error) {
 	paras, err := row.Elements("p")
 	if err != nil {
-		return ""
+		return "", nil
 	}
+	texts := make([]string, 0, len(paras))
 	for _, p := range paras {
-		text := normalizeWhitespace(p.MustText())
-		if strings.HasPrefix(text, "Published ") {
-			return text
+		text, err := elementText(p)
+		if err != nil {
+			return "", err
 		}
+		texts = append(texts, normalizeWhitespace(text))
 	}
-	for _, p := range paras {
-		text := normalizeWhitespace(p.MustText())
-		if strings.HasPrefix(text, "Updated ") {
-			return text
+	for _, prefix := range []string{"Published ", "Updated "} {
+		for _, text := range texts {
+			if strings.HasPrefix(text, prefix) {
+				return text, nil
+			}
 		}
 	}
-	return ""
+	return "", nil
 }
 
-func extractMediumStats(row *rod.Element) (string, string) {
+func extractMediumStats(row *rod.Element) (string, string, error) {
 	if statNodes, err := row.Elements("svg + p"); err == nil && len(statNodes) > 0 {
-		claps, responses := firstTwoNumeric(statNodes)
-		if claps != "" || responses != "" {
-			return claps, responses
+		claps, responses, err := firstTwoNumeric(statNodes)
+		if err != nil || claps != "" || responses != "" {
+			return claps, responses, err
 		}
 	}
 
 	paras, err := row.Elements("p")
 	if err != nil {
-		return "", ""
+		return "", "", nil
 	}
 
 	return firstTwoNumeric(paras)
 }
 
-func firstTwoNumeric(elements []*rod.Element) (string, string) {
+func firstTwoNumeric(elements []*rod.Element) (string, string, error) {
 	var counts []string
 	for _, el := range elements {
-		text := normalizeWhitespace(el.MustText())
+		text, err := elementText(el)
+		if err != nil {
+			return "", "", err
+		}
+		text = normalizeWhitespace(text)
 		if mediumCountRegex.MatchString(text) {
 			counts = append(counts, text)
 		}
@@ -272,7 +324,7 @@ func firstTwoNumeric(elements []*rod.Element) (string, string) {
 	if len(counts) >= 2 {
 		responses = counts[1]
 	}
-	return claps, responses
+	return claps, responses, nil
 }
 
 func normalizeMediumURL(href string) string {
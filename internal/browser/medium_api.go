@@ -2,11 +2,13 @@ package browser
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -17,6 +19,7 @@ type MediumAPIClient struct {
 	UID           string
 	SID           string
 	XSRF          string
+	BaseURL       string // https://medium.com unless MEDIUM_BASE_URL points elsewhere (e.g. a test server)
 	Client        *http.Client
 	revisionCount int // Track revision count for publishing
 }
@@ -50,10 +53,15 @@ type mediumParagraph struct {
 
 // NewMediumAPIClient creates a new API client
 func NewMediumAPIClient(uid, sid, xsrf string) *MediumAPIClient {
+	baseURL := os.Getenv("MEDIUM_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://medium.com"
+	}
 	return &MediumAPIClient{
-		UID:  uid,
-		SID:  sid,
-		XSRF: xsrf,
+		UID:     uid,
+		SID:     sid,
+		XSRF:    xsrf,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
 		Client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -67,7 +75,7 @@ func stripHijackingPrefix(body []byte) []byte {
 }
 
 // makeRequest performs an authenticated request to Medium
-func (c *MediumAPIClient) makeRequest(method, url string, body interface{}) ([]byte, error) {
+func (c *MediumAPIClient) makeRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -77,7 +85,7 @@ func (c *MediumAPIClient) makeRequest(method, url string, body interface{}) ([]b
 		reqBody = bytes.NewReader(jsonData)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -88,8 +96,8 @@ func (c *MediumAPIClient) makeRequest(method, url string, body interface{}) ([]b
 	req.Header.Set("X-XSRF-Token", c.XSRF)
 	req.Header.Set("X-Obvious-CID", "web")
 	req.Header.Set("X-Client-Date", fmt.Sprintf("%d", time.Now().UnixMilli()))
-	req.Header.Set("Origin", "https://medium.com")
-	req.Header.Set("Referer", "https://medium.com/new-story")
+	req.Header.Set("Origin", c.BaseURL)
+	req.Header.Set("Referer", c.BaseURL+"/new-story")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:147.0) Gecko/20100101 Firefox/147.0")
 
 	// Set cookies
@@ -116,7 +124,7 @@ func (c *MediumAPIClient) makeRequest(method, url string, body interface{}) ([]b
 }
 
 // CreateStory creates a new draft story and returns the post ID
-func (c *MediumAPIClient) CreateStory() (string, error) {
+func (c *MediumAPIClient) CreateStory(ctx context.Context) (string, error) {
 	log.Println("📝 Creating new Medium story...")
 
 	payload := map[string]interface{}{
@@ -126,7 +134,7 @@ func (c *MediumAPIClient) CreateStory() (string, error) {
 		"visibility": 0,
 	}
 
-	respBody, err := c.makeRequest("POST", "https://medium.com/new-story", payload)
+	respBody, err := c.makeRequest(ctx, "POST", "/new-story", payload)
 	if err != nil {
 		return "", fmt.Errorf("create story: %w", err)
 	}
@@ -164,8 +172,9 @@ func generateParagraphName() string {
 	return fmt.Sprintf("%04x", now%0x10000)
 }
 
-// UpdateContent updates the story content using Medium's delta format
-func (c *MediumAPIClient) UpdateContent(postID, title, content string) error {
+// UpdateContent updates the story content using Medium's delta format.
+// A non-empty subtitle is written as the paragraph Medium shows under the title.
+func (c *MediumAPIClient) UpdateContent(ctx context.Context, postID, title, subtitle, content string) error {
 	log.Println("✍️  Updating story content...")
 
 	// Reset revision counter
@@ -210,10 +219,30 @@ func (c *MediumAPIClient) UpdateContent(postID, title, content string) error {
 	// Store the title paragraph name for the update delta
 	deltas[2].Paragraph.Name = deltas[1].Paragraph.Name
 
+	idx := 1
+
+	// Subtitle paragraph (type 13) directly below the title
+	if subtitle = strings.TrimSpace(subtitle); subtitle != "" {
+		subtitleName := generateParagraphName()
+		deltas = append(deltas,
+			mediumDelta{
+				Type:      1,
+				Index:     idx,
+				Paragraph: &mediumParagraph{Name: subtitleName, Type: 13, Text: "", Markups: []string{}},
+			},
+			mediumDelta{
+				Type:           3,
+				Index:          idx,
+				Paragraph:      &mediumParagraph{Name: subtitleName, Type: 13, Text: subtitle, Markups: []string{}},
+				VerifySameName: true,
+			},
+		)
+		idx++
+	}
+
 	// Split content into paragraphs (simple approach: by newlines)
 	paragraphs := strings.Split(strings.TrimSpace(content), "\n\n")
 
-	idx := 1
 	for _, para := range paragraphs {
 		para = strings.TrimSpace(para)
 		if para == "" {
@@ -256,8 +285,7 @@ func (c *MediumAPIClient) UpdateContent(postID, title, content string) error {
 		"baseRev": -1,
 	}
 
-	url := fmt.Sprintf("https://medium.com/p/%s/deltas", postID)
-	_, err := c.makeRequest("POST", url, payload)
+	_, err := c.makeRequest(ctx, "POST", fmt.Sprintf("/p/%s/deltas", postID), payload)
 	if err != nil {
 		return fmt.Errorf("update content: %w", err)
 	}
@@ -270,7 +298,7 @@ func (c *MediumAPIClient) UpdateContent(postID, title, content string) error {
 }
 
 // UpdateMetadata sets tags and other metadata
-func (c *MediumAPIClient) UpdateMetadata(postID string, tags []string) error {
+func (c *MediumAPIClient) UpdateMetadata(ctx context.Context, postID string, tags []string) error {
 	log.Println("🏷️  Updating metadata...")
 
 	payload := map[string]interface{}{
@@ -283,8 +311,7 @@ func (c *MediumAPIClient) UpdateMetadata(postID string, tags []string) error {
 		"isMarkedPaywallOnly": false,
 	}
 
-	url := fmt.Sprintf("https://medium.com/_/api/posts/%s/metadata", postID)
-	_, err := c.makeRequest("PUT", url, payload)
+	_, err := c.makeRequest(ctx, "PUT", fmt.Sprintf("/_/api/posts/%s/metadata", postID), payload)
 	if err != nil {
 		return fmt.Errorf("update metadata: %w", err)
 	}
@@ -294,7 +321,7 @@ func (c *MediumAPIClient) UpdateMetadata(postID string, tags []string) error {
 }
 
 // PublishPost publishes the story
-func (c *MediumAPIClient) PublishPost(postID string) (string, error) {
+func (c *MediumAPIClient) PublishPost(ctx context.Context, postID string) (string, error) {
 	log.Println("🚀 Publishing story...")
 
 	// Use actual revision count from deltas
@@ -311,8 +338,7 @@ func (c *MediumAPIClient) PublishPost(postID string) (string, error) {
 		"latestRev":       latestRev,
 	}
 
-	url := fmt.Sprintf("https://medium.com/p/%s/publish", postID)
-	respBody, err := c.makeRequest("POST", url, payload)
+	respBody, err := c.makeRequest(ctx, "POST", fmt.Sprintf("/p/%s/publish", postID), payload)
 	if err != nil {
 		return "", fmt.Errorf("publish post: %w", err)
 	}
@@ -337,35 +363,35 @@ func (c *MediumAPIClient) PublishPost(postID string) (string, error) {
 	}
 
 	// Fallback: construct URL from post ID
-	publishedURL := fmt.Sprintf("https://medium.com/p/%s", postID)
+	publishedURL := fmt.Sprintf("%s/p/%s", c.BaseURL, postID)
 	log.Printf("✅ Published (inferred URL): %s", publishedURL)
 	return publishedURL, nil
 }
 
 // Publish is the main method that orchestrates the entire publishing flow
-func (c *MediumAPIClient) Publish(title, content string, tags []string) (string, error) {
+func (c *MediumAPIClient) Publish(ctx context.Context, title, content string, meta PostMetadata) (string, error) {
 	log.Println("🌐 Starting Medium API publish flow...")
 
 	// Step 1: Create new story
-	postID, err := c.CreateStory()
+	postID, err := c.CreateStory(ctx)
 	if err != nil {
 		return "", err
 	}
 
 	// Step 2: Update content
-	if err := c.UpdateContent(postID, title, content); err != nil {
+	if err := c.UpdateContent(ctx, postID, title, meta.Subtitle, content); err != nil {
 		return "", err
 	}
 
 	// Step 3: Update metadata (tags)
-	if len(tags) > 0 {
-		if err := c.UpdateMetadata(postID, tags); err != nil {
+	if len(meta.Tags) > 0 {
+		if err := c.UpdateMetadata(ctx, postID, meta.Tags); err != nil {
 			return "", err
 		}
 	}
 
 	// Step 4: Publish
-	publishedURL, err := c.PublishPost(postID)
+	publishedURL, err := c.PublishPost(ctx, postID)
 	if err != nil {
 		return "", err
 	}
@@ -383,3 +409,41 @@ func ExtractPostIDFromURL(url string) string {
 	}
 	return ""
 }
+
+// RecentPosts lists the user's latest published stories from their profile stream
+func (c *MediumAPIClient) RecentPosts(ctx context.Context, limit int) ([]RecentPost, error) {
+	respBody, err := c.makeRequest(ctx, "GET", fmt.Sprintf("/_/api/users/%s/profile/stream?limit=%d", c.UID, limit), nil)
+	if err != nil {
+		return nil, fmt.Errorf("list posts: %w", err)
+	}
+
+	var resp mediumResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	var stream struct {
+		References struct {
+			Post map[string]struct {
+				ID               string `json:"id"`
+				Title            string `json:"title"`
+				FirstPublishedAt int64  `json:"firstPublishedAt"` // Unix milliseconds
+			} `json:"Post"`
+		} `json:"references"`
+	}
+	if err := json.Unmarshal(resp.Payload, &stream); err != nil {
+		return nil, fmt.Errorf("parse payload: %w", err)
+	}
+
+	posts := make([]RecentPost, 0, len(stream.References.Post))
+	for _, p := range stream.References.Post {
+		if p.FirstPublishedAt == 0 {
+			continue // Still a draft
+		}
+		posts = append(posts, RecentPost{
+			Title:       p.Title,
+			URL:         fmt.Sprintf("%s/p/%s", c.BaseURL, p.ID),
+			PublishedAt: time.UnixMilli(p.FirstPublishedAt),
+		})
+	}
+	return posts, nil
+}
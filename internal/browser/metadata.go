@@ -0,0 +1,20 @@
+package browser
+
+import "time"
+
+// PostMetadata is the optional metadata sent along with a post.
+// Fields a platform doesn't support are ignored by its publisher.
+type PostMetadata struct {
+	Tags     []string
+	Subtitle string // Medium
+	Series   string // Dev.to
+}
+
+// RecentPost is a post already live on a platform, as listed by its API or dashboard.
+// Body and PublishedAt are left empty when the listing doesn't show them.
+type RecentPost struct {
+	Title       string
+	URL         string
+	Body        string
+	PublishedAt time.Time
+}
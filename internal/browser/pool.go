@@ -0,0 +1,369 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/go-rod/stealth"
+)
+
+// ErrPoolClosed is returned by Acquire once the pool has shut down
+var ErrPoolClosed = errors.New("browser pool is closed")
+
+// healthTimeout bounds a health check; a browser slower than this is treated as hung
+const healthTimeout = 10 * time.Second
+
+// PoolConfig sizes the browser pool
+type PoolConfig struct {
+	Size           int           // Browsers running at once; jobs share them beyond this
+	MaxPages       int           // Pages a browser opens before it's replaced; 0 never replaces it
+	HealthInterval time.Duration // How often Run checks that every browser still answers
+}
+
+// DefaultPoolConfig applies unless BROWSER_POOL_SIZE, BROWSER_MAX_PAGES or
+// BROWSER_HEALTH_INTERVAL say otherwise
+var DefaultPoolConfig = PoolConfig{Size: 2, MaxPages: 50, HealthInterval: 30 * time.Second}
+
+// PoolConfigFromEnv reads BROWSER_POOL_SIZE, BROWSER_MAX_PAGES and BROWSER_HEALTH_INTERVAL
+func PoolConfigFromEnv() (PoolConfig, error) {
+	config := DefaultPoolConfig
+	if raw := os.Getenv("BROWSER_POOL_SIZE"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			return PoolConfig{}, fmt.Errorf("invalid BROWSER_POOL_SIZE %q: want a positive number", raw)
+		}
+		config.Size = size
+	}
+	if raw := os.Getenv("BROWSER_MAX_PAGES"); raw != "" {
+		pages, err := strconv.Atoi(raw)
+		if err != nil || pages < 0 {
+			return PoolConfig{}, fmt.Errorf("invalid BROWSER_MAX_PAGES %q: want a number, 0 for no limit", raw)
+		}
+		config.MaxPages = pages
+	}
+	if raw := os.Getenv("BROWSER_HEALTH_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			return PoolConfig{}, fmt.Errorf("invalid BROWSER_HEALTH_INTERVAL %q: want a duration such as 30s", raw)
+		}
+		config.HealthInterval = interval
+	}
+	return config, nil
+}
+
+// Pool runs up to Size browsers and hands each job an incognito context of its
+// own, so cookies and storage never leak from one user's job into another's.
+// Browsers are launched on demand, replaced once they've opened MaxPages pages
+// (long-lived browsers bloat), and relaunched when they stop answering.
+type Pool struct {
+	config PoolConfig
+	launch func() (*instance, error)
+
+	mu        sync.Mutex
+	instances []*instance
+	launching int
+	changed   *sync.Cond // Broadcast when a launch ends or a browser leaves, so waiting picks look again
+	closed    bool
+}
+
+// instance is one browser process
+type instance struct {
+	browser  *rod.Browser
+	launcher *launcher.Launcher
+	active   int  // Leases holding one of its contexts
+	pages    int  // Pages opened over its life
+	retired  bool // Takes no new leases; closed once the last is released
+	once     sync.Once
+}
+
+func NewPool(config PoolConfig) *Pool {
+	if config.Size < 1 {
+		config.Size = 1
+	}
+	p := &Pool{config: config, launch: func() (*instance, error) { return launchInstance(headlessMode()) }}
+	p.changed = sync.NewCond(&p.mu)
+	return p
+}
+
+// headlessMode is true in production unless BROWSER_HEADLESS says otherwise
+func headlessMode() bool {
+	// Check if running in production (Render sets PORT, Docker sets APP_ENV)
+	headless := os.Getenv("PORT") != "" || os.Getenv("APP_ENV") == "production"
+	if val := os.Getenv("BROWSER_HEADLESS"); val != "" {
+		headless = (val == "true" || val == "1")
+	}
+	return headless
+}
+
+// launchInstance starts a hardened Chrome
+func launchInstance(headless bool) (*instance, error) {
+	l := GetHardenedLauncher(headless, false)
+	u, err := l.Launch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch browser: %w", err)
+	}
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		l.Kill()
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+	log.Printf("🌐 Launched browser (pid %d)", l.PID())
+	return &instance{browser: b, launcher: l}, nil
+}
+
+// close shuts the browser down and removes its profile
+func (inst *instance) close() {
+	inst.once.Do(func() {
+		rod.Try(func() { inst.browser.MustClose() })
+		inst.launcher.Kill()
+		inst.launcher.Cleanup()
+	})
+}
+
+// Lease is a job's incognito context in one of the pool's browsers
+type Lease struct {
+	pool      *Pool
+	inst      *instance
+	incognito *rod.Browser
+	once      sync.Once
+}
+
+// Acquire leases an incognito context, launching a browser if the pool has
+// room and every running one is busy. Release the lease when the job is done.
+func (p *Pool) Acquire(ctx context.Context) (*Lease, error) {
+	// A browser that crashed since its last health check fails here; the next one is fresh
+	for attempt := 0; attempt < 2; attempt++ {
+		inst, err := p.pick(ctx)
+		if err != nil {
+			return nil, err
+		}
+		incognito, err := inst.browser.Context(ctx).Incognito()
+		if err == nil {
+			return &Lease{pool: p, inst: inst, incognito: incognito}, nil
+		}
+		if ctx.Err() != nil {
+			p.release(inst)
+			return nil, ctx.Err()
+		}
+		log.Printf("⚠️ Browser isn't answering (%v); replacing it", err)
+		p.discard(inst)
+	}
+	return nil, fmt.Errorf("no browser available")
+}
+
+// pick reserves the least busy browser, or launches one while the pool has
+// room. With the pool full of browsers still launching, or of retired ones
+// finishing their jobs, it waits for one.
+func (p *Pool) pick(ctx context.Context) (*instance, error) {
+	// Wake the wait below when the job gives up
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.changed.Broadcast()
+	})
+	defer stop()
+
+	p.mu.Lock()
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		if err := ctx.Err(); err != nil {
+			p.mu.Unlock()
+			return nil, err
+		}
+		var best *instance
+		running := p.launching
+		for _, inst := range p.instances {
+			if inst.retired {
+				// A retired browser still runs until its last job releases it
+				if inst.active > 0 {
+					running++
+				}
+				continue
+			}
+			running++
+			if best == nil || inst.active < best.active {
+				best = inst
+			}
+		}
+		if best != nil && (best.active == 0 || running >= p.config.Size) {
+			best.active++
+			p.mu.Unlock()
+			return best, nil
+		}
+		if running < p.config.Size {
+			break
+		}
+		p.changed.Wait()
+	}
+	p.launching++
+	p.mu.Unlock()
+
+	inst, err := p.launch()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.launching--
+	p.changed.Broadcast()
+	if err != nil {
+		return nil, err
+	}
+	if p.closed {
+		go inst.close()
+		return nil, ErrPoolClosed
+	}
+	inst.active = 1
+	p.instances = append(p.instances, inst)
+	return inst, nil
+}
+
+// release returns a lease's hold on the browser, closing it if it's retired and idle
+func (p *Pool) release(inst *instance) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	inst.active--
+	if inst.retired && inst.active == 0 {
+		p.remove(inst)
+		go inst.close()
+	}
+}
+
+// discard drops a broken browser; jobs still using it fail on their own
+func (p *Pool) discard(inst *instance) {
+	p.mu.Lock()
+	inst.active--
+	p.remove(inst)
+	p.mu.Unlock()
+	go inst.close()
+}
+
+// remove takes inst out of the pool; the caller holds p.mu
+func (p *Pool) remove(inst *instance) {
+	inst.retired = true
+	p.changed.Broadcast()
+	for i, candidate := range p.instances {
+		if candidate == inst {
+			p.instances = append(p.instances[:i], p.instances[i+1:]...)
+			return
+		}
+	}
+}
+
+// Run checks every HealthInterval that the browsers still answer, replacing
+// the ones that don't, until ctx is cancelled
+func (p *Pool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkHealth()
+		}
+	}
+}
+
+func (p *Pool) checkHealth() {
+	p.mu.Lock()
+	instances := append([]*instance(nil), p.instances...)
+	p.mu.Unlock()
+
+	for _, inst := range instances {
+		if _, err := inst.browser.Timeout(healthTimeout).Version(); err != nil {
+			log.Printf("⚠️ Browser failed its health check (%v); replacing it", err)
+			p.mu.Lock()
+			p.remove(inst)
+			p.mu.Unlock()
+			go inst.close()
+		}
+	}
+}
+
+// Close shuts every browser down; jobs still running fail
+func (p *Pool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	instances := p.instances
+	p.instances = nil
+	p.changed.Broadcast()
+	p.mu.Unlock()
+
+	for _, inst := range instances {
+		inst.close()
+	}
+}
+
+// Page opens a tab in the lease's context
+func (l *Lease) Page(url string) (*rod.Page, error) {
+	l.countPage()
+	return l.incognito.Page(proto.TargetCreateTarget{URL: url})
+}
+
+// StealthPage opens a blank tab with the stealth scripts that hide automation loaded
+func (l *Lease) StealthPage() (*rod.Page, error) {
+	l.countPage()
+	return stealth.Page(l.incognito)
+}
+
+// countPage retires the browser once it has opened its share of pages
+func (l *Lease) countPage() {
+	p := l.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l.inst.pages++
+	if p.config.MaxPages > 0 && l.inst.pages >= p.config.MaxPages && !l.inst.retired {
+		log.Printf("♻️ Browser opened %d pages; replacing it once its jobs finish", l.inst.pages)
+		l.inst.retired = true
+	}
+}
+
+// Release closes the lease's context, with its pages and cookies
+func (l *Lease) Release() {
+	l.once.Do(func() {
+		// Disposing on the parent browser works after the job's ctx is cancelled
+		rod.Try(func() {
+			proto.TargetDisposeBrowserContext{BrowserContextID: l.incognito.BrowserContextID}.Call(l.inst.browser)
+		})
+		l.pool.release(l.inst)
+	})
+}
+
+var (
+	defaultPoolMu sync.Mutex
+	defaultPool   *Pool
+)
+
+// SetDefaultPool makes the automation functions take their browsers from p
+func SetDefaultPool(p *Pool) {
+	defaultPoolMu.Lock()
+	defer defaultPoolMu.Unlock()
+	defaultPool = p
+}
+
+// DefaultPool returns the pool the automation functions use; unless one was
+// set, it is configured from the environment on first use
+func DefaultPool() *Pool {
+	defaultPoolMu.Lock()
+	defer defaultPoolMu.Unlock()
+	if defaultPool == nil {
+		config, err := PoolConfigFromEnv()
+		if err != nil {
+			log.Printf("⚠️ %v; using the default browser pool", err)
+			config = DefaultPoolConfig
+		}
+		defaultPool = NewPool(config)
+	}
+	return defaultPool
+}
@@ -0,0 +1,124 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	config, err := PoolConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPoolConfig, config)
+
+	t.Setenv("BROWSER_POOL_SIZE", "4")
+	t.Setenv("BROWSER_MAX_PAGES", "0")
+	t.Setenv("BROWSER_HEALTH_INTERVAL", "1m")
+	config, err = PoolConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, PoolConfig{Size: 4, MaxPages: 0, HealthInterval: time.Minute}, config)
+
+	for name, invalid := range map[string]string{"BROWSER_POOL_SIZE": "0", "BROWSER_MAX_PAGES": "-1", "BROWSER_HEALTH_INTERVAL": "often"} {
+		t.Setenv(name, invalid)
+		_, err := PoolConfigFromEnv()
+		assert.Error(t, err, name)
+		t.Setenv(name, "")
+	}
+}
+
+func TestPool_ClosedPoolLaunchesNothing(t *testing.T) {
+	p := NewPool(DefaultPoolConfig)
+	p.Close()
+	_, err := p.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrPoolClosed)
+}
+
+func TestPool_ConcurrentColdStartsStayWithinSize(t *testing.T) {
+	p := NewPool(PoolConfig{Size: 2, HealthInterval: time.Minute})
+	var launches atomic.Int32
+	p.launch = func() (*instance, error) {
+		launches.Add(1)
+		time.Sleep(50 * time.Millisecond) // Every job asks while the browsers are still starting
+		return &instance{}, nil
+	}
+
+	const jobs = 8
+	picked := make([]*instance, jobs)
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inst, err := p.pick(context.Background())
+			assert.NoError(t, err)
+			picked[i] = inst
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 2, launches.Load(), "BROWSER_POOL_SIZE caps the browsers")
+	require.Len(t, p.instances, 2)
+	assert.Equal(t, jobs, p.instances[0].active+p.instances[1].active)
+	for _, inst := range picked {
+		assert.Contains(t, p.instances, inst)
+	}
+}
+
+func TestPool_WaitingPickGivesUpWithTheJob(t *testing.T) {
+	p := NewPool(PoolConfig{Size: 1, HealthInterval: time.Minute})
+	started, finish := make(chan struct{}), make(chan struct{})
+	p.launch = func() (*instance, error) {
+		close(started)
+		<-finish
+		return nil, errors.New("no chrome")
+	}
+	defer close(finish)
+	go p.pick(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := p.pick(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPool_RetiredBrowserCountsUntilItsJobsFinish(t *testing.T) {
+	p := NewPool(PoolConfig{Size: 1, MaxPages: 1, HealthInterval: time.Minute})
+	var launches atomic.Int32
+	p.launch = func() (*instance, error) {
+		launches.Add(1)
+		return &instance{}, nil
+	}
+
+	old, err := p.pick(context.Background())
+	require.NoError(t, err)
+	(&Lease{pool: p, inst: old}).countPage()
+	require.True(t, old.retired)
+
+	picked := make(chan *instance, 1)
+	go func() {
+		inst, err := p.pick(context.Background())
+		assert.NoError(t, err)
+		picked <- inst
+	}()
+	select {
+	case <-picked:
+		t.Fatal("a second browser launched while the retired one was still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.EqualValues(t, 1, launches.Load())
+
+	p.mu.Lock()
+	old.active-- // Release without closing the fake browser
+	p.remove(old)
+	p.mu.Unlock()
+	inst := <-picked
+	assert.NotSame(t, old, inst)
+	assert.EqualValues(t, 2, launches.Load())
+}
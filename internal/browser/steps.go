@@ -0,0 +1,149 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Typed failures of the automation flows; callers tell them apart with errors.Is
+var (
+	ErrSessionExpired   = errors.New("session expired")                    // The platform sent us to its login page; the account needs reconnecting
+	ErrSelectorNotFound = errors.New("selector not found")                 // The page changed, or never finished loading
+	ErrWAFBlocked       = errors.New("blocked by the platform's firewall") // A bot challenge, or saves the platform refuses
+	ErrTimeout          = errors.New("browser automation timed out")
+)
+
+const (
+	navigationTimeout = 30 * time.Second
+	elementTimeout    = 15 * time.Second
+)
+
+// wafTitles are page titles of bot challenges (Cloudflare, Fastly and friends)
+var wafTitles = []string{"just a moment", "attention required", "access denied", "request blocked"}
+
+// wafSelectors are elements only challenge pages have
+const wafSelectors = "#challenge-form, #cf-challenge-running, #challenge-stage, iframe[src*='challenges.cloudflare.com']"
+
+// stepError names the step that failed and turns a step timeout into ErrTimeout.
+// A cancelled job keeps context.Canceled, so shutdown isn't taken for a failure.
+func stepError(step string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%s: %w", step, ErrTimeout)
+	default:
+		return fmt.Errorf("%s: %w", step, err)
+	}
+}
+
+// navigate loads url, waiting at most navigationTimeout
+func navigate(page *rod.Page, url string) (err error) {
+	defer traced(page.GetContext(), "navigate to "+url, time.Now(), &err)
+	p := page.Timeout(navigationTimeout)
+	defer p.CancelTimeout()
+	if err := p.Navigate(url); err != nil {
+		return stepError("navigate to "+url, err)
+	}
+	if err := p.WaitLoad(); err != nil {
+		return stepError("load "+url, err)
+	}
+	return nil
+}
+
+// waitStable waits for the page to stop changing, at most elementTimeout
+func waitStable(page *rod.Page) (err error) {
+	defer traced(page.GetContext(), "wait for the page", time.Now(), &err)
+	p := page.Timeout(elementTimeout)
+	defer p.CancelTimeout()
+	if err := p.WaitStable(time.Second); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return stepError("wait for the page", err)
+	}
+	// A page that keeps animating is still usable
+	return page.GetContext().Err()
+}
+
+// element waits up to elementTimeout for the first of the selectors to match
+func element(page *rod.Page, selectors ...string) (el *rod.Element, err error) {
+	defer traced(page.GetContext(), "find "+strings.Join(selectors, " | "), time.Now(), &err)
+	p := page.Timeout(elementTimeout)
+	defer p.CancelTimeout()
+	race := p.Race()
+	for _, selector := range selectors {
+		race = race.Element(selector)
+	}
+	el, err = race.Do()
+	if err != nil {
+		return nil, notFound(page, err, strings.Join(selectors, " | "))
+	}
+	return el.Context(page.GetContext()), nil
+}
+
+// elementR waits up to elementTimeout for a selector match whose text matches the regex
+func elementR(page *rod.Page, selector, regex string) (el *rod.Element, err error) {
+	defer traced(page.GetContext(), fmt.Sprintf("find %s /%s/", selector, regex), time.Now(), &err)
+	p := page.Timeout(elementTimeout)
+	defer p.CancelTimeout()
+	el, err = p.ElementR(selector, regex)
+	if err != nil {
+		return nil, notFound(page, err, fmt.Sprintf("%s /%s/", selector, regex))
+	}
+	return el.Context(page.GetContext()), nil
+}
+
+// notFound reports a selector that never matched, unless the job was cancelled meanwhile
+func notFound(page *rod.Page, err error, selector string) error {
+	if ctxErr := page.GetContext().Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", ErrSelectorNotFound, selector)
+	}
+	return stepError("find "+selector, err)
+}
+
+// elementText reads the element's text, waiting at most elementTimeout
+func elementText(el *rod.Element) (string, error) {
+	e := el.Timeout(elementTimeout)
+	defer e.CancelTimeout()
+	text, err := e.Text()
+	if err != nil {
+		return "", stepError("read the text", err)
+	}
+	return text, nil
+}
+
+// click clicks the element once with the left button
+func click(el *rod.Element, step string) (err error) {
+	defer traced(el.GetContext(), step, time.Now(), &err)
+	return stepError(step, el.Click(proto.InputMouseButtonLeft, 1))
+}
+
+// checkLanding tells why a page isn't the one we asked for: the platform's
+// login page (ErrSessionExpired) or a bot challenge (ErrWAFBlocked)
+func checkLanding(page *rod.Page, loginPrefix string) (err error) {
+	defer traced(page.GetContext(), "check the landing page", time.Now(), &err)
+	info, err := page.Info()
+	if err != nil {
+		return stepError("read the page", err)
+	}
+	if strings.HasPrefix(info.URL, loginPrefix) {
+		return fmt.Errorf("redirected to %s: %w", info.URL, ErrSessionExpired)
+	}
+	title := strings.ToLower(info.Title)
+	for _, marker := range wafTitles {
+		if strings.Contains(title, marker) {
+			return fmt.Errorf("%q page: %w", info.Title, ErrWAFBlocked)
+		}
+	}
+	if has, _, _ := page.Has(wafSelectors); has {
+		return fmt.Errorf("challenge page at %s: %w", info.URL, ErrWAFBlocked)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepError(t *testing.T) {
+	assert.NoError(t, stepError("click publish", nil))
+
+	err := stepError("click publish", fmt.Errorf("cdp: %w", context.DeadlineExceeded))
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.EqualError(t, err, "click publish: browser automation timed out")
+
+	err = stepError("click publish", context.Canceled)
+	assert.ErrorIs(t, err, context.Canceled, "a cancelled job isn't a timeout")
+	assert.False(t, errors.Is(err, ErrTimeout))
+}
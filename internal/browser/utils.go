@@ -23,10 +23,12 @@ func RandomSleep(baseMs int, jitterMs int) {
 }
 
 // HumanType simulates human-like typing with jitter.
-func HumanType(page *rod.Page, text string) {
+func HumanType(page *rod.Page, text string) error {
 	for i, char := range text {
 		k := input.Key(char)
-		page.Keyboard.Type(k)
+		if err := page.Keyboard.Type(k); err != nil {
+			return err
+		}
 
 		latency := rand.Intn(50) + 30
 		if i%15 == 0 {
@@ -34,8 +36,7 @@ func HumanType(page *rod.Page, text string) {
 		}
 		time.Sleep(time.Duration(latency) * time.Millisecond)
 	}
-	page.Keyboard.MustType(input.Space)
-	page.Keyboard.MustType(input.Backspace)
+	return page.Keyboard.Type(input.Space, input.Backspace)
 }
 
 // PrepareImageUpload resolves a data URL, HTTP(S) URL, or local path into a temp file ready for SetFiles.
@@ -0,0 +1,180 @@
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	sendBuffer = 256
+	// maxFrameBytes bounds a single update or snapshot
+	maxFrameBytes = 2 << 20
+)
+
+// Client is one WebSocket connection in a draft room
+type Client struct {
+	ID          string
+	DraftID     string
+	WorkspaceID string
+	UserID      string
+
+	send chan []byte
+
+	mu        sync.Mutex
+	canEdit   bool
+	checkedAt time.Time // When canEdit was last checked against the user's role
+	cursor    *Cursor
+	seenAt    time.Time
+	closed    bool
+}
+
+func NewClient(draftID, workspaceID, userID string, canEdit bool) *Client {
+	return &Client{
+		ID:          randomID(),
+		DraftID:     draftID,
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		canEdit:     canEdit,
+		checkedAt:   time.Now(),
+		send:        make(chan []byte, sendBuffer),
+	}
+}
+
+// Serve joins the room and pumps frames until the connection closes
+func (c *Client) Serve(ctx context.Context, hub *Hub, ws *websocket.Conn) {
+	ws.MaxPayloadBytes = maxFrameBytes
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go c.writePump(ctx, ws)
+
+	if err := hub.Join(ctx, c); err != nil {
+		log.Printf("❌ Collab join failed for draft %s: %v", c.DraftID, err)
+		c.Send(Message{Type: TypeError, Error: "failed to join draft"})
+		hub.Leave(context.Background(), c)
+		c.close()
+		return
+	}
+	defer func() {
+		hub.Leave(context.Background(), c)
+		c.close()
+	}()
+
+	for {
+		var msg Message
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+		if err := hub.Handle(ctx, c, msg); err != nil {
+			c.Send(Message{Type: TypeError, Error: err.Error()})
+			if errors.Is(err, ErrRemoved) {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) writePump(ctx context.Context, ws *websocket.Conn) {
+	defer ws.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data, ok := <-c.send:
+			if !ok {
+				return
+			}
+			if err := websocket.Message.Send(ws, string(data)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Send queues a message for this client
+func (c *Client) Send(msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("⚠️ Failed to encode collab message: %v", err)
+		return
+	}
+	c.sendRaw(data)
+}
+
+// sendRaw never blocks: a client that can't keep up is disconnected
+// and has to resync, which is cheaper than stalling the room
+func (c *Client) sendRaw(data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- data:
+	default:
+		log.Printf("⚠️ Collab client %s is too slow, disconnecting", c.ID)
+		c.closed = true
+		close(c.send)
+	}
+}
+
+func (c *Client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// CanEdit reports whether the client may change the document
+func (c *Client) CanEdit() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.canEdit
+}
+
+// roleCheckDue reports whether the client's role was last checked longer than
+// every ago, and if so counts it as checked now
+func (c *Client) roleCheckDue(every time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.checkedAt) < every {
+		return false
+	}
+	c.checkedAt = time.Now()
+	return true
+}
+
+// setCanEdit updates the client's edit permission and reports whether it changed
+func (c *Client) setCanEdit(canEdit bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := c.canEdit != canEdit
+	c.canEdit = canEdit
+	return changed
+}
+
+func (c *Client) setCursor(cursor *Cursor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cursor = cursor
+}
+
+func (c *Client) touch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seenAt = time.Now()
+}
+
+func (c *Client) presence() Presence {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Presence{ClientID: c.ID, UserID: c.UserID, CanEdit: c.canEdit, Cursor: c.cursor, SeenAt: c.seenAt}
+}
@@ -0,0 +1,560 @@
+package collab
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+	"postificus/internal/textdiff"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	channelPrefix = "collab:draft:"
+
+	// maxBacklog caps the updates kept for late joiners
+	maxBacklog = 5000
+	// backlogTTL drops a room's backlog after a day without activity
+	backlogTTL = 24 * time.Hour
+	// presenceTTL hides editors that stopped sending heartbeats (e.g. their instance died)
+	presenceTTL = 90 * time.Second
+	// roleCheckInterval is how often a connected member's role is looked up again;
+	// updates come with every keystroke, so not on each of them
+	roleCheckInterval = 10 * time.Second
+)
+
+var (
+	ErrReadOnly = errors.New("read-only participant")
+	// ErrRemoved disconnects a client whose user can no longer see the draft
+	ErrRemoved = errors.New("no longer a member of the workspace")
+)
+
+// DraftSaver loads drafts and persists collaborative snapshots; *service.DraftService satisfies it
+type DraftSaver interface {
+	GetDraft(ctx context.Context, workspaceID string, userID string, id string) (*domain.Draft, error)
+	// SaveContent saves the title and content if the draft is still at version and
+	// returns the saved draft; storage.ErrVersionConflict if it changed since
+	SaveContent(ctx context.Context, workspaceID, userID, draftID string, version int64, title, content string) (*domain.Draft, error)
+}
+
+// RoleChecker looks up a user's current role; *service.WorkspaceService satisfies it
+type RoleChecker interface {
+	RoleOf(ctx context.Context, workspaceID string, userID string) (domain.Role, error)
+}
+
+// Hub tracks the draft rooms on this instance.
+// With a Redis client, messages and presence are shared with other instances;
+// without one it works for a single instance.
+type Hub struct {
+	instanceID string
+	redis      *redis.Client
+	drafts     DraftSaver
+	roles      RoleChecker // Optional; without it a client keeps the role it connected with
+
+	mu    sync.Mutex
+	rooms map[string]*room
+}
+
+type room struct {
+	clients map[*Client]struct{}
+	backlog []json.RawMessage // Only used without Redis; dropped with the room
+	version int64             // Draft version the room's document is based on; 0 until loaded
+	base    *document         // Only used without Redis: the saved document at some version
+
+	saveMu sync.Mutex // One snapshot save at a time, each against the previous one's version
+}
+
+// document is a saved title and content, the base that snapshots are merged from
+type document struct {
+	Version int64  `json:"version"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+func NewHub(drafts DraftSaver, roles RoleChecker, redisClient *redis.Client) *Hub {
+	return &Hub{
+		instanceID: randomID(),
+		redis:      redisClient,
+		drafts:     drafts,
+		roles:      roles,
+		rooms:      make(map[string]*room),
+	}
+}
+
+// Run relays messages published by other instances until ctx is cancelled
+func (h *Hub) Run(ctx context.Context) {
+	if h.redis == nil {
+		return
+	}
+
+	sub := h.redis.PSubscribe(ctx, channelPrefix+"*")
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var env envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				log.Printf("⚠️ Invalid collab envelope: %v", err)
+				continue
+			}
+			if env.Origin == h.instanceID {
+				continue
+			}
+			h.observe(env.DraftID, env.Message)
+			h.deliver(env.DraftID, env.Message, nil)
+		}
+	}
+}
+
+// Join adds the client to its draft's room and sends it the backlog and roster
+func (h *Hub) Join(ctx context.Context, c *Client) error {
+	h.mu.Lock()
+	r, ok := h.rooms[c.DraftID]
+	if !ok {
+		r = &room{clients: make(map[*Client]struct{})}
+		h.rooms[c.DraftID] = r
+	}
+	r.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	// A new room's snapshots are saved against the version it started from, so
+	// edits made outside the session in the meantime aren't overwritten
+	if !ok {
+		draft, err := h.drafts.GetDraft(ctx, c.WorkspaceID, c.UserID, c.DraftID)
+		if err != nil {
+			return fmt.Errorf("failed to load draft: %w", err)
+		}
+		if err := h.setBase(ctx, c.DraftID, draft); err != nil {
+			return err
+		}
+		h.setVersion(c.DraftID, draft.Version)
+	}
+
+	backlog, err := h.backlog(ctx, c.DraftID)
+	if err != nil {
+		return err
+	}
+	if err := h.touchPresence(ctx, c); err != nil {
+		return err
+	}
+	roster, err := h.roster(ctx, c.DraftID)
+	if err != nil {
+		return err
+	}
+
+	c.Send(Message{Type: TypeSync, ClientID: c.ID, Updates: backlog, Presence: roster})
+	return h.broadcast(ctx, c, Message{Type: TypePresence, Presence: []Presence{c.presence()}})
+}
+
+// Leave removes the client and tells the room
+func (h *Hub) Leave(ctx context.Context, c *Client) {
+	h.mu.Lock()
+	if r, ok := h.rooms[c.DraftID]; ok {
+		delete(r.clients, c)
+		if len(r.clients) == 0 {
+			delete(h.rooms, c.DraftID)
+		}
+	}
+	h.mu.Unlock()
+
+	if h.redis != nil {
+		h.redis.HDel(ctx, presenceKey(c.DraftID), c.ID)
+	}
+	if err := h.broadcast(ctx, c, Message{Type: TypeLeave, ClientID: c.ID}); err != nil {
+		log.Printf("⚠️ Failed to announce collab leave for draft %s: %v", c.DraftID, err)
+	}
+}
+
+// Handle processes one message received from a client
+func (h *Hub) Handle(ctx context.Context, c *Client, msg Message) error {
+	if err := h.recheckRole(ctx, c); err != nil {
+		return err
+	}
+
+	switch msg.Type {
+	case TypeUpdate:
+		if !c.CanEdit() {
+			return ErrReadOnly
+		}
+		if len(msg.Update) == 0 {
+			return errors.New("update is empty")
+		}
+		if err := h.appendBacklog(ctx, c.DraftID, msg.Update); err != nil {
+			return err
+		}
+		return h.broadcast(ctx, c, Message{Type: TypeUpdate, ClientID: c.ID, UserID: c.UserID, Update: msg.Update})
+
+	case TypePresence:
+		c.setCursor(msg.Cursor)
+		if err := h.touchPresence(ctx, c); err != nil {
+			return err
+		}
+		return h.broadcast(ctx, c, Message{Type: TypePresence, Presence: []Presence{c.presence()}})
+
+	case TypeSnapshot:
+		if !c.CanEdit() {
+			return ErrReadOnly
+		}
+		return h.saveSnapshot(ctx, c, msg)
+
+	default:
+		return fmt.Errorf("unknown message type %q", msg.Type)
+	}
+}
+
+// recheckRole looks the client's role up again, at most every roleCheckInterval:
+// members can be demoted or removed while connected. A demoted editor carries on
+// read-only, and the room sees the change in its presence; a removed member gets
+// ErrRemoved and is disconnected.
+func (h *Hub) recheckRole(ctx context.Context, c *Client) error {
+	if h.roles == nil || !c.roleCheckDue(roleCheckInterval) {
+		return nil
+	}
+	role, err := h.roles.RoleOf(ctx, c.WorkspaceID, c.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !role.Can(domain.PermViewDrafts) {
+		return ErrRemoved
+	}
+	if c.setCanEdit(role.Can(domain.PermEditDrafts)) {
+		return h.broadcast(ctx, c, Message{Type: TypePresence, Presence: []Presence{c.presence()}})
+	}
+	return nil
+}
+
+// saveSnapshot saves the document against the version the room last loaded or
+// saved. Saved like an editor autosave, so it is throttled and deduplicated into
+// revisions. If the draft was changed outside the session (e.g. a REST save), the
+// snapshot is merged three-way with the saved draft, from the document the room
+// was based on, and everyone is reset to the merged draft. Edits that can't be
+// merged are sent to the room as a conflict before it's reset to the saved draft.
+func (h *Hub) saveSnapshot(ctx context.Context, c *Client, msg Message) error {
+	h.mu.Lock()
+	r, ok := h.rooms[c.DraftID]
+	h.mu.Unlock()
+	if !ok {
+		return errors.New("not in the draft's room")
+	}
+
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	version := h.version(c.DraftID)
+	if version == 0 {
+		return errors.New("draft is still loading")
+	}
+
+	saved, err := h.drafts.SaveContent(ctx, c.WorkspaceID, c.UserID, c.DraftID, version, msg.Title, msg.Content)
+	switch {
+	case err == nil:
+		if err := h.setBase(ctx, c.DraftID, saved); err != nil {
+			return err
+		}
+		h.setVersion(c.DraftID, saved.Version)
+		return h.publish(ctx, c.DraftID, Message{Type: TypeSaved, Version: saved.Version}, nil)
+	case !errors.Is(err, storage.ErrVersionConflict):
+		return err
+	}
+
+	server, err := h.drafts.GetDraft(ctx, c.WorkspaceID, c.UserID, c.DraftID)
+	if err != nil {
+		return fmt.Errorf("failed to reload draft: %w", err)
+	}
+	base, err := h.base(ctx, c.DraftID, version)
+	if err != nil {
+		return err
+	}
+	if base != nil {
+		title, titleOK := textdiff.MergeLine(base.Title, msg.Title, server.Title)
+		content, conflicts := textdiff.Merge3(base.Content, msg.Content, server.Content)
+		if titleOK && len(conflicts) == 0 {
+			merged, err := h.drafts.SaveContent(ctx, c.WorkspaceID, c.UserID, c.DraftID, server.Version, title, content)
+			switch {
+			case err == nil:
+				return h.reset(ctx, c.DraftID, merged)
+			case !errors.Is(err, storage.ErrVersionConflict):
+				return err
+			}
+			// Lost a second race; the room's edits go out as a conflict against the newest copy
+			if server, err = h.drafts.GetDraft(ctx, c.WorkspaceID, c.UserID, c.DraftID); err != nil {
+				return fmt.Errorf("failed to reload draft: %w", err)
+			}
+		}
+	}
+
+	// The room's document is about to be replaced; clients can offer it back to their users
+	conflict := Message{Type: TypeConflict, Title: msg.Title, Content: msg.Content, Version: server.Version}
+	if err := h.publish(ctx, c.DraftID, conflict, nil); err != nil {
+		return err
+	}
+	return h.reset(ctx, c.DraftID, server)
+}
+
+// reset restarts the room from the saved draft: the updates so far build the
+// document it replaces, so late joiners start from the draft instead
+func (h *Hub) reset(ctx context.Context, draftID string, draft *domain.Draft) error {
+	if err := h.clearBacklog(ctx, draftID); err != nil {
+		return err
+	}
+	if err := h.setBase(ctx, draftID, draft); err != nil {
+		return err
+	}
+	h.setVersion(draftID, draft.Version)
+	return h.publish(ctx, draftID, Message{Type: TypeReset, Title: draft.Title, Content: draft.Content, Version: draft.Version}, nil)
+}
+
+// setBase keeps the saved draft as the base of merges against its version; in
+// Redis, so the instance that saves next can merge from it
+func (h *Hub) setBase(ctx context.Context, draftID string, draft *domain.Draft) error {
+	doc := &document{Version: draft.Version, Title: draft.Title, Content: draft.Content}
+	if h.redis == nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if r, ok := h.rooms[draftID]; ok && (r.base == nil || doc.Version >= r.base.Version) {
+			r.base = doc
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return h.redis.Set(ctx, baseKey(draftID, draft.Version), data, backlogTTL).Err()
+}
+
+// base returns the saved document at version, or nil if it isn't known
+func (h *Hub) base(ctx context.Context, draftID string, version int64) (*document, error) {
+	if h.redis == nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if r, ok := h.rooms[draftID]; ok && r.base != nil && r.base.Version == version {
+			return r.base, nil
+		}
+		return nil, nil
+	}
+
+	data, err := h.redis.Get(ctx, baseKey(draftID, version)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load collab base: %w", err)
+	}
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil
+	}
+	return &doc, nil
+}
+
+// version returns the draft version the room's document is based on
+func (h *Hub) version(draftID string) int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if r, ok := h.rooms[draftID]; ok {
+		return r.version
+	}
+	return 0
+}
+
+// setVersion moves the room's version forward; versions only grow, so a late
+// message from another instance can't move it back
+func (h *Hub) setVersion(draftID string, version int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if r, ok := h.rooms[draftID]; ok && version > r.version {
+		r.version = version
+	}
+}
+
+// observe keeps the room's version in step with saves made on other instances
+func (h *Hub) observe(draftID string, data []byte) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+	if msg.Type == TypeSaved || msg.Type == TypeReset {
+		h.setVersion(draftID, msg.Version)
+	}
+}
+
+// broadcast delivers msg to everyone in the room except the sender, on all instances
+func (h *Hub) broadcast(ctx context.Context, from *Client, msg Message) error {
+	return h.publish(ctx, from.DraftID, msg, from)
+}
+
+// publish delivers msg to everyone in the draft's room but except, on all instances
+func (h *Hub) publish(ctx context.Context, draftID string, msg Message, except *Client) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	h.deliver(draftID, data, except)
+
+	if h.redis == nil {
+		return nil
+	}
+	env, err := json.Marshal(envelope{Origin: h.instanceID, DraftID: draftID, Message: data})
+	if err != nil {
+		return err
+	}
+	return h.redis.Publish(ctx, channelPrefix+draftID, env).Err()
+}
+
+func (h *Hub) deliver(draftID string, data []byte, except *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[draftID]
+	if !ok {
+		return
+	}
+	for c := range r.clients {
+		if c != except {
+			c.sendRaw(data)
+		}
+	}
+}
+
+func (h *Hub) appendBacklog(ctx context.Context, draftID string, update json.RawMessage) error {
+	if h.redis == nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if r, ok := h.rooms[draftID]; ok {
+			r.backlog = append(r.backlog, update)
+			if len(r.backlog) > maxBacklog {
+				r.backlog = r.backlog[len(r.backlog)-maxBacklog:]
+			}
+		}
+		return nil
+	}
+
+	key := backlogKey(draftID)
+	pipe := h.redis.TxPipeline()
+	pipe.RPush(ctx, key, []byte(update))
+	pipe.LTrim(ctx, key, -maxBacklog, -1)
+	pipe.Expire(ctx, key, backlogTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (h *Hub) clearBacklog(ctx context.Context, draftID string) error {
+	if h.redis == nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if r, ok := h.rooms[draftID]; ok {
+			r.backlog = nil
+		}
+		return nil
+	}
+	return h.redis.Del(ctx, backlogKey(draftID)).Err()
+}
+
+func (h *Hub) backlog(ctx context.Context, draftID string) ([]json.RawMessage, error) {
+	if h.redis == nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if r, ok := h.rooms[draftID]; ok {
+			return append([]json.RawMessage(nil), r.backlog...), nil
+		}
+		return nil, nil
+	}
+
+	items, err := h.redis.LRange(ctx, backlogKey(draftID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load collab backlog: %w", err)
+	}
+	updates := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		updates[i] = json.RawMessage(item)
+	}
+	return updates, nil
+}
+
+func (h *Hub) touchPresence(ctx context.Context, c *Client) error {
+	c.touch()
+	if h.redis == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(c.presence())
+	if err != nil {
+		return err
+	}
+	key := presenceKey(c.DraftID)
+	pipe := h.redis.TxPipeline()
+	pipe.HSet(ctx, key, c.ID, data)
+	pipe.Expire(ctx, key, backlogTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// roster lists everyone currently in the room, across instances
+func (h *Hub) roster(ctx context.Context, draftID string) ([]Presence, error) {
+	if h.redis == nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		roster := []Presence{}
+		if r, ok := h.rooms[draftID]; ok {
+			for c := range r.clients {
+				roster = append(roster, c.presence())
+			}
+		}
+		return roster, nil
+	}
+
+	entries, err := h.redis.HGetAll(ctx, presenceKey(draftID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load presence: %w", err)
+	}
+	roster := []Presence{}
+	for clientID, raw := range entries {
+		var p Presence
+		if err := json.Unmarshal([]byte(raw), &p); err != nil || time.Since(p.SeenAt) > presenceTTL {
+			h.redis.HDel(ctx, presenceKey(draftID), clientID)
+			continue
+		}
+		roster = append(roster, p)
+	}
+	return roster, nil
+}
+
+func backlogKey(draftID string) string {
+	return channelPrefix + draftID + ":updates"
+}
+
+func presenceKey(draftID string) string {
+	return channelPrefix + draftID + ":presence"
+}
+
+func baseKey(draftID string, version int64) string {
+	return channelPrefix + draftID + ":base:" + strconv.FormatInt(version, 10)
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strings.ReplaceAll(time.Now().Format("150405.000000000"), ".", "")
+	}
+	return hex.EncodeToString(b)
+}
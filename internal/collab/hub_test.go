@@ -0,0 +1,229 @@
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSaver stores drafts at a version, like the draft service's If-Match saves
+type fakeSaver struct {
+	saved   []*domain.Draft
+	current domain.Draft
+}
+
+func (f *fakeSaver) GetDraft(ctx context.Context, workspaceID string, userID string, id string) (*domain.Draft, error) {
+	draft := f.current
+	if draft.Version == 0 {
+		draft.Version = 1
+	}
+	return &draft, nil
+}
+
+func (f *fakeSaver) SaveContent(ctx context.Context, workspaceID, userID, draftID string, version int64, title, content string) (*domain.Draft, error) {
+	current, _ := f.GetDraft(ctx, workspaceID, userID, draftID)
+	if version != current.Version {
+		return nil, storage.ErrVersionConflict
+	}
+	f.current = domain.Draft{ID: draftID, WorkspaceID: workspaceID, UserID: userID, Title: title, Content: content, Version: version + 1}
+	saved := f.current
+	f.saved = append(f.saved, &saved)
+	return &saved, nil
+}
+
+func next(t *testing.T, c *Client) Message {
+	t.Helper()
+	select {
+	case data := <-c.send:
+		var msg Message
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return msg
+	default:
+		t.Fatalf("no message queued for %s", c.UserID)
+		return Message{}
+	}
+}
+
+func TestHub_RelaysUpdatesAndPresence(t *testing.T) {
+	ctx := context.Background()
+	saver := &fakeSaver{}
+	hub := NewHub(saver, nil, nil)
+
+	alice := NewClient("d1", "ws", "alice", true)
+	bob := NewClient("d1", "ws", "bob", true)
+	other := NewClient("d2", "ws", "carol", true)
+
+	require.NoError(t, hub.Join(ctx, alice))
+	assert.Equal(t, TypeSync, next(t, alice).Type)
+	require.NoError(t, hub.Join(ctx, other))
+	next(t, other)
+
+	require.NoError(t, hub.Handle(ctx, alice, Message{Type: TypeUpdate, Update: json.RawMessage(`[1,2,3]`)}))
+
+	// Late joiners get the backlog and the roster
+	require.NoError(t, hub.Join(ctx, bob))
+	sync := next(t, bob)
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`[1,2,3]`)}, sync.Updates)
+	assert.Len(t, sync.Presence, 2)
+
+	joined := next(t, alice)
+	assert.Equal(t, TypePresence, joined.Type)
+	assert.Equal(t, "bob", joined.Presence[0].UserID)
+
+	require.NoError(t, hub.Handle(ctx, bob, Message{Type: TypePresence, Cursor: &Cursor{Anchor: 3, Head: 7}}))
+	cursor := next(t, alice)
+	assert.Equal(t, &Cursor{Anchor: 3, Head: 7}, cursor.Presence[0].Cursor)
+
+	require.NoError(t, hub.Handle(ctx, bob, Message{Type: TypeUpdate, Update: json.RawMessage(`"op"`)}))
+	update := next(t, alice)
+	assert.Equal(t, bob.ID, update.ClientID)
+	assert.JSONEq(t, `"op"`, string(update.Update))
+
+	// Other rooms and the sender itself hear nothing
+	assert.Empty(t, other.send)
+	assert.Empty(t, bob.send)
+
+	hub.Leave(ctx, bob)
+	assert.Equal(t, Message{Type: TypeLeave, ClientID: bob.ID}, next(t, alice))
+}
+
+func TestHub_ReadOnlyAndSnapshots(t *testing.T) {
+	ctx := context.Background()
+	saver := &fakeSaver{}
+	hub := NewHub(saver, nil, nil)
+
+	viewer := NewClient("d1", "ws", "viewer", false)
+	editor := NewClient("d1", "ws", "editor", true)
+	require.NoError(t, hub.Join(ctx, viewer))
+	require.NoError(t, hub.Join(ctx, editor))
+
+	assert.ErrorIs(t, hub.Handle(ctx, viewer, Message{Type: TypeUpdate, Update: json.RawMessage(`1`)}), ErrReadOnly)
+	assert.ErrorIs(t, hub.Handle(ctx, viewer, Message{Type: TypeSnapshot, Content: "x"}), ErrReadOnly)
+
+	require.NoError(t, hub.Handle(ctx, editor, Message{Type: TypeSnapshot, Title: "T", Content: "Body"}))
+	require.Len(t, saver.saved, 1)
+	assert.Equal(t, &domain.Draft{ID: "d1", WorkspaceID: "ws", UserID: "editor", Title: "T", Content: "Body", Version: 2}, saver.saved[0])
+}
+
+func TestHub_SnapshotConflictingWithOutsideEditResetsRoom(t *testing.T) {
+	ctx := context.Background()
+	saver := &fakeSaver{current: domain.Draft{ID: "d1", Title: "T", Content: "Start", Version: 3}}
+	hub := NewHub(saver, nil, nil)
+
+	alice := NewClient("d1", "ws", "alice", true)
+	bob := NewClient("d1", "ws", "bob", true)
+	require.NoError(t, hub.Join(ctx, alice))
+	require.NoError(t, hub.Join(ctx, bob))
+	next(t, alice)
+	next(t, alice)
+	next(t, bob)
+
+	// Snapshots in a row each build on the one before
+	require.NoError(t, hub.Handle(ctx, alice, Message{Type: TypeSnapshot, Title: "T", Content: "One"}))
+	assert.Equal(t, Message{Type: TypeSaved, Version: 4}, next(t, bob))
+	assert.Equal(t, Message{Type: TypeSaved, Version: 4}, next(t, alice))
+	require.NoError(t, hub.Handle(ctx, bob, Message{Type: TypeUpdate, Update: json.RawMessage(`"op"`)}))
+	next(t, alice)
+
+	// A REST save lands while the session is open
+	saver.current = domain.Draft{ID: "d1", Title: "T", Content: "Edited over REST", Version: 5}
+
+	require.NoError(t, hub.Handle(ctx, bob, Message{Type: TypeSnapshot, Title: "T", Content: "Two"}))
+	assert.Equal(t, "Edited over REST", saver.current.Content, "the outside edit survives")
+	// Both changed the same line: the room hears what it's losing before the reset
+	conflict := Message{Type: TypeConflict, Title: "T", Content: "Two", Version: 5}
+	reset := Message{Type: TypeReset, Title: "T", Content: "Edited over REST", Version: 5}
+	for _, c := range []*Client{alice, bob} {
+		assert.Equal(t, conflict, next(t, c))
+		assert.Equal(t, reset, next(t, c))
+	}
+
+	// The room carries on from the server copy
+	late := NewClient("d1", "ws", "carol", true)
+	require.NoError(t, hub.Join(ctx, late))
+	assert.Empty(t, next(t, late).Updates)
+	require.NoError(t, hub.Handle(ctx, alice, Message{Type: TypeSnapshot, Title: "T", Content: "Three"}))
+	assert.Equal(t, "Three", saver.current.Content)
+}
+
+func TestHub_SnapshotMergesOutsideEdit(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	for name, redisClient := range map[string]*redis.Client{"local": nil, "redis": client} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			saver := &fakeSaver{current: domain.Draft{ID: "d1", Title: "T", Content: "intro\nmiddle\nend\n", Version: 3}}
+			hub := NewHub(saver, nil, redisClient)
+
+			alice := NewClient("d1", "ws", "alice", true)
+			require.NoError(t, hub.Join(ctx, alice))
+			next(t, alice)
+			require.NoError(t, hub.Handle(ctx, alice, Message{Type: TypeUpdate, Update: json.RawMessage(`"op"`)}))
+
+			// A REST save changes the end while the room edits the intro
+			saver.current = domain.Draft{ID: "d1", Title: "T", Content: "intro\nmiddle\nend, edited over REST\n", Version: 4}
+
+			require.NoError(t, hub.Handle(ctx, alice, Message{Type: TypeSnapshot, Title: "T", Content: "intro, edited live\nmiddle\nend\n"}))
+			merged := "intro, edited live\nmiddle\nend, edited over REST\n"
+			assert.Equal(t, merged, saver.current.Content, "neither side's edit is lost")
+			assert.Equal(t, Message{Type: TypeReset, Title: "T", Content: merged, Version: 5}, next(t, alice))
+
+			// Late joiners start from the merged draft, and the next snapshot builds on it
+			late := NewClient("d1", "ws", "carol", true)
+			require.NoError(t, hub.Join(ctx, late))
+			assert.Empty(t, next(t, late).Updates)
+			require.NoError(t, hub.Handle(ctx, alice, Message{Type: TypeSnapshot, Title: "T", Content: merged + "more\n"}))
+			assert.Equal(t, int64(6), saver.current.Version)
+		})
+	}
+}
+
+// fakeRoles hands out roles like the workspace service
+type fakeRoles map[string]domain.Role
+
+func (f fakeRoles) RoleOf(ctx context.Context, workspaceID string, userID string) (domain.Role, error) {
+	return f[userID], nil
+}
+
+func TestHub_RechecksRolesWhileConnected(t *testing.T) {
+	ctx := context.Background()
+	roles := fakeRoles{"alice": domain.RoleWriter, "bob": domain.RoleWriter}
+	hub := NewHub(&fakeSaver{}, roles, nil)
+
+	alice := NewClient("d1", "ws", "alice", true)
+	bob := NewClient("d1", "ws", "bob", true)
+	require.NoError(t, hub.Join(ctx, alice))
+	require.NoError(t, hub.Join(ctx, bob))
+	next(t, alice)
+	next(t, alice)
+	next(t, bob)
+	stale := func(c *Client) { c.checkedAt = time.Now().Add(-roleCheckInterval) }
+
+	// Demoted: the next check finds out, and the room sees bob go read-only
+	roles["bob"] = domain.RoleViewer
+	require.NoError(t, hub.Handle(ctx, bob, Message{Type: TypeUpdate, Update: json.RawMessage(`1`)}), "checked at most every interval")
+	next(t, alice)
+	stale(bob)
+	assert.ErrorIs(t, hub.Handle(ctx, bob, Message{Type: TypeUpdate, Update: json.RawMessage(`2`)}), ErrReadOnly)
+	assert.False(t, bob.CanEdit())
+	presence := next(t, alice)
+	assert.Equal(t, TypePresence, presence.Type)
+	assert.False(t, presence.Presence[0].CanEdit)
+	assert.Empty(t, alice.send, "the refused update isn't relayed")
+
+	// Removed from the workspace: disconnected
+	delete(roles, "alice")
+	stale(alice)
+	assert.ErrorIs(t, hub.Handle(ctx, alice, Message{Type: TypePresence}), ErrRemoved)
+}
@@ -0,0 +1,90 @@
+// Package collab relays real-time collaborative edits between clients of a draft.
+//
+// The server does not interpret document updates: clients exchange opaque CRDT
+// (e.g. Yjs) or OT operations, and the hub fans them out to every client in the
+// draft's room, across API instances via Redis pub/sub. Recent updates are kept
+// so late joiners can catch up, and clients periodically send a materialized
+// snapshot of the document, which is saved through the draft service and so
+// lands in the revision history.
+//
+// Snapshots are saved against the draft version the room last loaded or saved,
+// like an If-Match save. When the draft was changed outside the session, the
+// snapshot is merged three-way with the saved draft, the backlog cleared, and
+// every client is sent a reset with the merged draft, which replaces its shared
+// document. Edits that overlap the outside change can't be merged: the room is
+// sent a conflict with its own document, then reset to the saved draft.
+//
+// A member's role is checked again while connected: a demoted editor continues
+// read-only, and one removed from the workspace is disconnected.
+//
+// Wire protocol (JSON text frames):
+//
+//	client -> server  {"type":"update","update":<any>}
+//	                  {"type":"presence","cursor":{"anchor":12,"head":18}}
+//	                  {"type":"snapshot","title":"...","content":"..."}
+//	server -> client  {"type":"sync","client_id":"...","updates":[...],"presence":[...]}
+//	                  {"type":"update","client_id":"...","user_id":"...","update":<any>}
+//	                  {"type":"presence","presence":[{...}]}
+//	                  {"type":"leave","client_id":"..."}
+//	                  {"type":"saved","version":7}
+//	                  {"type":"reset","title":"...","content":"...","version":8}
+//	                  {"type":"conflict","title":"...","content":"...","version":8}
+//	                  {"type":"error","error":"..."}
+//
+// Clients should send a presence message at least every 30 seconds as a heartbeat.
+// When the sync backlog is empty, the client seeds the shared document from the saved draft.
+package collab
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	TypeSync     = "sync"
+	TypeUpdate   = "update"
+	TypePresence = "presence"
+	TypeSnapshot = "snapshot"
+	TypeLeave    = "leave"
+	TypeSaved    = "saved"
+	TypeReset    = "reset"
+	TypeConflict = "conflict"
+	TypeError    = "error"
+)
+
+// Cursor is a selection in the shared document; Anchor == Head for a caret
+type Cursor struct {
+	Anchor int `json:"anchor"`
+	Head   int `json:"head"`
+}
+
+// Presence describes one connected editor
+type Presence struct {
+	ClientID string    `json:"client_id"`
+	UserID   string    `json:"user_id"`
+	CanEdit  bool      `json:"can_edit"`
+	Cursor   *Cursor   `json:"cursor,omitempty"`
+	SeenAt   time.Time `json:"seen_at"`
+}
+
+// Message is the envelope for every frame in both directions
+type Message struct {
+	Type     string            `json:"type"`
+	ClientID string            `json:"client_id,omitempty"`
+	UserID   string            `json:"user_id,omitempty"`
+	Update   json.RawMessage   `json:"update,omitempty"`
+	Updates  []json.RawMessage `json:"updates,omitempty"`
+	Cursor   *Cursor           `json:"cursor,omitempty"`
+	Presence []Presence        `json:"presence,omitempty"`
+	Title    string            `json:"title,omitempty"`
+	Content  string            `json:"content,omitempty"`
+	Version  int64             `json:"version,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// envelope is what travels over Redis between API instances
+type envelope struct {
+	Origin  string          `json:"origin"`
+	DraftID string          `json:"draft_id"`
+	Message json.RawMessage `json:"message"`
+}
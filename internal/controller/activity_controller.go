@@ -1,25 +1,29 @@
 package controller
 
 import (
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
+	"postificus/internal/domain"
 	"postificus/internal/service"
+	"postificus/internal/throttle"
 
 	"github.com/labstack/echo/v4"
 )
 
 type ActivityController struct {
-	service *service.ActivityService
+	service    *service.ActivityService
+	workspaces *service.WorkspaceService
 }
 
-func NewActivityController(service *service.ActivityService) *ActivityController {
-	return &ActivityController{service: service}
+func NewActivityController(service *service.ActivityService, workspaces *service.WorkspaceService) *ActivityController {
+	return &ActivityController{service: service, workspaces: workspaces}
 }
 
 func (c *ActivityController) GetDevtoActivity(ctx echo.Context) error {
-	userID := service.DefaultUserID()
+	userID := currentUserID(ctx)
 	limit := 10
 	if raw := ctx.QueryParam("limit"); raw != "" {
 		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
@@ -27,10 +31,15 @@ func (c *ActivityController) GetDevtoActivity(ctx echo.Context) error {
 		}
 	}
 
-	posts, err := c.service.FetchLiveDevtoActivity(ctx.Request().Context(), userID, limit)
+	workspaceID := currentWorkspaceID(ctx, userID)
+	if err := c.workspaces.Authorize(ctx.Request().Context(), workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	posts, err := c.service.FetchLiveDevtoActivity(ctx.Request().Context(), workspaceID, limit)
 	if err != nil {
 		// Can be improved to handle 401 specifically
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return scrapeError(ctx, err)
 	}
 
 	return ctx.JSON(http.StatusOK, map[string]interface{}{
@@ -41,7 +50,7 @@ func (c *ActivityController) GetDevtoActivity(ctx echo.Context) error {
 }
 
 func (c *ActivityController) GetMediumActivity(ctx echo.Context) error {
-	userID := service.DefaultUserID()
+	userID := currentUserID(ctx)
 	limit := 10
 	if raw := ctx.QueryParam("limit"); raw != "" {
 		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
@@ -49,9 +58,14 @@ func (c *ActivityController) GetMediumActivity(ctx echo.Context) error {
 		}
 	}
 
-	posts, err := c.service.FetchLiveMediumActivity(ctx.Request().Context(), userID, limit)
+	workspaceID := currentWorkspaceID(ctx, userID)
+	if err := c.workspaces.Authorize(ctx.Request().Context(), workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	posts, err := c.service.FetchLiveMediumActivity(ctx.Request().Context(), workspaceID, limit)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return scrapeError(ctx, err)
 	}
 
 	return ctx.JSON(http.StatusOK, map[string]interface{}{
@@ -60,3 +74,11 @@ func (c *ActivityController) GetMediumActivity(ctx echo.Context) error {
 		"retrieved_at": time.Now().UTC().Format(time.RFC3339),
 	})
 }
+
+// scrapeError reports a failed live fetch; one over the account's rate limits says when to try again
+func scrapeError(ctx echo.Context, err error) error {
+	if wait, ok := throttle.RetryAfter(err); ok {
+		ctx.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+	return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+}
@@ -38,6 +38,12 @@ func (m *MockActivityRepo) DeleteCredentials(ctx context.Context, userID string,
 func (m *MockActivityRepo) GetAllCredentials(ctx context.Context, userID string) ([]domain.UserCredential, error) {
 	return nil, nil
 }
+func (m *MockActivityRepo) GetCredentialsByID(ctx context.Context, workspaceID string, id string) (*domain.UserCredential, error) {
+	return nil, nil
+}
+func (m *MockActivityRepo) DeleteCredentialsByID(ctx context.Context, workspaceID string, id string) error {
+	return nil
+}
 func (m *MockActivityRepo) UpsertUnifiedPost(ctx context.Context, userID string, post domain.UnifiedPost) error {
 	return nil
 }
@@ -54,8 +60,8 @@ func TestActivityController_GetMediumActivity_NoCreds(t *testing.T) {
 
 	// Mock Chain: Controller -> Service -> Repo
 	mockRepo := new(MockActivityRepo)
-	svc := service.NewActivityService(mockRepo) // Uses real service
-	ctrl := NewActivityController(svc)
+	svc := service.NewActivityService(mockRepo, nil, nil)                // Uses real service
+	ctrl := NewActivityController(svc, service.NewWorkspaceService(nil)) // Personal workspace needs no membership lookup
 
 	// User ID assumption (middleware usually sets this, but for test we might need to modify controller or assume default)
 	// The controller reads UserID from... wait, let's check code.
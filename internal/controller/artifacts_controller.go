@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"postificus/internal/service"
+	"postificus/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// artifactsLinkExpiry is how long the download URLs of failure artifacts work
+const artifactsLinkExpiry = 15 * time.Minute
+
+// ArtifactsController serves the failure artifacts of browser runs to operators
+type ArtifactsController struct{}
+
+func NewArtifactsController() *ArtifactsController {
+	return &ArtifactsController{}
+}
+
+// GetPublishArtifacts handles GET /api/admin/publish/:job_id/attempts/:attempt/artifacts,
+// the link in a publish log's artifacts_url. The artifacts bucket is private, so
+// each file comes back as a short-lived presigned URL.
+func (c *ArtifactsController) GetPublishArtifacts(ctx echo.Context) error {
+	attempt, err := strconv.Atoi(ctx.Param("attempt"))
+	if err != nil || attempt < 1 {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "attempt must be a positive number"})
+	}
+
+	key := service.PublishArtifactsKey(ctx.Param("job_id"), attempt)
+	files, err := storage.PresignArtifacts(ctx.Request().Context(), key, artifactsLinkExpiry)
+	if errors.Is(err, storage.ErrNoArtifactsBucket) {
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": "No artifacts bucket configured"})
+	}
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"job_id":     ctx.Param("job_id"),
+		"attempt":    attempt,
+		"files":      files,
+		"expires_at": time.Now().Add(artifactsLinkExpiry).UTC(),
+	})
+}
@@ -21,8 +21,9 @@ func NewAuthController(service *service.AuthService) *AuthController {
 func (c *AuthController) HandleConnectPlatform(ctx echo.Context) error {
 	// Parse request
 	var req struct {
-		Platform string `json:"platform"`
-		UserID   string `json:"user_id"`
+		Platform    string `json:"platform"`
+		UserID      string `json:"user_id"`
+		WorkspaceID string `json:"workspace_id"`
 	}
 	if err := ctx.Bind(&req); err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
@@ -35,12 +36,18 @@ func (c *AuthController) HandleConnectPlatform(ctx echo.Context) error {
 
 	// Default User ID (MVP)
 	if req.UserID == "" {
-		req.UserID = service.DefaultUserID()
+		req.UserID = currentUserID(ctx)
+	}
+	if req.WorkspaceID == "" {
+		req.WorkspaceID = currentWorkspaceID(ctx, req.UserID)
 	}
 
 	// Call Service
-	username, err := c.service.ConnectPlatform(ctx.Request().Context(), req.UserID, req.Platform)
+	username, err := c.service.ConnectPlatform(ctx.Request().Context(), req.WorkspaceID, req.UserID, req.Platform)
 	if err != nil {
+		if errorStatus(err) == http.StatusForbidden {
+			return ctx.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		return ctx.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
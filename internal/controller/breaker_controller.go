@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"postificus/internal/breaker"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BreakerController is the operator API over the publish circuit breakers
+type BreakerController struct {
+	breakers *breaker.Group
+}
+
+func NewBreakerController(breakers *breaker.Group) *BreakerController {
+	return &BreakerController{breakers: breakers}
+}
+
+// ListBreakers handles GET /api/admin/breakers
+func (c *BreakerController) ListBreakers(ctx echo.Context) error {
+	snaps, err := c.breakers.Snapshots(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{"breakers": snaps})
+}
+
+// ResetBreaker handles POST /api/admin/breakers/:name/reset, closing the
+// breaker without waiting for a probe (e.g. once a platform outage is over)
+func (c *BreakerController) ResetBreaker(ctx echo.Context) error {
+	err := c.breakers.Reset(ctx.Request().Context(), ctx.Param("name"))
+	if errors.Is(err, breaker.ErrUnknownBreaker) {
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": "Unknown breaker"})
+	}
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "reset", "name": ctx.Param("name")})
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"postificus/internal/collab"
+	"postificus/internal/domain"
+	"postificus/internal/middleware"
+	"postificus/internal/service"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+type CollabController struct {
+	drafts         *service.DraftService
+	workspaces     *service.WorkspaceService
+	hub            *collab.Hub
+	allowedOrigins []string // Pages that may open a session; see middleware.OriginAllowed
+}
+
+func NewCollabController(drafts *service.DraftService, workspaces *service.WorkspaceService, hub *collab.Hub, allowedOrigins []string) *CollabController {
+	return &CollabController{drafts: drafts, workspaces: workspaces, hub: hub, allowedOrigins: allowedOrigins}
+}
+
+// Connect handles GET /api/drafts/:id/collab and upgrades to a WebSocket.
+// Browsers can't set headers on WebSocket requests, so pass ?workspace_id= instead.
+// Viewers join read-only: they see updates and presence but can't edit.
+// The browser sends the user's credentials with any site's WebSocket, so the
+// handshake refuses origins outside the CORS allowlist.
+func (c *CollabController) Connect(ctx echo.Context) error {
+	draftID := ctx.Param("id")
+	userID := currentUserID(ctx)
+	workspaceID := currentWorkspaceID(ctx, userID)
+	reqCtx := ctx.Request().Context()
+
+	if _, err := c.drafts.GetDraft(reqCtx, workspaceID, userID, draftID); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	role, err := c.workspaces.RoleOf(reqCtx, workspaceID, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("failed to check permissions: %v", err)})
+	}
+
+	client := collab.NewClient(draftID, workspaceID, userID, role.Can(domain.PermEditDrafts))
+	websocket.Server{
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			if !middleware.OriginAllowed(c.allowedOrigins, req) {
+				return fmt.Errorf("origin %q not allowed", req.Header.Get("Origin"))
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			client.Serve(reqCtx, c.hub, ws)
+		},
+	}.ServeHTTP(ctx.Response(), ctx.Request())
+	return nil
+}
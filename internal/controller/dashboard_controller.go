@@ -1,12 +1,12 @@
 package controller
 
 import (
-	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 
-	"postificus/internal/rabbitmq"
+	"postificus/internal/domain"
 	"postificus/internal/service"
 
 	"github.com/labstack/echo/v4"
@@ -14,19 +14,21 @@ import (
 
 type DashboardController struct {
 	activityService *service.ActivityService
-	producer        *rabbitmq.Producer
+	workspaces      *service.WorkspaceService
+	jobs            *service.JobService
 }
 
-func NewDashboardController(activityService *service.ActivityService, producer *rabbitmq.Producer) *DashboardController {
+func NewDashboardController(activityService *service.ActivityService, workspaces *service.WorkspaceService, jobs *service.JobService) *DashboardController {
 	return &DashboardController{
 		activityService: activityService,
-		producer:        producer,
+		workspaces:      workspaces,
+		jobs:            jobs,
 	}
 }
 
 // GetDashboardActivity returns the unified list of posts from the local database.
 func (c *DashboardController) GetDashboardActivity(ctx echo.Context) error {
-	userID := service.DefaultUserID()
+	userID := currentUserID(ctx)
 
 	limit := 20
 	if raw := ctx.QueryParam("limit"); raw != "" {
@@ -48,7 +50,7 @@ func (c *DashboardController) GetDashboardActivity(ctx echo.Context) error {
 
 // TriggerSync enqueues a background task to sync activity for a specific platform (or all).
 func (c *DashboardController) TriggerSync(ctx echo.Context) error {
-	userID := service.DefaultUserID()
+	userID := currentUserID(ctx)
 
 	var req struct {
 		Platform string `json:"platform"` // "medium", "devto", or "all"
@@ -58,6 +60,11 @@ func (c *DashboardController) TriggerSync(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
+	workspaceID := currentWorkspaceID(ctx, userID)
+	if err := c.workspaces.Authorize(ctx.Request().Context(), workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+
 	platforms := []string{}
 	if req.Platform == "all" || req.Platform == "" {
 		platforms = []string{"medium", "devto"}
@@ -65,29 +72,24 @@ func (c *DashboardController) TriggerSync(ctx echo.Context) error {
 		platforms = []string{req.Platform}
 	}
 
-	enqueued := 0
+	payloads := make([]service.SyncPlatformPayload, 0, len(platforms))
 	for _, p := range platforms {
-		payload := service.SyncPlatformPayload{
-			UserID:   userID,
-			Platform: p,
-		}
-
-		bytes, err := json.Marshal(payload)
-		if err != nil {
-			continue // Skip invalid
-		}
+		payloads = append(payloads, service.SyncPlatformPayload{
+			UserID:      userID,
+			WorkspaceID: workspaceID,
+			Platform:    p,
+		})
+	}
 
-		// Publish to RabbitMQ
-		err = c.producer.Publish(service.TypeSyncPlatformActivity, bytes)
-		if err != nil {
-			return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("Failed to enqueue %s: %v", p, err)})
-		}
-		fmt.Printf("Enqueued task for platform %s\n", p)
-		enqueued++
+	// Hand off to the worker through the outbox; all platforms are queued or none
+	if err := c.jobs.QueueSync(ctx.Request().Context(), payloads...); err != nil {
+		log.Printf("❌ Failed to queue sync tasks: %v", err)
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue sync"})
 	}
+	log.Printf("Queued sync tasks for %v", platforms)
 
 	return ctx.JSON(http.StatusOK, map[string]interface{}{
-		"message":  fmt.Sprintf("Triggered sync for %d platforms", enqueued),
-		"enqueued": enqueued,
+		"message":  fmt.Sprintf("Triggered sync for %d platforms", len(payloads)),
+		"enqueued": len(payloads),
 	})
 }
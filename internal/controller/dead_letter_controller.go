@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"postificus/internal/queue"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDeadLetterLimit bounds a DLQ listing unless ?limit= asks for more
+const defaultDeadLetterLimit = 50
+
+// DeadLetterController is the operator API over the job queues' dead letters
+type DeadLetterController struct {
+	jobs   queue.DeadLetterAdmin
+	queues map[string]bool
+}
+
+// NewDeadLetterController exposes the DLQs of the given queues only
+func NewDeadLetterController(jobs queue.DeadLetterAdmin, queues ...string) *DeadLetterController {
+	known := make(map[string]bool, len(queues))
+	for _, name := range queues {
+		known[name] = true
+	}
+	return &DeadLetterController{jobs: jobs, queues: known}
+}
+
+type deadLetterResponse struct {
+	ID         string          `json:"id"`
+	Queue      string          `json:"queue"`
+	Payload    json.RawMessage `json:"payload"`
+	Error      string          `json:"error"`
+	Retries    int             `json:"retries"`
+	EnqueuedAt *time.Time      `json:"enqueued_at,omitempty"`
+	FailedAt   *time.Time      `json:"failed_at,omitempty"`
+}
+
+func newDeadLetterResponse(dl queue.DeadLetter) deadLetterResponse {
+	resp := deadLetterResponse{ID: dl.ID, Queue: dl.Queue, Error: dl.Error, Retries: dl.Retries}
+	if json.Valid(dl.Payload) {
+		resp.Payload = dl.Payload
+	} else {
+		// Not JSON; hand it back as a string rather than breaking the listing
+		resp.Payload, _ = json.Marshal(string(dl.Payload))
+	}
+	if !dl.EnqueuedAt.IsZero() {
+		resp.EnqueuedAt = &dl.EnqueuedAt
+	}
+	if !dl.FailedAt.IsZero() {
+		resp.FailedAt = &dl.FailedAt
+	}
+	return resp
+}
+
+// knownQueue rejects queue names the worker doesn't consume
+func (c *DeadLetterController) knownQueue(ctx echo.Context) (string, bool) {
+	name := ctx.Param("queue")
+	return name, c.queues[name]
+}
+
+// ListDeadLetters handles GET /api/admin/dlq/:queue?limit=50
+func (c *DeadLetterController) ListDeadLetters(ctx echo.Context) error {
+	name, ok := c.knownQueue(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": "Unknown queue"})
+	}
+	limit := defaultDeadLetterLimit
+	if raw := ctx.QueryParam("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid limit"})
+		}
+		limit = parsed
+	}
+
+	dead, err := c.jobs.ListDeadLetters(ctx.Request().Context(), name, limit)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	messages := make([]deadLetterResponse, 0, len(dead))
+	for _, dl := range dead {
+		messages = append(messages, newDeadLetterResponse(dl))
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"queue":    name,
+		"messages": messages,
+		"count":    len(messages),
+	})
+}
+
+// ReplayDeadLetter handles POST /api/admin/dlq/:queue/:id/replay.
+// An optional {"payload": {...}} body replaces the payload, e.g. to fix tags.
+func (c *DeadLetterController) ReplayDeadLetter(ctx echo.Context) error {
+	name, ok := c.knownQueue(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": "Unknown queue"})
+	}
+
+	var req struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		}
+	}
+	var payload []byte
+	if len(req.Payload) > 0 && string(req.Payload) != "null" {
+		if !json.Valid(req.Payload) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Payload must be JSON"})
+		}
+		payload = req.Payload
+	}
+
+	if err := c.jobs.ReplayDeadLetter(ctx.Request().Context(), name, ctx.Param("id"), payload); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{"status": "replayed", "replayed": 1})
+}
+
+// ReplayDeadLetters handles POST /api/admin/dlq/:queue/replay
+func (c *DeadLetterController) ReplayDeadLetters(ctx echo.Context) error {
+	name, ok := c.knownQueue(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": "Unknown queue"})
+	}
+	replayed, err := c.jobs.ReplayDeadLetters(ctx.Request().Context(), name)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]interface{}{"error": err.Error(), "replayed": replayed})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{"status": "replayed", "replayed": replayed})
+}
+
+// PurgeDeadLetters handles DELETE /api/admin/dlq/:queue
+func (c *DeadLetterController) PurgeDeadLetters(ctx echo.Context) error {
+	name, ok := c.knownQueue(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusNotFound, map[string]string{"error": "Unknown queue"})
+	}
+	purged, err := c.jobs.PurgeDeadLetters(ctx.Request().Context(), name)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{"status": "purged", "purged": purged})
+}
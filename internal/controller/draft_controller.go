@@ -1,12 +1,17 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"postificus/internal/domain"
 	"postificus/internal/service"
+	"postificus/internal/storage"
 
 	"github.com/labstack/echo/v4"
 )
@@ -19,22 +24,38 @@ func NewDraftController(service *service.DraftService) *DraftController {
 	return &DraftController{service: service}
 }
 
+// UpdateDraft handles PUT /api/drafts/:id.
+// With an If-Match header the save only applies to that version; a stale save is merged
+// against base_title/base_content when given, otherwise answered with 409 and the server copy.
 func (c *DraftController) UpdateDraft(ctx echo.Context) error {
 	id := ctx.Param("id")
 	var payload struct {
-		Title          string   `json:"title"`
-		Content        string   `json:"content"`
-		CoverImage     string   `json:"cover_image"`
-		PublishTargets []string `json:"publish_targets"`
+		Title          string    `json:"title"`
+		Content        string    `json:"content"`
+		CoverImage     string    `json:"cover_image"`
+		PublishTargets []string  `json:"publish_targets"`
+		Tags           *[]string `json:"tags"`
+		Subtitle       *string   `json:"subtitle"`
+		Excerpt        *string   `json:"excerpt"`
+		Series         *string   `json:"series"`
+		SeriesOrder    *int      `json:"series_order"`
+		BaseTitle      *string   `json:"base_title"`
+		BaseContent    *string   `json:"base_content"`
 	}
 	if err := ctx.Bind(&payload); err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
-	userID := service.DefaultUserID()
+	ifMatch, ok := parseIfMatch(ctx.Request().Header.Get("If-Match"))
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid If-Match header"})
+	}
+
+	userID := currentUserID(ctx)
 
 	draft := &domain.Draft{
 		ID:             id,
+		WorkspaceID:    currentWorkspaceID(ctx, userID),
 		UserID:         userID,
 		Title:          payload.Title,
 		Content:        payload.Content,
@@ -42,32 +63,218 @@ func (c *DraftController) UpdateDraft(ctx echo.Context) error {
 		PublishTargets: payload.PublishTargets,
 	}
 
-	if err := c.service.SaveDraft(ctx.Request().Context(), draft); err != nil {
-		fmt.Printf("Error saving draft: %v\n", err)
+	// Metadata omitted from the request keeps its saved value
+	if payload.Tags == nil || payload.Subtitle == nil || payload.Excerpt == nil || payload.Series == nil || payload.SeriesOrder == nil {
+		saved, err := c.service.GetDraft(ctx.Request().Context(), draft.WorkspaceID, userID, id)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+		}
+		if saved != nil {
+			draft.Tags, draft.Subtitle, draft.Excerpt = saved.Tags, saved.Subtitle, saved.Excerpt
+			draft.Series, draft.SeriesOrder = saved.Series, saved.SeriesOrder
+		}
+	}
+	if payload.Tags != nil {
+		draft.Tags = *payload.Tags
+	}
+	if payload.Subtitle != nil {
+		draft.Subtitle = *payload.Subtitle
+	}
+	if payload.Excerpt != nil {
+		draft.Excerpt = *payload.Excerpt
+	}
+	if payload.Series != nil {
+		draft.Series = *payload.Series
+	}
+	if payload.SeriesOrder != nil {
+		draft.SeriesOrder = *payload.SeriesOrder
+	}
+
+	var base *service.DraftBase
+	if payload.BaseContent != nil {
+		base = &service.DraftBase{Content: *payload.BaseContent, Title: payload.Title}
+		if payload.BaseTitle != nil {
+			base.Title = *payload.BaseTitle
+		}
+	}
+
+	merged, err := c.service.SaveDraftIfMatch(ctx.Request().Context(), draft, ifMatch, base)
+	if err != nil {
+		var conflict *service.ConflictError
+		if errors.As(err, &conflict) {
+			ctx.Response().Header().Set("ETag", draftETag(conflict.Server.Version))
+			return ctx.JSON(http.StatusConflict, map[string]interface{}{
+				"error":     conflict.Error(),
+				"server":    draftResponse(conflict.Server),
+				"conflicts": conflict.Conflicts,
+			})
+		}
+		log.Printf("❌ Error saving draft: %v", err)
+		if status := errorStatus(err); status != http.StatusInternalServerError {
+			return ctx.JSON(status, map[string]string{"error": err.Error()})
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save draft"})
 	}
 
-	return ctx.NoContent(http.StatusOK)
+	ctx.Response().Header().Set("ETag", draftETag(draft.Version))
+	response := map[string]interface{}{
+		"version": draft.Version,
+		"merged":  merged,
+	}
+	if merged {
+		// The client must adopt the merged text
+		response["title"] = draft.Title
+		response["content"] = draft.Content
+	}
+	return ctx.JSON(http.StatusOK, response)
 }
 
 func (c *DraftController) GetDraft(ctx echo.Context) error {
 	id := ctx.Param("id")
-	userID := service.DefaultUserID()
+	userID := currentUserID(ctx)
 
-	draft, err := c.service.GetDraft(ctx.Request().Context(), id, userID)
+	draft, err := c.service.GetDraft(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, id)
 	if err != nil {
+		if errorStatus(err) == http.StatusForbidden {
+			return ctx.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		// Differentiate between not found and internal error?
 		// For now simple 404
 		return ctx.JSON(http.StatusNotFound, map[string]string{"error": "Draft not found"})
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]interface{}{
+	ctx.Response().Header().Set("ETag", draftETag(draft.Version))
+	return ctx.JSON(http.StatusOK, draftResponse(draft))
+}
+
+func draftResponse(draft *domain.Draft) map[string]interface{} {
+	return map[string]interface{}{
 		"id":              draft.ID,
+		"workspace_id":    draft.WorkspaceID,
 		"title":           draft.Title,
 		"content":         draft.Content,
 		"cover_image":     draft.CoverImage,
 		"publish_targets": draft.PublishTargets,
 		"last_saved_at":   draft.LastSavedAt.UTC().Format(time.RFC3339),
 		"is_published":    draft.IsPublished,
+		"status":          draft.Status,
+		"reviewer_id":     draft.ReviewerID,
+		"scheduled_at":    draft.ScheduledAt,
+		"version":         draft.Version,
+		"tags":            draft.Tags,
+		"subtitle":        draft.Subtitle,
+		"excerpt":         draft.Excerpt,
+		"series":          draft.Series,
+		"series_order":    draft.SeriesOrder,
+	}
+}
+
+// draftETag renders a draft version as a strong ETag
+func draftETag(version int64) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// parseIfMatch reads the expected version from an If-Match header.
+// A missing header or "*" means any version (blind write).
+func parseIfMatch(header string) (int64, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return storage.AnyVersion, true
+	}
+	header = strings.TrimPrefix(header, "W/")
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// TransitionDraft handles POST /api/drafts/:id/transitions
+func (c *DraftController) TransitionDraft(ctx echo.Context) error {
+	var req service.TransitionRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	userID := currentUserID(ctx)
+	draft, err := c.service.Transition(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"), req)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, draft)
+}
+
+// AssignReviewer handles PUT /api/drafts/:id/reviewer
+func (c *DraftController) AssignReviewer(ctx echo.Context) error {
+	var req struct {
+		ReviewerID string `json:"reviewer_id"`
+	}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	userID := currentUserID(ctx)
+	draft, err := c.service.AssignReviewer(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"), req.ReviewerID)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, draft)
+}
+
+func (c *DraftController) ListComments(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	comments, err := c.service.ListComments(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"comments": comments,
+		"count":    len(comments),
+	})
+}
+
+// AddComment handles POST /api/drafts/:id/comments
+func (c *DraftController) AddComment(ctx echo.Context) error {
+	var req struct {
+		Body       string `json:"body"`
+		RangeStart int    `json:"range_start"`
+		RangeEnd   int    `json:"range_end"`
+	}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	comment := &domain.ReviewComment{
+		Body:       req.Body,
+		RangeStart: req.RangeStart,
+		RangeEnd:   req.RangeEnd,
+	}
+
+	userID := currentUserID(ctx)
+	if err := c.service.AddComment(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"), comment); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusCreated, comment)
+}
+
+func (c *DraftController) ResolveComment(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	err := c.service.ResolveComment(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"), ctx.Param("comment_id"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "resolved"})
+}
+
+// GetAuditLog returns who moved the draft through the workflow, oldest first
+func (c *DraftController) GetAuditLog(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	entries, err := c.service.ListAuditLog(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
 	})
 }
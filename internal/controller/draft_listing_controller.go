@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListDrafts handles GET /api/drafts.
+//
+// Query parameters: status (comma-separated), target, tag, updated_after, updated_before
+// (RFC3339 or YYYY-MM-DD), sort (updated|title|status), order (asc|desc), limit, offset.
+func (c *DraftController) ListDrafts(ctx echo.Context) error {
+	return c.listDrafts(ctx, false)
+}
+
+// ListTrash handles GET /api/drafts/trash and accepts the same parameters as ListDrafts
+func (c *DraftController) ListTrash(ctx echo.Context) error {
+	return c.listDrafts(ctx, true)
+}
+
+func (c *DraftController) listDrafts(ctx echo.Context, trashed bool) error {
+	userID := currentUserID(ctx)
+	filter := storage.DraftFilter{
+		WorkspaceID: currentWorkspaceID(ctx, userID),
+		Target:      ctx.QueryParam("target"),
+		Tag:         ctx.QueryParam("tag"),
+		Trashed:     trashed,
+		Sort:        ctx.QueryParam("sort"),
+		Ascending:   strings.EqualFold(ctx.QueryParam("order"), "asc"),
+	}
+	if trashed && filter.Sort == "" {
+		filter.Sort = "deleted"
+	}
+
+	if raw := ctx.QueryParam("status"); raw != "" {
+		for _, status := range strings.Split(raw, ",") {
+			filter.Statuses = append(filter.Statuses, domain.DraftStatus(strings.TrimSpace(status)))
+		}
+	}
+	if raw := ctx.QueryParam("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			filter.Limit = parsed
+		}
+	}
+	if raw := ctx.QueryParam("offset"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			filter.Offset = parsed
+		}
+	}
+
+	var ok bool
+	if filter.UpdatedAfter, ok = parseTimeParam(ctx.QueryParam("updated_after")); !ok {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid updated_after"})
+	}
+	if filter.UpdatedBefore, ok = parseTimeParam(ctx.QueryParam("updated_before")); !ok {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid updated_before"})
+	}
+
+	drafts, total, err := c.service.ListDrafts(ctx.Request().Context(), userID, filter)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"drafts": drafts,
+		"count":  len(drafts),
+		"total":  total,
+	})
+}
+
+// parseTimeParam accepts RFC3339 timestamps or plain dates; empty means unset
+func parseTimeParam(raw string) (*time.Time, bool) {
+	if raw == "" {
+		return nil, true
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t, true
+		}
+	}
+	return nil, false
+}
+
+// DeleteDraft handles DELETE /api/drafts/:id by moving the draft to the trash
+func (c *DraftController) DeleteDraft(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	if err := c.service.TrashDraft(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id")); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "trashed"})
+}
+
+// RestoreDraft handles POST /api/drafts/:id/restore, taking a draft out of the trash
+func (c *DraftController) RestoreDraft(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	draft, err := c.service.UntrashDraft(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, draft)
+}
+
+// DuplicateDraft handles POST /api/drafts/:id/duplicate
+func (c *DraftController) DuplicateDraft(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	draft, err := c.service.DuplicateDraft(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusCreated, draft)
+}
@@ -6,53 +6,110 @@ import (
 	"os"
 	"time"
 
-	"postificus/internal/rabbitmq"
+	"postificus/internal/domain"
 	"postificus/internal/service"
 
 	"github.com/labstack/echo/v4"
 )
 
 type PublishController struct {
-	producer *rabbitmq.Producer
+	jobs   *service.JobService
+	drafts *service.DraftService
 }
 
-func NewPublishController(producer *rabbitmq.Producer) *PublishController {
-	return &PublishController{producer: producer}
+func NewPublishController(jobs *service.JobService, drafts *service.DraftService) *PublishController {
+	return &PublishController{jobs: jobs, drafts: drafts}
 }
 
 func (c *PublishController) PublishPost(ctx echo.Context) error {
 	platform := ctx.Param("platform")
 
 	var req struct {
-		Title      string   `json:"title"`
-		Content    string   `json:"content"`
-		CoverImage string   `json:"cover_image"`
-		Tags       []string `json:"tags"`
-		BlogURL    string   `json:"blog_url"`
+		Title       string   `json:"title"`
+		Content     string   `json:"content"`
+		CoverImage  string   `json:"cover_image"`
+		Tags        []string `json:"tags"`
+		Subtitle    string   `json:"subtitle"`
+		Excerpt     string   `json:"excerpt"`
+		Series      string   `json:"series"`
+		SeriesOrder int      `json:"series_order"`
+		BlogURL     string   `json:"blog_url"`
+		AccountID   string   `json:"account_id"`
+		DraftID     string   `json:"draft_id"`
 	}
 	if err := ctx.Bind(&req); err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
 
+	userID := currentUserID(ctx)
+	workspaceID := currentWorkspaceID(ctx, userID)
+	if err := c.drafts.AuthorizePublish(ctx.Request().Context(), workspaceID, userID, req.DraftID); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	// A draft is published as saved (and reviewed), metadata included
+	if req.DraftID != "" {
+		draft, err := c.drafts.GetDraft(ctx.Request().Context(), workspaceID, userID, req.DraftID)
+		if err != nil {
+			return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+		}
+		requested := &domain.Draft{
+			Title:       req.Title,
+			Content:     req.Content,
+			CoverImage:  req.CoverImage,
+			Tags:        req.Tags,
+			Subtitle:    req.Subtitle,
+			Excerpt:     req.Excerpt,
+			Series:      req.Series,
+			SeriesOrder: req.SeriesOrder,
+		}
+		if err := service.CheckPublishedRevision(draft, requested); err != nil {
+			return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+		}
+		req.Title, req.Content, req.CoverImage = draft.Title, draft.Content, draft.CoverImage
+		req.Tags, req.Subtitle, req.Excerpt = draft.Tags, draft.Subtitle, draft.Excerpt
+		req.Series, req.SeriesOrder = draft.Series, draft.SeriesOrder
+	}
+
 	// Create Task Payload
 	payload := service.PublishPayload{
-		UserID:     service.DefaultUserID(),
-		Platform:   platform,
-		Title:      req.Title,
-		Content:    req.Content,
-		CoverImage: req.CoverImage,
-		Tags:       req.Tags,
-		BlogURL:    req.BlogURL,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		AccountID:   req.AccountID,
+		DraftID:     req.DraftID,
+		Platform:    platform,
+		Title:       req.Title,
+		Content:     req.Content,
+		CoverImage:  req.CoverImage,
+		Tags:        req.Tags,
+		Subtitle:    req.Subtitle,
+		Excerpt:     req.Excerpt,
+		Series:      req.Series,
+		SeriesOrder: req.SeriesOrder,
+		BlogURL:     req.BlogURL,
 	}
 
-	bytes, err := service.NewPublishPayload(payload)
+	// Hand off to the worker; the job is committed to the outbox with its publish record,
+	// and the relay keeps trying the queue until the broker has confirmed it
+	job, queued, err := c.jobs.QueuePublish(ctx.Request().Context(), payload)
 	if err != nil {
+		log.Printf("❌ Failed to queue publish task: %v", err)
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to create task"})
 	}
 
-	// Publish to RabbitMQ
-	if err := c.producer.Publish(service.TypePublishPost, bytes); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to enqueue task"})
+	// The same revision was already asked for; report that job instead of posting it twice
+	if !queued {
+		resp := map[string]interface{}{
+			"status": job.Status,
+			"job_id": job.JobID,
+		}
+		if job.Status == domain.PublishSucceeded {
+			resp["url"] = job.ExternalURL
+			resp["message"] = "Already published"
+		} else {
+			resp["message"] = "Already queued"
+		}
+		return ctx.JSON(http.StatusOK, resp)
 	}
 
 	// WAKE-ON-DEMAND (Fire & Forget)
@@ -72,6 +129,7 @@ func (c *PublishController) PublishPost(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, map[string]interface{}{
 		"status":  "queued",
-		"message": "Task submitted to RabbitMQ",
+		"job_id":  job.JobID,
+		"message": "Task accepted by the worker queue",
 	})
 }
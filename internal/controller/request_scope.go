@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"postificus/internal/service"
+	"postificus/internal/storage"
+	"postificus/internal/throttle"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WorkspaceHeader selects the workspace a request acts on
+const WorkspaceHeader = "X-Workspace-ID"
+
+// currentUserID returns the user set by auth middleware, falling back to the MVP user
+func currentUserID(ctx echo.Context) string {
+	if userID, ok := ctx.Get("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	return service.DefaultUserID()
+}
+
+// currentWorkspaceID returns the requested workspace, defaulting to the user's personal one
+func currentWorkspaceID(ctx echo.Context, userID string) string {
+	if workspaceID := ctx.Request().Header.Get(WorkspaceHeader); workspaceID != "" {
+		return workspaceID
+	}
+	if workspaceID := ctx.QueryParam("workspace_id"); workspaceID != "" {
+		return workspaceID
+	}
+	return userID
+}
+
+// errorStatus maps service/storage errors to HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrReviewRequired):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, storage.ErrVersionConflict), errors.Is(err, service.ErrRevisionMismatch):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrInvalidInput), errors.Is(err, service.ErrLastOwner):
+		return http.StatusBadRequest
+	case errors.Is(err, throttle.ErrLimited):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// revisionParam parses a numeric revision ID from a path or query value
+func revisionParam(value string) (int64, bool) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	return id, err == nil && id > 0
+}
+
+// ListRevisions handles GET /api/drafts/:id/revisions (metadata only, newest first)
+func (c *DraftController) ListRevisions(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	revisions, err := c.service.ListRevisions(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"revisions": revisions,
+		"count":     len(revisions),
+	})
+}
+
+// GetRevision handles GET /api/drafts/:id/revisions/:rev_id
+func (c *DraftController) GetRevision(ctx echo.Context) error {
+	revisionID, ok := revisionParam(ctx.Param("rev_id"))
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid revision id"})
+	}
+
+	userID := currentUserID(ctx)
+	revision, err := c.service.GetRevision(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"), revisionID)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions handles GET /api/drafts/:id/revisions/diff?from=<rev>&to=<rev>.
+// Without "to" the diff runs against the current draft.
+func (c *DraftController) DiffRevisions(ctx echo.Context) error {
+	fromID, ok := revisionParam(ctx.QueryParam("from"))
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "from revision is required"})
+	}
+	var toID int64
+	if to := ctx.QueryParam("to"); to != "" && to != "current" {
+		if toID, ok = revisionParam(to); !ok {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to revision"})
+		}
+	}
+
+	userID := currentUserID(ctx)
+	diff, err := c.service.DiffRevisions(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"), fromID, toID)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, diff)
+}
+
+// RestoreRevision handles POST /api/drafts/:id/revisions/:rev_id/restore
+func (c *DraftController) RestoreRevision(ctx echo.Context) error {
+	revisionID, ok := revisionParam(ctx.Param("rev_id"))
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid revision id"})
+	}
+
+	userID := currentUserID(ctx)
+	draft, err := c.service.RestoreRevision(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id"), revisionID)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, draft)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"postificus/internal/service"
+	"postificus/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SearchController struct {
+	service *service.SearchService
+}
+
+func NewSearchController(service *service.SearchService) *SearchController {
+	return &SearchController{service: service}
+}
+
+// Search handles GET /api/search?q=...
+//
+// Optional filters: platform (comma-separated; "postificus" means local drafts),
+// status (comma-separated), limit, offset.
+func (c *SearchController) Search(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	query := storage.SearchQuery{
+		Text:        ctx.QueryParam("q"),
+		WorkspaceID: currentWorkspaceID(ctx, userID),
+		UserID:      userID,
+		Platforms:   splitList(ctx.QueryParam("platform")),
+		Statuses:    splitList(ctx.QueryParam("status")),
+	}
+	if raw := ctx.QueryParam("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			query.Limit = parsed
+		}
+	}
+	if raw := ctx.QueryParam("offset"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			query.Offset = parsed
+		}
+	}
+
+	results, err := c.service.Search(ctx.Request().Context(), query)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"results": results,
+		"count":   len(results),
+		"query":   query.Text,
+	})
+}
+
+// splitList parses a comma-separated query value, skipping blanks
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
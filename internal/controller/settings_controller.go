@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"fmt"
+	"log"
 	"net/http"
 
 	"postificus/internal/domain"
@@ -37,11 +37,11 @@ func (c *SettingsController) SaveCredentials(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Platform and credentials required"})
 	}
 
-	userID := service.DefaultUserID()
+	userID := currentUserID(ctx)
 
 	// Manual save bypasses provider login
-	if err := c.authService.ManualSaveCredentials(ctx.Request().Context(), userID, req.Platform, req.Credentials); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	if err := c.authService.ManualSaveCredentials(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, req.Platform, req.Credentials); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
 	}
 
 	return ctx.JSON(http.StatusOK, map[string]string{"status": "saved"})
@@ -49,20 +49,45 @@ func (c *SettingsController) SaveCredentials(ctx echo.Context) error {
 
 func (c *SettingsController) DeleteCredentials(ctx echo.Context) error {
 	platform := ctx.Param("platform")
-	userID := service.DefaultUserID()
-	if err := c.authService.DeleteCredentials(ctx.Request().Context(), userID, platform); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	userID := currentUserID(ctx)
+	if err := c.authService.DeleteCredentials(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, platform); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "disconnected"})
+}
+
+// ListAccounts returns every connected platform account of the workspace
+func (c *SettingsController) ListAccounts(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	accounts, err := c.authService.ListAccounts(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"accounts": accounts,
+		"count":    len(accounts),
+	})
+}
+
+// DeleteAccount disconnects a single account when several exist for a platform
+func (c *SettingsController) DeleteAccount(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	if err := c.authService.DeleteAccount(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("id")); err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
 	}
 	return ctx.JSON(http.StatusOK, map[string]string{"status": "disconnected"})
 }
 
 func (c *SettingsController) GetCredentialsStatus(ctx echo.Context) error {
 	platform := ctx.Param("platform")
-	userID := service.DefaultUserID()
+	userID := currentUserID(ctx)
 
-	connected, account, err := c.authService.GetConnectionStatus(ctx.Request().Context(), userID, platform)
+	connected, account, err := c.authService.GetConnectionStatus(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, platform)
 	if err != nil {
-		fmt.Printf("Error checking status: %v\n", err)
+		log.Printf("❌ Error checking status: %v", err)
+		if status := errorStatus(err); status != http.StatusInternalServerError {
+			return ctx.JSON(status, map[string]string{"error": err.Error()})
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check status"})
 	}
 
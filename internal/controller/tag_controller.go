@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+
+	"postificus/internal/domain"
+	"postificus/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TagController struct {
+	service *service.TagService
+}
+
+func NewTagController(service *service.TagService) *TagController {
+	return &TagController{service: service}
+}
+
+// ListMappings handles GET /api/tags/mappings?platform=devto
+func (c *TagController) ListMappings(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	mappings, err := c.service.ListMappings(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.QueryParam("platform"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"mappings": mappings,
+		"count":    len(mappings),
+	})
+}
+
+// SaveMapping handles PUT /api/tags/mappings/:platform/:tag with {"platform_tag": "..."}
+func (c *TagController) SaveMapping(ctx echo.Context) error {
+	var req struct {
+		PlatformTag string `json:"platform_tag"`
+	}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	userID := currentUserID(ctx)
+	mapping, err := c.service.SaveMapping(ctx.Request().Context(), userID, domain.TagMapping{
+		WorkspaceID: currentWorkspaceID(ctx, userID),
+		Platform:    ctx.Param("platform"),
+		Tag:         ctx.Param("tag"),
+		PlatformTag: req.PlatformTag,
+	})
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, mapping)
+}
+
+// DeleteMapping handles DELETE /api/tags/mappings/:platform/:tag
+func (c *TagController) DeleteMapping(ctx echo.Context) error {
+	userID := currentUserID(ctx)
+	err := c.service.DeleteMapping(ctx.Request().Context(), currentWorkspaceID(ctx, userID), userID, ctx.Param("platform"), ctx.Param("tag"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+
+	"postificus/internal/domain"
+	"postificus/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WorkspaceController struct {
+	service *service.WorkspaceService
+}
+
+func NewWorkspaceController(service *service.WorkspaceService) *WorkspaceController {
+	return &WorkspaceController{service: service}
+}
+
+// ListWorkspaces returns the workspaces the current user belongs to
+func (c *WorkspaceController) ListWorkspaces(ctx echo.Context) error {
+	workspaces, err := c.service.ListWorkspaces(ctx.Request().Context(), currentUserID(ctx))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"workspaces": workspaces,
+		"count":      len(workspaces),
+	})
+}
+
+// CreateWorkspace creates a shared workspace owned by the current user
+func (c *WorkspaceController) CreateWorkspace(ctx echo.Context) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	workspace, err := c.service.CreateWorkspace(ctx.Request().Context(), currentUserID(ctx), req.Name)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusCreated, workspace)
+}
+
+func (c *WorkspaceController) ListMembers(ctx echo.Context) error {
+	members, err := c.service.ListMembers(ctx.Request().Context(), ctx.Param("id"), currentUserID(ctx))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"members": members,
+		"count":   len(members),
+	})
+}
+
+// SetMemberRole handles PUT /api/workspaces/:id/members/:user_id
+func (c *WorkspaceController) SetMemberRole(ctx echo.Context) error {
+	var req struct {
+		Role domain.Role `json:"role"`
+	}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	err := c.service.SetMemberRole(ctx.Request().Context(), ctx.Param("id"), currentUserID(ctx), ctx.Param("user_id"), req.Role)
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "saved"})
+}
+
+func (c *WorkspaceController) RemoveMember(ctx echo.Context) error {
+	err := c.service.RemoveMember(ctx.Request().Context(), ctx.Param("id"), currentUserID(ctx), ctx.Param("user_id"))
+	if err != nil {
+		return ctx.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "removed"})
+}
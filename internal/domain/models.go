@@ -5,24 +5,38 @@ import (
 	"time"
 )
 
-// UserCredential represents the stored credentials for a platform
+// UserCredential represents the stored credentials for a platform account.
+// A workspace may hold several accounts for the same platform.
 type UserCredential struct {
-	UserID      string          `json:"user_id"`
+	ID          string          `json:"id"`
+	WorkspaceID string          `json:"workspace_id"`
 	Platform    string          `json:"platform"`
+	AccountName string          `json:"account_name"`
 	Credentials json.RawMessage `json:"credentials"`
 	UpdatedAt   time.Time       `json:"updated_at"`
 }
 
 // Draft represents a blog post draft
 type Draft struct {
-	ID             string    `json:"id"`
-	UserID         string    `json:"user_id"`
-	Title          string    `json:"title"`
-	Content        string    `json:"content"`
-	CoverImage     string    `json:"cover_image"`
-	PublishTargets []string  `json:"publish_targets"`
-	LastSavedAt    time.Time `json:"last_saved_at"`
-	IsPublished    bool      `json:"is_published"`
+	ID             string      `json:"id"`
+	WorkspaceID    string      `json:"workspace_id"`
+	UserID         string      `json:"user_id"`
+	Title          string      `json:"title"`
+	Content        string      `json:"content"`
+	CoverImage     string      `json:"cover_image"`
+	PublishTargets []string    `json:"publish_targets"`
+	LastSavedAt    time.Time   `json:"last_saved_at"`
+	IsPublished    bool        `json:"is_published"`
+	Status         DraftStatus `json:"status"`
+	ReviewerID     string      `json:"reviewer_id,omitempty"`
+	ScheduledAt    *time.Time  `json:"scheduled_at,omitempty"`
+	Version        int64       `json:"version"` // Bumped on every content save; used as the ETag
+	Tags           []string    `json:"tags"`
+	Subtitle       string      `json:"subtitle"`
+	Excerpt        string      `json:"excerpt"` // Short summary for previews and platform descriptions
+	Series         string      `json:"series"`
+	SeriesOrder    int         `json:"series_order"`         // Position within Series, starting at 1
+	DeletedAt      *time.Time  `json:"deleted_at,omitempty"` // Set while the draft is in the trash
 }
 
 // Profile represents user profile information
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// PublishStatus tracks a publish job from the API to the platform
+type PublishStatus string
+
+const (
+	PublishQueued     PublishStatus = "queued"
+	PublishProcessing PublishStatus = "processing"
+	PublishSucceeded  PublishStatus = "success"
+	PublishFailed     PublishStatus = "failed"
+)
+
+// PublishLog is the record of one publish job. JobID is the job's idempotency
+// key (see PublishKey); the queue carries it as the message ID.
+type PublishLog struct {
+	ID           int64         `json:"id"`
+	JobID        string        `json:"job_id"`
+	WorkspaceID  string        `json:"workspace_id"`
+	DraftID      string        `json:"draft_id,omitempty"`
+	Platform     string        `json:"platform"`
+	Status       PublishStatus `json:"status"`
+	ExternalURL  string        `json:"external_url,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Attempts     int           `json:"attempts"`                // Times a worker has started the job
+	ArtifactsURL string        `json:"artifacts_url,omitempty"` // Screenshot, DOM, console log and timeline of the last failed browser run
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// PublishKey is the idempotency key of publishing one revision of a draft to a
+// platform account: asking again for the same content yields the same job.
+// revisionHash is the content address from RevisionHash; accountID is empty
+// for the workspace's default account of the platform.
+func PublishKey(workspaceID, draftID, platform, accountID, revisionHash string) string {
+	h := sha256.New()
+	for _, part := range []string{workspaceID, draftID, platform, revisionHash} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	// Appended only when set, so the default account keeps the keys it had
+	if accountID != "" {
+		h.Write([]byte("account:" + accountID))
+		h.Write([]byte{0})
+	}
+	return "publish-" + hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// PublishRevisionHash is the content address of what a publish sends: the
+// revision (see RevisionHash) and the metadata posted with it, the fields a
+// save treats as publishable. Retagging a draft is a new publish, not a
+// repeat of the last one. Metadata is hashed only when set, so a post without
+// any keeps the key it had.
+func PublishRevisionHash(revisionHash string, tags []string, subtitle, excerpt, series string, seriesOrder int) string {
+	parts := []string{}
+	for _, tag := range tags {
+		parts = append(parts, "tag:"+tag)
+	}
+	if subtitle != "" {
+		parts = append(parts, "subtitle:"+subtitle)
+	}
+	if excerpt != "" {
+		parts = append(parts, "excerpt:"+excerpt)
+	}
+	if series != "" {
+		parts = append(parts, "series:"+series)
+	}
+	if seriesOrder != 0 {
+		parts = append(parts, "series_order:"+strconv.Itoa(seriesOrder))
+	}
+	if len(parts) == 0 {
+		return revisionHash
+	}
+
+	h := sha256.New()
+	for _, part := range append([]string{revisionHash}, parts...) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
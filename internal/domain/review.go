@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// DraftStatus is the editorial state of a draft
+type DraftStatus string
+
+const (
+	StatusDraft     DraftStatus = "draft"
+	StatusInReview  DraftStatus = "in_review"
+	StatusApproved  DraftStatus = "approved"
+	StatusScheduled DraftStatus = "scheduled"
+	StatusPublished DraftStatus = "published"
+)
+
+// draftTransitions lists the states a user can move a draft to from each state.
+// Only a publish job that went out marks a draft published; a scheduled draft
+// goes back to approved when it's unscheduled or its publish is queued.
+var draftTransitions = map[DraftStatus][]DraftStatus{
+	StatusDraft:     {StatusInReview},
+	StatusInReview:  {StatusDraft, StatusApproved},
+	StatusApproved:  {StatusDraft, StatusScheduled},
+	StatusScheduled: {StatusApproved},
+	StatusPublished: {StatusDraft},
+}
+
+// Valid reports whether s is one of the known states
+func (s DraftStatus) Valid() bool {
+	_, ok := draftTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether the workflow allows moving from s to next
+func (s DraftStatus) CanTransitionTo(next DraftStatus) bool {
+	for _, allowed := range draftTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ReviewComment is a reviewer note anchored to a character range of the draft content
+type ReviewComment struct {
+	ID         string    `json:"id"`
+	DraftID    string    `json:"draft_id"`
+	AuthorID   string    `json:"author_id"`
+	Body       string    `json:"body"`
+	RangeStart int       `json:"range_start"`
+	RangeEnd   int       `json:"range_end"`
+	Quote      string    `json:"quote"` // Anchored text, used to re-anchor after edits
+	Resolved   bool      `json:"resolved"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditEntry records who moved a draft through the workflow
+type AuditEntry struct {
+	ID         int64       `json:"id"`
+	DraftID    string      `json:"draft_id"`
+	ActorID    string      `json:"actor_id"`
+	Action     string      `json:"action"` // 'transition', 'assign_reviewer'
+	FromStatus DraftStatus `json:"from_status,omitempty"`
+	ToStatus   DraftStatus `json:"to_status,omitempty"`
+	Detail     string      `json:"detail,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// RevisionKind records why a revision was captured
+type RevisionKind string
+
+const (
+	RevisionAutosave RevisionKind = "autosave" // Periodic snapshot of editor saves
+	RevisionRestore  RevisionKind = "restore"  // Content restored from an older revision
+	RevisionPublish  RevisionKind = "publish"  // Content as it was published
+)
+
+// Revision is an immutable snapshot of a draft's content.
+// Snapshots are content-addressed by Hash, so identical content is stored once.
+type Revision struct {
+	ID         int64        `json:"id"`
+	DraftID    string       `json:"draft_id"`
+	Hash       string       `json:"hash"`
+	AuthorID   string       `json:"author_id"`
+	Kind       RevisionKind `json:"kind"`
+	Title      string       `json:"title"`
+	Content    string       `json:"content,omitempty"`
+	CoverImage string       `json:"cover_image,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// RevisionHash returns the content address of a draft snapshot
+func RevisionHash(title, content, coverImage string) string {
+	h := sha256.New()
+	for _, part := range []string{title, content, coverImage} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
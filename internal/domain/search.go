@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// SearchResult is one ranked hit from a draft or a synced post.
+// TitleHighlight and Snippet are HTML-escaped text with matches wrapped in <mark> tags.
+type SearchResult struct {
+	Kind           string    `json:"kind"` // 'draft' or 'post'
+	ID             string    `json:"id"`   // Draft ID or the post's remote ID
+	Platform       string    `json:"platform"`
+	Title          string    `json:"title"`
+	TitleHighlight string    `json:"title_highlight"`
+	Snippet        string    `json:"snippet"`
+	Status         string    `json:"status"`
+	URL            string    `json:"url"`
+	Rank           float64   `json:"rank"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "strings"
+
+// NormalizeTags lowercases, trims and de-duplicates tags, dropping a leading '#'
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// TagMapping renames one of our tags for a platform, e.g. "golang" -> "go" on Dev.to
+type TagMapping struct {
+	WorkspaceID string `json:"workspace_id"`
+	Platform    string `json:"platform"`
+	Tag         string `json:"tag"`
+	PlatformTag string `json:"platform_tag"`
+}
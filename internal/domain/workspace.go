@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// Role is a member's role inside a workspace
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleWriter Role = "writer"
+	RoleViewer Role = "viewer"
+)
+
+// Permission is an action that can be granted to a role
+type Permission string
+
+const (
+	PermViewDrafts        Permission = "drafts:view"
+	PermEditDrafts        Permission = "drafts:edit"
+	PermPublish           Permission = "drafts:publish"
+	PermReviewDrafts      Permission = "drafts:review" // Approve drafts and publish without approval
+	PermManageCredentials Permission = "credentials:manage"
+	PermManageMembers     Permission = "members:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleOwner:  {PermViewDrafts, PermEditDrafts, PermPublish, PermReviewDrafts, PermManageCredentials, PermManageMembers},
+	RoleEditor: {PermViewDrafts, PermEditDrafts, PermPublish, PermReviewDrafts, PermManageCredentials},
+	RoleWriter: {PermViewDrafts, PermEditDrafts, PermPublish},
+	RoleViewer: {PermViewDrafts},
+}
+
+// Valid reports whether r is one of the known roles
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants the given permission
+func (r Role) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Workspace owns drafts and platform credentials shared by its members.
+// Every user also has a personal workspace whose ID equals their user ID.
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      Role      `json:"role,omitempty"` // Role of the requesting user (listing only)
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Membership links a user to a workspace with a role
+type Membership struct {
+	WorkspaceID string    `json:"workspace_id"`
+	UserID      string    `json:"user_id"`
+	Role        Role      `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"postificus/internal/breaker"
+	"postificus/internal/service"
+	"postificus/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type breakerList struct {
+	Breakers []struct {
+		Name     string `json:"name"`
+		State    string `json:"state"`
+		Failures int    `json:"failures"`
+		ProbeAt  string `json:"probe_at"`
+	} `json:"breakers"`
+}
+
+// breakerState finds the platform's breaker in the admin listing
+func (h *harness) breakerState(platform string) string {
+	h.t.Helper()
+	var list breakerList
+	require.Equal(h.t, http.StatusOK, h.do(http.MethodGet, "/api/admin/breakers", nil, &list))
+	for _, b := range list.Breakers {
+		if b.Name == platform {
+			return b.State
+		}
+	}
+	h.t.Fatalf("no %s breaker in %+v", platform, list)
+	return ""
+}
+
+func TestBreakerOpensAndResets(t *testing.T) {
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	assert.Equal(t, "closed", h.breakerState("devto"))
+
+	// Every attempt fails: the breaker opens and later jobs don't reach Dev.to
+	h.devto.fail(http.StatusInternalServerError)
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Outage", "content": "Down"}, nil))
+	h.eventually(func() bool { return len(h.jobs.DeadLetters(devtoLane)) == 1 }, "job should be dead-lettered")
+	assert.Equal(t, "open", h.breakerState("devto"))
+	assert.Equal(t, "closed", h.breakerState("medium"))
+
+	// The state lives in Redis, so another worker's breaker is open too
+	other, err := service.NewPublishBreakers(storage.RedisClient).Get("devto").Snapshot(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, breaker.StateOpen, other.State)
+
+	attempts := h.devto.attemptCount()
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Refused", "content": "Not tried"}, nil))
+	h.eventually(func() bool { return len(h.jobs.DeadLetters(devtoLane)) == 2 }, "job should be dead-lettered")
+	assert.Equal(t, attempts, h.devto.attemptCount(), "the open breaker kept the job off Dev.to")
+
+	// The outage is over: reset rather than wait for a probe, and replay
+	h.devto.fail(0)
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/admin/breakers/devto/reset", nil, nil))
+	assert.Equal(t, "closed", h.breakerState("devto"))
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, publishDLQ+"/replay", nil, nil))
+	h.eventually(func() bool { return len(h.devto.published()) == 2 }, "replayed jobs should publish")
+
+	assert.Equal(t, http.StatusNotFound, h.do(http.MethodPost, "/api/admin/breakers/linkedin/reset", nil, nil))
+}
+
+func TestBreakerIgnoresMissingCredentials(t *testing.T) {
+	h := newHarness(t)
+	t.Setenv("DEVTO_API_KEY", "")
+	t.Setenv("DEVTO_SESSION_TOKEN", "")
+
+	for _, title := range []string{"One", "Two", "Three", "Four"} {
+		require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": title, "content": "Body"}, nil))
+	}
+	h.eventually(func() bool { return len(h.jobs.DeadLetters(devtoLane)) == 4 }, "jobs should be dead-lettered")
+	assert.Equal(t, "closed", h.breakerState("devto"), "a missing account says nothing about Dev.to")
+}
@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deadLetterList struct {
+	Messages []struct {
+		ID      string          `json:"id"`
+		Payload json.RawMessage `json:"payload"`
+		Error   string          `json:"error"`
+		Retries int             `json:"retries"`
+	} `json:"messages"`
+	Count int `json:"count"`
+}
+
+// publishDLQ is where Dev.to publishes that failed for good end up
+var publishDLQ = "/api/admin/dlq/" + devtoLane
+
+func TestDeadLetterReplayWithEditedPayload(t *testing.T) {
+	h := newHarness(t)
+	t.Setenv("DEVTO_API_KEY", "")
+	t.Setenv("DEVTO_SESSION_TOKEN", "")
+
+	// Nothing connected yet: a permanent failure, dead-lettered without retries
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{
+		"title":   "Draft title",
+		"content": "Body",
+	}, nil))
+	h.eventually(func() bool { return len(h.jobs.DeadLetters(devtoLane)) == 1 }, "job should be dead-lettered")
+
+	var dead deadLetterList
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, publishDLQ, nil, &dead))
+	require.Equal(t, 1, dead.Count)
+	msg := dead.Messages[0]
+	assert.Contains(t, msg.Error, "credentials missing", "failure reason is kept")
+	assert.Zero(t, msg.Retries)
+
+	// Connect the account, fix the title and replay
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(msg.Payload, &payload))
+	payload["title"] = "Final title"
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, publishDLQ+"/"+msg.ID+"/replay", map[string]interface{}{"payload": payload}, nil))
+
+	h.eventually(func() bool { return len(h.devto.published()) == 1 }, "replayed job should publish")
+	assert.Equal(t, "Final title", h.devto.published()[0].Title)
+
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, publishDLQ, nil, &dead))
+	assert.Zero(t, dead.Count)
+	assert.Equal(t, http.StatusNotFound, h.do(http.MethodPost, publishDLQ+"/"+msg.ID+"/replay", nil, nil), "already replayed")
+}
+
+func TestDeadLetterPurge(t *testing.T) {
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	h.devto.fail(http.StatusInternalServerError)
+
+	for _, title := range []string{"Doomed", "Also doomed"} {
+		require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": title, "content": "Body"}, nil))
+	}
+	h.eventually(func() bool { return len(h.jobs.DeadLetters(devtoLane)) == 2 }, "jobs should be dead-lettered")
+
+	var purged map[string]interface{}
+	require.Equal(t, http.StatusOK, h.do(http.MethodDelete, publishDLQ, nil, &purged))
+	assert.Equal(t, float64(2), purged["purged"])
+	assert.Empty(t, h.jobs.DeadLetters(devtoLane))
+}
+
+func TestDeadLetterAdminRequiresToken(t *testing.T) {
+	h := newHarness(t)
+
+	req, err := http.NewRequest(http.MethodGet, h.api.URL+publishDLQ, nil)
+	require.NoError(t, err)
+	resp, err := h.api.Client().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	assert.Equal(t, http.StatusNotFound, h.do(http.MethodGet, "/api/admin/dlq/not-a-queue", nil, nil))
+}
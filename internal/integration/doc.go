@@ -0,0 +1,10 @@
+// Package integration runs the API router and worker handlers in one process
+// against local stand-ins: the in-memory store, an embedded Redis, the in-memory
+// job queue and fake Medium/Dev.to servers. Nothing leaves the machine.
+//
+// With TEST_REDIS_URL set, every scenario shares rate limits, breakers and
+// finished jobs through that Redis instead. With TEST_DATABASE_URL and
+// TEST_RABBITMQ_URL set, the save → publish → dashboard scenarios run again on
+// a scratch Postgres database and RabbitMQ. CI sets all three and fails when
+// one is missing.
+package integration
@@ -0,0 +1,570 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"postificus/internal/controller"
+	"postificus/internal/middleware"
+	"postificus/internal/queue"
+	"postificus/internal/server"
+	"postificus/internal/service"
+	"postificus/internal/storage"
+	"postificus/internal/throttle"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// harness is one API + worker pair sharing storage, Redis and a job queue
+type harness struct {
+	t      *testing.T
+	api    *httptest.Server
+	store  *storage.Store
+	jobs   *countingQueue
+	medium *fakeMedium
+	devto  *fakeDevto
+}
+
+// newHarness runs on the in-memory store and queue
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	return newHarnessOn(t, memoryBackend)
+}
+
+func newHarnessOn(t *testing.T, open backend) *harness {
+	t.Helper()
+
+	// Redis: the API caches the dashboard and the collab hub fans out through it;
+	// the workers share rate limits, breakers and finished jobs in it
+	previous := storage.RedisClient
+	storage.RedisClient = testRedis(t)
+	t.Cleanup(func() { storage.RedisClient = previous })
+
+	// Platforms: the publishers find the fakes through their base URL overrides
+	medium := newFakeMedium(t)
+	devto := newFakeDevto(t)
+	t.Setenv("MEDIUM_BASE_URL", medium.URL)
+	t.Setenv("DEVTO_BASE_URL", devto.URL)
+	t.Setenv("WORKER_URL", "")
+	t.Setenv("ADMIN_TOKEN", adminToken)
+
+	store, backing := open(t)
+	jobs := &countingQueue{Queue: backing, enqueued: make(map[string]int)}
+	jobs.SetRetryPolicy(service.TypePublishPost, testRetryPolicy)
+
+	// Worker side, wired like cmd/worker
+	workspaces := service.NewWorkspaceService(store.Workspaces)
+	drafts := service.NewDraftService(store.Drafts, store.Reviews, store.Revisions, workspaces)
+	limits, err := throttle.LimitsFromEnv()
+	require.NoError(t, err)
+	platformLimits := throttle.New(storage.RedisClient, limits)
+	activity := service.NewActivityService(store.Credentials, store.Posts, platformLimits)
+	publisher := service.NewPublishService(store.Credentials, drafts, store.TagMappings, activity, store.PublishLogs, platformLimits, service.NewPublishBreakers(storage.RedisClient))
+
+	workers := queue.NewWorkers(jobs)
+	dedupe := queue.NewDeduplicator(storage.RedisClient)
+	router := queue.NewRouter()
+	router.Handle(service.TypePublishPost, publisher.HandlePublishTask)
+	for _, name := range harnessQueues {
+		workers.Start(name, 1, dedupe.Wrap(name, router.Route))
+	}
+	t.Cleanup(func() { workers.Shutdown(time.Second) })
+
+	// API side, wired like cmd/api
+	srv := server.New(store, jobs)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	go srv.RunOutbox(relayCtx)
+	t.Cleanup(stopRelay)
+
+	api := httptest.NewServer(srv)
+	t.Cleanup(api.Close)
+
+	return &harness{t: t, api: api, store: store, jobs: jobs, medium: medium, devto: devto}
+}
+
+// do sends a JSON request to the API and decodes the JSON response into out (if non-nil)
+func (h *harness) do(method, path string, body interface{}, out interface{}) int {
+	h.t.Helper()
+	return h.request(method, path, "", body, out)
+}
+
+// doInWorkspace sends a request acting on another workspace
+func (h *harness) doInWorkspace(method, path, workspaceID string, body interface{}) int {
+	h.t.Helper()
+	return h.request(method, path, workspaceID, body, nil)
+}
+
+func (h *harness) request(method, path, workspaceID string, body interface{}, out interface{}) int {
+	h.t.Helper()
+
+	var raw []byte
+	if body != nil {
+		var err error
+		raw, err = json.Marshal(body)
+		require.NoError(h.t, err)
+	}
+
+	req, err := http.NewRequest(method, h.api.URL+path, bytes.NewReader(raw))
+	require.NoError(h.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if workspaceID != "" {
+		req.Header.Set(controller.WorkspaceHeader, workspaceID)
+	}
+	if strings.HasPrefix(path, "/api/admin/") {
+		req.Header.Set(middleware.AdminTokenHeader, adminToken)
+	}
+
+	resp, err := h.api.Client().Do(req)
+	require.NoError(h.t, err)
+	defer resp.Body.Close()
+
+	if out != nil {
+		require.NoError(h.t, json.NewDecoder(resp.Body).Decode(out), "%s %s", method, path)
+	}
+	return resp.StatusCode
+}
+
+// adminToken unlocks /api/admin; request sends it on admin paths
+const adminToken = "test-admin-token"
+
+// Lanes the publish tests' jobs travel through
+var (
+	mediumLane = queue.Lane("medium")
+	devtoLane  = queue.Lane("devto")
+)
+
+// harnessQueues are the queues the harness's workers consume
+var harnessQueues = []string{mediumLane, devtoLane, service.TypePublishPost}
+
+// backend opens the storage and job queue a harness runs on
+type backend func(t *testing.T) (*storage.Store, queue.Queue)
+
+// testBackends are the backends the full-stack scenarios run on: memory always,
+// and Postgres with RabbitMQ when TEST_DATABASE_URL and TEST_RABBITMQ_URL are set
+func testBackends(t *testing.T) map[string]backend {
+	backends := map[string]backend{storage.BackendMemory: memoryBackend}
+	if requireEnv(t, "TEST_DATABASE_URL", "TEST_RABBITMQ_URL") {
+		backends[storage.BackendPostgres] = postgresBackend
+	}
+	return backends
+}
+
+// requireEnv reports whether the variables naming a test backend are all set.
+// CI runs against the real backends, so there a missing one fails the test
+// rather than leaving it to the local stand-ins.
+func requireEnv(t *testing.T, names ...string) bool {
+	t.Helper()
+	for _, name := range names {
+		if os.Getenv(name) != "" {
+			continue
+		}
+		if os.Getenv("CI") != "" {
+			t.Fatalf("%s must be set in CI (see docker-compose.test.yml)", name)
+		}
+		return false
+	}
+	return true
+}
+
+// testRedis connects to the server at TEST_REDIS_URL, emptying its database
+// before and after the test, or starts an embedded Redis when it's unset.
+// The client is closed when the test ends.
+func testRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	if !requireEnv(t, "TEST_REDIS_URL") {
+		client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+		t.Cleanup(func() { client.Close() })
+		return client
+	}
+
+	options, err := redis.ParseURL(os.Getenv("TEST_REDIS_URL"))
+	require.NoError(t, err)
+	client := redis.NewClient(options)
+	require.NoError(t, client.FlushDB(context.Background()).Err())
+	t.Cleanup(func() {
+		if err := client.FlushDB(context.Background()).Err(); err != nil {
+			t.Logf("failed to flush Redis: %v", err)
+		}
+		client.Close()
+	})
+	return client
+}
+
+func memoryBackend(t *testing.T) (*storage.Store, queue.Queue) {
+	return storage.NewMemoryStore(), queue.NewMemory()
+}
+
+// postgresBackend migrates a scratch database on the server at TEST_DATABASE_URL,
+// dropped when the test ends, and empties the harness's queues on the broker at
+// TEST_RABBITMQ_URL
+func postgresBackend(t *testing.T) (*storage.Store, queue.Queue) {
+	t.Helper()
+	ctx := context.Background()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	admin, err := pgx.Connect(ctx, url)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close(context.Background()) })
+	name := "postificus_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err = admin.Exec(ctx, `CREATE DATABASE `+name)
+	require.NoError(t, err)
+
+	config, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	config.ConnConfig.Database = name
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		if _, err := admin.Exec(context.Background(), `DROP DATABASE IF EXISTS `+name+` WITH (FORCE)`); err != nil {
+			t.Logf("failed to drop %s: %v", name, err)
+		}
+	})
+	migrator, err := storage.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// The broker outlives the test; drop what an earlier run left on its queues
+	brokerURL := os.Getenv("TEST_RABBITMQ_URL")
+	conn, err := amqp.Dial(brokerURL)
+	require.NoError(t, err)
+	ch, err := conn.Channel()
+	require.NoError(t, err)
+	for _, queueName := range harnessQueues {
+		for _, q := range []string{queueName, queueName + queue.DeadLetterSuffix} {
+			_, err := ch.QueueDelete(q, false, false, false)
+			require.NoError(t, err)
+		}
+	}
+	conn.Close()
+
+	jobs, err := queue.DialRabbitMQ(brokerURL, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { jobs.Close() })
+	return storage.NewPostgresStore(db), jobs
+}
+
+// testRetryPolicy keeps the worker's backoff short enough for tests
+var testRetryPolicy = queue.RetryPolicy{MaxRetries: 2, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+// countingQueue wraps the harness's queue, counting what the API enqueues
+type countingQueue struct {
+	queue.Queue
+	mu       sync.Mutex
+	enqueued map[string]int
+	down     error // Returned by Enqueue while set, like a broker that never confirms
+}
+
+func (q *countingQueue) Enqueue(ctx context.Context, name string, payload []byte, opts ...queue.Option) error {
+	q.mu.Lock()
+	if q.down != nil {
+		q.mu.Unlock()
+		return q.down
+	}
+	q.enqueued[name]++
+	q.mu.Unlock()
+	return q.Queue.Enqueue(ctx, name, payload, opts...)
+}
+
+// DeadLetters returns the payloads of a queue's dead-lettered jobs
+func (q *countingQueue) DeadLetters(name string) [][]byte {
+	letters, err := q.ListDeadLetters(context.Background(), name, 0)
+	if err != nil {
+		return nil
+	}
+	payloads := make([][]byte, 0, len(letters))
+	for _, dl := range letters {
+		payloads = append(payloads, dl.Payload)
+	}
+	return payloads
+}
+
+func (q *countingQueue) setDown(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.down = err
+}
+
+func (q *countingQueue) count(name string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.enqueued[name]
+}
+
+// eventually waits for the worker to catch up
+func (h *harness) eventually(condition func() bool, msg string) {
+	h.t.Helper()
+	require.Eventually(h.t, condition, 5*time.Second, 10*time.Millisecond, msg)
+}
+
+// fakeMedium implements the slice of Medium's private API the publisher uses
+type fakeMedium struct {
+	*httptest.Server
+	mu      sync.Mutex
+	nextID  int
+	stories map[string]*mediumStory
+}
+
+type mediumStory struct {
+	Title     string
+	Subtitle  string
+	Body      []string
+	Tags      []string
+	Published bool
+}
+
+func newFakeMedium(t *testing.T) *fakeMedium {
+	f := &fakeMedium{stories: make(map[string]*mediumStory)}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeMedium) serve(w http.ResponseWriter, r *http.Request) {
+	if sid, err := r.Cookie("sid"); err != nil || sid.Value == "" || r.Header.Get("X-XSRF-Token") == "" {
+		http.Error(w, "not signed in", http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/new-story":
+		f.nextID++
+		id := fmt.Sprintf("%012x", f.nextID)
+		f.stories[id] = &mediumStory{}
+		f.reply(w, map[string]string{"id": id})
+
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "p" && parts[2] == "deltas":
+		story, ok := f.stories[parts[1]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Deltas []struct {
+				Type      int `json:"type"`
+				Paragraph *struct {
+					Type int    `json:"type"`
+					Text string `json:"text"`
+				} `json:"paragraph"`
+			} `json:"deltas"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, delta := range req.Deltas {
+			if delta.Type != 3 || delta.Paragraph == nil {
+				continue // Only updates carry text
+			}
+			switch delta.Paragraph.Type {
+			case 3:
+				story.Title = delta.Paragraph.Text
+			case 13:
+				story.Subtitle = delta.Paragraph.Text
+			default:
+				story.Body = append(story.Body, delta.Paragraph.Text)
+			}
+		}
+		f.reply(w, map[string]string{})
+
+	case r.Method == http.MethodPut && len(parts) == 5 && parts[2] == "posts" && parts[4] == "metadata":
+		story, ok := f.stories[parts[3]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Tags []string `json:"tags"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		story.Tags = req.Tags
+		f.reply(w, map[string]string{})
+
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "p" && parts[2] == "publish":
+		story, ok := f.stories[parts[1]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		story.Published = true
+		f.reply(w, map[string]string{"mediumUrl": f.storyURL(parts[1])})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// reply writes a Medium-style response, XSSI prefix included
+func (f *fakeMedium) reply(w http.ResponseWriter, value interface{}) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"success": true,
+		"payload": map[string]interface{}{"value": value},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append([]byte("])}while(1);</x>"), body...))
+}
+
+func (f *fakeMedium) storyURL(id string) string {
+	return f.URL + "/@demo/" + id
+}
+
+func (f *fakeMedium) published() []*mediumStory {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var stories []*mediumStory
+	for _, story := range f.stories {
+		if story.Published {
+			stories = append(stories, story)
+		}
+	}
+	return stories
+}
+
+// fakeDevto implements the Forem article API; failWith makes it reject articles,
+// and loseResponses makes it publish them but answer with an error
+type fakeDevto struct {
+	*httptest.Server
+	mu            sync.Mutex
+	articles      []devtoArticle
+	attempts      int
+	failWith      int
+	loseResponses int
+	listings      int
+}
+
+type devtoArticle struct {
+	Title        string   `json:"title"`
+	BodyMarkdown string   `json:"body_markdown"`
+	Published    bool     `json:"published"`
+	Tags         []string `json:"tags"`
+	Series       string   `json:"series"`
+	MainImage    string   `json:"main_image"`
+	publishedAt  time.Time
+}
+
+const devtoAPIKey = "test-api-key"
+
+func newFakeDevto(t *testing.T) *fakeDevto {
+	f := &fakeDevto{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeDevto) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("api-key") != devtoAPIKey {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/articles/me/all":
+		f.listings++
+		list := make([]map[string]interface{}, 0, len(f.articles))
+		for i := len(f.articles) - 1; i >= 0; i-- {
+			a := f.articles[i]
+			list = append(list, map[string]interface{}{
+				"title":         a.Title,
+				"url":           f.articleURL(i + 1),
+				"body_markdown": a.BodyMarkdown,
+				"published":     a.Published,
+				"published_at":  a.publishedAt,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+		return
+	case r.Method != http.MethodPost || r.URL.Path != "/api/articles":
+		http.NotFound(w, r)
+		return
+	}
+
+	f.attempts++
+	if f.failWith != 0 {
+		http.Error(w, `{"error":"rejected"}`, f.failWith)
+		return
+	}
+
+	var req struct {
+		Article devtoArticle `json:"article"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Article.publishedAt = time.Now()
+	f.articles = append(f.articles, req.Article)
+	id := len(f.articles)
+	if f.loseResponses > 0 {
+		f.loseResponses--
+		http.Error(w, "upstream timed out", http.StatusGatewayTimeout)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":  id,
+		"url": f.articleURL(id),
+	})
+}
+
+func (f *fakeDevto) articleURL(id int) string {
+	return fmt.Sprintf("%s/demo/article-%d", f.URL, id)
+}
+
+// loseResponse makes the next n articles go live without the client hearing about it
+func (f *fakeDevto) loseResponse(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.loseResponses = n
+}
+
+func (f *fakeDevto) attemptCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts
+}
+
+func (f *fakeDevto) listingCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.listings
+}
+
+func (f *fakeDevto) fail(status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failWith = status
+}
+
+func (f *fakeDevto) published() []devtoArticle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]devtoArticle(nil), f.articles...)
+}
@@ -0,0 +1,354 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"postificus/internal/domain"
+	"postificus/internal/metrics"
+	"postificus/internal/queue"
+	"postificus/internal/service"
+	"postificus/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dashboardResponse struct {
+	Posts []domain.UnifiedPost `json:"posts"`
+	Count int                  `json:"count"`
+}
+
+// dashboardPost returns the dashboard entry of a platform, or nil
+func (h *harness) dashboardPost(platform string) *domain.UnifiedPost {
+	h.t.Helper()
+	var dashboard dashboardResponse
+	require.Equal(h.t, http.StatusOK, h.do(http.MethodGet, "/api/dashboard/activity", nil, &dashboard))
+	for i := range dashboard.Posts {
+		if dashboard.Posts[i].Platform == platform {
+			return &dashboard.Posts[i]
+		}
+	}
+	return nil
+}
+
+func (h *harness) saveDraft(id string, draft map[string]interface{}) {
+	h.t.Helper()
+	require.Equal(h.t, http.StatusOK, h.do(http.MethodPut, "/api/drafts/"+id, draft, nil))
+}
+
+func (h *harness) connect(platform string, credentials map[string]string) {
+	h.t.Helper()
+	body := map[string]interface{}{"platform": platform, "credentials": credentials}
+	require.Equal(h.t, http.StatusOK, h.do(http.MethodPost, "/api/settings/credentials", body, nil))
+}
+
+func TestPublishDraftToMedium(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newHarnessOn(t, backend)
+			draftID := uuid.NewString()
+			h.connect("medium", map[string]string{"uid": "u1", "sid": "s1", "xsrf": "x1"})
+			h.saveDraft(draftID, map[string]interface{}{
+				"title":           "Shipping Go services",
+				"content":         "First paragraph.\n\nSecond paragraph.",
+				"publish_targets": []string{"medium"},
+				"tags":            []string{"go", "devops"},
+				"subtitle":        "Notes from production",
+			})
+
+			// The editor's draft shows up before anything is published, and warms the cache
+			require.NotNil(t, h.dashboardPost("postificus"))
+			require.Nil(t, h.dashboardPost("medium"))
+
+			var queued map[string]interface{}
+			status := h.do(http.MethodPost, "/api/publish/medium", map[string]interface{}{
+				"title":    "Shipping Go services",
+				"content":  "First paragraph.\n\nSecond paragraph.",
+				"draft_id": draftID,
+			}, &queued)
+			require.Equal(t, http.StatusOK, status)
+			assert.Equal(t, "queued", queued["status"])
+
+			h.eventually(func() bool { return len(h.medium.published()) == 1 }, "worker should publish the story")
+			stories := h.medium.published()
+			assert.Equal(t, "Shipping Go services", stories[0].Title)
+			assert.Equal(t, "Notes from production", stories[0].Subtitle, "metadata comes from the saved draft")
+			assert.Equal(t, []string{"First paragraph.", "Second paragraph."}, stories[0].Body)
+			assert.Equal(t, []string{"go", "devops"}, stories[0].Tags)
+
+			h.eventually(func() bool { return h.dashboardPost("medium") != nil }, "dashboard should list the published post")
+			post := h.dashboardPost("medium")
+			assert.Equal(t, h.medium.URL+"/@demo/000000000001", post.URL)
+			assert.Equal(t, "published", post.Status)
+
+			// The dashboard entry is written after the draft is marked published
+			var draft map[string]interface{}
+			require.Equal(t, http.StatusOK, h.do(http.MethodGet, "/api/drafts/"+draftID, nil, &draft))
+			assert.Equal(t, string(domain.StatusPublished), draft["status"])
+			assert.Equal(t, true, draft["is_published"])
+		})
+	}
+}
+
+func TestPublishToDevtoWithMappedTags(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newHarnessOn(t, backend)
+			draftID := uuid.NewString()
+			h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+			h.saveDraft(draftID, map[string]interface{}{
+				"title":       "Generics in practice",
+				"content":     "Body",
+				"cover_image": "https://images.example/cover.png",
+				"tags":        []string{"golang"},
+				"series":      "Go basics",
+			})
+			require.Equal(t, http.StatusOK, h.do(http.MethodPut, "/api/tags/mappings/devto/golang", map[string]string{"platform_tag": "go"}, nil))
+
+			require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{
+				"title":       "Generics in practice",
+				"content":     "Body",
+				"cover_image": "https://images.example/cover.png",
+				"draft_id":    draftID,
+			}, nil))
+
+			h.eventually(func() bool { return h.dashboardPost("devto") != nil }, "worker should publish the article")
+			articles := h.devto.published()
+			require.Len(t, articles, 1)
+			assert.Equal(t, []string{"go"}, articles[0].Tags, "workspace tag mapping applies")
+			assert.Equal(t, "Go basics", articles[0].Series)
+			assert.Equal(t, "https://images.example/cover.png", articles[0].MainImage)
+			assert.True(t, articles[0].Published)
+
+			assert.Equal(t, h.devto.URL+"/demo/article-1", h.dashboardPost("devto").URL)
+		})
+	}
+}
+
+func TestPublishFailureLeavesDraftUnpublished(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newHarnessOn(t, backend)
+			draftID := uuid.NewString()
+			h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+			h.saveDraft(draftID, map[string]interface{}{"title": "Rejected", "content": "Body"})
+			h.devto.fail(http.StatusUnprocessableEntity)
+
+			require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{
+				"title":    "Rejected",
+				"content":  "Body",
+				"draft_id": draftID,
+			}, nil))
+
+			h.eventually(func() bool { return len(h.jobs.DeadLetters(devtoLane)) == 1 }, "job should be dead-lettered")
+			assert.Equal(t, testRetryPolicy.MaxRetries+1, h.devto.attemptCount(), "retried before giving up")
+
+			assert.Nil(t, h.dashboardPost("devto"))
+			var draft map[string]interface{}
+			require.Equal(t, http.StatusOK, h.do(http.MethodGet, "/api/drafts/"+draftID, nil, &draft))
+			assert.Equal(t, false, draft["is_published"])
+		})
+	}
+}
+
+func TestPublishRequiresPermission(t *testing.T) {
+	h := newHarness(t)
+
+	// A workspace the default user doesn't belong to
+	status := h.doInWorkspace(http.MethodPost, "/api/publish/medium", "someone-elses-workspace", map[string]interface{}{
+		"title":   "Nope",
+		"content": "Body",
+	})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Zero(t, h.jobs.count(mediumLane), "nothing is enqueued")
+}
+
+func TestPublishSurvivesQueueOutage(t *testing.T) {
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	h.jobs.setDown(errors.New("waiting for broker confirm: context deadline exceeded"))
+
+	// The job is committed to the outbox even though the broker isn't taking it
+	var resp struct {
+		Status string `json:"status"`
+		JobID  string `json:"job_id"`
+	}
+	status := h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Later", "content": "Body"}, &resp)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "queued", resp.Status)
+	require.NotEmpty(t, resp.JobID)
+
+	record, err := h.store.PublishLogs.GetPublishLog(context.Background(), resp.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishQueued, record.Status)
+	assert.Zero(t, h.jobs.count(devtoLane))
+
+	// Once the broker is back the relay delivers it
+	h.jobs.setDown(nil)
+	h.eventually(func() bool { return len(h.devto.published()) == 1 }, "job relayed after the outage")
+	h.eventually(func() bool {
+		record, err := h.store.PublishLogs.GetPublishLog(context.Background(), resp.JobID)
+		return err == nil && record.Status == domain.PublishSucceeded && record.ExternalURL != ""
+	}, "publish log records the post")
+}
+
+type publishResponse struct {
+	Status string `json:"status"`
+	JobID  string `json:"job_id"`
+	URL    string `json:"url"`
+}
+
+func TestPublishingTheSameRevisionTwice(t *testing.T) {
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	h.saveDraft("draft-4", map[string]interface{}{"title": "Once only", "content": "Body"})
+	article := map[string]interface{}{"title": "Once only", "content": "Body", "draft_id": "draft-4"}
+
+	var first publishResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", article, &first))
+	assert.Equal(t, "queued", first.Status)
+	h.eventually(func() bool {
+		record, err := h.store.PublishLogs.GetPublishLog(context.Background(), first.JobID)
+		return err == nil && record.Status == domain.PublishSucceeded
+	}, "first publish should finish")
+
+	// A double click (or a retried request) returns the finished job instead of posting again
+	var second publishResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", article, &second))
+	assert.Equal(t, string(domain.PublishSucceeded), second.Status)
+	assert.Equal(t, first.JobID, second.JobID)
+	assert.Equal(t, h.devto.URL+"/demo/article-1", second.URL)
+	assert.Equal(t, 1, h.jobs.count(devtoLane))
+	assert.Len(t, h.devto.published(), 1)
+
+	// Text the draft doesn't have isn't published under its name
+	article["content"] = "Body, revised"
+	assert.Equal(t, http.StatusConflict, h.do(http.MethodPost, "/api/publish/devto", article, nil))
+
+	// An edited revision is a new job
+	var edited publishResponse
+	h.saveDraft("draft-4", map[string]interface{}{"title": "Once only", "content": "Body, revised"})
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", article, &edited))
+	assert.Equal(t, "queued", edited.Status)
+	assert.NotEqual(t, first.JobID, edited.JobID)
+	h.eventually(func() bool { return len(h.devto.published()) == 2 }, "edited revision should publish")
+}
+
+func TestPublishRetryFindsTheLostPost(t *testing.T) {
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	h.devto.loseResponse(1)
+
+	var resp publishResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Published once", "content": "Body"}, &resp))
+
+	// The first attempt posts the article but fails; the retry finds it instead of posting a copy
+	h.eventually(func() bool {
+		record, err := h.store.PublishLogs.GetPublishLog(context.Background(), resp.JobID)
+		return err == nil && record.Status == domain.PublishSucceeded
+	}, "retry should settle the job")
+	record, err := h.store.PublishLogs.GetPublishLog(context.Background(), resp.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, h.devto.URL+"/demo/article-1", record.ExternalURL)
+	assert.Equal(t, 2, record.Attempts)
+	assert.Len(t, h.devto.published(), 1)
+	assert.Equal(t, 1, h.devto.attemptCount())
+	h.eventually(func() bool { return h.dashboardPost("devto") != nil }, "dashboard should list the found post")
+}
+
+func TestPublishRetryOfAPostedJobSpendsNoToken(t *testing.T) {
+	t.Setenv("PLATFORM_POSTS_PER_DAY", "devto=1")
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	h.devto.loseResponse(1)
+
+	// The first attempt spends the day's one post; the retry only finds it, so it isn't held back
+	var resp publishResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Posted once", "content": "Body"}, &resp))
+	h.eventually(func() bool {
+		record, err := h.store.PublishLogs.GetPublishLog(context.Background(), resp.JobID)
+		return err == nil && record.Status == domain.PublishSucceeded
+	}, "retry should settle the job without a post token")
+	assert.Len(t, h.devto.published(), 1)
+}
+
+func TestPublishRetryCheckWaitsForARequestToken(t *testing.T) {
+	t.Setenv("PLATFORM_REQUESTS_PER_MINUTE", "devto=1")
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	h.devto.loseResponse(1)
+	limited := metrics.PlatformRateLimited.WithLabelValues("devto", "request")
+	before := testutil.ToFloat64(limited)
+
+	// The first attempt's post spends the minute's one request; the retry's duplicate check waits for the next
+	var resp publishResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Checked later", "content": "Body"}, &resp))
+	h.eventually(func() bool { return testutil.ToFloat64(limited) == before+1 }, "duplicate check should be held back")
+	time.Sleep(50 * time.Millisecond)
+
+	record, err := h.store.PublishLogs.GetPublishLog(context.Background(), resp.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishQueued, record.Status, "deferred, not failed")
+	assert.Equal(t, 1, record.Attempts, "the deferred retry isn't counted")
+	assert.Zero(t, h.devto.listingCount(), "the platform isn't scraped over the limit")
+	assert.Len(t, h.devto.published(), 1)
+}
+
+func TestPublishOverTheDailyLimitWaits(t *testing.T) {
+	t.Setenv("PLATFORM_POSTS_PER_DAY", "devto=1")
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	limited := metrics.PlatformRateLimited.WithLabelValues("devto", "post")
+	before := testutil.ToFloat64(limited)
+
+	var first, second publishResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Today", "content": "Body"}, &first))
+	h.eventually(func() bool { return len(h.devto.published()) == 1 }, "first post is within the limit")
+
+	// The account's one post for the day is used up: the next job waits instead of failing
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Tomorrow", "content": "Body"}, &second))
+	h.eventually(func() bool { return testutil.ToFloat64(limited) == before+1 }, "second post should be held back")
+	time.Sleep(50 * time.Millisecond)
+
+	record, err := h.store.PublishLogs.GetPublishLog(context.Background(), second.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishQueued, record.Status, "deferred, not failed")
+	assert.Zero(t, record.Attempts)
+	assert.Empty(t, h.jobs.DeadLetters(devtoLane))
+	assert.Len(t, h.devto.published(), 1)
+
+	// The spent token is kept in Redis, where every worker takes from it
+	keys, err := storage.RedisClient.Keys(context.Background(), "throttle:devto:*:posts").Result()
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func TestRedeliveredJobRunsOnce(t *testing.T) {
+	h := newHarness(t)
+	h.connect("devto", map[string]string{"api_key": devtoAPIKey})
+	ctx := context.Background()
+
+	var first publishResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Once", "content": "Body"}, &first))
+	h.eventually(func() bool { return len(h.devto.published()) == 1 }, "first delivery should publish")
+	h.eventually(func() bool {
+		n, err := storage.RedisClient.Exists(ctx, "queue:"+devtoLane+":done:"+first.JobID).Result()
+		return err == nil && n == 1
+	}, "the finished job should be recorded in Redis")
+
+	// The relay delivers again, as it does when it dies before marking a message
+	// sent. The copy would be dead-lettered if it ran; a later job behind it on
+	// the lane shows it has been handled.
+	require.NoError(t, h.jobs.Enqueue(ctx, devtoLane, []byte("{}"), queue.WithMessageID(first.JobID), queue.WithType(service.TypePublishPost)))
+	require.Equal(t, http.StatusOK, h.do(http.MethodPost, "/api/publish/devto", map[string]interface{}{"title": "Next", "content": "Body"}, nil))
+	h.eventually(func() bool { return len(h.devto.published()) == 2 }, "the next job should publish")
+
+	assert.Empty(t, h.jobs.DeadLetters(devtoLane), "the copy was skipped")
+	assert.Equal(t, 2, h.devto.attemptCount())
+}
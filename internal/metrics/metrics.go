@@ -48,6 +48,11 @@ var (
 		Help: "State of the circuit breaker (0=Closed, 1=Open, 2=HalfOpen)",
 	}, []string{"name"})
 
+	PlatformRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "platform_rate_limited_total",
+		Help: "Total number of outbound platform actions held back by the per-account rate limits",
+	}, []string{"platform", "action"})
+
 	// Worker Metrics
 	WorkerJobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "worker_job_duration_seconds",
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminTokenHeader carries ADMIN_TOKEN on admin requests (a Bearer token works too)
+const AdminTokenHeader = "X-Admin-Token"
+
+// RequireAdmin guards operator endpoints with the shared ADMIN_TOKEN.
+// Without ADMIN_TOKEN the admin API is disabled.
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		expected := os.Getenv("ADMIN_TOKEN")
+		if expected == "" {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Admin API is disabled"})
+		}
+
+		token := c.Request().Header.Get(AdminTokenHeader)
+		if token == "" {
+			token = strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid admin token"})
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Authenticate identifies the caller from a Supabase access token (an HS256
+// JWT signed with the project's JWT secret) in the Authorization header and
+// sets "user_id" to its subject, for the rate limiter and the controllers. It
+// has to run before the rate limiter, which keys signed-in users by it.
+// Requests without a token stay anonymous; a token that doesn't verify is
+// refused. Bearer values that aren't JWTs (the admin token) are left to
+// RequireAdmin. An empty secret disables authentication.
+func Authenticate(secret string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if secret == "" {
+			return next
+		}
+		return func(c echo.Context) error {
+			token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || strings.Count(token, ".") != 2 {
+				return next(c)
+			}
+			userID, err := verifyToken(token, []byte(secret), time.Now())
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid access token"})
+			}
+			c.Set("user_id", userID)
+			return next(c)
+		}
+	}
+}
+
+// AuthenticateFromEnv authenticates with SUPABASE_JWT_SECRET
+func AuthenticateFromEnv() echo.MiddlewareFunc {
+	return Authenticate(os.Getenv("SUPABASE_JWT_SECRET"))
+}
+
+var errInvalidToken = errors.New("invalid access token")
+
+// verifyToken checks the token's signature and expiry and returns its subject
+func verifyToken(token string, secret []byte, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return "", errInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errInvalidToken
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", errInvalidToken
+	}
+
+	var claims struct {
+		Subject   string `json:"sub"`
+		ExpiresAt int64  `json:"exp"`
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", errInvalidToken
+	}
+	if claims.Subject == "" || claims.ExpiresAt == 0 || !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return "", errInvalidToken
+	}
+	return claims.Subject, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// AllowedOriginsFromEnv reads CORS_ALLOWED_ORIGINS (comma-separated, e.g.
+// "https://app.postificus.com"). Empty means no allowlist.
+func AllowedOriginsFromEnv() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// CORS allows cross-origin API calls from the allowed origins, or from any
+// origin without an allowlist
+func CORS(allowed []string) echo.MiddlewareFunc {
+	if len(allowed) == 0 {
+		return echoMiddleware.CORS()
+	}
+	return echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{AllowOrigins: allowed})
+}
+
+// OriginAllowed reports whether a browser on the request's Origin may open a
+// session that the browser authenticates for it (a WebSocket, which CORS doesn't
+// cover). Without an allowlist only the API's own origin may. Requests without an
+// Origin don't come from a page and are allowed.
+func OriginAllowed(allowed []string, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, a := range allowed {
+		if a != "*" && strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
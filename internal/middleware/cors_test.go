@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{"https://app.postificus.com"}
+	for origin, want := range map[string]bool{
+		"":                           true,
+		"https://app.postificus.com": true,
+		"https://api.postificus.com": true, // The API's own origin
+		"https://evil.example":       false,
+		"null":                       false,
+	} {
+		req := httptest.NewRequest("GET", "https://api.postificus.com/api/drafts/d1/collab", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		assert.Equal(t, want, OriginAllowed(allowed, req), origin)
+	}
+
+	// A wildcard allowlist still doesn't open WebSockets to every site
+	req := httptest.NewRequest("GET", "https://api.postificus.com/api/drafts/d1/collab", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	assert.False(t, OriginAllowed([]string{"*"}, req))
+}
@@ -1,28 +1,303 @@
 package middleware
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"postificus/internal/storage"
-
 	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
 )
 
-func RateLimit(next echo.HandlerFunc) echo.HandlerFunc {
+// RatePolicy limits how often one client calls a group of routes. Clients are
+// the signed-in user (see Authenticate), or the IP address for anonymous requests.
+type RatePolicy struct {
+	Name   string   `json:"name"`             // Names the policy's counters; unique
+	Method string   `json:"method,omitempty"` // Empty matches every method
+	Routes []string `json:"routes"`           // Echo route patterns ("/api/drafts/:id"); a trailing * matches a prefix, "*" alone every route
+	Limit  int      `json:"limit"`            // Requests per window; 0 is no limit
+	Window Duration `json:"window"`           // e.g. "1m"
+}
+
+// Duration is a time.Duration written as "30s" or "1m" in JSON
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"1m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// minRateInterval is the shortest time between requests a policy may allow;
+// GCRA works in whole microseconds
+const minRateInterval = time.Microsecond
+
+func (p RatePolicy) validate() error {
+	switch {
+	case len(p.Routes) == 0:
+		return fmt.Errorf("rate limit policy %s has no routes", p.Name)
+	case p.Limit < 0 || (p.Limit > 0 && p.Window <= 0):
+		return fmt.Errorf("rate limit policy %s needs a positive limit and window", p.Name)
+	case p.Limit > 0 && time.Duration(p.Window)/time.Duration(p.Limit) < minRateInterval:
+		return fmt.Errorf("rate limit policy %s allows more than one request per %s", p.Name, minRateInterval)
+	}
+	return nil
+}
+
+func (p RatePolicy) matches(method, route string) bool {
+	if p.Method != "" && !strings.EqualFold(p.Method, method) {
+		return false
+	}
+	for _, pattern := range p.Routes {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(route, prefix) {
+			return true
+		}
+		if pattern == route {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRatePolicies apply unless RATE_LIMIT_POLICIES replaces them.
+// The first policy matching a request applies.
+var DefaultRatePolicies = []RatePolicy{
+	// Every publish starts browser automation on someone's account
+	{Name: "publish", Method: http.MethodPost, Routes: []string{"/api/publish/*"}, Limit: 10, Window: Duration(time.Minute)},
+	{Name: "sync", Method: http.MethodPost, Routes: []string{"/api/dashboard/sync"}, Limit: 6, Window: Duration(time.Minute)},
+	// The editor autosaves every few seconds while someone types
+	{Name: "autosave", Method: http.MethodPut, Routes: []string{"/api/drafts/:id"}, Limit: 300, Window: Duration(time.Minute)},
+	{Name: "default", Routes: []string{"*"}, Limit: 120, Window: Duration(time.Minute)},
+}
+
+// rateLimitExempt are routes monitoring polls; they are never limited
+var rateLimitExempt = map[string]bool{"/metrics": true, "/health": true}
+
+// RateLimiter enforces RatePolicies with GCRA (the generic cell rate algorithm):
+// per client and policy it keeps one timestamp, the theoretical arrival time of
+// the next request, so a window's requests can come in a burst but never more.
+// The timestamps live in Redis when a client is given, so every API instance
+// shares them, and in process memory otherwise (or while Redis can't be reached).
+type RateLimiter struct {
+	client     *redis.Client
+	policies   []RatePolicy
+	allowNets  []*net.IPNet
+	allowUsers map[string]bool
+
+	mu      sync.Mutex
+	local   map[string]time.Time // Without Redis: key -> theoretical arrival time
+	pruneAt int                  // Size of local at which expired arrival times are dropped
+}
+
+// localPruneSize is the smallest size at which local is pruned; past it, the
+// map is pruned whenever it doubles, so pruning costs O(1) per request
+const localPruneSize = 1024
+
+// NewRateLimiter builds the limiter. The allowlist takes IP addresses, CIDR
+// ranges and "user:<id>" entries; requests from them are never limited.
+func NewRateLimiter(client *redis.Client, policies []RatePolicy, allowlist []string) (*RateLimiter, error) {
+	l := &RateLimiter{client: client, allowUsers: make(map[string]bool), local: make(map[string]time.Time), pruneAt: localPruneSize}
+	names := make(map[string]bool)
+	for _, p := range policies {
+		if p.Name == "" || names[p.Name] {
+			return nil, fmt.Errorf("rate limit policies need unique names (got %q)", p.Name)
+		}
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		names[p.Name] = true
+	}
+	l.policies = policies
+
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+		case strings.HasPrefix(entry, "user:"):
+			l.allowUsers[strings.TrimPrefix(entry, "user:")] = true
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rate limit allowlist entry %q: %w", entry, err)
+			}
+			l.allowNets = append(l.allowNets, network)
+		default:
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid rate limit allowlist entry %q", entry)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			l.allowNets = append(l.allowNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return l, nil
+}
+
+// RateLimiterFromEnv builds the limiter from RATE_LIMIT_POLICIES (a JSON array of
+// RatePolicy; DefaultRatePolicies when empty) and RATE_LIMIT_ALLOWLIST (comma-separated)
+func RateLimiterFromEnv(client *redis.Client) (*RateLimiter, error) {
+	policies := DefaultRatePolicies
+	if raw := os.Getenv("RATE_LIMIT_POLICIES"); raw != "" {
+		policies = nil
+		if err := json.Unmarshal([]byte(raw), &policies); err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_POLICIES: %w", err)
+		}
+		for _, p := range policies {
+			if err := p.validate(); err != nil {
+				return nil, fmt.Errorf("invalid RATE_LIMIT_POLICIES: %w", err)
+			}
+		}
+	}
+	var allowlist []string
+	if raw := os.Getenv("RATE_LIMIT_ALLOWLIST"); raw != "" {
+		allowlist = strings.Split(raw, ",")
+	}
+	return NewRateLimiter(client, policies, allowlist)
+}
+
+// Limit is the middleware. Responses carry the RateLimit-Limit, -Remaining,
+// -Reset and -Policy headers; refused requests get 429 and Retry-After.
+func (l *RateLimiter) Limit(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		if storage.RedisClient == nil {
+		route := c.Path()
+		if rateLimitExempt[route] {
 			return next(c)
 		}
-		key := "rl:" + c.RealIP()
-		ctx := c.Request().Context()
-		count, _ := storage.RedisClient.Incr(ctx, key).Result()
-		if count == 1 {
-			storage.RedisClient.Expire(ctx, key, time.Minute)
+		var policy *RatePolicy
+		for i := range l.policies {
+			if l.policies[i].matches(c.Request().Method, route) {
+				policy = &l.policies[i]
+				break
+			}
+		}
+		if policy == nil || policy.Limit == 0 {
+			return next(c)
 		}
-		if count > 60 {
+
+		client := "ip:" + c.RealIP()
+		userID, _ := c.Get("user_id").(string)
+		if userID != "" {
+			client = "user:" + userID
+		}
+		if l.allowlisted(userID, c.RealIP()) {
+			return next(c)
+		}
+
+		result := l.take(c, "rl:"+policy.Name+":"+client, *policy)
+		h := c.Response().Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(result.remaining))
+		h.Set("RateLimit-Reset", seconds(result.reset))
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", policy.Limit, seconds(time.Duration(policy.Window))))
+		if !result.allowed {
+			h.Set("Retry-After", seconds(result.retryAfter))
 			return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Too many requests"})
 		}
 		return next(c)
 	}
 }
+
+// allowlisted reports whether the user or IP address is never limited
+func (l *RateLimiter) allowlisted(userID string, ip string) bool {
+	if userID != "" && l.allowUsers[userID] {
+		return true
+	}
+	parsed := net.ParseIP(ip)
+	for _, network := range l.allowNets {
+		if parsed != nil && network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// seconds rounds up to whole seconds, as the headers want them
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+type rateResult struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration // Until the client's whole limit is available again
+	retryAfter time.Duration // Until the next request is allowed, when refused
+}
+
+// gcraScript runs GCRA on the arrival time in KEYS[1]. ARGV holds the emission
+// interval (one request's share of the window) and the window, both in
+// microseconds. It returns allowed (1/0), remaining, reset and retry-after (µs).
+// The clock is Redis's own, so API instances with drifting clocks agree.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local tat = math.max(tonumber(redis.call('GET', KEYS[1])) or now, now)
+local next_tat = tat + interval
+if next_tat - now > window then
+	return {0, 0, tat - now, next_tat - now - window}
+end
+redis.call('SET', KEYS[1], string.format('%.0f', next_tat), 'PX', math.ceil((next_tat - now) / 1000))
+return {1, math.floor((window - (next_tat - now)) / interval), next_tat - now, 0}
+`)
+
+func (l *RateLimiter) take(c echo.Context, key string, policy RatePolicy) rateResult {
+	window := time.Duration(policy.Window)
+	interval := window / time.Duration(policy.Limit)
+	if l.client != nil {
+		values, err := gcraScript.Run(c.Request().Context(), l.client, []string{key}, interval.Microseconds(), window.Microseconds()).Int64Slice()
+		if err == nil && len(values) == 4 {
+			return rateResult{
+				allowed:    values[0] == 1,
+				remaining:  int(values[1]),
+				reset:      time.Duration(values[2]) * time.Microsecond,
+				retryAfter: time.Duration(values[3]) * time.Microsecond,
+			}
+		}
+		log.Printf("⚠️ Rate limit check failed, using this instance's counters: %v", err)
+	}
+	return l.takeLocal(key, interval, window)
+}
+
+// takeLocal is gcraScript on the process's own arrival times
+func (l *RateLimiter) takeLocal(key string, interval, window time.Duration) rateResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	tat := now
+	if stored, ok := l.local[key]; ok && stored.After(now) {
+		tat = stored
+	}
+	next := tat.Add(interval)
+	if next.Sub(now) > window {
+		return rateResult{reset: tat.Sub(now), retryAfter: next.Sub(now) - window}
+	}
+	if len(l.local) >= l.pruneAt {
+		for k, stored := range l.local {
+			if !stored.After(now) {
+				delete(l.local, k)
+			}
+		}
+		l.pruneAt = max(2*len(l.local), localPruneSize)
+	}
+	l.local[key] = next
+	return rateResult{allowed: true, remaining: int((window - next.Sub(now)) / interval), reset: next.Sub(now)}
+}
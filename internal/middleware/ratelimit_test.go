@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicies = []RatePolicy{
+	{Name: "publish", Method: http.MethodPost, Routes: []string{"/api/publish/*"}, Limit: 2, Window: Duration(time.Minute)},
+	{Name: "autosave", Method: http.MethodPut, Routes: []string{"/api/drafts/:id"}, Limit: 100, Window: Duration(time.Minute)},
+	{Name: "default", Routes: []string{"*"}, Limit: 3, Window: Duration(time.Minute)},
+}
+
+// testLimiters returns a Redis-backed and a process-local limiter
+func testLimiters(t *testing.T, allowlist ...string) map[string]*RateLimiter {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	shared, err := NewRateLimiter(client, testPolicies, allowlist)
+	require.NoError(t, err)
+	local, err := NewRateLimiter(nil, testPolicies, allowlist)
+	require.NoError(t, err)
+	return map[string]*RateLimiter{"redis": shared, "local": local}
+}
+
+func limitedServer(l *RateLimiter, userID string) *echo.Echo {
+	e := echo.New()
+	if userID != "" {
+		e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Set("user_id", userID)
+				return next(c)
+			}
+		})
+	}
+	e.Use(l.Limit)
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/health", ok)
+	e.GET("/api/drafts", ok)
+	e.PUT("/api/drafts/:id", ok)
+	e.POST("/api/publish/:platform", ok)
+	return e
+}
+
+func send(e *echo.Echo, method, path, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimiter_PerRoutePolicies(t *testing.T) {
+	for name, l := range testLimiters(t) {
+		t.Run(name, func(t *testing.T) {
+			e := limitedServer(l, "")
+
+			first := send(e, http.MethodPost, "/api/publish/devto", "10.0.0.1")
+			require.Equal(t, http.StatusOK, first.Code)
+			assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+			assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+			assert.Equal(t, "2;w=60", first.Header().Get("RateLimit-Policy"))
+			assert.Equal(t, "30", first.Header().Get("RateLimit-Reset"))
+
+			require.Equal(t, http.StatusOK, send(e, http.MethodPost, "/api/publish/medium", "10.0.0.1").Code)
+			refused := send(e, http.MethodPost, "/api/publish/devto", "10.0.0.1")
+			require.Equal(t, http.StatusTooManyRequests, refused.Code)
+			assert.Equal(t, "0", refused.Header().Get("RateLimit-Remaining"))
+			retryAfter, err := strconv.Atoi(refused.Header().Get("Retry-After"))
+			require.NoError(t, err)
+			assert.InDelta(t, 30, retryAfter, 1, "one request comes back every 60s/2")
+
+			// Other routes and other clients have counters of their own
+			for range 50 {
+				require.Equal(t, http.StatusOK, send(e, http.MethodPut, "/api/drafts/d1", "10.0.0.1").Code, "autosave is lenient")
+			}
+			assert.Equal(t, http.StatusOK, send(e, http.MethodPost, "/api/publish/devto", "10.0.0.2").Code)
+			for range 3 {
+				require.Equal(t, http.StatusOK, send(e, http.MethodGet, "/api/drafts", "10.0.0.1").Code)
+			}
+			assert.Equal(t, http.StatusTooManyRequests, send(e, http.MethodGet, "/api/drafts", "10.0.0.1").Code)
+
+			// Health checks are never limited
+			for range 10 {
+				rec := send(e, http.MethodGet, "/health", "10.0.0.1")
+				require.Equal(t, http.StatusOK, rec.Code)
+				assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+			}
+		})
+	}
+}
+
+func TestRateLimiter_PerUser(t *testing.T) {
+	for name, l := range testLimiters(t) {
+		t.Run(name, func(t *testing.T) {
+			alice, bob := limitedServer(l, "alice"), limitedServer(l, "bob")
+			for range 2 {
+				require.Equal(t, http.StatusOK, send(alice, http.MethodPost, "/api/publish/devto", "10.0.0.1").Code)
+			}
+			assert.Equal(t, http.StatusTooManyRequests, send(alice, http.MethodPost, "/api/publish/devto", "10.0.0.9").Code, "a user is one client on any address")
+			assert.Equal(t, http.StatusOK, send(bob, http.MethodPost, "/api/publish/devto", "10.0.0.1").Code, "users behind one address don't share a limit")
+		})
+	}
+}
+
+func TestRateLimiter_SignedInUsersBehindOneAddress(t *testing.T) {
+	for name, l := range testLimiters(t) {
+		t.Run(name, func(t *testing.T) {
+			// Wired as the server does: the user is known before the limiter runs
+			e := echo.New()
+			e.Use(Authenticate(testJWTSecret))
+			e.Use(l.Limit)
+			e.POST("/api/publish/:platform", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+			publish := func(userID string) int {
+				req := httptest.NewRequest(http.MethodPost, "/api/publish/devto", nil)
+				req.RemoteAddr = "10.0.0.1:1234"
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+userToken(userID))
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				return rec.Code
+			}
+
+			for range 2 {
+				require.Equal(t, http.StatusOK, publish("alice"))
+			}
+			assert.Equal(t, http.StatusTooManyRequests, publish("alice"))
+			for range 2 {
+				assert.Equal(t, http.StatusOK, publish("bob"), "bob has a bucket of his own on alice's address")
+			}
+		})
+	}
+}
+
+func TestRateLimiter_PrunesLocalPastThreshold(t *testing.T) {
+	l, err := NewRateLimiter(nil, testPolicies, nil)
+	require.NoError(t, err)
+	expired := time.Now().Add(-time.Second)
+	for i := range localPruneSize - 1 {
+		l.local["rl:stale:"+strconv.Itoa(i)] = expired
+	}
+
+	l.takeLocal("rl:default:ip:10.0.0.1", time.Second, time.Minute)
+	assert.Len(t, l.local, localPruneSize, "below the threshold nothing is pruned")
+	l.takeLocal("rl:default:ip:10.0.0.2", time.Second, time.Minute)
+	assert.Len(t, l.local, 2, "at the threshold the expired arrival times go")
+	assert.Equal(t, localPruneSize, l.pruneAt)
+}
+
+func TestRateLimiter_Allowlist(t *testing.T) {
+	for name, l := range testLimiters(t, "192.168.0.0/16", "10.0.0.7", "user:ci-bot") {
+		t.Run(name, func(t *testing.T) {
+			e := limitedServer(l, "")
+			bot := limitedServer(l, "ci-bot")
+			for range 5 {
+				require.Equal(t, http.StatusOK, send(e, http.MethodPost, "/api/publish/devto", "192.168.4.2").Code)
+				require.Equal(t, http.StatusOK, send(e, http.MethodPost, "/api/publish/devto", "10.0.0.7").Code)
+				require.Equal(t, http.StatusOK, send(bot, http.MethodPost, "/api/publish/devto", "10.0.0.1").Code)
+			}
+			for range 2 {
+				require.Equal(t, http.StatusOK, send(e, http.MethodPost, "/api/publish/devto", "10.0.0.8").Code)
+			}
+			assert.Equal(t, http.StatusTooManyRequests, send(e, http.MethodPost, "/api/publish/devto", "10.0.0.8").Code)
+		})
+	}
+}
+
+func TestRateLimiterFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_POLICIES", `[{"name":"publish","method":"POST","routes":["/api/publish/*"],"limit":1,"window":"1h"},{"name":"rest","routes":["*"],"limit":0}]`)
+	t.Setenv("RATE_LIMIT_ALLOWLIST", "127.0.0.1, ::1")
+	l, err := RateLimiterFromEnv(nil)
+	require.NoError(t, err)
+	require.Len(t, l.policies, 2)
+	assert.Equal(t, Duration(time.Hour), l.policies[0].Window)
+	assert.Equal(t, 10, DefaultRatePolicies[0].Limit, "configured policies replace the defaults without touching them")
+
+	e := limitedServer(l, "")
+	require.Equal(t, http.StatusOK, send(e, http.MethodPost, "/api/publish/devto", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send(e, http.MethodPost, "/api/publish/devto", "10.0.0.1").Code)
+	for range 10 {
+		require.Equal(t, http.StatusOK, send(e, http.MethodGet, "/api/drafts", "10.0.0.1").Code, "a zero limit is no limit")
+	}
+
+	for _, invalid := range []string{`{"name":"x"}`, `[{"name":"x","routes":["*"],"limit":1,"window":"soon"}]`, `[{"name":"x","routes":["*"],"limit":1}]`, `[{"routes":["*"],"limit":1,"window":"1m"}]`,
+		`[{"name":"x","routes":["*"],"limit":1001,"window":"1ms"}]`} {
+		t.Setenv("RATE_LIMIT_POLICIES", invalid)
+		_, err := RateLimiterFromEnv(nil)
+		assert.Error(t, err, invalid)
+	}
+	// Under a microsecond between requests would divide by zero in GCRA
+	_, err = NewRateLimiter(nil, []RatePolicy{{Name: "x", Routes: []string{"*"}, Limit: 10, Window: Duration(time.Nanosecond)}}, nil)
+	assert.Error(t, err)
+
+	t.Setenv("RATE_LIMIT_POLICIES", "")
+	t.Setenv("RATE_LIMIT_ALLOWLIST", "not-an-ip")
+	_, err = RateLimiterFromEnv(nil)
+	assert.Error(t, err)
+}
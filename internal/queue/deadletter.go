@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+// DeadLetter is a job that failed for good, kept for inspection and replay
+type DeadLetter struct {
+	ID         string    // Addresses the job in ReplayDeadLetter
+	Queue      string    // The queue the job failed on
+	Type       string    // The job's type on a shared queue; empty if it was the queue's own
+	Priority   uint8     // Zero when the job had none
+	Payload    []byte    // As last attempted
+	Error      string    // Why the last attempt failed
+	Retries    int       // Retries before giving up
+	EnqueuedAt time.Time // Zero when the backend doesn't know
+	FailedAt   time.Time
+}
+
+// options re-enqueue a dead letter as the job it was
+func (dl DeadLetter) options() []Option {
+	return []Option{WithMessageID(dl.ID), WithType(dl.Type), WithPriority(dl.Priority)}
+}
+
+// DeadLetterAdmin inspects and replays a queue's dead-lettered jobs.
+// Lookups of unknown jobs fail with storage.ErrNotFound.
+type DeadLetterAdmin interface {
+	// ListDeadLetters returns up to limit dead-lettered jobs, oldest first
+	ListDeadLetters(ctx context.Context, queue string, limit int) ([]DeadLetter, error)
+	// ReplayDeadLetter moves one job back to its queue with a fresh retry budget.
+	// A non-nil payload replaces the job's payload.
+	ReplayDeadLetter(ctx context.Context, queue string, id string, payload []byte) error
+	// ReplayDeadLetters moves every job back to its queue
+	ReplayDeadLetters(ctx context.Context, queue string) (int, error)
+	// PurgeDeadLetters drops every dead-lettered job of the queue
+	PurgeDeadLetters(ctx context.Context, queue string) (int, error)
+}
+
+// maxErrorLength bounds the failure reason kept with a dead letter
+const maxErrorLength = 1024
+
+func failureReason(err error) string {
+	reason := err.Error()
+	if len(reason) > maxErrorLength {
+		reason = reason[:maxErrorLength]
+	}
+	return reason
+}
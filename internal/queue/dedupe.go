@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DedupeWindow is how long a Deduplicator remembers that a job finished
+const DedupeWindow = 24 * time.Hour
+
+// Deduplicator skips jobs whose message ID already finished. The outbox relay
+// delivers at least once, so the same job can arrive twice; its idempotency key
+// travels as the message ID. Finished IDs are kept in Redis when a client is
+// given, so every worker sees them, and in process memory otherwise.
+type Deduplicator struct {
+	client *redis.Client
+	window time.Duration
+
+	mu   sync.Mutex
+	done map[string]time.Time // Without Redis: key -> when it is forgotten
+}
+
+func NewDeduplicator(client *redis.Client) *Deduplicator {
+	return &Deduplicator{client: client, window: DedupeWindow, done: make(map[string]time.Time)}
+}
+
+// Wrap runs handler for the queue's jobs that haven't finished before.
+// Jobs without a message ID always run.
+func (d *Deduplicator) Wrap(queue string, handler Handler) Handler {
+	return func(ctx context.Context, payload []byte) error {
+		id := MessageID(ctx)
+		if id == "" {
+			return handler(ctx, payload)
+		}
+		key := "queue:" + queue + ":done:" + id
+		if d.finished(ctx, key) {
+			log.Printf("⏭️ Skipping duplicate of finished job %s on %s", id, queue)
+			return nil
+		}
+		if err := handler(ctx, payload); err != nil {
+			return err
+		}
+		d.remember(ctx, key)
+		return nil
+	}
+}
+
+func (d *Deduplicator) finished(ctx context.Context, key string) bool {
+	if d.client != nil {
+		n, err := d.client.Exists(ctx, key).Result()
+		if err != nil {
+			// Running a job twice beats dropping it
+			log.Printf("⚠️ Dedupe check failed, running job anyway: %v", err)
+			return false
+		}
+		return n > 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	expires, ok := d.done[key]
+	return ok && time.Now().Before(expires)
+}
+
+func (d *Deduplicator) remember(ctx context.Context, key string) {
+	if d.client != nil {
+		if err := d.client.Set(ctx, key, 1, d.window).Err(); err != nil {
+			log.Printf("⚠️ Failed to record finished job: %v", err)
+		}
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	for k, expires := range d.done {
+		if now.After(expires) {
+			delete(d.done, k)
+		}
+	}
+	d.done[key] = now.Add(d.window)
+}
@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A lane is one platform's queue. It carries every kind of job for the platform
+// (publishes and syncs alike), so the lane's consumer count caps how much work
+// hits the platform at once, and priorities order the kinds within the lane.
+const lanePrefix = "lane:"
+
+// Lane names the queue of a platform's lane
+func Lane(platform string) string {
+	return lanePrefix + platform
+}
+
+// IsLane reports whether queue is a lane
+func IsLane(queue string) bool {
+	return strings.HasPrefix(queue, lanePrefix)
+}
+
+// Job priorities within a lane; higher runs first. Jobs without one get PriorityBackground.
+const (
+	PriorityBackground  uint8 = 1 // Syncs and other work nobody is waiting on
+	PriorityInteractive uint8 = 5 // Work a user just asked for
+	MaxPriority         uint8 = 9 // Declared as the lanes' x-max-priority on RabbitMQ
+)
+
+type jobTypeKey struct{}
+
+// JobType returns the type of the job a handler is running (see WithType).
+// Jobs enqueued without one are of their queue's type: the queue name.
+func JobType(ctx context.Context) string {
+	t, _ := ctx.Value(jobTypeKey{}).(string)
+	return t
+}
+
+// withJob passes a job's ID and type to its handler
+func withJob(ctx context.Context, queue string, id string, jobType string) context.Context {
+	if jobType == "" {
+		jobType = queue
+	}
+	return context.WithValue(withMessageID(ctx, id), jobTypeKey{}, jobType)
+}
+
+// Router hands each job of a shared queue to the handler of its type
+type Router struct {
+	handlers map[string]Handler
+}
+
+func NewRouter() *Router {
+	return &Router{handlers: make(map[string]Handler)}
+}
+
+// Handle registers the handler of one job type
+func (r *Router) Handle(jobType string, handler Handler) {
+	r.handlers[jobType] = handler
+}
+
+// Route is the Handler to consume the shared queue with
+func (r *Router) Route(ctx context.Context, payload []byte) error {
+	handler, ok := r.handlers[JobType(ctx)]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job type %q", JobType(ctx)))
+	}
+	return handler(ctx, payload)
+}
+
+// ParseLanes reads a lane concurrency setting such as "medium=1,devto=2"
+func ParseLanes(spec string) (map[string]int, error) {
+	lanes := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		platform, raw, ok := strings.Cut(entry, "=")
+		platform = strings.TrimSpace(platform)
+		if !ok || platform == "" {
+			return nil, fmt.Errorf("invalid lane %q (want platform=consumers)", entry)
+		}
+		consumers, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || consumers < 0 {
+			return nil, fmt.Errorf("invalid consumer count for lane %q: %q", platform, raw)
+		}
+		lanes[platform] = consumers
+	}
+	return lanes, nil
+}
@@ -0,0 +1,123 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_DispatchesByJobType(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			got := make(chan string, 3)
+			router := NewRouter()
+			router.Handle("publish", func(ctx context.Context, payload []byte) error {
+				got <- "publish:" + string(payload)
+				return nil
+			})
+			router.Handle("legacy", func(ctx context.Context, payload []byte) error {
+				got <- "legacy:" + string(payload)
+				return nil
+			})
+			var recovered atomic.Bool
+			router.Handle("sync", func(ctx context.Context, payload []byte) error {
+				if !recovered.Load() {
+					return errors.New("platform down")
+				}
+				got <- "sync:" + string(payload)
+				return nil
+			})
+			consume(t, q, Lane("devto"), router.Route)
+			consume(t, q, "legacy", router.Route)
+
+			require.NoError(t, q.Enqueue(ctx, Lane("devto"), []byte("a"), WithType("publish"), WithPriority(PriorityInteractive)))
+			require.NoError(t, q.Enqueue(ctx, "legacy", []byte("b"))) // No type: the queue's own
+			var jobs []string
+			for range 2 {
+				select {
+				case job := <-got:
+					jobs = append(jobs, job)
+				case <-time.After(2 * time.Second):
+					t.Fatalf("only %v arrived", jobs)
+				}
+			}
+			assert.ElementsMatch(t, []string{"publish:a", "legacy:b"}, jobs)
+
+			// Failed jobs keep their type through retries and the DLQ, and unknown types fail for good
+			require.NoError(t, q.Enqueue(ctx, Lane("devto"), []byte("c"), WithType("sync")))
+			require.NoError(t, q.Enqueue(ctx, Lane("devto"), []byte("d"), WithType("unknown")))
+			var dead []DeadLetter
+			require.Eventually(t, func() bool {
+				var err error
+				dead, err = q.ListDeadLetters(ctx, Lane("devto"), 0)
+				return err == nil && len(dead) == 2
+			}, 2*time.Second, 10*time.Millisecond)
+			types := map[string]int{}
+			for _, dl := range dead {
+				types[dl.Type] = dl.Retries
+			}
+			assert.Equal(t, map[string]int{"sync": DefaultMaxRetries, "unknown": 0}, types)
+
+			recovered.Store(true)
+			for _, dl := range dead {
+				if dl.Type == "sync" {
+					require.NoError(t, q.ReplayDeadLetter(ctx, Lane("devto"), dl.ID, nil))
+				}
+			}
+			select {
+			case job := <-got:
+				assert.Equal(t, "sync:c", job)
+			case <-time.After(2 * time.Second):
+				t.Fatal("replayed job never arrived")
+			}
+		})
+	}
+}
+
+func TestMemory_PriorityJobsGoFirst(t *testing.T) {
+	ctx := context.Background()
+	q := NewMemory()
+	for _, payload := range []string{"sync-1", "sync-2"} {
+		require.NoError(t, q.Enqueue(ctx, "lane", []byte(payload), WithPriority(PriorityBackground)))
+	}
+	require.NoError(t, q.Enqueue(ctx, "lane", []byte("publish"), WithPriority(PriorityInteractive)))
+
+	got := make(chan string, 3)
+	consume(t, q, "lane", func(ctx context.Context, payload []byte) error {
+		got <- string(payload)
+		return nil
+	})
+	var order []string
+	for range 3 {
+		select {
+		case payload := <-got:
+			order = append(order, payload)
+		case <-time.After(2 * time.Second):
+			t.Fatal("jobs never arrived")
+		}
+	}
+	assert.Equal(t, []string{"publish", "sync-1", "sync-2"}, order)
+}
+
+func TestParseLanes(t *testing.T) {
+	lanes, err := ParseLanes(" medium=1, devto = 2,,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"medium": 1, "devto": 2}, lanes)
+
+	for _, invalid := range []string{"medium", "=2", "devto=two", "devto=-1"} {
+		_, err := ParseLanes(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRabbitMQ_LanesArePriorityQueues(t *testing.T) {
+	assert.Equal(t, int32(MaxPriority), queueArgs(Lane("medium"))["x-max-priority"])
+	_, ok := queueArgs("publish:post")["x-max-priority"]
+	assert.False(t, ok, "queues declared before lanes keep their arguments")
+}
@@ -0,0 +1,216 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"postificus/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// Memory is an in-process queue for tests and single-binary setups.
+// Jobs are lost when the process exits. Priorities come in two levels:
+// jobs above PriorityBackground overtake the rest.
+type Memory struct {
+	retryPolicies
+
+	mu     sync.Mutex
+	queues map[string]*memoryQueue
+	dead   map[string][]DeadLetter
+}
+
+type memoryQueue struct {
+	urgent chan memoryJob
+	normal chan memoryJob
+}
+
+type memoryJob struct {
+	id         string
+	jobType    string
+	priority   uint8
+	payload    []byte
+	attempt    int
+	enqueuedAt time.Time
+}
+
+// memoryBuffer is how many ready jobs a queue holds before Enqueue blocks
+const memoryBuffer = 1024
+
+func NewMemory() *Memory {
+	return &Memory{
+		queues: make(map[string]*memoryQueue),
+		dead:   make(map[string][]DeadLetter),
+	}
+}
+
+func (m *Memory) queue(name string) *memoryQueue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q, ok := m.queues[name]
+	if !ok {
+		q = &memoryQueue{urgent: make(chan memoryJob, memoryBuffer), normal: make(chan memoryJob, memoryBuffer)}
+		m.queues[name] = q
+	}
+	return q
+}
+
+// channel returns the channel a job waits in
+func (q *memoryQueue) channel(job memoryJob) chan memoryJob {
+	if job.priority > PriorityBackground {
+		return q.urgent
+	}
+	return q.normal
+}
+
+func (m *Memory) Enqueue(ctx context.Context, queue string, payload []byte, opts ...Option) error {
+	o := applyOptions(opts)
+	job := memoryJob{id: o.MessageID, jobType: o.Type, priority: o.Priority, payload: append([]byte(nil), payload...), enqueuedAt: time.Now()}
+	if job.id == "" {
+		job.id = uuid.NewString()
+	}
+	ch := m.queue(queue).channel(job)
+	if o.Delay > 0 {
+		time.AfterFunc(o.Delay, func() { ch <- job })
+		return nil
+	}
+
+	select {
+	case ch <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Memory) Consume(ctx context.Context, queue string, handler Handler) error {
+	q := m.queue(queue)
+	for {
+		var job memoryJob
+		select {
+		case job = <-q.urgent:
+		default:
+			select {
+			case <-ctx.Done():
+				return nil
+			case job = <-q.urgent:
+			case job = <-q.normal:
+			}
+		}
+
+		err := runHandler(withJob(context.WithoutCancel(ctx), queue, job.id, job.jobType), handler, job.payload)
+		if err == nil {
+			continue
+		}
+		ch := q.channel(job)
+		if errors.Is(err, ErrInterrupted) {
+			log.Printf("⏸️ Requeueing interrupted message on %s", queue)
+			go func() { ch <- job }()
+			continue
+		}
+		if delay, ok := deferral(err); ok {
+			log.Printf("⏳ Deferring message on %s for %s: %v", queue, delay, err)
+			time.AfterFunc(delay, func() { ch <- job })
+			continue
+		}
+		log.Printf("❌ Error processing message on %s: %v", queue, err)
+		if policy := m.retryPolicy(job.jobType, queue); policy.shouldRetry(job.attempt, err) {
+			job.attempt++
+			delay := policy.Backoff(job.attempt)
+			log.Printf("🔄 Retrying message on %s in %s (Attempt %d/%d)...", queue, delay, job.attempt, policy.MaxRetries)
+			// Requeue off the consumer goroutine so a full buffer can't deadlock it
+			time.AfterFunc(delay, func() { ch <- job })
+			continue
+		}
+		log.Printf("💀 Giving up on message on %s. Moving to DLQ.", queue)
+		m.mu.Lock()
+		m.dead[queue] = append(m.dead[queue], DeadLetter{
+			ID:         job.id,
+			Queue:      queue,
+			Type:       job.jobType,
+			Priority:   job.priority,
+			Payload:    job.payload,
+			Error:      failureReason(err),
+			Retries:    job.attempt,
+			EnqueuedAt: job.enqueuedAt,
+			FailedAt:   time.Now(),
+		})
+		m.mu.Unlock()
+	}
+}
+
+// DeadLetters returns the payloads of a queue's dead-lettered jobs
+func (m *Memory) DeadLetters(queue string) [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	payloads := make([][]byte, 0, len(m.dead[queue]))
+	for _, dl := range m.dead[queue] {
+		payloads = append(payloads, dl.Payload)
+	}
+	return payloads
+}
+
+func (m *Memory) ListDeadLetters(ctx context.Context, queue string, limit int) ([]DeadLetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dead := m.dead[queue]
+	if limit > 0 && len(dead) > limit {
+		dead = dead[:limit]
+	}
+	return append([]DeadLetter{}, dead...), nil
+}
+
+func (m *Memory) ReplayDeadLetter(ctx context.Context, queue string, id string, payload []byte) error {
+	m.mu.Lock()
+	var found *DeadLetter
+	for i, dl := range m.dead[queue] {
+		if dl.ID == id {
+			found = &dl
+			m.dead[queue] = append(m.dead[queue][:i:i], m.dead[queue][i+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+	if found == nil {
+		return storage.ErrNotFound
+	}
+
+	if payload == nil {
+		payload = found.Payload
+	}
+	return m.Enqueue(ctx, queue, payload, found.options()...)
+}
+
+func (m *Memory) ReplayDeadLetters(ctx context.Context, queue string) (int, error) {
+	m.mu.Lock()
+	dead := m.dead[queue]
+	delete(m.dead, queue)
+	m.mu.Unlock()
+
+	for i, dl := range dead {
+		if err := m.Enqueue(ctx, queue, dl.Payload, dl.options()...); err != nil {
+			// Keep what wasn't replayed
+			m.mu.Lock()
+			rest := append([]DeadLetter{}, dead[i:]...)
+			m.dead[queue] = append(rest, m.dead[queue]...)
+			m.mu.Unlock()
+			return i, err
+		}
+	}
+	return len(dead), nil
+}
+
+func (m *Memory) PurgeDeadLetters(ctx context.Context, queue string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.dead[queue])
+	delete(m.dead, queue)
+	return n, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
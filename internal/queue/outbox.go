@@ -0,0 +1,105 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"postificus/internal/storage"
+)
+
+// Outbox relay tuning
+const (
+	outboxBatch     = 50
+	outboxLease     = 30 * time.Second // A claimed message is handed out again after this
+	outboxPoll      = time.Second
+	outboxRetention = 24 * time.Hour // Sent messages are kept this long for inspection
+)
+
+// outboxBackoff spaces out sends of a message the queue keeps refusing
+var outboxBackoff = RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 0.2}
+
+// OutboxRelay moves jobs from the storage outbox onto the queue. A message is
+// marked sent only after Enqueue returned, so delivery is at least once: a
+// crash in between sends it again under the same message ID, and the worker's
+// Deduplicator drops the copy.
+type OutboxRelay struct {
+	outbox storage.OutboxRepository
+	jobs   Queue
+	wake   chan struct{}
+}
+
+func NewOutboxRelay(outbox storage.OutboxRepository, jobs Queue) *OutboxRelay {
+	return &OutboxRelay{outbox: outbox, jobs: jobs, wake: make(chan struct{}, 1)}
+}
+
+// Notify makes the relay look at the outbox now rather than at its next poll
+func (r *OutboxRelay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default: // A wake-up is already pending
+	}
+}
+
+// Run relays pending messages until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	poll := time.NewTicker(outboxPoll)
+	defer poll.Stop()
+	purge := time.NewTicker(time.Hour)
+	defer purge.Stop()
+
+	for {
+		r.flush(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-poll.C:
+		case <-purge.C:
+			if n, err := r.outbox.PurgeSent(ctx, time.Now().Add(-outboxRetention)); err != nil {
+				log.Printf("⚠️ Failed to purge the outbox: %v", err)
+			} else if n > 0 {
+				log.Printf("🧹 Purged %d sent outbox messages", n)
+			}
+		}
+	}
+}
+
+// flush relays due messages until none are left and returns how many it sent
+func (r *OutboxRelay) flush(ctx context.Context) int {
+	sent := 0
+	for ctx.Err() == nil {
+		messages, err := r.outbox.Claim(ctx, outboxBatch, outboxLease)
+		if err != nil {
+			log.Printf("⚠️ Failed to read the outbox: %v", err)
+			return sent
+		}
+
+		var down error // Once one send fails, the rest of the batch goes back without waiting on the queue
+		for _, m := range messages {
+			err := down
+			if err == nil {
+				err = r.jobs.Enqueue(ctx, m.Queue, m.Payload, WithMessageID(m.IdempotencyKey), WithType(m.Type), WithPriority(m.Priority))
+			}
+			if err != nil {
+				down = err
+				retryIn := outboxBackoff.Backoff(m.Attempts)
+				log.Printf("⚠️ Failed to relay job %s to %s (attempt %d), retrying in %s: %v", m.IdempotencyKey, m.Queue, m.Attempts, retryIn, err)
+				if err := r.outbox.Release(ctx, m.ID, failureReason(err), retryIn); err != nil {
+					log.Printf("⚠️ Failed to release outbox message %d: %v", m.ID, err)
+				}
+				continue
+			}
+			// If this fails the lease runs out and the job goes out twice, which is what dedupe is for
+			if err := r.outbox.MarkSent(ctx, m.ID); err != nil {
+				log.Printf("⚠️ Failed to mark outbox message %d sent: %v", m.ID, err)
+			}
+			sent++
+		}
+
+		if len(messages) < outboxBatch || down != nil {
+			return sent
+		}
+	}
+	return sent
+}
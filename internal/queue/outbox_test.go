@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"postificus/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRelay_DeliversWithIdempotencyKey(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := storage.NewMemoryStore()
+			require.NoError(t, store.Outbox.Add(ctx, storage.OutboxMessage{Queue: "relayed", Type: "job:kind", Payload: []byte("job"), IdempotencyKey: "key-1"}))
+
+			relay := NewOutboxRelay(store.Outbox, q)
+			assert.Equal(t, 1, relay.flush(ctx))
+			assert.Equal(t, 0, relay.flush(ctx), "sent messages stay sent")
+
+			type delivery struct{ id, jobType, payload string }
+			got := make(chan delivery, 1)
+			consume(t, q, "relayed", func(ctx context.Context, payload []byte) error {
+				got <- delivery{MessageID(ctx), JobType(ctx), string(payload)}
+				return nil
+			})
+			select {
+			case d := <-got:
+				assert.Equal(t, delivery{"key-1", "job:kind", "job"}, d)
+			case <-time.After(2 * time.Second):
+				t.Fatal("job never arrived")
+			}
+		})
+	}
+}
+
+// refusingQueue is a queue whose broker never confirms
+type refusingQueue struct{ *Memory }
+
+func (refusingQueue) Enqueue(ctx context.Context, queue string, payload []byte, opts ...Option) error {
+	return errors.New("broker unavailable")
+}
+
+func TestOutboxRelay_KeepsWhatTheQueueRefuses(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	require.NoError(t, store.Outbox.Add(ctx,
+		storage.OutboxMessage{Queue: "refused", Payload: []byte("a"), IdempotencyKey: "a"},
+		storage.OutboxMessage{Queue: "refused", Payload: []byte("b"), IdempotencyKey: "b"},
+	))
+
+	relay := NewOutboxRelay(store.Outbox, refusingQueue{NewMemory()})
+	assert.Equal(t, 0, relay.flush(ctx))
+
+	// Both are backed off, not lost
+	claimed, err := store.Outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+	var due []storage.OutboxMessage
+	require.Eventually(t, func() bool {
+		claimed, err := store.Outbox.Claim(ctx, 10, time.Minute)
+		if err != nil {
+			return false
+		}
+		due = append(due, claimed...) // Jitter may bring them back one at a time
+		return len(due) == 2
+	}, 3*time.Second, 50*time.Millisecond)
+	for _, m := range due {
+		assert.Equal(t, "broker unavailable", m.LastError)
+	}
+}
+
+func TestDeduplicator_SkipsFinishedJobs(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	for name, dedupe := range map[string]*Deduplicator{"memory": NewDeduplicator(nil), "redis": NewDeduplicator(client)} {
+		t.Run(name, func(t *testing.T) {
+			runs := 0
+			fail := true
+			handler := dedupe.Wrap("dedupe", func(ctx context.Context, payload []byte) error {
+				runs++
+				if fail {
+					return errors.New("flaky")
+				}
+				return nil
+			})
+			ctx := withMessageID(context.Background(), "job-1")
+
+			// A failed attempt doesn't count as finished
+			assert.Error(t, handler(ctx, nil))
+			fail = false
+			require.NoError(t, handler(ctx, nil))
+			require.NoError(t, handler(ctx, nil), "a redelivered copy is acked")
+			assert.Equal(t, 2, runs)
+
+			// Other jobs, and jobs without an ID, still run
+			require.NoError(t, handler(withMessageID(context.Background(), "job-2"), nil))
+			require.NoError(t, handler(context.Background(), nil))
+			require.NoError(t, handler(context.Background(), nil))
+			assert.Equal(t, 5, runs)
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"postificus/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The memory and Redis backends share semantics; RabbitMQ needs a broker and is covered by hand
+func testQueues(t *testing.T) map[string]Queue {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	rq := NewRedis(client)
+	rq.Block = 20 * time.Millisecond
+	rq.SetDefaultRetryPolicy(fastRetries)
+	mq := NewMemory()
+	mq.SetDefaultRetryPolicy(fastRetries)
+	return map[string]Queue{
+		BackendMemory: mq,
+		BackendRedis:  rq,
+	}
+}
+
+// fastRetries keeps DefaultRetryPolicy's retry count with test-sized delays
+var fastRetries = RetryPolicy{MaxRetries: DefaultMaxRetries, BaseDelay: 5 * time.Millisecond, MaxDelay: 20 * time.Millisecond}
+
+// consume runs a consumer until the test ends
+func consume(t *testing.T, q Queue, queue string, handler Handler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, q.Consume(ctx, queue, handler))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestQueue_DeliversJobs(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var got []string
+			consume(t, q, "jobs", func(ctx context.Context, payload []byte) error {
+				mu.Lock()
+				defer mu.Unlock()
+				got = append(got, string(payload))
+				return nil
+			})
+
+			ctx := context.Background()
+			require.NoError(t, q.Enqueue(ctx, "jobs", []byte("a")))
+			require.NoError(t, q.Enqueue(ctx, "jobs", []byte("b"), WithMessageID("job-b")))
+
+			assert.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(got) == 2
+			}, 2*time.Second, 10*time.Millisecond)
+			assert.ElementsMatch(t, []string{"a", "b"}, got)
+		})
+	}
+}
+
+func TestQueue_RetriesThenGivesUp(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			var attempts atomic.Int32
+			consume(t, q, "flaky", func(ctx context.Context, payload []byte) error {
+				attempts.Add(1)
+				return errors.New("boom")
+			})
+
+			require.NoError(t, q.Enqueue(context.Background(), "flaky", []byte("x")))
+			assert.Eventually(t, func() bool { return attempts.Load() == DefaultMaxRetries+1 }, 2*time.Second, 10*time.Millisecond)
+
+			// No further attempts once dead-lettered
+			time.Sleep(100 * time.Millisecond)
+			assert.Equal(t, int32(DefaultMaxRetries+1), attempts.Load())
+		})
+	}
+}
+
+func TestQueue_BacksOffBetweenRetries(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			q.SetRetryPolicy("backoff", RetryPolicy{MaxRetries: 2, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+			var mu sync.Mutex
+			var at []time.Time
+			consume(t, q, "backoff", func(ctx context.Context, payload []byte) error {
+				mu.Lock()
+				defer mu.Unlock()
+				at = append(at, time.Now())
+				return errors.New("rate limited")
+			})
+
+			require.NoError(t, q.Enqueue(context.Background(), "backoff", []byte("x")))
+			assert.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(at) == 3
+			}, 3*time.Second, 10*time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.GreaterOrEqual(t, at[1].Sub(at[0]), 100*time.Millisecond)
+			assert.GreaterOrEqual(t, at[2].Sub(at[1]), 200*time.Millisecond, "delay doubles")
+		})
+	}
+}
+
+func TestQueue_PermanentErrorsSkipRetries(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			var attempts atomic.Int32
+			consume(t, q, "permanent", func(ctx context.Context, payload []byte) error {
+				attempts.Add(1)
+				return fmt.Errorf("publish failed: %w", Permanent(errors.New("credentials missing")))
+			})
+
+			require.NoError(t, q.Enqueue(context.Background(), "permanent", []byte("x")))
+			assert.Eventually(t, func() bool { return attempts.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+			time.Sleep(100 * time.Millisecond)
+			assert.Equal(t, int32(1), attempts.Load())
+		})
+	}
+}
+
+func TestQueue_PanicsFailTheJob(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			var attempts atomic.Int32
+			done := make(chan string, 2)
+			consume(t, q, "panicky", func(ctx context.Context, payload []byte) error {
+				if string(payload) == "bad" && attempts.Add(1) == 1 {
+					panic("nil element")
+				}
+				done <- string(payload)
+				return nil
+			})
+
+			ctx := context.Background()
+			require.NoError(t, q.Enqueue(ctx, "panicky", []byte("bad")))
+			require.NoError(t, q.Enqueue(ctx, "panicky", []byte("good")))
+			var got []string
+			for range 2 {
+				select {
+				case payload := <-done:
+					got = append(got, payload)
+				case <-time.After(2 * time.Second):
+					t.Fatalf("only %v processed", got)
+				}
+			}
+			assert.ElementsMatch(t, []string{"bad", "good"}, got, "the panicked job was retried and the consumer kept going")
+			assert.Equal(t, int32(2), attempts.Load())
+		})
+	}
+}
+
+func TestQueue_DeferDoesNotUseUpRetries(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			q.SetRetryPolicy("limited", RetryPolicy{MaxRetries: 0})
+			var mu sync.Mutex
+			var at []time.Time
+			consume(t, q, "limited", func(ctx context.Context, payload []byte) error {
+				mu.Lock()
+				defer mu.Unlock()
+				at = append(at, time.Now())
+				if len(at) < 4 {
+					return Defer(50*time.Millisecond, errors.New("over the rate limit"))
+				}
+				return nil
+			})
+
+			require.NoError(t, q.Enqueue(context.Background(), "limited", []byte("x")))
+			assert.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(at) == 4
+			}, 3*time.Second, 10*time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for i := 1; i < len(at); i++ {
+				assert.GreaterOrEqual(t, at[i].Sub(at[i-1]), 50*time.Millisecond)
+			}
+			dead, err := q.ListDeadLetters(context.Background(), "limited", 0)
+			require.NoError(t, err)
+			assert.Empty(t, dead, "deferred jobs are never dead-lettered")
+		})
+	}
+}
+
+func TestQueue_Delay(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			delivered := make(chan time.Time, 1)
+			consume(t, q, "later", func(ctx context.Context, payload []byte) error {
+				delivered <- time.Now()
+				return nil
+			})
+
+			start := time.Now()
+			require.NoError(t, q.Enqueue(context.Background(), "later", []byte("x"), WithDelay(200*time.Millisecond)))
+			select {
+			case at := <-delivered:
+				assert.GreaterOrEqual(t, at.Sub(start), 200*time.Millisecond)
+			case <-time.After(2 * time.Second):
+				t.Fatal("delayed job never delivered")
+			}
+		})
+	}
+}
+
+func TestQueue_DeadLetterAdmin(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			var mu sync.Mutex
+			fixed := map[string]bool{}
+			consume(t, q, "admin", func(ctx context.Context, payload []byte) error {
+				if string(payload) == "broken" {
+					return Permanent(errors.New("bad tags"))
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				fixed[string(payload)] = true
+				return nil
+			})
+
+			for i := 0; i < 3; i++ {
+				require.NoError(t, q.Enqueue(ctx, "admin", []byte("broken")))
+			}
+			var dead []DeadLetter
+			assert.Eventually(t, func() bool {
+				dead, _ = q.ListDeadLetters(ctx, "admin", 0)
+				return len(dead) == 3
+			}, 2*time.Second, 10*time.Millisecond)
+			assert.Equal(t, "bad tags", dead[0].Error)
+			assert.Equal(t, []byte("broken"), dead[0].Payload)
+			assert.NotEmpty(t, dead[0].ID)
+			assert.False(t, dead[0].FailedAt.IsZero())
+			assert.False(t, dead[0].EnqueuedAt.IsZero())
+
+			limited, err := q.ListDeadLetters(ctx, "admin", 2)
+			require.NoError(t, err)
+			assert.Len(t, limited, 2)
+
+			// Replay one with an edited payload
+			require.NoError(t, q.ReplayDeadLetter(ctx, "admin", dead[0].ID, []byte("fixed")))
+			assert.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return fixed["fixed"]
+			}, 2*time.Second, 10*time.Millisecond)
+			assert.ErrorIs(t, q.ReplayDeadLetter(ctx, "admin", dead[0].ID, nil), storage.ErrNotFound)
+
+			// Replaying the rest fails them again; purge drops them
+			replayed, err := q.ReplayDeadLetters(ctx, "admin")
+			require.NoError(t, err)
+			assert.Equal(t, 2, replayed)
+			assert.Eventually(t, func() bool {
+				dead, _ = q.ListDeadLetters(ctx, "admin", 0)
+				return len(dead) == 2
+			}, 2*time.Second, 10*time.Millisecond)
+
+			purged, err := q.PurgeDeadLetters(ctx, "admin")
+			require.NoError(t, err)
+			assert.Equal(t, 2, purged)
+			dead, err = q.ListDeadLetters(ctx, "admin", 0)
+			require.NoError(t, err)
+			assert.Empty(t, dead)
+		})
+	}
+}
+
+func TestMemory_DeadLetters(t *testing.T) {
+	q := NewMemory()
+	q.SetDefaultRetryPolicy(fastRetries)
+	consume(t, q, "bad", func(ctx context.Context, payload []byte) error { return errors.New("nope") })
+
+	require.NoError(t, q.Enqueue(context.Background(), "bad", []byte("payload")))
+	assert.Eventually(t, func() bool { return len(q.DeadLetters("bad")) == 1 }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []byte("payload"), q.DeadLetters("bad")[0])
+}
+
+func TestRedis_DeadLettersWithReason(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	q := NewRedis(client)
+	q.Block = 20 * time.Millisecond
+	q.SetDefaultRetryPolicy(fastRetries)
+
+	consume(t, q, "bad", func(ctx context.Context, payload []byte) error { return errors.New("nope") })
+	require.NoError(t, q.Enqueue(context.Background(), "bad", []byte("payload")))
+
+	var dead []redis.XMessage
+	assert.Eventually(t, func() bool {
+		dead, _ = client.XRange(context.Background(), streamKey("bad"+DeadLetterSuffix), "-", "+").Result()
+		return len(dead) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "payload", dead[0].Values["payload"])
+	assert.Equal(t, "nope", dead[0].Values["error"])
+
+	length, err := client.XLen(context.Background(), streamKey("bad")).Result()
+	require.NoError(t, err)
+	assert.Zero(t, length, "failed entries are removed from the main stream")
+}
+
+func TestRedis_ClaimsStuckJobs(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	ctx := context.Background()
+
+	// A worker reads the job and dies before acking it
+	crashed := NewRedis(client)
+	require.NoError(t, client.XGroupCreateMkStream(ctx, streamKey("stuck"), crashed.group, "0").Err())
+	require.NoError(t, crashed.Enqueue(ctx, "stuck", []byte("orphan")))
+	_, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: crashed.group, Consumer: crashed.consumer, Streams: []string{streamKey("stuck"), ">"}, Count: 1,
+	}).Result()
+	require.NoError(t, err)
+
+	survivor := NewRedis(client)
+	survivor.Block = 20 * time.Millisecond
+	survivor.ClaimAfter = 50 * time.Millisecond
+	time.Sleep(survivor.ClaimAfter)
+
+	got := make(chan string, 1)
+	consume(t, survivor, "stuck", func(ctx context.Context, payload []byte) error {
+		got <- string(payload)
+		return nil
+	})
+	select {
+	case payload := <-got:
+		assert.Equal(t, "orphan", payload)
+	case <-time.After(2 * time.Second):
+		t.Fatal("stuck job was never claimed")
+	}
+}
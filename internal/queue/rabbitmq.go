@@ -0,0 +1,528 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"postificus/internal/storage"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RabbitMQ is the queue backed by durable RabbitMQ queues with a dead-letter exchange.
+// The connection is supervised: consumers survive broker restarts, and every
+// publish waits for the broker's confirm.
+type RabbitMQ struct {
+	retryPolicies
+
+	conn *rabbitConn
+}
+
+// DialRabbitMQ connects to RabbitMQ, retrying while the broker starts up
+func DialRabbitMQ(uri string, attempts int) (*RabbitMQ, error) {
+	var conn *amqp.Connection
+	var err error
+	for i := 0; i < attempts; i++ {
+		conn, err = amqp.Dial(uri)
+		if err == nil {
+			return &RabbitMQ{conn: newRabbitConn(uri, conn)}, nil
+		}
+		log.Printf("Waiting for RabbitMQ... (%v)", err)
+		time.Sleep(2 * time.Second)
+	}
+	return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+}
+
+// Close stops reconnecting and closes the connection
+func (r *RabbitMQ) Close() error {
+	return r.conn.close()
+}
+
+// queueArgs routes rejected messages to the queue's DLQ through the dlx exchange.
+// Lanes are priority queues; other queues keep the arguments they were first
+// declared with, since RabbitMQ refuses to redeclare a queue with different ones.
+func queueArgs(queueName string) amqp.Table {
+	args := amqp.Table{
+		"x-dead-letter-exchange":    "dlx",
+		"x-dead-letter-routing-key": queueName,
+	}
+	if IsLane(queueName) {
+		args["x-max-priority"] = int32(MaxPriority)
+	}
+	return args
+}
+
+// delayQueueName holds messages for one delay; they expire back into the main queue
+func delayQueueName(queueName string, delay time.Duration) string {
+	return fmt.Sprintf("%s:delay:%d", queueName, delay.Milliseconds())
+}
+
+// roundDelay caps how many delay queues jittered retries create: delays of a
+// second or more are rounded to whole seconds
+func roundDelay(delay time.Duration) time.Duration {
+	if delay >= time.Second {
+		return delay.Round(time.Second)
+	}
+	return delay
+}
+
+// Enqueue sends a persistent message to the queue (through a TTL queue when delayed).
+// It returns once the broker has confirmed the message.
+func (r *RabbitMQ) Enqueue(ctx context.Context, queueName string, payload []byte, opts ...Option) error {
+	o := applyOptions(opts)
+	if o.MessageID == "" {
+		o.MessageID = uuid.NewString() // Replay addresses dead letters by ID
+	}
+
+	// Bounds waiting for a reconnect as well as for the confirm
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return r.conn.withPublisher(ctx, func(ch *amqp.Channel) error {
+		// Ensure queue exists before publishing (safety)
+		_, err := ch.QueueDeclare(
+			queueName,            // name
+			true,                 // durable
+			false,                // delete when unused
+			false,                // exclusive
+			false,                // no-wait
+			queueArgs(queueName), // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare queue: %w", err)
+		}
+
+		return publish(ctx, ch, queueName, o.Delay, amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         payload,
+			DeliveryMode: amqp.Persistent, // Persist messages
+			MessageId:    o.MessageID,
+			Type:         o.Type,
+			Priority:     o.Priority,
+			Timestamp:    time.Now(),
+		})
+	})
+}
+
+// publish routes msg to the queue, or to a delay queue that dead-letters it back after delay
+func publish(ctx context.Context, ch *amqp.Channel, queueName string, delay time.Duration, msg amqp.Publishing) error {
+	routingKey := queueName
+	if delay > 0 {
+		// One queue per delay keeps expiry FIFO; idle delay queues delete themselves
+		routingKey = delayQueueName(queueName, delay)
+		ttl := delay.Milliseconds()
+		_, err := ch.QueueDeclare(routingKey, true, false, false, false, amqp.Table{
+			"x-message-ttl":             ttl,
+			"x-expires":                 ttl + time.Minute.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queueName,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to declare delay queue: %w", err)
+		}
+	}
+
+	return confirmPublish(ctx, ch, "", routingKey, msg)
+}
+
+// declareTopology declares the dlx exchange, the queue's DLQ and the queue itself
+func declareTopology(ch *amqp.Channel, queueName string) error {
+	// Ensure DLX exists
+	err := ch.ExchangeDeclare(
+		"dlx",    // name
+		"direct", // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	// Ensure DLQ exists
+	_, err = ch.QueueDeclare(
+		queueName+DeadLetterSuffix, // name
+		true,                       // durable
+		false,                      // delete when unused
+		false,                      // exclusive
+		false,                      // no-wait
+		nil,                        // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	// Bind DLQ to DLX
+	err = ch.QueueBind(
+		queueName+DeadLetterSuffix, // queue name
+		queueName,                  // routing key (same as original queue for simplicity)
+		"dlx",                      // exchange
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Ensure Main Queue exists with DLQ arguments
+	_, err = ch.QueueDeclare(
+		queueName,            // name
+		true,                 // durable
+		false,                // delete when unused
+		false,                // exclusive
+		false,                // no-wait
+		queueArgs(queueName), // arguments
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// errConsumerLost means the consumer's channel or connection went away
+var errConsumerLost = errors.New("rabbitmq delivery channel closed")
+
+// Consume declares the queue topology and processes messages until ctx is cancelled.
+// When the connection drops it waits for the reconnect, re-declares the topology
+// and starts consuming again.
+func (r *RabbitMQ) Consume(ctx context.Context, queueName string, handler Handler) error {
+	delay := reconnectMinDelay
+	for {
+		err := r.consume(ctx, queueName, handler)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, ErrClosed) {
+			return err
+		}
+		if errors.Is(err, errConsumerLost) {
+			delay = reconnectMinDelay
+		} else {
+			// Declaring or subscribing failed; don't spin on it
+			delay = min(delay*2, reconnectMaxDelay)
+		}
+		log.Printf("⚠️ Consumer for %s interrupted (%v); restarting...", queueName, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// consume runs one consumer on its own channel until ctx is cancelled or the channel dies
+func (r *RabbitMQ) consume(ctx context.Context, queueName string, handler Handler) error {
+	ch, err := r.conn.channel(ctx)
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := declareTopology(ch, queueName); err != nil {
+		return err
+	}
+
+	// Set QoS (prefetch count) - process 1 message at a time per worker (fair dispatch)
+	err = ch.Qos(
+		1,     // prefetch count
+		0,     // prefetch size
+		false, // global
+	)
+	if err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(
+		queueName, // queue
+		"",        // consumer
+		false,     // auto-ack (WE WILL MANUALLY ACK)
+		false,     // exclusive
+		false,     // no-local
+		false,     // no-wait
+		nil,       // args
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🐰 Consumer started for queue: %s", queueName)
+	for {
+		var d amqp.Delivery
+		var ok bool
+		select {
+		case <-ctx.Done():
+			log.Printf("Consumer stopped for queue: %s", queueName)
+			return nil
+		case d, ok = <-msgs:
+			if !ok {
+				return errConsumerLost
+			}
+		}
+
+		log.Printf("Received a message on %s", queueName)
+
+		err := runHandler(withJob(context.WithoutCancel(ctx), queueName, d.MessageId, d.Type), handler, d.Body)
+		if err == nil {
+			d.Ack(false)
+			log.Printf("✅ Message processed on %s", queueName)
+			continue
+		}
+		if errors.Is(err, ErrInterrupted) {
+			log.Printf("⏸️ Requeueing interrupted message on %s", queueName)
+			d.Nack(false, true)
+			continue
+		}
+		if after, ok := deferral(err); ok {
+			delay := roundDelay(after)
+			log.Printf("⏳ Deferring message on %s for %s: %v", queueName, delay, err)
+			r.republish(ctx, queueName, d, copyHeaders(d.Headers), delay)
+			continue
+		}
+
+		log.Printf("❌ Error processing message: %v", err)
+		retryCount := headerInt(d.Headers["x-retry-count"])
+		policy := r.retryPolicy(d.Type, queueName)
+		if !policy.shouldRetry(retryCount, err) {
+			log.Printf("💀 Giving up on message. Moving to DLQ.")
+			r.deadLetter(ctx, queueName, d, retryCount, err)
+			continue
+		}
+		delay := roundDelay(policy.Backoff(retryCount + 1))
+		log.Printf("🔄 Retrying message in %s (Attempt %d/%d)...", delay, retryCount+1, policy.MaxRetries)
+		// Verify Channel is open
+		if ch.IsClosed() {
+			log.Println("Channel closed, cannot retry")
+			continue
+		}
+		headers := copyHeaders(d.Headers)
+		headers["x-retry-count"] = int32(retryCount + 1)
+		r.republish(ctx, queueName, d, headers, delay)
+	}
+}
+
+// republish sends a copy of the delivery through a delay queue, which dead-letters
+// it back onto the queue when the TTL expires, then acks the original
+func (r *RabbitMQ) republish(ctx context.Context, queueName string, d amqp.Delivery, headers amqp.Table, delay time.Duration) {
+	pubCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	err := r.conn.withPublisher(pubCtx, func(pub *amqp.Channel) error {
+		return publish(pubCtx, pub, queueName, delay, amqp.Publishing{
+			ContentType:  d.ContentType,
+			Body:         d.Body,
+			Headers:      headers,
+			DeliveryMode: d.DeliveryMode,
+			MessageId:    d.MessageId,
+			Type:         d.Type,
+			Priority:     d.Priority,
+			Timestamp:    d.Timestamp,
+		})
+	})
+	if err != nil {
+		log.Printf("❌ Failed to republish message: %v", err)
+		d.Nack(false, true) // Force requeue if republish failed
+		return
+	}
+	d.Ack(false) // Ack original, new one is in queue
+}
+
+// copyHeaders copies a delivery's headers for republishing
+func copyHeaders(h amqp.Table) amqp.Table {
+	headers := amqp.Table{}
+	for k, v := range h {
+		// The broker keeps x-death itself; copying it would only grow every retry
+		if !strings.HasPrefix(k, "x-death") && !strings.HasPrefix(k, "x-first-death") && !strings.HasPrefix(k, "x-last-death") {
+			headers[k] = v
+		}
+	}
+	return headers
+}
+
+// Headers a dead-lettered message carries about its failure
+const (
+	headerRetryCount = "x-retry-count"
+	headerError      = "x-error"
+	headerFailedAt   = "x-failed-at"
+)
+
+// deadLetter publishes the message to the DLQ with why it failed, then acks it.
+// A plain nack also reaches the DLQ (through the dlx), just without the reason.
+func (r *RabbitMQ) deadLetter(ctx context.Context, queueName string, d amqp.Delivery, retryCount int, cause error) {
+	headers := copyHeaders(d.Headers)
+	headers[headerRetryCount] = int32(retryCount)
+	headers[headerError] = failureReason(cause)
+	headers[headerFailedAt] = time.Now().UTC()
+
+	messageID := d.MessageId
+	if messageID == "" {
+		messageID = uuid.NewString() // Replay addresses dead letters by ID
+	}
+
+	pubCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	err := r.conn.withPublisher(pubCtx, func(pub *amqp.Channel) error {
+		return confirmPublish(pubCtx, pub, "dlx", queueName, amqp.Publishing{
+			ContentType:  d.ContentType,
+			Body:         d.Body,
+			Headers:      headers,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    messageID,
+			Type:         d.Type,
+			Priority:     d.Priority,
+			Timestamp:    d.Timestamp,
+		})
+	})
+	if err != nil {
+		log.Printf("❌ Failed to publish to DLQ, nacking instead: %v", err)
+		d.Nack(false, false) // Requeue=false -> DLQ
+		return
+	}
+	d.Ack(false)
+}
+
+// parseRabbitDeadLetter reads a DLQ message; its message ID addresses it for replay
+func parseRabbitDeadLetter(queueName string, d amqp.Delivery) DeadLetter {
+	dl := DeadLetter{
+		ID:         d.MessageId,
+		Queue:      queueName,
+		Type:       d.Type,
+		Priority:   d.Priority,
+		Payload:    d.Body,
+		Retries:    headerInt(d.Headers[headerRetryCount]),
+		EnqueuedAt: d.Timestamp,
+	}
+	dl.Error, _ = d.Headers[headerError].(string)
+	dl.FailedAt, _ = d.Headers[headerFailedAt].(time.Time)
+	return dl
+}
+
+// ListDeadLetters peeks at the DLQ: messages are fetched unacked and go back
+// to the queue when the channel closes
+func (r *RabbitMQ) ListDeadLetters(ctx context.Context, queueName string, limit int) ([]DeadLetter, error) {
+	ch, err := r.conn.channel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+	if err := declareTopology(ch, queueName); err != nil {
+		return nil, err
+	}
+
+	dead := []DeadLetter{}
+	for limit <= 0 || len(dead) < limit {
+		d, ok, err := ch.Get(queueName+DeadLetterSuffix, false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		dead = append(dead, parseRabbitDeadLetter(queueName, d))
+	}
+	return dead, nil
+}
+
+func (r *RabbitMQ) ReplayDeadLetter(ctx context.Context, queueName string, id string, payload []byte) error {
+	ch, err := r.conn.channel(ctx)
+	if err != nil {
+		return err
+	}
+	defer ch.Close() // Requeues the messages we skipped
+	if err := declareTopology(ch, queueName); err != nil {
+		return err
+	}
+
+	for {
+		d, ok, err := ch.Get(queueName+DeadLetterSuffix, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return storage.ErrNotFound
+		}
+		if d.MessageId != id {
+			continue
+		}
+		if payload != nil {
+			d.Body = payload
+		}
+		return r.replay(ctx, queueName, d)
+	}
+}
+
+func (r *RabbitMQ) ReplayDeadLetters(ctx context.Context, queueName string) (int, error) {
+	ch, err := r.conn.channel(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	if err := declareTopology(ch, queueName); err != nil {
+		return 0, err
+	}
+	// Replay only what is there now: replayed jobs that fail again come back to the DLQ
+	dlq, err := ch.QueueDeclarePassive(queueName+DeadLetterSuffix, true, false, false, false, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for replayed < dlq.Messages {
+		d, ok, err := ch.Get(queueName+DeadLetterSuffix, false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			return replayed, nil
+		}
+		if err := r.replay(ctx, queueName, d); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// replay republishes a dead letter to its queue with a fresh retry budget, then acks it
+func (r *RabbitMQ) replay(ctx context.Context, queueName string, d amqp.Delivery) error {
+	headers := copyHeaders(d.Headers)
+	delete(headers, headerRetryCount)
+	delete(headers, headerError)
+	delete(headers, headerFailedAt)
+
+	pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err := r.conn.withPublisher(pubCtx, func(pub *amqp.Channel) error {
+		return publish(pubCtx, pub, queueName, 0, amqp.Publishing{
+			ContentType:  d.ContentType,
+			Body:         d.Body,
+			Headers:      headers,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    d.MessageId,
+			Type:         d.Type,
+			Priority:     d.Priority,
+			Timestamp:    d.Timestamp,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replay message: %w", err)
+	}
+	return d.Ack(false)
+}
+
+func (r *RabbitMQ) PurgeDeadLetters(ctx context.Context, queueName string) (int, error) {
+	ch, err := r.conn.channel(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	if err := declareTopology(ch, queueName); err != nil {
+		return 0, err
+	}
+	return ch.QueuePurge(queueName+DeadLetterSuffix, false)
+}
@@ -0,0 +1,236 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrClosed is returned by a RabbitMQ queue after Close
+var ErrClosed = errors.New("queue closed")
+
+// publisherPoolSize is how many idle confirm-mode channels are kept for publishing
+const publisherPoolSize = 8
+
+// Reconnect backoff bounds
+const (
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// rabbitConn is a supervised connection: when the broker drops it, it is
+// redialled with backoff, and callers wait for the new one.
+type rabbitConn struct {
+	uri string
+
+	mu    sync.Mutex
+	conn  *amqp.Connection
+	ready chan struct{} // Closed while conn is usable; replaced when it drops
+	done  chan struct{} // Closed by close
+
+	publishers chan *amqp.Channel // Idle confirm-mode channels
+}
+
+func newRabbitConn(uri string, conn *amqp.Connection) *rabbitConn {
+	c := &rabbitConn{
+		uri:        uri,
+		conn:       conn,
+		ready:      make(chan struct{}),
+		done:       make(chan struct{}),
+		publishers: make(chan *amqp.Channel, publisherPoolSize),
+	}
+	close(c.ready)
+	go c.supervise(conn)
+	return c
+}
+
+// supervise redials whenever the connection drops, until close
+func (c *rabbitConn) supervise(conn *amqp.Connection) {
+	for {
+		closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		select {
+		case <-c.done:
+			return
+		case amqpErr := <-closed:
+			select {
+			case <-c.done:
+				return
+			default:
+			}
+			log.Printf("⚠️ RabbitMQ connection lost: %v", amqpErr)
+		}
+
+		c.mu.Lock()
+		c.conn = nil
+		c.ready = make(chan struct{})
+		c.mu.Unlock()
+
+		conn = c.redial()
+		if conn == nil {
+			return
+		}
+
+		c.mu.Lock()
+		c.conn = conn
+		close(c.ready)
+		c.mu.Unlock()
+		log.Println("✅ Reconnected to RabbitMQ")
+	}
+}
+
+// redial dials until it succeeds or the connection is closed (then it returns nil)
+func (c *rabbitConn) redial() *amqp.Connection {
+	delay := reconnectMinDelay
+	for {
+		// Jitter keeps a fleet of workers from reconnecting in lockstep
+		wait := delay/2 + rand.N(delay/2+1)
+		select {
+		case <-c.done:
+			return nil
+		case <-time.After(wait):
+		}
+
+		conn, err := amqp.Dial(c.uri)
+		if err == nil {
+			return conn
+		}
+		log.Printf("Waiting for RabbitMQ... (%v)", err)
+		delay = min(delay*2, reconnectMaxDelay)
+	}
+}
+
+// connection returns the live connection, waiting while it is being re-established
+func (c *rabbitConn) connection(ctx context.Context) (*amqp.Connection, error) {
+	for {
+		c.mu.Lock()
+		conn, ready := c.conn, c.ready
+		c.mu.Unlock()
+		if conn != nil && !conn.IsClosed() {
+			return conn, nil
+		}
+
+		// A closed conn that supervise hasn't swapped out yet leaves ready closed; poll briefly
+		var poll <-chan time.Time
+		if conn != nil {
+			poll = time.After(100 * time.Millisecond)
+		}
+		select {
+		case <-ready:
+		case <-poll:
+		case <-c.done:
+			return nil, ErrClosed
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for RabbitMQ: %w", ctx.Err())
+		}
+	}
+}
+
+// channel opens a channel on the live connection
+func (c *rabbitConn) channel(ctx context.Context) (*amqp.Channel, error) {
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel: %w", err)
+	}
+	return ch, nil
+}
+
+// withPublisher runs fn on a pooled confirm-mode channel
+func (c *rabbitConn) withPublisher(ctx context.Context, fn func(ch *amqp.Channel) error) error {
+	ch, err := c.acquirePublisher(ctx)
+	if err != nil {
+		return err
+	}
+	err = fn(ch)
+	c.releasePublisher(ch)
+	return err
+}
+
+func (c *rabbitConn) acquirePublisher(ctx context.Context) (*amqp.Channel, error) {
+	for {
+		select {
+		case ch := <-c.publishers:
+			if !ch.IsClosed() {
+				return ch, nil
+			}
+			// Died with its connection or on a channel error; try the next one
+		default:
+			ch, err := c.channel(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err := ch.Confirm(false); err != nil {
+				ch.Close()
+				return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+			}
+			return ch, nil
+		}
+	}
+}
+
+func (c *rabbitConn) releasePublisher(ch *amqp.Channel) {
+	if ch.IsClosed() {
+		return
+	}
+	select {
+	case c.publishers <- ch:
+	default:
+		ch.Close()
+	}
+}
+
+// close stops reconnecting and closes the pool and the connection
+func (c *rabbitConn) close() error {
+	c.mu.Lock()
+	select {
+	case <-c.done:
+		c.mu.Unlock()
+		return nil
+	default:
+	}
+	close(c.done)
+	conn := c.conn
+	c.mu.Unlock()
+
+	for {
+		select {
+		case ch := <-c.publishers:
+			ch.Close()
+			continue
+		default:
+		}
+		break
+	}
+	if conn != nil && !conn.IsClosed() {
+		return conn.Close()
+	}
+	return nil
+}
+
+// confirmPublish publishes msg and, on a confirm-mode channel, waits until the broker has it
+func confirmPublish(ctx context.Context, ch *amqp.Channel, exchange string, routingKey string, msg amqp.Publishing) error {
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, msg)
+	if err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+	if confirm == nil {
+		return nil // Not a confirm-mode channel
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("waiting for broker confirm: %w", err)
+	}
+	if !acked {
+		return errors.New("broker refused the message")
+	}
+	return nil
+}
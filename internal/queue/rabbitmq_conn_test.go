@@ -0,0 +1,36 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// A connection that is being re-established; no broker needed
+func reconnectingConn() *rabbitConn {
+	return &rabbitConn{
+		ready:      make(chan struct{}),
+		done:       make(chan struct{}),
+		publishers: make(chan *amqp.Channel, publisherPoolSize),
+	}
+}
+
+func TestRabbitConn_CallersWaitForReconnect(t *testing.T) {
+	c := reconnectingConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.connection(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "waited for the reconnect")
+
+	// Publishers and consumers give up once the queue is closed
+	assert.NoError(t, c.close())
+	_, err = c.acquirePublisher(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.NoError(t, c.close(), "closing twice is harmless")
+}
@@ -0,0 +1,403 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"postificus/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is the queue backed by Redis Streams. Every queue is a stream read by one
+// consumer group; jobs stay pending until acked, and jobs left pending by a crashed
+// worker are taken over with XAUTOCLAIM once they have been idle for ClaimAfter.
+// Streams are strictly ordered: priorities are kept with the job but not honoured.
+type Redis struct {
+	retryPolicies
+
+	client   *redis.Client
+	group    string
+	consumer string
+
+	ClaimAfter time.Duration // How long a job may stay unacked before another worker takes it
+	Block      time.Duration // How long one read waits for new jobs
+}
+
+func NewRedis(client *redis.Client) *Redis {
+	host, _ := os.Hostname()
+	return &Redis{
+		client:     client,
+		group:      "workers",
+		consumer:   fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8]),
+		ClaimAfter: 10 * time.Minute, // Browser publishing can legitimately take minutes
+		Block:      2 * time.Second,
+	}
+}
+
+func streamKey(queue string) string  { return "queue:" + queue }
+func delayedKey(queue string) string { return "queue:" + queue + ":delayed" }
+
+// redisJob is what a stream entry (and a delayed set member) holds
+type redisJob struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Priority   uint8  `json:"priority"`
+	Payload    string `json:"payload"`
+	Attempt    int    `json:"attempt"`
+	EnqueuedAt int64  `json:"enqueued_at,string"` // Unix millis; a string so Lua keeps every digit
+}
+
+func (j redisJob) values() map[string]interface{} {
+	return map[string]interface{}{"id": j.ID, "type": j.Type, "priority": j.Priority, "payload": j.Payload, "attempt": j.Attempt, "enqueued_at": j.EnqueuedAt}
+}
+
+func parseRedisJob(msg redis.XMessage) redisJob {
+	job := redisJob{}
+	job.ID, _ = msg.Values["id"].(string)
+	job.Type, _ = msg.Values["type"].(string) // Absent on jobs from before shared queues
+	job.Payload, _ = msg.Values["payload"].(string)
+	if attempt, ok := msg.Values["attempt"].(string); ok {
+		job.Attempt, _ = strconv.Atoi(attempt)
+	}
+	if priority, ok := msg.Values["priority"].(string); ok {
+		p, _ := strconv.ParseUint(priority, 10, 8)
+		job.Priority = uint8(p)
+	}
+	if enqueuedAt, ok := msg.Values["enqueued_at"].(string); ok {
+		job.EnqueuedAt, _ = strconv.ParseInt(enqueuedAt, 10, 64)
+	}
+	return job
+}
+
+func (r *Redis) Enqueue(ctx context.Context, queue string, payload []byte, opts ...Option) error {
+	o := applyOptions(opts)
+	job := redisJob{ID: o.MessageID, Type: o.Type, Priority: o.Priority, Payload: string(payload), EnqueuedAt: time.Now().UnixMilli()}
+	if job.ID == "" {
+		job.ID = uuid.NewString() // Also keeps identical delayed payloads apart in the set
+	}
+
+	if o.Delay > 0 {
+		member, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		due := float64(time.Now().Add(o.Delay).UnixMilli())
+		return r.client.ZAdd(ctx, delayedKey(queue), redis.Z{Score: due, Member: member}).Err()
+	}
+	return r.client.XAdd(ctx, &redis.XAddArgs{Stream: streamKey(queue), Values: job.values()}).Err()
+}
+
+func (r *Redis) Consume(ctx context.Context, queue string, handler Handler) error {
+	stream := streamKey(queue)
+	err := r.client.XGroupCreateMkStream(ctx, stream, r.group, "0").Err()
+	if err != nil && !strings.Contains(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	log.Printf("🧵 Consumer started for stream: %s", stream)
+	lastClaim := time.Time{}
+	for ctx.Err() == nil {
+		if err := r.promoteDelayed(ctx, queue); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Failed to promote delayed jobs on %s: %v", queue, err)
+		}
+
+		if time.Since(lastClaim) >= r.ClaimAfter/2 {
+			lastClaim = time.Now()
+			r.claimStuck(ctx, queue, handler)
+		}
+
+		streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    r.group,
+			Consumer: r.consumer,
+			Streams:  []string{stream, ">"},
+			Count:    1,
+			Block:    r.Block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			log.Printf("⚠️ Failed to read %s: %v", stream, err)
+			time.Sleep(time.Second)
+			continue
+		}
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				r.process(ctx, queue, msg, handler)
+			}
+		}
+	}
+	log.Printf("Consumer stopped for stream: %s", stream)
+	return nil
+}
+
+// claimStuck takes over jobs another consumer read but never acked
+func (r *Redis) claimStuck(ctx context.Context, queue string, handler Handler) {
+	stream := streamKey(queue)
+	messages, _, err := r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    r.group,
+		Consumer: r.consumer,
+		MinIdle:  r.ClaimAfter,
+		Start:    "0-0",
+		Count:    10,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("⚠️ Failed to claim stuck jobs on %s: %v", stream, err)
+		}
+		return
+	}
+
+	for _, msg := range messages {
+		log.Printf("♻️ Claimed stuck job %s on %s", msg.ID, stream)
+		// A job that keeps killing its worker must not be claimed forever
+		pending, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: stream, Group: r.group, Start: msg.ID, End: msg.ID, Count: 1,
+		}).Result()
+		job := parseRedisJob(msg)
+		if err == nil && len(pending) == 1 && pending[0].RetryCount > int64(r.retryPolicy(job.Type, queue).MaxRetries)+1 {
+			r.deadLetter(ctx, queue, msg.ID, job, fmt.Errorf("abandoned by %d workers", pending[0].RetryCount))
+			continue
+		}
+		r.process(ctx, queue, msg, handler)
+	}
+}
+
+// process runs one job, then acks it, schedules a delayed retry or dead-letters it
+func (r *Redis) process(ctx context.Context, queue string, msg redis.XMessage, handler Handler) {
+	stream := streamKey(queue)
+	job := parseRedisJob(msg)
+	log.Printf("Received a message on %s", stream)
+
+	// Bookkeeping must finish even when shutdown has started
+	ctx = context.WithoutCancel(ctx)
+	err := runHandler(withJob(ctx, queue, job.ID, job.Type), handler, []byte(job.Payload))
+	if err == nil {
+		r.ack(ctx, stream, msg.ID)
+		log.Printf("✅ Message processed on %s", stream)
+		return
+	}
+	if errors.Is(err, ErrInterrupted) {
+		log.Printf("⏸️ Requeueing interrupted message on %s", stream)
+		r.requeue(ctx, stream, msg.ID, job)
+		return
+	}
+	if delay, ok := deferral(err); ok {
+		log.Printf("⏳ Deferring message on %s for %s: %v", stream, delay, err)
+		r.schedule(ctx, queue, msg.ID, job, delay)
+		return
+	}
+
+	log.Printf("❌ Error processing message: %v", err)
+	policy := r.retryPolicy(job.Type, queue)
+	if !policy.shouldRetry(job.Attempt, err) {
+		log.Printf("💀 Giving up on message. Moving to DLQ.")
+		r.deadLetter(ctx, queue, msg.ID, job, err)
+		return
+	}
+
+	job.Attempt++
+	delay := policy.Backoff(job.Attempt)
+	log.Printf("🔄 Retrying message in %s (Attempt %d/%d)...", delay, job.Attempt, policy.MaxRetries)
+	r.schedule(ctx, queue, msg.ID, job, delay)
+}
+
+// schedule moves a job from the stream to the delayed set, due after delay
+func (r *Redis) schedule(ctx context.Context, queue string, id string, job redisJob, delay time.Duration) {
+	stream := streamKey(queue)
+	member, err := json.Marshal(job)
+	if err != nil {
+		log.Printf("❌ Failed to encode delayed job: %v", err)
+		return
+	}
+	due := float64(time.Now().Add(delay).UnixMilli())
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, delayedKey(queue), redis.Z{Score: due, Member: member})
+		pipe.XAck(ctx, stream, r.group, id)
+		pipe.XDel(ctx, stream, id)
+		return nil
+	})
+	if err != nil {
+		// Left pending; XAUTOCLAIM will hand it out again
+		log.Printf("❌ Failed to schedule %s on %s: %v", id, stream, err)
+	}
+}
+
+// requeue puts a job back on the stream as it was, so no retry is used up
+func (r *Redis) requeue(ctx context.Context, stream string, id string, job redisJob) {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: job.values()})
+		pipe.XAck(ctx, stream, r.group, id)
+		pipe.XDel(ctx, stream, id)
+		return nil
+	})
+	if err != nil {
+		// Left pending; XAUTOCLAIM will hand it out again
+		log.Printf("⚠️ Failed to requeue %s on %s: %v", id, stream, err)
+	}
+}
+
+func (r *Redis) ack(ctx context.Context, stream string, id string) {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, stream, r.group, id)
+		pipe.XDel(ctx, stream, id)
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to ack %s on %s: %v", id, stream, err)
+	}
+}
+
+// deadLetter moves a job to the queue's DLQ stream along with why it failed
+func (r *Redis) deadLetter(ctx context.Context, queue string, id string, job redisJob, cause error) {
+	stream := streamKey(queue)
+	values := job.values()
+	values["error"] = failureReason(cause)
+	values["failed_at"] = time.Now().UnixMilli()
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: deadLetterKey(queue), Values: values})
+		pipe.XAck(ctx, stream, r.group, id)
+		pipe.XDel(ctx, stream, id)
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ Failed to dead-letter %s on %s: %v", id, stream, err)
+	}
+}
+
+// promoteScript atomically moves due jobs from the delayed set into the stream
+var promoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 100)
+for _, member in ipairs(due) do
+	redis.call('ZREM', KEYS[1], member)
+	local job = cjson.decode(member)
+	redis.call('XADD', KEYS[2], '*', 'id', job.id, 'type', job.type or '', 'priority', tostring(job.priority or 0),
+		'payload', job.payload, 'attempt', tostring(job.attempt), 'enqueued_at', job.enqueued_at)
+end
+return #due
+`)
+
+// promoteDelayed makes delayed jobs that are due visible to consumers
+func (r *Redis) promoteDelayed(ctx context.Context, queue string) error {
+	now := time.Now().UnixMilli()
+	return promoteScript.Run(ctx, r.client, []string{delayedKey(queue), streamKey(queue)}, now).Err()
+}
+
+func deadLetterKey(queue string) string { return streamKey(queue + DeadLetterSuffix) }
+
+// parseRedisDeadLetter reads a DLQ stream entry; its stream ID addresses it for replay
+func parseRedisDeadLetter(queue string, msg redis.XMessage) DeadLetter {
+	job := parseRedisJob(msg)
+	dl := DeadLetter{ID: msg.ID, Queue: queue, Type: job.Type, Priority: job.Priority, Payload: []byte(job.Payload), Retries: job.Attempt}
+	dl.Error, _ = msg.Values["error"].(string)
+	if job.EnqueuedAt > 0 {
+		dl.EnqueuedAt = time.UnixMilli(job.EnqueuedAt)
+	}
+	if failedAt, ok := msg.Values["failed_at"].(string); ok {
+		if ms, err := strconv.ParseInt(failedAt, 10, 64); err == nil {
+			dl.FailedAt = time.UnixMilli(ms)
+		}
+	}
+	return dl
+}
+
+func (r *Redis) ListDeadLetters(ctx context.Context, queue string, limit int) ([]DeadLetter, error) {
+	var messages []redis.XMessage
+	var err error
+	if limit > 0 {
+		messages, err = r.client.XRangeN(ctx, deadLetterKey(queue), "-", "+", int64(limit)).Result()
+	} else {
+		messages, err = r.client.XRange(ctx, deadLetterKey(queue), "-", "+").Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+	dead := make([]DeadLetter, 0, len(messages))
+	for _, msg := range messages {
+		dead = append(dead, parseRedisDeadLetter(queue, msg))
+	}
+	return dead, nil
+}
+
+func (r *Redis) ReplayDeadLetter(ctx context.Context, queue string, id string, payload []byte) error {
+	messages, err := r.client.XRange(ctx, deadLetterKey(queue), id, id).Result()
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return storage.ErrNotFound
+	}
+
+	job := parseRedisJob(messages[0])
+	if payload != nil {
+		job.Payload = string(payload)
+	}
+	return r.replay(ctx, queue, id, job)
+}
+
+// replay moves a DLQ entry back onto the main stream with a fresh retry budget
+func (r *Redis) replay(ctx context.Context, queue string, id string, job redisJob) error {
+	job.Attempt = 0
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: streamKey(queue), Values: job.values()})
+		pipe.XDel(ctx, deadLetterKey(queue), id)
+		return nil
+	})
+	return err
+}
+
+func (r *Redis) ReplayDeadLetters(ctx context.Context, queue string) (int, error) {
+	// Stop at the current last entry: replayed jobs that fail again land after it
+	last, err := r.client.XRevRangeN(ctx, deadLetterKey(queue), "+", "-", 1).Result()
+	if err != nil || len(last) == 0 {
+		return 0, err
+	}
+
+	replayed := 0
+	for {
+		messages, err := r.client.XRangeN(ctx, deadLetterKey(queue), "-", last[0].ID, 100).Result()
+		if err != nil {
+			return replayed, err
+		}
+		if len(messages) == 0 {
+			return replayed, nil
+		}
+		for _, msg := range messages {
+			if err := r.replay(ctx, queue, msg.ID, parseRedisJob(msg)); err != nil {
+				return replayed, err
+			}
+			replayed++
+		}
+	}
+}
+
+func (r *Redis) PurgeDeadLetters(ctx context.Context, queue string) (int, error) {
+	var length *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		length = pipe.XLen(ctx, deadLetterKey(queue))
+		pipe.Del(ctx, deadLetterKey(queue))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(length.Val()), nil
+}
+
+// Close leaves the shared client open; storage.CloseRedis owns it
+func (r *Redis) Close() error {
+	return nil
+}
@@ -0,0 +1,171 @@
+package queue
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy decides how often and how far apart a queue's failed jobs are retried
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt; 0 dead-letters on the first failure
+	BaseDelay  time.Duration // Delay before the first retry; doubles with every further one
+	MaxDelay   time.Duration // Upper bound for a single delay
+	Jitter     float64       // Fraction (0-1) of the delay that is randomised
+}
+
+// DefaultRetryPolicy applies to queues without a policy of their own.
+// Rate limits and WAF blocks need minutes, not milliseconds, to clear.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: DefaultMaxRetries,
+	BaseDelay:  30 * time.Second,
+	MaxDelay:   10 * time.Minute,
+	Jitter:     0.2,
+}
+
+// Backoff returns the delay before retry number attempt (1 for the first retry)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		// Spread retries over [delay*(1-jitter), delay] so failures don't come back in lockstep
+		delay -= delay * p.Jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// retryPolicies holds per-queue policies; the backends embed it
+type retryPolicies struct {
+	mu       sync.RWMutex
+	byQueue  map[string]RetryPolicy
+	fallback *RetryPolicy
+}
+
+// SetRetryPolicy overrides the retry policy of one queue, or of one job type on shared queues
+func (r *retryPolicies) SetRetryPolicy(queue string, policy RetryPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byQueue == nil {
+		r.byQueue = make(map[string]RetryPolicy)
+	}
+	r.byQueue[queue] = policy
+}
+
+// SetDefaultRetryPolicy overrides the policy of queues without one of their own
+func (r *retryPolicies) SetDefaultRetryPolicy(policy RetryPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = &policy
+}
+
+// retryPolicy returns the policy set for the first of names that has one: a job's
+// type, then its queue, so job types sharing a lane keep their own policies
+func (r *retryPolicies) retryPolicy(names ...string) RetryPolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, name := range names {
+		if policy, ok := r.byQueue[name]; ok {
+			return policy
+		}
+	}
+	if r.fallback != nil {
+		return *r.fallback
+	}
+	return DefaultRetryPolicy
+}
+
+// shouldRetry reports whether a job that failed on its attempt-th retry gets another one
+func (p RetryPolicy) shouldRetry(attempt int, err error) bool {
+	return !IsPermanent(err) && attempt < p.MaxRetries
+}
+
+// permanentError marks a failure that retrying can't fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying (missing credentials, unsupported
+// platform, malformed payload); the job is dead-lettered on the first failure.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or an error it wraps, was marked Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// deferredError marks a job that can't run yet, as opposed to one that failed
+type deferredError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *deferredError) Error() string { return e.err.Error() }
+func (e *deferredError) Unwrap() error { return e.err }
+
+// Defer puts the job back on its queue to run again after the delay, without
+// using up a retry: for work that mustn't run yet, such as an action over its
+// rate limit. The job keeps its ID, type and priority.
+func Defer(after time.Duration, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &deferredError{err: err, after: max(after, 0)}
+}
+
+// deferral returns how long a job whose handler returned err is deferred for
+func deferral(err error) (time.Duration, bool) {
+	var d *deferredError
+	if errors.As(err, &d) {
+		return d.after, true
+	}
+	return 0, false
+}
+
+// headerInt reads a numeric AMQP header whatever integer type the publisher used
+func headerInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int8:
+		return int(v)
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case uint8:
+		return int(v)
+	case uint16:
+		return int(v)
+	case uint32:
+		return int(v)
+	case uint64:
+		return int(v)
+	case float32:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+	return 0
+}
@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, p.Backoff(1))
+	assert.Equal(t, 2*time.Second, p.Backoff(2))
+	assert.Equal(t, 4*time.Second, p.Backoff(3))
+	assert.Equal(t, 5*time.Second, p.Backoff(4), "capped at MaxDelay")
+	assert.Equal(t, 5*time.Second, p.Backoff(60), "no overflow on large attempts")
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.Backoff(2)
+		assert.GreaterOrEqual(t, d, time.Second)
+		assert.LessOrEqual(t, d, 2*time.Second)
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 2}
+	assert.True(t, p.shouldRetry(0, errors.New("timeout")))
+	assert.True(t, p.shouldRetry(1, errors.New("timeout")))
+	assert.False(t, p.shouldRetry(2, errors.New("timeout")))
+	assert.False(t, p.shouldRetry(0, fmt.Errorf("wrapped: %w", Permanent(errors.New("no credentials")))))
+	assert.Nil(t, Permanent(nil))
+}
+
+func TestRetryPolicies_PerQueue(t *testing.T) {
+	var r retryPolicies
+	assert.Equal(t, DefaultRetryPolicy, r.retryPolicy("any"))
+
+	custom := RetryPolicy{MaxRetries: 5, BaseDelay: time.Minute}
+	r.SetRetryPolicy("publish", custom)
+	assert.Equal(t, custom, r.retryPolicy("publish"))
+	assert.Equal(t, DefaultRetryPolicy, r.retryPolicy("sync"))
+}
+
+func TestHeaderInt(t *testing.T) {
+	// Publishers disagree on the integer type of x-retry-count
+	for _, v := range []interface{}{int32(3), int64(3), int(3), int16(3), uint8(3), float64(3), "3"} {
+		assert.Equal(t, 3, headerInt(v), "%T", v)
+	}
+	assert.Zero(t, headerInt(nil))
+}
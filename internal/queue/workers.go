@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// abortGrace is how long interrupted jobs get to requeue themselves after the drain timeout
+const abortGrace = 10 * time.Second
+
+// Workers runs a worker process's consumers and shuts them down gracefully:
+// Shutdown stops fetching, lets in-flight jobs finish within a drain timeout,
+// then cancels the rest so they go back to the queue.
+type Workers struct {
+	jobs Queue
+	wg   sync.WaitGroup
+
+	fetch        context.Context // Cancelled when Shutdown starts
+	stopFetching context.CancelFunc
+	run          context.Context // Handed to handlers; cancelled when draining times out
+	abort        context.CancelFunc
+}
+
+func NewWorkers(jobs Queue) *Workers {
+	w := &Workers{jobs: jobs}
+	w.fetch, w.stopFetching = context.WithCancel(context.Background())
+	w.run, w.abort = context.WithCancel(context.Background())
+	return w
+}
+
+// Start runs concurrency consumers of the queue
+func (w *Workers) Start(queue string, concurrency int, handler Handler) {
+	wrapped := func(ctx context.Context, payload []byte) error {
+		err := handler(withJob(w.run, queue, MessageID(ctx), JobType(ctx)), payload)
+		if err != nil && w.run.Err() != nil {
+			// Whatever the handler made of the cancellation, the job didn't fail on its own
+			return fmt.Errorf("%w: %v", ErrInterrupted, err)
+		}
+		return err
+	}
+
+	for i := 0; i < concurrency; i++ {
+		w.wg.Add(1)
+		go func(id int) {
+			defer w.wg.Done()
+			log.Printf("Starting %s worker %d...", queue, id)
+			if err := w.jobs.Consume(w.fetch, queue, wrapped); err != nil {
+				log.Printf("❌ %s worker %d stopped: %v", queue, id, err)
+			}
+		}(i + 1)
+	}
+}
+
+// Shutdown stops fetching jobs and waits up to drainTimeout for in-flight ones.
+// Jobs still running after that are cancelled and requeued; the returned error
+// says so.
+func (w *Workers) Shutdown(drainTimeout time.Duration) error {
+	w.stopFetching()
+	if w.wait(drainTimeout) {
+		w.abort()
+		return nil
+	}
+
+	log.Printf("⏱️ Drain timeout (%s) reached; cancelling in-flight jobs", drainTimeout)
+	w.abort()
+	if !w.wait(abortGrace) {
+		return fmt.Errorf("workers did not stop %s after cancellation", abortGrace)
+	}
+	return fmt.Errorf("drain timeout after %s: in-flight jobs were requeued", drainTimeout)
+}
+
+// wait reports whether every consumer returned within timeout
+func (w *Workers) wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
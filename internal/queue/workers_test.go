@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkers_DrainLetsInFlightJobsFinish(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			started := make(chan struct{})
+			var finished atomic.Bool
+			workers := NewWorkers(q)
+			workers.Start("drain", 1, func(ctx context.Context, payload []byte) error {
+				close(started)
+				time.Sleep(100 * time.Millisecond)
+				finished.Store(ctx.Err() == nil)
+				return nil
+			})
+
+			require.NoError(t, q.Enqueue(context.Background(), "drain", []byte("x")))
+			<-started
+			require.NoError(t, workers.Shutdown(time.Second))
+			assert.True(t, finished.Load(), "job ran to completion with a live context")
+
+			dead, err := q.ListDeadLetters(context.Background(), "drain", 0)
+			require.NoError(t, err)
+			assert.Empty(t, dead)
+		})
+	}
+}
+
+func TestWorkers_DrainTimeoutRequeuesUnfinishedJobs(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			started := make(chan struct{})
+			workers := NewWorkers(q)
+			workers.Start("slow", 1, func(ctx context.Context, payload []byte) error {
+				close(started)
+				<-ctx.Done() // A browser publish that stops when its page is cancelled
+				return ctx.Err()
+			})
+
+			require.NoError(t, q.Enqueue(context.Background(), "slow", []byte("unfinished")))
+			<-started
+			assert.Error(t, workers.Shutdown(50*time.Millisecond), "drain timed out")
+
+			// The next worker gets the job with its retry budget intact
+			got := make(chan []byte, 1)
+			next := NewWorkers(q)
+			next.Start("slow", 1, func(ctx context.Context, payload []byte) error {
+				got <- payload
+				return nil
+			})
+			defer next.Shutdown(time.Second)
+
+			select {
+			case payload := <-got:
+				assert.Equal(t, "unfinished", string(payload))
+			case <-time.After(2 * time.Second):
+				t.Fatal("interrupted job was not requeued")
+			}
+			dead, err := q.ListDeadLetters(context.Background(), "slow", 0)
+			require.NoError(t, err)
+			assert.Empty(t, dead)
+		})
+	}
+}
+
+func TestWorkers_StopFetchingOnShutdown(t *testing.T) {
+	for name, q := range testQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			var handled atomic.Int32
+			workers := NewWorkers(q)
+			workers.Start("idle", 2, func(ctx context.Context, payload []byte) error {
+				handled.Add(1)
+				return nil
+			})
+			require.NoError(t, workers.Shutdown(time.Second))
+
+			require.NoError(t, q.Enqueue(context.Background(), "idle", []byte("late")))
+			time.Sleep(100 * time.Millisecond)
+			assert.Zero(t, handled.Load())
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"postificus/internal/collab"
+	"postificus/internal/controller"
+	"postificus/internal/middleware"
+	"postificus/internal/queue"
+	"postificus/internal/service"
+	"postificus/internal/storage"
+	"postificus/internal/throttle"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server is the HTTP API: every service and route, wired to a storage backend and a job queue
+type Server struct {
+	*echo.Echo
+	collab *collab.Hub
+	outbox *queue.OutboxRelay
+}
+
+// New builds the services, controllers and routes (the manual DI container)
+func New(store *storage.Store, jobs queue.Queue) *Server {
+	// Services (repositories come from the configured storage backend)
+	workspaceService := service.NewWorkspaceService(store.Workspaces)
+	authService := service.NewAuthService(store.Credentials, workspaceService)
+	draftService := service.NewDraftService(store.Drafts, store.Reviews, store.Revisions, workspaceService)
+	profileService := service.NewProfileService(store.Profiles)
+	// Live activity scrapes count against the same per-account limits as the worker's
+	limits, err := throttle.LimitsFromEnv()
+	if err != nil {
+		log.Printf("⚠️ %v; using the default platform limits", err)
+		limits = throttle.DefaultLimits
+	}
+	activityService := service.NewActivityService(store.Credentials, store.Posts, throttle.New(storage.RedisClient, limits))
+	searchService := service.NewSearchService(store.Search, workspaceService)
+	tagService := service.NewTagService(store.TagMappings, workspaceService)
+
+	// Jobs go through the transactional outbox; the relay moves them onto the queue
+	outboxRelay := queue.NewOutboxRelay(store.Outbox, jobs)
+	jobService := service.NewJobService(store.Outbox, store.PublishLogs, outboxRelay)
+
+	// Controllers
+	authController := controller.NewAuthController(authService)
+	settingsController := controller.NewSettingsController(authService, profileService)
+	draftController := controller.NewDraftController(draftService)
+	activityController := controller.NewActivityController(activityService, workspaceService)
+	dashboardController := controller.NewDashboardController(activityService, workspaceService, jobService)
+	publishController := controller.NewPublishController(jobService, draftService)
+	workspaceController := controller.NewWorkspaceController(workspaceService)
+	searchController := controller.NewSearchController(searchService)
+	tagController := controller.NewTagController(tagService)
+	deadLetterController := controller.NewDeadLetterController(jobs, service.JobQueues()...)
+	// The workers' breakers, seen through Redis; without it this process's own (idle) ones
+	breakerController := controller.NewBreakerController(service.NewPublishBreakers(storage.RedisClient))
+	artifactsController := controller.NewArtifactsController()
+
+	// Real-time collaboration; rooms are shared across instances through Redis
+	collabHub := collab.NewHub(draftService, workspaceService, storage.RedisClient)
+	allowedOrigins := middleware.AllowedOriginsFromEnv()
+	collabController := controller.NewCollabController(draftService, workspaceService, collabHub, allowedOrigins)
+
+	rateLimiter, err := middleware.RateLimiterFromEnv(storage.RedisClient)
+	if err != nil {
+		log.Printf("⚠️ %v; using the default rate limits", err)
+		rateLimiter, _ = middleware.NewRateLimiter(storage.RedisClient, middleware.DefaultRatePolicies, nil)
+	}
+
+	// Server Setup
+	e := echo.New()
+	e.Use(echoMiddleware.Logger())
+	e.Use(echoMiddleware.Recover())
+	e.Use(middleware.CORS(allowedOrigins))
+	// Identifies the user before the rate limiter buckets requests by them
+	e.Use(middleware.AuthenticateFromEnv())
+	e.Use(rateLimiter.Limit)
+	e.Use(middleware.PrometheusMiddleware)
+
+	// Routes
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+	e.GET("/health", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	// Auth & Connect
+	e.POST("/api/connect/:platform", authController.HandleConnectPlatform) // Unified
+
+	// Settings & Profile
+	e.POST("/api/settings/credentials", settingsController.SaveCredentials)
+	e.GET("/api/settings/credentials/:platform", settingsController.GetCredentialsStatus)
+	e.DELETE("/api/settings/credentials/:platform", settingsController.DeleteCredentials)
+	e.GET("/api/settings/accounts", settingsController.ListAccounts)
+	e.DELETE("/api/settings/accounts/:id", settingsController.DeleteAccount)
+	e.GET("/api/profile", settingsController.GetProfile)
+	e.PUT("/api/profile", settingsController.SaveProfile)
+
+	// Workspaces
+	e.GET("/api/workspaces", workspaceController.ListWorkspaces)
+	e.POST("/api/workspaces", workspaceController.CreateWorkspace)
+	e.GET("/api/workspaces/:id/members", workspaceController.ListMembers)
+	e.PUT("/api/workspaces/:id/members/:user_id", workspaceController.SetMemberRole)
+	e.DELETE("/api/workspaces/:id/members/:user_id", workspaceController.RemoveMember)
+
+	// Search
+	e.GET("/api/search", searchController.Search)
+
+	// Per-platform tag names
+	e.GET("/api/tags/mappings", tagController.ListMappings)
+	e.PUT("/api/tags/mappings/:platform/:tag", tagController.SaveMapping)
+	e.DELETE("/api/tags/mappings/:platform/:tag", tagController.DeleteMapping)
+
+	// Drafts
+	e.GET("/api/drafts", draftController.ListDrafts)
+	e.GET("/api/drafts/trash", draftController.ListTrash)
+	e.PUT("/api/drafts/:id", draftController.UpdateDraft)
+	e.DELETE("/api/drafts/:id", draftController.DeleteDraft)
+	e.POST("/api/drafts/:id/restore", draftController.RestoreDraft)
+	e.POST("/api/drafts/:id/duplicate", draftController.DuplicateDraft)
+	e.GET("/api/drafts/:id", draftController.GetDraft)
+	e.POST("/api/drafts/:id/transitions", draftController.TransitionDraft)
+	e.PUT("/api/drafts/:id/reviewer", draftController.AssignReviewer)
+	e.GET("/api/drafts/:id/comments", draftController.ListComments)
+	e.POST("/api/drafts/:id/comments", draftController.AddComment)
+	e.PUT("/api/drafts/:id/comments/:comment_id/resolve", draftController.ResolveComment)
+	e.GET("/api/drafts/:id/audit", draftController.GetAuditLog)
+	e.GET("/api/drafts/:id/collab", collabController.Connect)
+	e.GET("/api/drafts/:id/revisions", draftController.ListRevisions)
+	e.GET("/api/drafts/:id/revisions/diff", draftController.DiffRevisions)
+	e.GET("/api/drafts/:id/revisions/:rev_id", draftController.GetRevision)
+	e.POST("/api/drafts/:id/revisions/:rev_id/restore", draftController.RestoreRevision)
+
+	// Dashboard & Activity
+	e.GET("/api/dashboard/activity", dashboardController.GetDashboardActivity)
+	e.POST("/api/dashboard/sync", dashboardController.TriggerSync)
+
+	// Uploads
+	uploadController := controller.NewUploadController()
+	e.POST("/api/upload", uploadController.HandleUpload)
+
+	// Live Activity (Platform specific)
+	e.GET("/api/devto/activity", activityController.GetDevtoActivity)
+	e.GET("/api/medium/activity", activityController.GetMediumActivity)
+
+	// Publishing
+	e.POST("/api/publish/:platform", publishController.PublishPost)
+
+	// Operator endpoints, behind ADMIN_TOKEN
+	admin := e.Group("/api/admin", middleware.RequireAdmin)
+	admin.GET("/dlq/:queue", deadLetterController.ListDeadLetters)
+	admin.POST("/dlq/:queue/replay", deadLetterController.ReplayDeadLetters)
+	admin.POST("/dlq/:queue/:id/replay", deadLetterController.ReplayDeadLetter)
+	admin.DELETE("/dlq/:queue", deadLetterController.PurgeDeadLetters)
+	admin.GET("/breakers", breakerController.ListBreakers)
+	admin.POST("/breakers/:name/reset", breakerController.ResetBreaker)
+	admin.GET("/publish/:job_id/attempts/:attempt/artifacts", artifactsController.GetPublishArtifacts)
+
+	return &Server{Echo: e, collab: collabHub, outbox: outboxRelay}
+}
+
+// RunCollab relays collaboration rooms between instances until ctx is cancelled
+func (s *Server) RunCollab(ctx context.Context) {
+	s.collab.Run(ctx)
+}
+
+// RunOutbox relays queued jobs from the outbox to the job queue until ctx is cancelled
+func (s *Server) RunOutbox(ctx context.Context) {
+	s.outbox.Run(ctx)
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"time"
 
 	automation "postificus/internal/browser"
 	"postificus/internal/domain"
 	"postificus/internal/storage"
+	"postificus/internal/throttle"
 )
 
 const (
@@ -19,50 +21,22 @@ const (
 
 type ActivityService struct {
 	credsRepo storage.CredentialsRepository
+	posts     storage.PostRepository
+	limits    *throttle.Throttle // Optional; per-account platform rate limits
 }
 
-func NewActivityService(credsRepo storage.CredentialsRepository) *ActivityService {
+func NewActivityService(credsRepo storage.CredentialsRepository, posts storage.PostRepository, limits *throttle.Throttle) *ActivityService {
 	return &ActivityService{
 		credsRepo: credsRepo,
+		posts:     posts,
+		limits:    limits,
 	}
 }
 
-// UpsertPost inserts or updates a post in the unified_posts table.
+// UpsertPost inserts or updates a post in the dashboard cache.
 func (s *ActivityService) UpsertPost(ctx context.Context, userID string, post domain.UnifiedPost) error {
-	if storage.DB == nil {
-		return fmt.Errorf("database not initialized")
-	}
-
-	query := `
-		INSERT INTO unified_posts (user_id, platform, remote_id, title, url, status, views, reactions, comments, published_at, last_synced_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
-		ON CONFLICT (user_id, platform, remote_id) 
-		DO UPDATE SET 
-			title = EXCLUDED.title,
-			url = EXCLUDED.url,
-			status = EXCLUDED.status,
-			views = EXCLUDED.views,
-			reactions = EXCLUDED.reactions,
-			comments = EXCLUDED.comments,
-			published_at = EXCLUDED.published_at,
-			last_synced_at = NOW();
-	`
-
-	_, err := storage.DB.Exec(ctx, query,
-		userID,
-		post.Platform,
-		post.RemoteID,
-		post.Title,
-		post.URL,
-		post.Status,
-		post.Views,
-		post.Reactions,
-		post.Comments,
-		post.PublishedAt,
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to upsert post: %w", err)
+	if err := s.posts.UpsertPost(ctx, userID, post); err != nil {
+		return err
 	}
 
 	// Invalidate Cache for this user
@@ -74,12 +48,8 @@ func (s *ActivityService) UpsertPost(ctx context.Context, userID string, post do
 	return nil
 }
 
-// GetDashboardActivity returns the unified list of posts from local cache (Redis) or DB
+// GetDashboardActivity returns the unified list of posts from local cache (Redis) or storage
 func (s *ActivityService) GetDashboardActivity(ctx context.Context, userID string, limit int) ([]domain.UnifiedPost, error) {
-	if storage.DB == nil {
-		return nil, fmt.Errorf("database not initialized")
-	}
-
 	cacheKey := fmt.Sprintf("dashboard_activity:%s", userID)
 
 	// 1. Try Cache
@@ -94,53 +64,10 @@ func (s *ActivityService) GetDashboardActivity(ctx context.Context, userID strin
 		}
 	}
 
-	// 2. Cache Miss - Query DB
-	query := `
-		SELECT
-			up.platform,
-			up.remote_id,
-			up.title,
-			up.url,
-			up.status,
-			up.views,
-			up.reactions,
-			up.comments,
-			up.published_at,
-			d.publish_targets
-		FROM unified_posts up
-		LEFT JOIN drafts d
-			ON up.platform = 'postificus'
-			AND d.id::text = up.remote_id
-			AND d.user_id = up.user_id
-		WHERE up.user_id = $1
-		ORDER BY up.published_at DESC NULLS LAST, up.last_synced_at DESC
-		LIMIT $2
-	`
-
-	rows, err := storage.DB.Query(ctx, query, userID, limit)
+	// 2. Cache Miss - Query storage
+	posts, err := s.posts.ListPosts(ctx, userID, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query posts: %w", err)
-	}
-	defer rows.Close()
-
-	var posts []domain.UnifiedPost
-	for rows.Next() {
-		var p domain.UnifiedPost
-		var pubAt *time.Time // Handle nullable timestamp
-		var publishTargets []byte
-
-		err := rows.Scan(&p.Platform, &p.RemoteID, &p.Title, &p.URL, &p.Status, &p.Views, &p.Reactions, &p.Comments, &pubAt, &publishTargets)
-		if err != nil {
-			continue // Skip malformed rows
-		}
-
-		if pubAt != nil {
-			p.PublishedAt = *pubAt
-		}
-		if len(publishTargets) > 0 {
-			_ = json.Unmarshal(publishTargets, &p.PublishTargets)
-		}
-		posts = append(posts, p)
+		return nil, err
 	}
 
 	// 3. Set Cache (Async-ish, but blocking here for simplicity)
@@ -155,35 +82,45 @@ func (s *ActivityService) GetDashboardActivity(ctx context.Context, userID strin
 
 // Live Fetch Methods
 
-func (s *ActivityService) FetchLiveDevtoActivity(ctx context.Context, userID string, limit int) ([]automation.DevtoPost, error) {
+// FetchLiveDevtoActivity scrapes Dev.to using the workspace's connected account.
+// A scrape the account's rate limits don't allow yet fails with throttle.ErrLimited.
+func (s *ActivityService) FetchLiveDevtoActivity(ctx context.Context, workspaceID string, limit int) ([]automation.DevtoPost, error) {
 	if limit > 50 {
 		limit = 50
 	}
 
 	// Get Token
-	token, err := s.getDevtoToken(ctx, userID)
+	token, account, err := s.getDevtoToken(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.limits.Take(ctx, "devto", account, throttle.Request); err != nil {
+		return nil, err
+	}
 
-	posts, err := automation.FetchDevtoDashboardPosts(token, limit)
+	posts, err := automation.FetchDevtoDashboardPosts(ctx, token, limit)
 	if err != nil {
 		return nil, err
 	}
 	return posts, nil
 }
 
-func (s *ActivityService) FetchLiveMediumActivity(ctx context.Context, userID string, limit int) ([]automation.MediumPost, error) {
+// FetchLiveMediumActivity scrapes Medium using the workspace's connected account.
+// A scrape the account's rate limits don't allow yet fails with throttle.ErrLimited.
+func (s *ActivityService) FetchLiveMediumActivity(ctx context.Context, workspaceID string, limit int) ([]automation.MediumPost, error) {
 	if limit > 50 {
 		limit = 50
 	}
 
-	uid, sid, xsrf, err := s.getMediumCredentials(ctx, userID)
+	uid, sid, xsrf, account, err := s.getMediumCredentials(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.limits.Take(ctx, "medium", account, throttle.Request); err != nil {
+		return nil, err
+	}
 
-	posts, err := automation.FetchMediumPosts(uid, sid, xsrf, limit)
+	posts, err := automation.FetchMediumPosts(ctx, uid, sid, xsrf, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -192,37 +129,38 @@ func (s *ActivityService) FetchLiveMediumActivity(ctx context.Context, userID st
 
 // Helpers
 
-func (s *ActivityService) getDevtoToken(ctx context.Context, userID string) (string, error) {
-	creds, err := s.credsRepo.GetCredentials(ctx, userID, "devto")
+// getDevtoToken returns the workspace's Dev.to session token and the account it belongs to
+func (s *ActivityService) getDevtoToken(ctx context.Context, workspaceID string) (string, string, error) {
+	creds, err := s.credsRepo.GetCredentials(ctx, workspaceID, "devto")
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	if creds == nil {
 		// Fallback to env
 		if env := os.Getenv("DEVTO_SESSION_TOKEN"); env != "" {
-			return env, nil
+			return env, envAccount, nil
 		}
-		return "", errors.New("devto credentials missing")
+		return "", "", errors.New("devto credentials missing")
 	}
 
 	var details map[string]interface{}
 	if err := json.Unmarshal(creds.Credentials, &details); err == nil {
 		if token, ok := details["remember_user_token"].(string); ok && token != "" {
-			return token, nil
+			return token, rateAccount(creds), nil
 		}
 	}
-	return "", errors.New("invalid devto credentials format")
+	return "", "", errors.New("invalid devto credentials format")
 }
 
-func (s *ActivityService) getMediumCredentials(ctx context.Context, userID string) (string, string, string, error) {
-	creds, err := s.credsRepo.GetCredentials(ctx, userID, "medium")
+// getMediumCredentials returns the workspace's Medium cookies (uid, sid, xsrf) and
+// the account they belong to; cookies the workspace lacks come from the environment
+func (s *ActivityService) getMediumCredentials(ctx context.Context, workspaceID string) (uid, sid, xsrf, account string, err error) {
+	creds, err := s.credsRepo.GetCredentials(ctx, workspaceID, "medium")
 	if err != nil {
 		// Log error but try fallback
-		fmt.Printf("DB Error for Medium creds: %v\n", err)
+		log.Printf("⚠️ DB Error for Medium creds: %v", err)
 	}
 
-	var uid, sid, xsrf string
-
 	// Try DB
 	if creds != nil {
 		var details map[string]interface{}
@@ -245,8 +183,8 @@ func (s *ActivityService) getMediumCredentials(ctx context.Context, userID strin
 	}
 
 	if uid != "" && sid != "" && xsrf != "" {
-		return uid, sid, xsrf, nil
+		return uid, sid, xsrf, rateAccount(creds), nil
 	}
 
-	return "", "", "", errors.New("medium credentials missing (DB & Env)")
+	return "", "", "", "", errors.New("medium credentials missing (DB & Env)")
 }
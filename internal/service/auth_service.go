@@ -5,29 +5,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"postificus/internal/browser"
+	"postificus/internal/domain"
 	"postificus/internal/storage"
+	"time"
 )
 
 // AuthService handles authentication business logic
 type AuthService struct {
-	credsRepo storage.CredentialsRepository
+	credsRepo  storage.CredentialsRepository
+	workspaces *WorkspaceService
 }
 
 // NewAuthService creates a new instance
-func NewAuthService(credsRepo storage.CredentialsRepository) *AuthService {
+func NewAuthService(credsRepo storage.CredentialsRepository, workspaces *WorkspaceService) *AuthService {
 	return &AuthService{
-		credsRepo: credsRepo,
+		credsRepo:  credsRepo,
+		workspaces: workspaces,
 	}
 }
 
+// ConnectedAccount is the public view of a stored credential (secrets stripped)
+type ConnectedAccount struct {
+	ID          string `json:"id"`
+	Platform    string `json:"platform"`
+	AccountName string `json:"account_name"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
 // ConnectPlatform handles the flow of connecting a platform account
-func (s *AuthService) ConnectPlatform(ctx context.Context, userID string, platform string) (string, error) {
+func (s *AuthService) ConnectPlatform(ctx context.Context, workspaceID string, userID string, platform string) (string, error) {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermManageCredentials); err != nil {
+		return "", err
+	}
+
 	var creds map[string]string
 	var username string
 
 	// 1. Trigger Login via Browser Automation
 	if platform == "medium" {
-		uid, sid, xsrf, uname, loginErr := browser.WaitForMediumLogin()
+		uid, sid, xsrf, uname, loginErr := browser.WaitForMediumLogin(ctx)
 		if loginErr != nil {
 			return "", fmt.Errorf("login failed: %w", loginErr)
 		}
@@ -38,7 +54,7 @@ func (s *AuthService) ConnectPlatform(ctx context.Context, userID string, platfo
 		}
 		username = uname
 	} else if platform == "devto" {
-		token, uname, loginErr := browser.WaitForDevToLogin()
+		token, uname, loginErr := browser.WaitForDevToLogin(ctx)
 		if loginErr != nil {
 			return "", fmt.Errorf("login failed: %w", loginErr)
 		}
@@ -58,25 +74,67 @@ func (s *AuthService) ConnectPlatform(ctx context.Context, userID string, platfo
 	}
 
 	// 3. Save Credentials via Repository
-	if err := s.credsRepo.SaveCredentials(ctx, userID, platform, creds); err != nil {
+	if err := s.credsRepo.SaveCredentials(ctx, workspaceID, platform, creds); err != nil {
 		return "", fmt.Errorf("failed to save credentials: %w", err)
 	}
 
 	return username, nil
 }
 
+// DeleteCredentials disconnects every account of a platform
+func (s *AuthService) DeleteCredentials(ctx context.Context, workspaceID string, userID string, platform string) error {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermManageCredentials); err != nil {
+		return err
+	}
+	return s.credsRepo.DeleteCredentials(ctx, workspaceID, platform)
+}
+
+// DeleteAccount disconnects a single account by its credential ID
+func (s *AuthService) DeleteAccount(ctx context.Context, workspaceID string, userID string, id string) error {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermManageCredentials); err != nil {
+		return err
+	}
+	return s.credsRepo.DeleteCredentialsByID(ctx, workspaceID, id)
+}
+
 // ManualSaveCredentials allows saving credentials directly (non-interactive)
-func (s *AuthService) DeleteCredentials(ctx context.Context, userID string, platform string) error {
-	return s.credsRepo.DeleteCredentials(ctx, userID, platform)
+func (s *AuthService) ManualSaveCredentials(ctx context.Context, workspaceID string, userID string, platform string, creds map[string]string) error {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermManageCredentials); err != nil {
+		return err
+	}
+	return s.credsRepo.SaveCredentials(ctx, workspaceID, platform, creds)
 }
 
-func (s *AuthService) ManualSaveCredentials(ctx context.Context, userID string, platform string, creds map[string]string) error {
-	return s.credsRepo.SaveCredentials(ctx, userID, platform, creds)
+// ListAccounts returns every connected account of the workspace without secrets
+func (s *AuthService) ListAccounts(ctx context.Context, workspaceID string, userID string) ([]ConnectedAccount, error) {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return nil, err
+	}
+
+	creds, err := s.credsRepo.GetAllCredentials(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]ConnectedAccount, 0, len(creds))
+	for _, c := range creds {
+		accounts = append(accounts, ConnectedAccount{
+			ID:          c.ID,
+			Platform:    c.Platform,
+			AccountName: c.AccountName,
+			UpdatedAt:   c.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return accounts, nil
 }
 
 // GetConnectionStatus checks if a platform is connected and returns the account name
-func (s *AuthService) GetConnectionStatus(ctx context.Context, userID string, platform string) (bool, string, error) {
-	cred, err := s.credsRepo.GetCredentials(ctx, userID, platform)
+func (s *AuthService) GetConnectionStatus(ctx context.Context, workspaceID string, userID string, platform string) (bool, string, error) {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return false, "", err
+	}
+
+	cred, err := s.credsRepo.GetCredentials(ctx, workspaceID, platform)
 	if err != nil {
 		return false, "", err
 	}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+	"postificus/internal/textdiff"
+)
+
+// DraftBase is the content a client started editing from, used to merge concurrent edits
+type DraftBase struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// ConflictError reports a save against a stale version that could not be merged.
+// It wraps storage.ErrVersionConflict.
+type ConflictError struct {
+	Server    *domain.Draft
+	Conflicts []textdiff.Conflict
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("draft was modified (server version %d)", e.Server.Version)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return storage.ErrVersionConflict
+}
+
+// SaveDraftIfMatch saves the draft only if the stored version still equals ifMatch.
+// When the client lost the race but sent its base content, non-overlapping edits are
+// merged three-way against the server copy and saved; merged reports whether that happened.
+func (s *DraftService) SaveDraftIfMatch(ctx context.Context, draft *domain.Draft, ifMatch int64, base *DraftBase) (merged bool, err error) {
+	actorID := draft.UserID
+	err = s.saveDraft(ctx, draft, actorID, domain.RevisionAutosave, ifMatch)
+	if !errors.Is(err, storage.ErrVersionConflict) {
+		return false, err
+	}
+
+	server, err := s.draftRepo.GetDraft(ctx, draft.ID, draft.WorkspaceID)
+	if err != nil {
+		return false, err
+	}
+	if base == nil {
+		return false, &ConflictError{Server: server}
+	}
+
+	title, titleOK := textdiff.MergeLine(base.Title, draft.Title, server.Title)
+	content, conflicts := textdiff.Merge3(base.Content, draft.Content, server.Content)
+	if !titleOK || len(conflicts) > 0 {
+		return false, &ConflictError{Server: server, Conflicts: conflicts}
+	}
+
+	draft.Title = title
+	draft.Content = content
+	if err := s.saveDraft(ctx, draft, actorID, domain.RevisionAutosave, server.Version); err != nil {
+		if errors.Is(err, storage.ErrVersionConflict) {
+			// Lost a second race; let the client retry against the newest copy
+			if latest, getErr := s.draftRepo.GetDraft(ctx, draft.ID, draft.WorkspaceID); getErr == nil {
+				server = latest
+			}
+			return false, &ConflictError{Server: server}
+		}
+		return false, err
+	}
+	return true, nil
+}
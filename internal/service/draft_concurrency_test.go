@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newConcurrencyFixture(serverContent string) (*DraftService, *MockDraftRepository) {
+	draftRepo := new(MockDraftRepository)
+	draftRepo.On("GetDraft", mock.Anything, "d1", "u1").Return(&domain.Draft{
+		ID:          "d1",
+		WorkspaceID: "u1",
+		UserID:      "u1",
+		Title:       "Title",
+		Content:     serverContent,
+		Status:      domain.StatusDraft,
+		Version:     4,
+	}, nil)
+	draftRepo.On("SaveDraft", mock.Anything, mock.Anything, int64(4)).Return(nil)
+	draftRepo.On("UpdateDashboardCache", mock.Anything, mock.Anything).Return(nil)
+
+	revisionRepo := new(MockRevisionRepository)
+	revisionRepo.On("LatestRevision", mock.Anything, mock.Anything).Return(nil, nil)
+	revisionRepo.On("AddRevision", mock.Anything, mock.Anything).Return(nil)
+
+	// Personal workspace (ID == user ID) needs no membership lookup
+	return NewDraftService(draftRepo, new(MockReviewRepository), revisionRepo, NewWorkspaceService(nil)), draftRepo
+}
+
+func TestDraftService_SaveDraftIfMatch_MergesStaleSave(t *testing.T) {
+	svc, draftRepo := newConcurrencyFixture("one\ntwo\nTHREE\n")
+
+	draft := &domain.Draft{ID: "d1", WorkspaceID: "u1", UserID: "u1", Title: "Title", Content: "ONE\ntwo\nthree\n"}
+	base := &DraftBase{Title: "Title", Content: "one\ntwo\nthree\n"}
+
+	merged, err := svc.SaveDraftIfMatch(context.Background(), draft, 3, base)
+	assert.NoError(t, err)
+	assert.True(t, merged)
+	assert.Equal(t, "ONE\ntwo\nTHREE\n", draft.Content)
+	draftRepo.AssertCalled(t, "SaveDraft", mock.Anything, mock.Anything, int64(4))
+}
+
+func TestDraftService_SaveDraftIfMatch_ConflictReturnsServerCopy(t *testing.T) {
+	svc, draftRepo := newConcurrencyFixture("one\nTWO\nthree\n")
+
+	draft := &domain.Draft{ID: "d1", WorkspaceID: "u1", UserID: "u1", Title: "Title", Content: "one\n2\nthree\n"}
+	base := &DraftBase{Title: "Title", Content: "one\ntwo\nthree\n"}
+
+	_, err := svc.SaveDraftIfMatch(context.Background(), draft, 3, base)
+	assert.ErrorIs(t, err, storage.ErrVersionConflict)
+
+	var conflict *ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.Equal(t, int64(4), conflict.Server.Version)
+	assert.Len(t, conflict.Conflicts, 1)
+	draftRepo.AssertNotCalled(t, "SaveDraft", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDraftPageSize = 20
+	maxDraftPageSize     = 100
+)
+
+// ListDrafts returns a page of the workspace's drafts (or its trash) and the total match count
+func (s *DraftService) ListDrafts(ctx context.Context, userID string, filter storage.DraftFilter) ([]domain.Draft, int, error) {
+	if err := s.workspaces.Authorize(ctx, filter.WorkspaceID, userID, domain.PermViewDrafts); err != nil {
+		return nil, 0, err
+	}
+
+	for _, status := range filter.Statuses {
+		if !status.Valid() {
+			return nil, 0, fmt.Errorf("%w: unknown status %q", ErrInvalidInput, status)
+		}
+	}
+	if filter.Sort != "" {
+		if _, ok := storage.DraftSortColumns[filter.Sort]; !ok {
+			return nil, 0, fmt.Errorf("%w: cannot sort by %q", ErrInvalidInput, filter.Sort)
+		}
+	}
+	if filter.UpdatedAfter != nil && filter.UpdatedBefore != nil && !filter.UpdatedAfter.Before(*filter.UpdatedBefore) {
+		return nil, 0, fmt.Errorf("%w: updated_after must be before updated_before", ErrInvalidInput)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultDraftPageSize
+	}
+	filter.Limit = min(filter.Limit, maxDraftPageSize)
+	filter.Offset = max(filter.Offset, 0)
+	if tags := domain.NormalizeTags([]string{filter.Tag}); len(tags) == 1 {
+		filter.Tag = tags[0]
+	}
+
+	return s.draftRepo.ListDrafts(ctx, filter)
+}
+
+// TrashDraft moves a draft to the trash; it can be restored with UntrashDraft
+func (s *DraftService) TrashDraft(ctx context.Context, workspaceID string, userID string, draftID string) error {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermEditDrafts); err != nil {
+		return err
+	}
+	if err := s.draftRepo.TrashDraft(ctx, draftID, workspaceID); err != nil {
+		return err
+	}
+	return s.audit(ctx, draftID, userID, "trash", "", "", "")
+}
+
+func (s *DraftService) UntrashDraft(ctx context.Context, workspaceID string, userID string, draftID string) (*domain.Draft, error) {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermEditDrafts); err != nil {
+		return nil, err
+	}
+	if err := s.draftRepo.UntrashDraft(ctx, draftID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	draft, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, draftID, userID, "untrash", "", "", ""); err != nil {
+		return nil, err
+	}
+	if err := s.draftRepo.UpdateDashboardCache(ctx, draft); err != nil {
+		return nil, fmt.Errorf("failed to update dashboard cache: %w", err)
+	}
+	return draft, nil
+}
+
+// DuplicateDraft copies a draft's content into a new draft owned by the user.
+// The copy starts over in the workflow: no status, reviewer or schedule is carried.
+func (s *DraftService) DuplicateDraft(ctx context.Context, workspaceID string, userID string, draftID string) (*domain.Draft, error) {
+	source, err := s.GetDraft(ctx, workspaceID, userID, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	title := source.Title
+	if title != "" {
+		title += " (copy)"
+	}
+	copied := &domain.Draft{
+		ID:             uuid.NewString(),
+		WorkspaceID:    workspaceID,
+		UserID:         userID,
+		Title:          title,
+		Content:        source.Content,
+		CoverImage:     source.CoverImage,
+		PublishTargets: source.PublishTargets,
+		Tags:           source.Tags,
+		Subtitle:       source.Subtitle,
+		Excerpt:        source.Excerpt,
+		Series:         source.Series,
+		SeriesOrder:    source.SeriesOrder,
+	}
+	if err := s.SaveDraft(ctx, copied); err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, copied.ID, userID, "duplicate", "", "", "copied from "+source.ID); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDraftService_ListDrafts_NormalizesFilter(t *testing.T) {
+	draftRepo := new(MockDraftRepository)
+	draftRepo.On("ListDrafts", mock.Anything, mock.Anything).Return([]domain.Draft{}, 0, nil)
+	workspaceRepo := new(MockWorkspaceRepository)
+	workspaceRepo.On("GetMembership", mock.Anything, "team", "u1").Return(nil, nil)
+	svc := NewDraftService(draftRepo, new(MockReviewRepository), new(MockRevisionRepository), NewWorkspaceService(workspaceRepo))
+	ctx := context.Background()
+
+	_, _, err := svc.ListDrafts(ctx, "u1", storage.DraftFilter{WorkspaceID: "u1", Limit: 500, Offset: -3, Tag: " #GoLang "})
+	assert.NoError(t, err)
+	draftRepo.AssertCalled(t, "ListDrafts", mock.Anything, storage.DraftFilter{WorkspaceID: "u1", Limit: 100, Offset: 0, Tag: "golang"})
+
+	_, _, err = svc.ListDrafts(ctx, "u1", storage.DraftFilter{WorkspaceID: "u1", Statuses: []domain.DraftStatus{"lost"}})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, _, err = svc.ListDrafts(ctx, "u1", storage.DraftFilter{WorkspaceID: "u1", Sort: "content"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, _, err = svc.ListDrafts(ctx, "u1", storage.DraftFilter{WorkspaceID: "team"})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+	"postificus/internal/textdiff"
+)
+
+const (
+	defaultSnapshotInterval  = 5 * time.Minute
+	defaultRevisionRetention = 30 * 24 * time.Hour
+)
+
+// RevisionPolicy controls how often autosaves are snapshotted and how long they are kept
+type RevisionPolicy struct {
+	SnapshotInterval time.Duration
+	Retention        time.Duration
+}
+
+// RevisionPolicyFromEnv reads REVISION_SNAPSHOT_INTERVAL (e.g. "5m") and REVISION_RETENTION_DAYS
+func RevisionPolicyFromEnv() RevisionPolicy {
+	policy := RevisionPolicy{SnapshotInterval: defaultSnapshotInterval, Retention: defaultRevisionRetention}
+	if value := os.Getenv("REVISION_SNAPSHOT_INTERVAL"); value != "" {
+		if interval, err := time.ParseDuration(value); err == nil && interval >= 0 {
+			policy.SnapshotInterval = interval
+		}
+	}
+	if value := os.Getenv("REVISION_RETENTION_DAYS"); value != "" {
+		if days, err := strconv.Atoi(value); err == nil && days > 0 {
+			policy.Retention = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	return policy
+}
+
+// RevisionDiff is a unified diff between two revisions of a draft
+type RevisionDiff struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Title string `json:"title,omitempty"` // Set when the title changed
+	Diff  string `json:"diff"`
+}
+
+// trivialEditBytes is the most an autosave can change and still be throttled
+const trivialEditBytes = 200
+
+// snapshot records the draft's content as a revision.
+// Identical content is never recorded twice in a row. An autosave is throttled
+// to one per snapshot interval only while it's a small edit by the author of
+// the latest revision; another author's save or a bigger edit is always kept.
+// previous is the content the save replaced (nil if there was none): when the
+// throttle left it out of the history, it's recorded first, under the author
+// of the autosaves it skipped.
+func (s *DraftService) snapshot(ctx context.Context, previous, draft *domain.Draft, authorID string, kind domain.RevisionKind) error {
+	hash := domain.RevisionHash(draft.Title, draft.Content, draft.CoverImage)
+
+	latest, err := s.revisionRepo.LatestRevision(ctx, draft.ID)
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		if latest.Hash == hash {
+			return nil
+		}
+		if kind == domain.RevisionAutosave && latest.Kind == domain.RevisionAutosave && latest.AuthorID == authorID &&
+			time.Since(latest.CreatedAt) < s.revisionPolicy.SnapshotInterval && trivialEdit(latest, draft) {
+			return nil
+		}
+
+		if previous != nil {
+			previousHash := domain.RevisionHash(previous.Title, previous.Content, previous.CoverImage)
+			if previousHash != latest.Hash && previousHash != hash {
+				if err := s.revisionRepo.AddRevision(ctx, &domain.Revision{
+					DraftID:    draft.ID,
+					Hash:       previousHash,
+					AuthorID:   latest.AuthorID,
+					Kind:       domain.RevisionAutosave,
+					Title:      previous.Title,
+					Content:    previous.Content,
+					CoverImage: previous.CoverImage,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return s.revisionRepo.AddRevision(ctx, &domain.Revision{
+		DraftID:    draft.ID,
+		Hash:       hash,
+		AuthorID:   authorID,
+		Kind:       kind,
+		Title:      draft.Title,
+		Content:    draft.Content,
+		CoverImage: draft.CoverImage,
+	})
+}
+
+// trivialEdit reports whether the draft differs from the revision by a small
+// edit: the same cover, and no more than trivialEditBytes changed between the
+// title and the content
+func trivialEdit(rev *domain.Revision, draft *domain.Draft) bool {
+	if rev.CoverImage != draft.CoverImage {
+		return false
+	}
+	return editedBytes(rev.Title, draft.Title)+editedBytes(rev.Content, draft.Content) <= trivialEditBytes
+}
+
+// editedBytes is the length of the span that differs between a and b, past
+// their common prefix and suffix
+func editedBytes(a, b string) int {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return max(len(a), len(b)) - prefix - suffix
+}
+
+// ListRevisions returns revision metadata, newest first
+func (s *DraftService) ListRevisions(ctx context.Context, workspaceID string, userID string, draftID string) ([]domain.Revision, error) {
+	if _, err := s.GetDraft(ctx, workspaceID, userID, draftID); err != nil {
+		return nil, err
+	}
+	return s.revisionRepo.ListRevisions(ctx, draftID)
+}
+
+func (s *DraftService) GetRevision(ctx context.Context, workspaceID string, userID string, draftID string, revisionID int64) (*domain.Revision, error) {
+	if _, err := s.GetDraft(ctx, workspaceID, userID, draftID); err != nil {
+		return nil, err
+	}
+	return s.revisionRepo.GetRevision(ctx, draftID, revisionID)
+}
+
+// DiffRevisions compares two revisions. A zero toID compares against the current draft.
+func (s *DraftService) DiffRevisions(ctx context.Context, workspaceID string, userID string, draftID string, fromID, toID int64) (*RevisionDiff, error) {
+	draft, err := s.GetDraft(ctx, workspaceID, userID, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.revisionRepo.GetRevision(ctx, draftID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	toName, toTitle, toContent := "current", draft.Title, draft.Content
+	if toID != 0 {
+		to, err := s.revisionRepo.GetRevision(ctx, draftID, toID)
+		if err != nil {
+			return nil, err
+		}
+		toName, toTitle, toContent = fmt.Sprintf("revision %d", to.ID), to.Title, to.Content
+	}
+
+	fromName := fmt.Sprintf("revision %d", from.ID)
+	diff := &RevisionDiff{
+		From: fromName,
+		To:   toName,
+		Diff: textdiff.Unified(fromName, toName, from.Content, toContent, textdiff.DefaultContext),
+	}
+	if from.Title != toTitle {
+		diff.Title = toTitle
+	}
+	return diff, nil
+}
+
+// RestoreRevision makes an older revision the current content.
+// The content being replaced is snapshotted first so the restore can be undone.
+func (s *DraftService) RestoreRevision(ctx context.Context, workspaceID string, userID string, draftID string, revisionID int64) (*domain.Draft, error) {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermEditDrafts); err != nil {
+		return nil, err
+	}
+
+	draft, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.revisionRepo.GetRevision(ctx, draftID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.snapshot(ctx, nil, draft, userID, domain.RevisionRestore); err != nil {
+		return nil, fmt.Errorf("failed to snapshot current content: %w", err)
+	}
+
+	draft.Title = rev.Title
+	draft.Content = rev.Content
+	draft.CoverImage = rev.CoverImage
+	if err := s.saveDraft(ctx, draft, userID, domain.RevisionRestore, storage.AnyVersion); err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, draft.ID, userID, "restore_revision", "", "", fmt.Sprintf("revision %d", rev.ID)); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// PruneRevisions drops autosave snapshots past the retention window
+func (s *DraftService) PruneRevisions(ctx context.Context) (int64, error) {
+	return s.revisionRepo.PruneAutosaves(ctx, time.Now().Add(-s.revisionPolicy.Retention))
+}
+
+// RunRevisionPruner prunes old revisions periodically until ctx is cancelled
+func (s *DraftService) RunRevisionPruner(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		pruned, err := s.PruneRevisions(ctx)
+		if err != nil {
+			log.Printf("⚠️ Revision pruning failed: %v", err)
+		} else if pruned > 0 {
+			log.Printf("🧹 Pruned %d old draft revisions", pruned)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
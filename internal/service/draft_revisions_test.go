@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRevisionRepository
+type MockRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevisionRepository) AddRevision(ctx context.Context, rev *domain.Revision) error {
+	return m.Called(ctx, rev).Error(0)
+}
+
+func (m *MockRevisionRepository) LatestRevision(ctx context.Context, draftID string) (*domain.Revision, error) {
+	args := m.Called(ctx, draftID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Revision), args.Error(1)
+}
+
+func (m *MockRevisionRepository) ListRevisions(ctx context.Context, draftID string) ([]domain.Revision, error) {
+	args := m.Called(ctx, draftID)
+	return args.Get(0).([]domain.Revision), args.Error(1)
+}
+
+func (m *MockRevisionRepository) GetRevision(ctx context.Context, draftID string, id int64) (*domain.Revision, error) {
+	args := m.Called(ctx, draftID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Revision), args.Error(1)
+}
+
+func (m *MockRevisionRepository) PruneAutosaves(ctx context.Context, olderThan time.Time) (int64, error) {
+	args := m.Called(ctx, olderThan)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestDraftService_Snapshot_DedupesAndThrottles(t *testing.T) {
+	draft := &domain.Draft{ID: "d1", Title: "T", Content: "Hello"}
+	ctx := context.Background()
+
+	cases := []struct {
+		name   string
+		latest *domain.Revision
+		kind   domain.RevisionKind
+		stored bool
+	}{
+		{"first revision", nil, domain.RevisionAutosave, true},
+		{"identical content", &domain.Revision{Hash: domain.RevisionHash("T", "Hello", ""), Kind: domain.RevisionRestore}, domain.RevisionRestore, false},
+		{"recent autosave", &domain.Revision{Hash: "old", AuthorID: "writer", Kind: domain.RevisionAutosave, Title: "T", Content: "Hell", CreatedAt: time.Now()}, domain.RevisionAutosave, false},
+		{"stale autosave", &domain.Revision{Hash: "old", AuthorID: "writer", Kind: domain.RevisionAutosave, Title: "T", Content: "Hell", CreatedAt: time.Now().Add(-time.Hour)}, domain.RevisionAutosave, true},
+		{"recent autosave by someone else", &domain.Revision{Hash: "old", AuthorID: "editor", Kind: domain.RevisionAutosave, Title: "T", Content: "Hell", CreatedAt: time.Now()}, domain.RevisionAutosave, true},
+		{"recent autosave before a big edit", &domain.Revision{Hash: "old", AuthorID: "writer", Kind: domain.RevisionAutosave, Title: "T", Content: strings.Repeat("x", 300), CreatedAt: time.Now()}, domain.RevisionAutosave, true},
+		{"restore bypasses interval", &domain.Revision{Hash: "old", AuthorID: "writer", Kind: domain.RevisionAutosave, CreatedAt: time.Now()}, domain.RevisionRestore, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockRevisionRepository)
+			if tc.latest == nil {
+				repo.On("LatestRevision", mock.Anything, "d1").Return(nil, nil)
+			} else {
+				repo.On("LatestRevision", mock.Anything, "d1").Return(tc.latest, nil)
+			}
+			repo.On("AddRevision", mock.Anything, mock.Anything).Return(nil)
+
+			svc := &DraftService{revisionRepo: repo, revisionPolicy: RevisionPolicy{SnapshotInterval: 5 * time.Minute}}
+			assert.NoError(t, svc.snapshot(ctx, nil, draft, "writer", tc.kind))
+
+			if tc.stored {
+				repo.AssertCalled(t, "AddRevision", mock.Anything, mock.MatchedBy(func(r *domain.Revision) bool {
+					return r.Hash == domain.RevisionHash("T", "Hello", "") && r.Kind == tc.kind && r.Content == "Hello"
+				}))
+			} else {
+				repo.AssertNotCalled(t, "AddRevision", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDraftService_Snapshot_KeepsThrottledContentBeforeAnotherAuthor(t *testing.T) {
+	ctx := context.Background()
+	latest := &domain.Revision{Hash: domain.RevisionHash("T", "Hello", ""), AuthorID: "writer", Kind: domain.RevisionAutosave, Title: "T", Content: "Hello", CreatedAt: time.Now()}
+	// The writer's last autosaves were throttled; the editor's save replaces them
+	previous := &domain.Draft{ID: "d1", Title: "T", Content: "Hello, world"}
+	draft := &domain.Draft{ID: "d1", Title: "T", Content: "Hello, editor"}
+
+	repo := new(MockRevisionRepository)
+	repo.On("LatestRevision", mock.Anything, "d1").Return(latest, nil)
+	repo.On("AddRevision", mock.Anything, mock.Anything).Return(nil)
+
+	svc := &DraftService{revisionRepo: repo, revisionPolicy: RevisionPolicy{SnapshotInterval: 5 * time.Minute}}
+	require.NoError(t, svc.snapshot(ctx, previous, draft, "editor", domain.RevisionAutosave))
+
+	require.Len(t, repo.Calls, 3)
+	kept := repo.Calls[1].Arguments.Get(1).(*domain.Revision)
+	assert.Equal(t, "Hello, world", kept.Content)
+	assert.Equal(t, "writer", kept.AuthorID, "the skipped autosaves were the writer's")
+	saved := repo.Calls[2].Arguments.Get(1).(*domain.Revision)
+	assert.Equal(t, "Hello, editor", saved.Content)
+	assert.Equal(t, "editor", saved.AuthorID)
+}
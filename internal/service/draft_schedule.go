@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"postificus/internal/domain"
+)
+
+// scheduleBatch caps the drafts one scheduler run queues; the rest wait for the next
+const scheduleBatch = 100
+
+// Scheduler queues the publishes of scheduled drafts once their time comes:
+// one job per publish target, as the user who scheduled the draft. The draft
+// goes back to approved and its jobs take it from there to published.
+type Scheduler struct {
+	drafts *DraftService
+	jobs   *JobService
+}
+
+func NewScheduler(drafts *DraftService, jobs *JobService) *Scheduler {
+	return &Scheduler{drafts: drafts, jobs: jobs}
+}
+
+// PublishDue queues the drafts whose time has come and returns how many it queued.
+// A draft that fails stays scheduled and is tried again on the next run.
+func (s *Scheduler) PublishDue(ctx context.Context) (int, error) {
+	due, err := s.drafts.draftRepo.ListDueScheduled(ctx, time.Now(), scheduleBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, draft := range due {
+		ok, err := s.publish(ctx, draft.WorkspaceID, draft.ID)
+		if err != nil {
+			log.Printf("⚠️ Failed to publish scheduled draft %s: %v", draft.ID, err)
+			continue
+		}
+		if ok {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+// publish queues one scheduled draft; ok is false when it had been unscheduled
+// meanwhile or its scheduler may no longer publish it
+func (s *Scheduler) publish(ctx context.Context, workspaceID string, draftID string) (ok bool, err error) {
+	draft, err := s.drafts.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return false, err
+	}
+	if draft.Status != domain.StatusScheduled || draft.ScheduledAt == nil || draft.ScheduledAt.After(time.Now()) {
+		return false, nil
+	}
+	actorID, err := s.drafts.scheduledBy(ctx, draft)
+	if err != nil {
+		return false, err
+	}
+	scheduledAt := *draft.ScheduledAt
+
+	// Off the schedule before any job is queued: a publish that finishes quickly
+	// marks the draft published, which mustn't be undone afterwards
+	draft.Status = domain.StatusApproved
+	draft.ScheduledAt = nil
+	if err := s.drafts.draftRepo.UpdateWorkflow(ctx, draft); err != nil {
+		return false, err
+	}
+
+	detail := "publish queued to " + strings.Join(draft.PublishTargets, ", ")
+	err = s.drafts.AuthorizePublish(ctx, workspaceID, actorID, draft.ID)
+	if err == nil {
+		err = s.queue(ctx, draft, actorID)
+		if err != nil {
+			// Back on the schedule for the next run; jobs already queued are found by their keys
+			draft.Status = domain.StatusScheduled
+			draft.ScheduledAt = &scheduledAt
+			if restoreErr := s.drafts.draftRepo.UpdateWorkflow(ctx, draft); restoreErr != nil {
+				log.Printf("⚠️ Failed to reschedule draft %s: %v", draft.ID, restoreErr)
+			}
+			return false, err
+		}
+		ok = true
+	} else {
+		detail = "schedule cancelled: " + err.Error()
+		log.Printf("⚠️ Scheduled draft %s not published: %v", draft.ID, err)
+	}
+
+	if err := s.drafts.audit(ctx, draft.ID, actorID, "transition", domain.StatusScheduled, domain.StatusApproved, detail); err != nil {
+		return ok, err
+	}
+	if err := s.drafts.draftRepo.UpdateDashboardCache(ctx, draft); err != nil {
+		return ok, fmt.Errorf("failed to update dashboard cache: %w", err)
+	}
+	return ok, nil
+}
+
+// queue adds a publish job for each of the draft's targets
+func (s *Scheduler) queue(ctx context.Context, draft *domain.Draft, actorID string) error {
+	for _, platform := range draft.PublishTargets {
+		_, _, err := s.jobs.QueuePublish(ctx, PublishPayload{
+			UserID:      actorID,
+			WorkspaceID: draft.WorkspaceID,
+			DraftID:     draft.ID,
+			Platform:    platform,
+			Title:       draft.Title,
+			Content:     draft.Content,
+			CoverImage:  draft.CoverImage,
+			Tags:        draft.Tags,
+			Subtitle:    draft.Subtitle,
+			Excerpt:     draft.Excerpt,
+			Series:      draft.Series,
+			SeriesOrder: draft.SeriesOrder,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to queue %s publish: %w", platform, err)
+		}
+	}
+	return nil
+}
+
+// Run publishes due drafts periodically until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		queued, err := s.PublishDue(ctx)
+		if err != nil {
+			log.Printf("⚠️ Scheduled publishing failed: %v", err)
+		} else if queued > 0 {
+			log.Printf("🗓️ Queued %d scheduled drafts for publishing", queued)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scheduledBy returns who scheduled the draft, from its latest move to
+// scheduled in the audit log; the author for a draft scheduled without one
+func (s *DraftService) scheduledBy(ctx context.Context, draft *domain.Draft) (string, error) {
+	entries, err := s.reviewRepo.ListAuditEntries(ctx, draft.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load audit log: %w", err)
+	}
+	actorID := draft.UserID
+	var latest int64
+	for _, e := range entries {
+		if e.Action == "transition" && e.ToStatus == domain.StatusScheduled && e.ID >= latest {
+			actorID, latest = e.ActorID, e.ID
+		}
+	}
+	return actorID, nil
+}
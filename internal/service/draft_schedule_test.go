@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newScheduleFixture has an approved draft in a team where writer and editor are members
+func newScheduleFixture(t *testing.T) (*storage.Store, *DraftService, *Scheduler, string) {
+	t.Helper()
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	team := &domain.Workspace{Name: "Team"}
+	require.NoError(t, store.Workspaces.CreateWorkspace(ctx, team, "owner"))
+	require.NoError(t, store.Workspaces.SaveMember(ctx, &domain.Membership{WorkspaceID: team.ID, UserID: "writer", Role: domain.RoleWriter}))
+	require.NoError(t, store.Workspaces.SaveMember(ctx, &domain.Membership{WorkspaceID: team.ID, UserID: "editor", Role: domain.RoleEditor}))
+
+	draft := &domain.Draft{ID: "d1", WorkspaceID: team.ID, UserID: "writer", Title: "T", Content: "Body", PublishTargets: []string{"devto", "medium"}, Tags: []string{"go"}}
+	require.NoError(t, store.Drafts.SaveDraft(ctx, draft, storage.AnyVersion))
+	draft.Status = domain.StatusApproved
+	require.NoError(t, store.Drafts.UpdateWorkflow(ctx, draft))
+
+	drafts := NewDraftService(store.Drafts, store.Reviews, store.Revisions, NewWorkspaceService(store.Workspaces))
+	scheduler := NewScheduler(drafts, NewJobService(store.Outbox, store.PublishLogs, nil))
+	return store, drafts, scheduler, team.ID
+}
+
+// makeDue moves the draft's scheduled time into the past
+func makeDue(t *testing.T, store *storage.Store, team string) {
+	t.Helper()
+	draft, err := store.Drafts.GetDraft(context.Background(), "d1", team)
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Second)
+	draft.ScheduledAt = &past
+	require.NoError(t, store.Drafts.UpdateWorkflow(context.Background(), draft))
+}
+
+func TestDraftService_ScheduleAndUnschedule(t *testing.T) {
+	_, drafts, _, team := newScheduleFixture(t)
+	ctx := context.Background()
+	later := time.Now().Add(time.Hour)
+	earlier := time.Now().Add(-time.Hour)
+
+	_, err := drafts.Transition(ctx, team, "writer", "d1", TransitionRequest{To: domain.StatusScheduled})
+	assert.ErrorIs(t, err, ErrInvalidInput, "scheduling needs a time")
+	_, err = drafts.Transition(ctx, team, "writer", "d1", TransitionRequest{To: domain.StatusScheduled, ScheduledAt: &earlier})
+	assert.ErrorIs(t, err, ErrInvalidInput, "the time must be ahead")
+
+	draft, err := drafts.Transition(ctx, team, "writer", "d1", TransitionRequest{To: domain.StatusScheduled, ScheduledAt: &later})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusScheduled, draft.Status)
+	require.NotNil(t, draft.ScheduledAt)
+	assert.WithinDuration(t, later, *draft.ScheduledAt, time.Second)
+
+	draft, err = drafts.Transition(ctx, team, "writer", "d1", TransitionRequest{To: domain.StatusApproved, Note: "not yet"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, draft.Status, "unscheduling keeps the approval")
+	assert.Nil(t, draft.ScheduledAt)
+}
+
+func TestScheduler_QueuesDueDraftsAsTheirScheduler(t *testing.T) {
+	store, drafts, scheduler, team := newScheduleFixture(t)
+	ctx := context.Background()
+	later := time.Now().Add(time.Hour)
+	_, err := drafts.Transition(ctx, team, "writer", "d1", TransitionRequest{To: domain.StatusScheduled, ScheduledAt: &later})
+	require.NoError(t, err)
+
+	queued, err := scheduler.PublishDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, queued, "nothing is due yet")
+
+	makeDue(t, store, team)
+	queued, err = scheduler.PublishDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, queued)
+
+	messages, err := store.Outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	platforms := []string{}
+	for _, message := range messages {
+		var payload PublishPayload
+		require.NoError(t, json.Unmarshal(message.Payload, &payload))
+		assert.Equal(t, "writer", payload.UserID, "the job runs as whoever scheduled it")
+		assert.Equal(t, "d1", payload.DraftID)
+		assert.Equal(t, "Body", payload.Content)
+		assert.Equal(t, []string{"go"}, payload.Tags)
+		platforms = append(platforms, payload.Platform)
+	}
+	assert.ElementsMatch(t, []string{"devto", "medium"}, platforms)
+
+	draft, err := drafts.GetDraft(ctx, team, "writer", "d1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, draft.Status, "its jobs take it on to published")
+	assert.Nil(t, draft.ScheduledAt)
+
+	queued, err = scheduler.PublishDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, queued, "a queued draft isn't queued again")
+}
+
+func TestScheduler_CancelsWhenTheSchedulerLostPublishAccess(t *testing.T) {
+	store, drafts, scheduler, team := newScheduleFixture(t)
+	ctx := context.Background()
+	later := time.Now().Add(time.Hour)
+	_, err := drafts.Transition(ctx, team, "writer", "d1", TransitionRequest{To: domain.StatusScheduled, ScheduledAt: &later})
+	require.NoError(t, err)
+	require.NoError(t, store.Workspaces.SaveMember(ctx, &domain.Membership{WorkspaceID: team, UserID: "writer", Role: domain.RoleViewer}))
+
+	makeDue(t, store, team)
+	queued, err := scheduler.PublishDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, queued)
+
+	messages, err := store.Outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	draft, err := drafts.GetDraft(ctx, team, "editor", "d1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, draft.Status)
+
+	entries, err := store.Reviews.ListAuditEntries(ctx, "d1")
+	require.NoError(t, err)
+	last := entries[len(entries)-1]
+	assert.Equal(t, domain.StatusApproved, last.ToStatus)
+	assert.Contains(t, last.Detail, "schedule cancelled")
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"strings"
 	"time"
 
 	"postificus/internal/domain"
@@ -10,25 +14,99 @@ import (
 )
 
 type DraftService struct {
-	draftRepo storage.DraftRepository
+	draftRepo      storage.DraftRepository
+	reviewRepo     storage.ReviewRepository
+	revisionRepo   storage.RevisionRepository
+	revisionPolicy RevisionPolicy
+	workspaces     *WorkspaceService
 }
 
-func NewDraftService(draftRepo storage.DraftRepository) *DraftService {
-	return &DraftService{draftRepo: draftRepo}
+func NewDraftService(draftRepo storage.DraftRepository, reviewRepo storage.ReviewRepository, revisionRepo storage.RevisionRepository, workspaces *WorkspaceService) *DraftService {
+	return &DraftService{
+		draftRepo:      draftRepo,
+		reviewRepo:     reviewRepo,
+		revisionRepo:   revisionRepo,
+		revisionPolicy: RevisionPolicyFromEnv(),
+		workspaces:     workspaces,
+	}
 }
 
+// SaveDraft writes the draft unconditionally (last write wins).
+// draft.UserID is the acting user; an existing draft keeps its author.
 func (s *DraftService) SaveDraft(ctx context.Context, draft *domain.Draft) error {
+	return s.saveDraft(ctx, draft, draft.UserID, domain.RevisionAutosave, storage.AnyVersion)
+}
+
+// saveDraft persists the draft on behalf of actorID, who needs edit access and is
+// recorded as the revision author. draft.UserID ends up as the draft's author.
+func (s *DraftService) saveDraft(ctx context.Context, draft *domain.Draft, actorID string, kind domain.RevisionKind, expectedVersion int64) error {
+	role, err := s.workspaces.RoleOf(ctx, draft.WorkspaceID, actorID)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !role.Can(domain.PermEditDrafts) {
+		return fmt.Errorf("%w: %s requires %s", ErrForbidden, draft.WorkspaceID, domain.PermEditDrafts)
+	}
+
+	if err := normalizeMetadata(draft); err != nil {
+		return err
+	}
+
+	// Carry the workflow state over; approved or published content edited by a non-reviewer goes back to draft
+	existing, err := s.draftRepo.GetDraft(ctx, draft.ID, draft.WorkspaceID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("failed to load draft: %w", err)
+	}
+	draft.Status = domain.StatusDraft
+	reopened := false
+	if existing != nil {
+		// Fail fast on a stale copy; the repository re-checks atomically
+		if expectedVersion != storage.AnyVersion && existing.Version != expectedVersion {
+			return storage.ErrVersionConflict
+		}
+
+		// The dashboard row belongs to the author, not whoever saved last
+		draft.UserID = existing.UserID
+		draft.Status = existing.Status
+		draft.ReviewerID = existing.ReviewerID
+		draft.ScheduledAt = existing.ScheduledAt
+		draft.IsPublished = existing.IsPublished
+
+		changed := publishableChanged(existing, draft)
+		// A published draft can be published again, so its edits need review too
+		approved := existing.Status == domain.StatusApproved || existing.Status == domain.StatusScheduled || existing.Status == domain.StatusPublished
+		if changed && approved && !role.Can(domain.PermReviewDrafts) {
+			draft.Status = domain.StatusDraft
+			draft.ScheduledAt = nil
+			reopened = true
+		}
+	}
+
 	// 1. Best-effort Redis cache (don't block DB save on failure)
 	if storage.RedisClient != nil {
 		storage.RedisClient.Set(ctx, fmt.Sprintf("draft:%s:content", draft.ID), draft.Content, 1*time.Hour)
 	}
 
 	// 2. Persist to DB
-	if err := s.draftRepo.SaveDraft(ctx, draft); err != nil {
+	if err := s.draftRepo.SaveDraft(ctx, draft, expectedVersion); err != nil {
 		return fmt.Errorf("failed to persist draft: %w", err)
 	}
 
-	// 3. Update Dashboard Cache
+	if reopened {
+		if err := s.draftRepo.UpdateWorkflow(ctx, draft); err != nil {
+			return err
+		}
+		if err := s.audit(ctx, draft.ID, actorID, "transition", existing.Status, draft.Status, "approval reset by edit"); err != nil {
+			return err
+		}
+	}
+
+	// 3. Revision history; a failed snapshot shouldn't lose the save itself
+	if err := s.snapshot(ctx, existing, draft, actorID, kind); err != nil {
+		log.Printf("⚠️ Failed to snapshot revision for draft %s: %v", draft.ID, err)
+	}
+
+	// 4. Update Dashboard Cache
 	if err := s.draftRepo.UpdateDashboardCache(ctx, draft); err != nil {
 		return fmt.Errorf("failed to update dashboard cache: %w", err)
 	}
@@ -36,6 +114,83 @@ func (s *DraftService) SaveDraft(ctx context.Context, draft *domain.Draft) error
 	return nil
 }
 
-func (s *DraftService) GetDraft(ctx context.Context, id string, userID string) (*domain.Draft, error) {
-	return s.draftRepo.GetDraft(ctx, id, userID)
+// publishableChanged reports whether anything a publish sends out differs between the drafts
+func publishableChanged(a, b *domain.Draft) bool {
+	return a.Title != b.Title || a.Content != b.Content || a.CoverImage != b.CoverImage ||
+		!slices.Equal(a.Tags, b.Tags) || a.Subtitle != b.Subtitle || a.Excerpt != b.Excerpt ||
+		a.Series != b.Series || a.SeriesOrder != b.SeriesOrder
+}
+
+func (s *DraftService) GetDraft(ctx context.Context, workspaceID string, userID string, id string) (*domain.Draft, error) {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return nil, err
+	}
+	return s.draftRepo.GetDraft(ctx, id, workspaceID)
+}
+
+func (s *DraftService) audit(ctx context.Context, draftID, actorID, action string, from, to domain.DraftStatus, detail string) error {
+	err := s.reviewRepo.AddAuditEntry(ctx, &domain.AuditEntry{
+		DraftID:    draftID,
+		ActorID:    actorID,
+		Action:     action,
+		FromStatus: from,
+		ToStatus:   to,
+		Detail:     detail,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+const (
+	maxDraftTags     = 10
+	maxSubtitleLen   = 200
+	maxExcerptLen    = 500
+	maxSeriesNameLen = 100
+)
+
+// normalizeMetadata cleans up tags, subtitle, excerpt and series before saving
+func normalizeMetadata(draft *domain.Draft) error {
+	draft.Tags = domain.NormalizeTags(draft.Tags)
+	if len(draft.Tags) > maxDraftTags {
+		return fmt.Errorf("%w: at most %d tags", ErrInvalidInput, maxDraftTags)
+	}
+
+	draft.Subtitle = strings.TrimSpace(draft.Subtitle)
+	draft.Excerpt = strings.TrimSpace(draft.Excerpt)
+	draft.Series = strings.TrimSpace(draft.Series)
+	switch {
+	case len([]rune(draft.Subtitle)) > maxSubtitleLen:
+		return fmt.Errorf("%w: subtitle is longer than %d characters", ErrInvalidInput, maxSubtitleLen)
+	case len([]rune(draft.Excerpt)) > maxExcerptLen:
+		return fmt.Errorf("%w: excerpt is longer than %d characters", ErrInvalidInput, maxExcerptLen)
+	case len([]rune(draft.Series)) > maxSeriesNameLen:
+		return fmt.Errorf("%w: series name is longer than %d characters", ErrInvalidInput, maxSeriesNameLen)
+	case draft.SeriesOrder < 0:
+		return fmt.Errorf("%w: series_order must be positive", ErrInvalidInput)
+	}
+
+	if draft.Series == "" {
+		draft.SeriesOrder = 0
+	} else if draft.SeriesOrder == 0 {
+		draft.SeriesOrder = 1
+	}
+	return nil
+}
+
+// SaveContent replaces only the title and content, keeping the draft's metadata
+// (used by collaborative sessions, which edit the document body). The save only
+// applies if the draft is still at version; otherwise storage.ErrVersionConflict.
+func (s *DraftService) SaveContent(ctx context.Context, workspaceID, userID, draftID string, version int64, title, content string) (*domain.Draft, error) {
+	draft, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	draft.Title = title
+	draft.Content = content
+	if err := s.saveDraft(ctx, draft, userID, domain.RevisionAutosave, version); err != nil {
+		return nil, err
+	}
+	return draft, nil
 }
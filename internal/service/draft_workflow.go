@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"postificus/internal/domain"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrReviewRequired    = errors.New("draft must be approved before publishing")
+	ErrRevisionMismatch  = errors.New("publish request differs from the saved draft")
+)
+
+// TransitionRequest moves a draft to another editorial state
+type TransitionRequest struct {
+	To          domain.DraftStatus `json:"to"`
+	Note        string             `json:"note"`
+	ScheduledAt *time.Time         `json:"scheduled_at,omitempty"` // When a scheduled draft is published
+}
+
+// Transition enforces the editorial workflow:
+//
+//	draft -> in_review -> approved -> scheduled
+//
+// A draft only becomes published through a publish job (see MarkPublished);
+// a scheduled one gets its jobs from the scheduler (see Scheduler) and moving it
+// back to approved unschedules it.
+// Writers need an approval before publishing; owners and editors review and may skip it.
+func (s *DraftService) Transition(ctx context.Context, workspaceID string, userID string, draftID string, req TransitionRequest) (*domain.Draft, error) {
+	role, err := s.workspaces.RoleOf(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !role.Can(domain.PermViewDrafts) {
+		return nil, fmt.Errorf("%w: %s requires %s", ErrForbidden, workspaceID, domain.PermViewDrafts)
+	}
+
+	draft, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := draft.Status
+	if !req.To.Valid() || !from.CanTransitionTo(req.To) || req.To == from {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, req.To)
+	}
+	if err := s.checkTransition(role, userID, draft, req); err != nil {
+		return nil, err
+	}
+
+	draft.Status = req.To
+	draft.ScheduledAt = nil
+	detail := strings.TrimSpace(req.Note)
+	if req.To == domain.StatusScheduled {
+		at := req.ScheduledAt.UTC()
+		draft.ScheduledAt = &at
+		detail = strings.TrimSuffix("publish at "+at.Format(time.RFC3339)+": "+detail, ": ")
+	}
+
+	if err := s.draftRepo.UpdateWorkflow(ctx, draft); err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, draft.ID, userID, "transition", from, draft.Status, detail); err != nil {
+		return nil, err
+	}
+	if err := s.draftRepo.UpdateDashboardCache(ctx, draft); err != nil {
+		return nil, fmt.Errorf("failed to update dashboard cache: %w", err)
+	}
+
+	return draft, nil
+}
+
+// checkTransition applies the per-target permission rules
+func (s *DraftService) checkTransition(role domain.Role, userID string, draft *domain.Draft, req TransitionRequest) error {
+	forbidden := func(reason string) error {
+		return fmt.Errorf("%w: %s", ErrForbidden, reason)
+	}
+
+	switch req.To {
+	case domain.StatusInReview:
+		if !role.Can(domain.PermEditDrafts) {
+			return forbidden("submitting for review requires edit access")
+		}
+	case domain.StatusDraft:
+		// Authors may withdraw or reopen their own draft; reviewers may request changes
+		if !role.Can(domain.PermReviewDrafts) && !(draft.UserID == userID && role.Can(domain.PermEditDrafts)) {
+			return forbidden("only the author or a reviewer can move a draft back")
+		}
+	case domain.StatusScheduled:
+		// The scheduled publish goes out as the user who scheduled it
+		if !role.Can(domain.PermPublish) {
+			return forbidden("scheduling requires publish access")
+		}
+		if req.ScheduledAt == nil || !req.ScheduledAt.After(time.Now()) {
+			return fmt.Errorf("%w: scheduled_at must be in the future", ErrInvalidInput)
+		}
+		if len(draft.PublishTargets) == 0 {
+			return fmt.Errorf("%w: a scheduled draft needs publish targets", ErrInvalidInput)
+		}
+	case domain.StatusApproved:
+		if draft.Status == domain.StatusScheduled {
+			// Unscheduling keeps the existing approval
+			if !role.Can(domain.PermPublish) {
+				return forbidden("unscheduling requires publish access")
+			}
+			return nil
+		}
+		if !role.Can(domain.PermReviewDrafts) {
+			return forbidden("approving requires reviewer access")
+		}
+		if draft.UserID == userID {
+			return forbidden("authors cannot approve their own draft")
+		}
+		if draft.ReviewerID != "" && draft.ReviewerID != userID {
+			return forbidden("draft is assigned to another reviewer")
+		}
+	}
+	return nil
+}
+
+// canPublishFrom reports whether the role may publish a draft in the given state
+func canPublishFrom(role domain.Role, status domain.DraftStatus) bool {
+	if role.Can(domain.PermReviewDrafts) {
+		return true
+	}
+	return status == domain.StatusApproved || status == domain.StatusScheduled || status == domain.StatusPublished
+}
+
+// AuthorizePublish checks that the user may enqueue a publish job.
+// Users without reviewer access must publish an approved draft, identified by draftID.
+func (s *DraftService) AuthorizePublish(ctx context.Context, workspaceID string, userID string, draftID string) error {
+	role, err := s.workspaces.RoleOf(ctx, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !role.Can(domain.PermPublish) {
+		return fmt.Errorf("%w: %s requires %s", ErrForbidden, workspaceID, domain.PermPublish)
+	}
+
+	if draftID == "" {
+		if role.Can(domain.PermReviewDrafts) {
+			return nil
+		}
+		return fmt.Errorf("%w: draft_id is required", ErrReviewRequired)
+	}
+
+	draft, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if !canPublishFrom(role, draft.Status) {
+		return ErrReviewRequired
+	}
+	return nil
+}
+
+// CheckPublishedRevision checks a publish request against the saved draft, which
+// is what gets published because it is what was reviewed. A request may leave
+// fields out, but values it does send must match; publishing other text or
+// metadata under an approved draft's name would skip the review.
+func CheckPublishedRevision(saved *domain.Draft, requested *domain.Draft) error {
+	for _, field := range []struct{ name, requested, saved string }{
+		{"title", requested.Title, saved.Title},
+		{"content", requested.Content, saved.Content},
+		{"cover_image", requested.CoverImage, saved.CoverImage},
+		{"subtitle", strings.TrimSpace(requested.Subtitle), saved.Subtitle},
+		{"excerpt", strings.TrimSpace(requested.Excerpt), saved.Excerpt},
+		{"series", strings.TrimSpace(requested.Series), saved.Series},
+	} {
+		if field.requested != "" && field.requested != field.saved {
+			return fmt.Errorf("%w: %s; save the draft first", ErrRevisionMismatch, field.name)
+		}
+	}
+	if requested.SeriesOrder != 0 && requested.SeriesOrder != saved.SeriesOrder {
+		return fmt.Errorf("%w: series_order; save the draft first", ErrRevisionMismatch)
+	}
+	if len(requested.Tags) > 0 && !slices.Equal(domain.NormalizeTags(requested.Tags), saved.Tags) {
+		return fmt.Errorf("%w: tags; save the draft first", ErrRevisionMismatch)
+	}
+	return nil
+}
+
+// MarkPublished records a successful publish job (called by the worker)
+func (s *DraftService) MarkPublished(ctx context.Context, workspaceID string, actorID string, draftID string, detail string) error {
+	draft, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	from := draft.Status
+	draft.Status = domain.StatusPublished
+	draft.IsPublished = true
+	draft.ScheduledAt = nil
+
+	if err := s.draftRepo.UpdateWorkflow(ctx, draft); err != nil {
+		return err
+	}
+	if err := s.audit(ctx, draft.ID, actorID, "transition", from, draft.Status, detail); err != nil {
+		return err
+	}
+	if err := s.snapshot(ctx, nil, draft, actorID, domain.RevisionPublish); err != nil {
+		log.Printf("⚠️ Failed to snapshot published revision for draft %s: %v", draft.ID, err)
+	}
+	return s.draftRepo.UpdateDashboardCache(ctx, draft)
+}
+
+// AssignReviewer sets (or clears, with an empty reviewerID) the required approver
+func (s *DraftService) AssignReviewer(ctx context.Context, workspaceID string, userID string, draftID string, reviewerID string) (*domain.Draft, error) {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermEditDrafts); err != nil {
+		return nil, err
+	}
+
+	draft, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviewerID != "" {
+		if reviewerID == draft.UserID {
+			return nil, fmt.Errorf("%w: authors cannot review their own draft", ErrInvalidInput)
+		}
+		role, err := s.workspaces.RoleOf(ctx, workspaceID, reviewerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check reviewer: %w", err)
+		}
+		if !role.Can(domain.PermReviewDrafts) {
+			return nil, fmt.Errorf("%w: %s cannot review drafts in this workspace", ErrInvalidInput, reviewerID)
+		}
+	}
+
+	draft.ReviewerID = reviewerID
+	if err := s.draftRepo.UpdateWorkflow(ctx, draft); err != nil {
+		return nil, err
+	}
+
+	detail := "reviewer cleared"
+	if reviewerID != "" {
+		detail = "reviewer " + reviewerID
+	}
+	if err := s.audit(ctx, draft.ID, userID, "assign_reviewer", "", "", detail); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// AddComment anchors a review comment to [RangeStart, RangeEnd) of the content,
+// measured in characters (Unicode code points)
+func (s *DraftService) AddComment(ctx context.Context, workspaceID string, userID string, draftID string, comment *domain.ReviewComment) error {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return err
+	}
+
+	draft, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	comment.Body = strings.TrimSpace(comment.Body)
+	if comment.Body == "" {
+		return fmt.Errorf("%w: comment body required", ErrInvalidInput)
+	}
+
+	content := []rune(draft.Content)
+	if comment.RangeStart < 0 || comment.RangeEnd < comment.RangeStart || comment.RangeEnd > len(content) {
+		return fmt.Errorf("%w: range [%d, %d) is outside the content", ErrInvalidInput, comment.RangeStart, comment.RangeEnd)
+	}
+
+	comment.DraftID = draftID
+	comment.AuthorID = userID
+	comment.Quote = string(content[comment.RangeStart:comment.RangeEnd])
+	return s.reviewRepo.AddComment(ctx, comment)
+}
+
+func (s *DraftService) ListComments(ctx context.Context, workspaceID string, userID string, draftID string) ([]domain.ReviewComment, error) {
+	if _, err := s.GetDraft(ctx, workspaceID, userID, draftID); err != nil {
+		return nil, err
+	}
+	return s.reviewRepo.ListComments(ctx, draftID)
+}
+
+func (s *DraftService) ResolveComment(ctx context.Context, workspaceID string, userID string, draftID string, commentID string) error {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermEditDrafts); err != nil {
+		return err
+	}
+	if _, err := s.draftRepo.GetDraft(ctx, draftID, workspaceID); err != nil {
+		return err
+	}
+	return s.reviewRepo.ResolveComment(ctx, draftID, commentID)
+}
+
+func (s *DraftService) ListAuditLog(ctx context.Context, workspaceID string, userID string, draftID string) ([]domain.AuditEntry, error) {
+	if _, err := s.GetDraft(ctx, workspaceID, userID, draftID); err != nil {
+		return nil, err
+	}
+	return s.reviewRepo.ListAuditEntries(ctx, draftID)
+}
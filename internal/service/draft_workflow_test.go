@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockDraftRepository
+type MockDraftRepository struct {
+	mock.Mock
+}
+
+func (m *MockDraftRepository) SaveDraft(ctx context.Context, draft *domain.Draft, expectedVersion int64) error {
+	return m.Called(ctx, draft, expectedVersion).Error(0)
+}
+
+func (m *MockDraftRepository) GetDraft(ctx context.Context, id string, workspaceID string) (*domain.Draft, error) {
+	args := m.Called(ctx, id, workspaceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	// Hand out a copy so the service can't mutate the fixture between calls
+	draft := *args.Get(0).(*domain.Draft)
+	return &draft, args.Error(1)
+}
+
+func (m *MockDraftRepository) UpdateDashboardCache(ctx context.Context, draft *domain.Draft) error {
+	return m.Called(ctx, draft).Error(0)
+}
+
+func (m *MockDraftRepository) UpdateWorkflow(ctx context.Context, draft *domain.Draft) error {
+	return m.Called(ctx, draft).Error(0)
+}
+
+// MockReviewRepository
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) AddComment(ctx context.Context, comment *domain.ReviewComment) error {
+	return m.Called(ctx, comment).Error(0)
+}
+
+func (m *MockReviewRepository) ListComments(ctx context.Context, draftID string) ([]domain.ReviewComment, error) {
+	args := m.Called(ctx, draftID)
+	return args.Get(0).([]domain.ReviewComment), args.Error(1)
+}
+
+func (m *MockReviewRepository) ResolveComment(ctx context.Context, draftID string, commentID string) error {
+	return m.Called(ctx, draftID, commentID).Error(0)
+}
+
+func (m *MockReviewRepository) AddAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	return m.Called(ctx, entry).Error(0)
+}
+
+func (m *MockReviewRepository) ListAuditEntries(ctx context.Context, draftID string) ([]domain.AuditEntry, error) {
+	args := m.Called(ctx, draftID)
+	return args.Get(0).([]domain.AuditEntry), args.Error(1)
+}
+
+func newWorkflowFixture(status domain.DraftStatus) (*DraftService, *MockDraftRepository, *MockReviewRepository) {
+	workspaceRepo := new(MockWorkspaceRepository)
+	workspaceRepo.On("GetMembership", mock.Anything, "team", "writer").Return(&domain.Membership{Role: domain.RoleWriter}, nil)
+	workspaceRepo.On("GetMembership", mock.Anything, "team", "editor").Return(&domain.Membership{Role: domain.RoleEditor}, nil)
+
+	draftRepo := new(MockDraftRepository)
+	stored := &domain.Draft{
+		ID:          "d1",
+		WorkspaceID: "team",
+		UserID:      "writer",
+		Content:     "Hello, world",
+		Status:      status,
+	}
+	draftRepo.On("GetDraft", mock.Anything, "d1", "team").Return(stored, nil)
+	draftRepo.On("SaveDraft", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	// Workflow changes stick, so later reads see them
+	draftRepo.On("UpdateWorkflow", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored.Status = args.Get(1).(*domain.Draft).Status
+	}).Return(nil)
+	draftRepo.On("UpdateDashboardCache", mock.Anything, mock.Anything).Return(nil)
+
+	reviewRepo := new(MockReviewRepository)
+	reviewRepo.On("AddAuditEntry", mock.Anything, mock.Anything).Return(nil)
+	reviewRepo.On("AddComment", mock.Anything, mock.Anything).Return(nil)
+
+	revisionRepo := new(MockRevisionRepository)
+	revisionRepo.On("LatestRevision", mock.Anything, mock.Anything).Return(nil, nil)
+	revisionRepo.On("AddRevision", mock.Anything, mock.Anything).Return(nil)
+
+	return NewDraftService(draftRepo, reviewRepo, revisionRepo, NewWorkspaceService(workspaceRepo)), draftRepo, reviewRepo
+}
+
+func TestDraftService_WriterNeedsApprovalToPublish(t *testing.T) {
+	svc, _, _ := newWorkflowFixture(domain.StatusInReview)
+	ctx := context.Background()
+
+	_, err := svc.Transition(ctx, "team", "writer", "d1", TransitionRequest{To: domain.StatusPublished})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	assert.ErrorIs(t, svc.AuthorizePublish(ctx, "team", "writer", "d1"), ErrReviewRequired)
+	assert.ErrorIs(t, svc.AuthorizePublish(ctx, "team", "writer", ""), ErrReviewRequired)
+	assert.NoError(t, svc.AuthorizePublish(ctx, "team", "editor", ""))
+}
+
+func TestDraftService_OnlyAPublishJobMarksADraftPublished(t *testing.T) {
+	svc, _, _ := newWorkflowFixture(domain.StatusApproved)
+	ctx := context.Background()
+
+	_, err := svc.Transition(ctx, "team", "editor", "d1", TransitionRequest{To: domain.StatusPublished})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	require.NoError(t, svc.MarkPublished(ctx, "team", "editor", "d1", "published to devto"))
+	draft, err := svc.GetDraft(ctx, "team", "editor", "d1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPublished, draft.Status)
+}
+
+func TestDraftService_WriterEditOfPublishedDraftNeedsReview(t *testing.T) {
+	svc, _, reviewRepo := newWorkflowFixture(domain.StatusPublished)
+	ctx := context.Background()
+	require.NoError(t, svc.AuthorizePublish(ctx, "team", "writer", "d1"), "the reviewed text may go out again")
+
+	err := svc.SaveDraft(ctx, &domain.Draft{ID: "d1", WorkspaceID: "team", UserID: "writer", Content: "Unreviewed text"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.AuthorizePublish(ctx, "team", "writer", "d1"), ErrReviewRequired)
+	reviewRepo.AssertCalled(t, "AddAuditEntry", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.FromStatus == domain.StatusPublished && e.ToStatus == domain.StatusDraft && e.Detail == "approval reset by edit"
+	}))
+}
+
+func TestDraftService_WriterMetadataEditOfApprovedDraftNeedsReview(t *testing.T) {
+	svc, _, _ := newWorkflowFixture(domain.StatusApproved)
+	ctx := context.Background()
+
+	err := svc.SaveDraft(ctx, &domain.Draft{ID: "d1", WorkspaceID: "team", UserID: "writer", Content: "Hello, world", Tags: []string{"crypto"}})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.AuthorizePublish(ctx, "team", "writer", "d1"), ErrReviewRequired)
+}
+
+func TestDraftService_CollaboratorSaveKeepsAuthorsDashboardRow(t *testing.T) {
+	svc, draftRepo, _ := newWorkflowFixture(domain.StatusDraft)
+	ctx := context.Background()
+
+	_, err := svc.SaveContent(ctx, "team", "editor", "d1", 0, "Title", "Edited by the editor")
+	require.NoError(t, err)
+
+	draftRepo.AssertCalled(t, "UpdateDashboardCache", mock.Anything, mock.MatchedBy(func(d *domain.Draft) bool {
+		return d.UserID == "writer"
+	}))
+	draftRepo.AssertNotCalled(t, "UpdateDashboardCache", mock.Anything, mock.MatchedBy(func(d *domain.Draft) bool {
+		return d.UserID == "editor"
+	}))
+}
+
+func TestCheckPublishedRevision(t *testing.T) {
+	saved := &domain.Draft{
+		Title:      "Reviewed",
+		Content:    "Approved text",
+		CoverImage: "https://images.example/a.png",
+		Tags:       []string{"go", "testing"},
+		Subtitle:   "Approved subtitle",
+	}
+
+	assert.NoError(t, CheckPublishedRevision(saved, &domain.Draft{}), "omitted fields come from the draft")
+	assert.NoError(t, CheckPublishedRevision(saved, &domain.Draft{Title: "Reviewed", Content: "Approved text"}), "matching values are fine")
+	assert.NoError(t, CheckPublishedRevision(saved, &domain.Draft{Tags: []string{"#Go", "testing"}}), "tags compare normalized")
+
+	for name, requested := range map[string]*domain.Draft{
+		"content":  {Title: "Reviewed", Content: "Unreviewed text"},
+		"cover":    {CoverImage: "https://images.example/b.png"},
+		"tags":     {Tags: []string{"go", "crypto"}},
+		"subtitle": {Subtitle: "Unreviewed subtitle"},
+		"excerpt":  {Excerpt: "Unreviewed excerpt"},
+		"series":   {Series: "Unreviewed series"},
+	} {
+		assert.ErrorIs(t, CheckPublishedRevision(saved, requested), ErrRevisionMismatch, name)
+	}
+}
+
+func TestDraftService_ApproveRecordsAudit(t *testing.T) {
+	svc, _, reviewRepo := newWorkflowFixture(domain.StatusInReview)
+	ctx := context.Background()
+
+	_, err := svc.Transition(ctx, "team", "writer", "d1", TransitionRequest{To: domain.StatusApproved})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	draft, err := svc.Transition(ctx, "team", "editor", "d1", TransitionRequest{To: domain.StatusApproved, Note: "LGTM"})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, draft.Status)
+
+	reviewRepo.AssertCalled(t, "AddAuditEntry", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.ActorID == "editor" && e.FromStatus == domain.StatusInReview && e.ToStatus == domain.StatusApproved && e.Detail == "LGTM"
+	}))
+}
+
+func TestDraftService_AddComment_AnchorsQuote(t *testing.T) {
+	svc, _, _ := newWorkflowFixture(domain.StatusInReview)
+	ctx := context.Background()
+
+	comment := &domain.ReviewComment{Body: "Comma splice?", RangeStart: 5, RangeEnd: 12}
+	assert.NoError(t, svc.AddComment(ctx, "team", "editor", "d1", comment))
+	assert.Equal(t, ", world", comment.Quote)
+
+	outOfRange := &domain.ReviewComment{Body: "?", RangeStart: 5, RangeEnd: 99}
+	assert.ErrorIs(t, svc.AddComment(ctx, "team", "editor", "d1", outOfRange), ErrInvalidInput)
+}
+
+func (m *MockDraftRepository) ListDrafts(ctx context.Context, filter storage.DraftFilter) ([]domain.Draft, int, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Draft), args.Int(1), args.Error(2)
+}
+
+func (m *MockDraftRepository) TrashDraft(ctx context.Context, id string, workspaceID string) error {
+	return m.Called(ctx, id, workspaceID).Error(0)
+}
+
+func (m *MockDraftRepository) UntrashDraft(ctx context.Context, id string, workspaceID string) error {
+	return m.Called(ctx, id, workspaceID).Error(0)
+}
+
+func (m *MockDraftRepository) ListDueScheduled(ctx context.Context, before time.Time, limit int) ([]domain.Draft, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]domain.Draft), args.Error(1)
+}
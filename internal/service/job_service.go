@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"postificus/internal/domain"
+	"postificus/internal/queue"
+	"postificus/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// LanePlatforms are the platforms with a lane of their own: their publishes and
+// syncs share one queue, whose consumer count caps the work hitting the platform.
+// Jobs for other platforms go to their type's queue.
+var LanePlatforms = []string{"medium", "devto"}
+
+// defaultLaneConsumers sizes the lanes unless WORKER_LANES says otherwise; lanes
+// missing here get one consumer
+var defaultLaneConsumers = map[string]int{"medium": 1, "devto": 2}
+
+// WorkerLanes returns the consumer count of every lane, the defaults resized by
+// spec (WORKER_LANES, e.g. "medium=1,devto=2"). spec can only resize lanes:
+// naming a platform without one is an error, since its jobs go to their
+// type's queue and a lane for it would never get any.
+func WorkerLanes(spec string) (map[string]int, error) {
+	configured, err := queue.ParseLanes(spec)
+	if err != nil {
+		return nil, err
+	}
+	lanes := make(map[string]int, len(LanePlatforms))
+	for _, platform := range LanePlatforms {
+		consumers, ok := defaultLaneConsumers[platform]
+		if !ok {
+			consumers = 1
+		}
+		lanes[platform] = consumers
+	}
+	for platform, consumers := range configured {
+		if _, ok := lanes[platform]; !ok {
+			return nil, fmt.Errorf("%s has no lane (lanes: %s)", platform, strings.Join(LanePlatforms, ", "))
+		}
+		lanes[platform] = consumers
+	}
+	return lanes, nil
+}
+
+// jobQueue returns the queue a job of the type for the platform goes to
+func jobQueue(platform, jobType string) string {
+	for _, p := range LanePlatforms {
+		if p == platform {
+			return queue.Lane(platform)
+		}
+	}
+	return jobType
+}
+
+// JobQueues lists every queue the worker consumes
+func JobQueues() []string {
+	queues := []string{TypePublishPost, TypeSyncPlatformActivity}
+	for _, platform := range LanePlatforms {
+		queues = append(queues, queue.Lane(platform))
+	}
+	return queues
+}
+
+// JobService hands work to the worker through the transactional outbox: jobs
+// are stored with the rows they belong to, and the outbox relay enqueues them.
+type JobService struct {
+	outbox      storage.OutboxRepository
+	publishLogs storage.PublishLogRepository
+	relay       *queue.OutboxRelay // Optional; woken so jobs don't wait for its next poll
+}
+
+func NewJobService(outbox storage.OutboxRepository, publishLogs storage.PublishLogRepository, relay *queue.OutboxRelay) *JobService {
+	return &JobService{outbox: outbox, publishLogs: publishLogs, relay: relay}
+}
+
+// QueuePublish records a publish job and its outbox message in one transaction.
+// The job ID is the idempotency key of the draft revision on the platform
+// account, so asking twice for the same publish returns the first job, queued
+// false, unless that job failed, in which case it's queued again. The revision
+// covers the post's metadata as well as its content.
+func (s *JobService) QueuePublish(ctx context.Context, payload PublishPayload) (entry *domain.PublishLog, queued bool, err error) {
+	revision := domain.PublishRevisionHash(
+		domain.RevisionHash(payload.Title, payload.Content, payload.CoverImage),
+		payload.Tags, payload.Subtitle, payload.Excerpt, payload.Series, payload.SeriesOrder,
+	)
+	payload.JobID = domain.PublishKey(payload.CredentialsWorkspace(), payload.DraftID, payload.Platform, payload.AccountID, revision)
+	body, err := NewPublishPayload(payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	entry = &domain.PublishLog{
+		JobID:       payload.JobID,
+		WorkspaceID: payload.CredentialsWorkspace(),
+		DraftID:     payload.DraftID,
+		Platform:    payload.Platform,
+	}
+	// Someone is waiting on a publish; it goes ahead of the syncs in the platform's lane
+	message := storage.OutboxMessage{
+		Queue:          jobQueue(payload.Platform, TypePublishPost),
+		Type:           TypePublishPost,
+		Priority:       queue.PriorityInteractive,
+		Payload:        body,
+		IdempotencyKey: payload.JobID,
+	}
+	queued, err = s.publishLogs.QueuePublish(ctx, entry, message)
+	if err != nil {
+		return nil, false, err
+	}
+	if queued {
+		s.wakeRelay()
+	}
+	return entry, queued, nil
+}
+
+// QueueSync adds one sync job per payload; either all of them are queued or none
+func (s *JobService) QueueSync(ctx context.Context, payloads ...SyncPlatformPayload) error {
+	messages := make([]storage.OutboxMessage, 0, len(payloads))
+	for _, p := range payloads {
+		body, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+		messages = append(messages, storage.OutboxMessage{
+			Queue:          jobQueue(p.Platform, TypeSyncPlatformActivity),
+			Type:           TypeSyncPlatformActivity,
+			Priority:       queue.PriorityBackground,
+			Payload:        body,
+			IdempotencyKey: uuid.NewString(),
+		})
+	}
+	if err := s.outbox.Add(ctx, messages...); err != nil {
+		return err
+	}
+	s.wakeRelay()
+	return nil
+}
+
+func (s *JobService) wakeRelay() {
+	if s.relay != nil {
+		s.relay.Notify()
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerLanes(t *testing.T) {
+	lanes, err := WorkerLanes("")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"medium": 1, "devto": 2}, lanes)
+
+	lanes, err = WorkerLanes("devto=0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"medium": 1, "devto": 0}, lanes, "a lane can be paused, the others keep their defaults")
+
+	_, err = WorkerLanes("linkedin=1")
+	assert.ErrorContains(t, err, "linkedin has no lane")
+
+	_, err = WorkerLanes("medium=-1")
+	assert.Error(t, err)
+}
+
+func TestJobService_QueuePublish_OneJobPerAccount(t *testing.T) {
+	store := storage.NewMemoryStore()
+	jobs := NewJobService(store.Outbox, store.PublishLogs, nil)
+	ctx := context.Background()
+	publish := func(account string) (string, bool) {
+		entry, queued, err := jobs.QueuePublish(ctx, PublishPayload{WorkspaceID: "team", DraftID: "d1", AccountID: account, Platform: "medium", Title: "T", Content: "C"})
+		require.NoError(t, err)
+		return entry.JobID, queued
+	}
+
+	first, queued := publish("acct-1")
+	assert.True(t, queued)
+	second, queued := publish("acct-2")
+	assert.True(t, queued, "the same revision goes out to a second account")
+	assert.NotEqual(t, first, second)
+
+	again, queued := publish("acct-1")
+	assert.False(t, queued, "asking again for the same account is answered by its first job")
+	assert.Equal(t, first, again)
+
+	byDefault, queued := publish("")
+	assert.True(t, queued)
+	assert.NotEqual(t, first, byDefault)
+}
+
+func TestJobService_QueuePublish_MetadataIsPartOfTheRevision(t *testing.T) {
+	store := storage.NewMemoryStore()
+	jobs := NewJobService(store.Outbox, store.PublishLogs, nil)
+	ctx := context.Background()
+	publish := func(payload PublishPayload) (string, bool) {
+		payload.WorkspaceID, payload.DraftID, payload.Platform = "team", "d1", "devto"
+		payload.Title, payload.Content = "T", "C"
+		entry, queued, err := jobs.QueuePublish(ctx, payload)
+		require.NoError(t, err)
+		return entry.JobID, queued
+	}
+
+	plain, queued := publish(PublishPayload{})
+	assert.True(t, queued)
+	assert.Equal(t, domain.PublishKey("team", "d1", "devto", "", domain.RevisionHash("T", "C", "")), plain,
+		"a post without metadata keeps the key it had")
+
+	tagged, queued := publish(PublishPayload{Tags: []string{"go"}})
+	assert.True(t, queued, "retagging is a new publish")
+	assert.NotEqual(t, plain, tagged)
+
+	for name, payload := range map[string]PublishPayload{
+		"subtitle":     {Tags: []string{"go"}, Subtitle: "S"},
+		"excerpt":      {Tags: []string{"go"}, Excerpt: "E"},
+		"series":       {Tags: []string{"go"}, Series: "Intro"},
+		"series order": {Tags: []string{"go"}, Series: "Intro", SeriesOrder: 2},
+	} {
+		key, queued := publish(payload)
+		assert.True(t, queued, name)
+		assert.NotEqual(t, tagged, key, name)
+	}
+
+	again, queued := publish(PublishPayload{Tags: []string{"go"}})
+	assert.False(t, queued, "the same content and metadata is the same publish")
+	assert.Equal(t, tagged, again)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"postificus/internal/browser"
+	"postificus/internal/throttle"
+)
+
+const (
+	// publishClaimStale is how long a processing job may go without finishing
+	// before another worker assumes its worker died and takes it over
+	publishClaimStale = 15 * time.Minute
+	// recentPostsChecked is how far back the duplicate check looks on the platform
+	recentPostsChecked = 30
+	// platformClockSkew widens the duplicate check's window for platform clocks running behind ours
+	platformClockSkew = 2 * time.Minute
+)
+
+// findPublishedCopy looks for the payload's post among the account's recent posts
+// on the platform, for a retry whose earlier attempt may have published it
+// without telling us (a lost response, a worker killed mid-flow). It returns
+// the copy's URL, or "" if there is none or no way to look.
+// Posts published before since don't count, when the platform says when they were.
+// Listing the posts spends one of account's request tokens; over the limit it
+// returns the throttle's LimitedError.
+func (s *PublishService) findPublishedCopy(ctx context.Context, p PublishPayload, credsMap map[string]string, account string, since time.Time) (string, error) {
+	request := func() error { return s.limits.Take(ctx, p.Platform, account, throttle.Request) }
+	var posts []browser.RecentPost
+	switch p.Platform {
+	case "medium":
+		uid := getCredential(credsMap, "uid", "MEDIUM_UID")
+		sid := getCredential(credsMap, "sid", "MEDIUM_SID")
+		xsrf := getCredential(credsMap, "xsrf", "MEDIUM_XSRF")
+		if uid == "" || sid == "" {
+			return "", nil
+		}
+		if err := request(); err != nil {
+			return "", err
+		}
+		var err error
+		if posts, err = browser.NewMediumAPIClient(uid, sid, xsrf).RecentPosts(ctx, recentPostsChecked); err != nil {
+			return "", fmt.Errorf("failed to list medium posts: %w", err)
+		}
+
+	case "devto":
+		if apiKey := getCredential(credsMap, "api_key", "DEVTO_API_KEY"); apiKey != "" {
+			if err := request(); err != nil {
+				return "", err
+			}
+			var err error
+			if posts, err = browser.NewDevtoAPIClient(apiKey).RecentArticles(ctx, recentPostsChecked); err != nil {
+				return "", fmt.Errorf("failed to list devto articles: %w", err)
+			}
+			break
+		}
+		token := getCredential(credsMap, "remember_user_token", "DEVTO_SESSION_TOKEN")
+		if token == "" {
+			token = getCredential(credsMap, "token", "DEVTO_SESSION_TOKEN")
+		}
+		if token == "" {
+			return "", nil
+		}
+		if err := request(); err != nil {
+			return "", err
+		}
+		dashboard, err := browser.FetchDevtoDashboardPosts(ctx, token, recentPostsChecked)
+		if err != nil {
+			return "", fmt.Errorf("failed to list devto articles: %w", err)
+		}
+		for _, d := range dashboard {
+			posts = append(posts, browser.RecentPost{Title: d.Title, URL: d.URL})
+		}
+
+	default:
+		return "", nil
+	}
+
+	title := normalizeTitle(p.Title)
+	body := contentHash(p.Content)
+	for _, post := range posts {
+		if !post.PublishedAt.IsZero() && post.PublishedAt.Before(since.Add(-platformClockSkew)) {
+			continue
+		}
+		if (title != "" && normalizeTitle(post.Title) == title) || (post.Body != "" && contentHash(post.Body) == body) {
+			return post.URL, nil
+		}
+	}
+	return "", nil
+}
+
+// normalizeTitle ignores the case and spacing platforms tend to change
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+func contentHash(content string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(strings.TrimSpace(content)))
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"unicode"
+
+	"postificus/internal/browser"
+	"postificus/internal/domain"
+)
+
+// platformTagLimits is how many tags each platform accepts
+var platformTagLimits = map[string]int{
+	"devto":    4,
+	"medium":   5,
+	"hashnode": 5,
+}
+
+// MapPostMetadata turns a payload's draft metadata into what the platform understands.
+// mappings renames our tags for this platform (tag -> platform tag).
+//
+//   - Dev.to: up to 4 alphanumeric tags and the series name. Dev.to orders a
+//     series by publish date, so SeriesOrder is not sent.
+//   - Medium: up to 5 tags and the subtitle.
+//   - Hashnode: tags, subtitle and series.
+func MapPostMetadata(platform string, p PublishPayload, mappings map[string]string) browser.PostMetadata {
+	meta := browser.PostMetadata{}
+
+	seen := make(map[string]bool)
+	for _, tag := range domain.NormalizeTags(p.Tags) {
+		if mapped, ok := mappings[tag]; ok {
+			tag = mapped
+		}
+		if platform == "devto" {
+			// Dev.to only accepts lowercase alphanumeric tags
+			tag = strings.Map(func(r rune) rune {
+				if unicode.IsLetter(r) || unicode.IsDigit(r) {
+					return unicode.ToLower(r)
+				}
+				return -1
+			}, tag)
+		}
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		meta.Tags = append(meta.Tags, tag)
+	}
+	if limit, ok := platformTagLimits[platform]; ok && len(meta.Tags) > limit {
+		meta.Tags = meta.Tags[:limit]
+	}
+
+	switch platform {
+	case "devto":
+		meta.Series = p.Series
+	case "medium":
+		meta.Subtitle = p.Subtitle
+	case "hashnode":
+		meta.Subtitle = p.Subtitle
+		meta.Series = p.Series
+	}
+	return meta
+}
+
+// postMetadata loads the workspace's tag mappings and maps the payload for its platform.
+// Mapping lookups are best-effort: without them, tags go out unchanged.
+func (s *PublishService) postMetadata(ctx context.Context, p PublishPayload) browser.PostMetadata {
+	mappings := make(map[string]string)
+	if s.tagMappings != nil {
+		list, err := s.tagMappings.ListTagMappings(ctx, p.CredentialsWorkspace(), p.Platform)
+		if err != nil {
+			log.Printf("⚠️ Failed to load tag mappings for %s: %v", p.Platform, err)
+		}
+		for _, m := range list {
+			mappings[m.Tag] = m.PlatformTag
+		}
+	}
+	return MapPostMetadata(p.Platform, p, mappings)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapPostMetadata(t *testing.T) {
+	p := PublishPayload{
+		Tags:     []string{"#GoLang", "web-dev", "Go", "testing", "cloud", "rust"},
+		Subtitle: "A short tour",
+		Series:   "Go basics",
+	}
+	mappings := map[string]string{"golang": "go"}
+
+	devto := MapPostMetadata("devto", p, mappings)
+	assert.Equal(t, []string{"go", "webdev", "testing", "cloud"}, devto.Tags)
+	assert.Equal(t, "Go basics", devto.Series)
+	assert.Empty(t, devto.Subtitle)
+
+	medium := MapPostMetadata("medium", p, mappings)
+	assert.Equal(t, []string{"go", "web-dev", "testing", "cloud", "rust"}, medium.Tags)
+	assert.Equal(t, "A short tour", medium.Subtitle)
+	assert.Empty(t, medium.Series)
+
+	hashnode := MapPostMetadata("hashnode", p, nil)
+	assert.Equal(t, []string{"golang", "web-dev", "go", "testing", "cloud"}, hashnode.Tags)
+	assert.Equal(t, "A short tour", hashnode.Subtitle)
+	assert.Equal(t, "Go basics", hashnode.Series)
+}
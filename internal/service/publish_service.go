@@ -3,15 +3,22 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"postificus/internal/breaker"
 	"postificus/internal/browser"
+	"postificus/internal/domain"
 	"postificus/internal/metrics"
+	"postificus/internal/queue"
 	"postificus/internal/storage"
+	"postificus/internal/throttle"
+
+	"github.com/redis/go-redis/v9"
 )
 
 const (
@@ -19,13 +26,21 @@ const (
 )
 
 type PublishPayload struct {
-	UserID     string   `json:"user_id"`
-	Platform   string   `json:"platform"` // "medium", "linkedin", "devto"
-	Title      string   `json:"title"`
-	Content    string   `json:"content"`
-	CoverImage string   `json:"cover_image,omitempty"`
-	Tags       []string `json:"tags,omitempty"`
-	BlogURL    string   `json:"blog_url,omitempty"` // For LinkedIn
+	JobID       string   `json:"job_id,omitempty"` // Idempotency key; the job's publish log is kept under it
+	UserID      string   `json:"user_id"`
+	WorkspaceID string   `json:"workspace_id,omitempty"` // Defaults to the user's personal workspace
+	AccountID   string   `json:"account_id,omitempty"`   // Specific connected account; latest one if empty
+	DraftID     string   `json:"draft_id,omitempty"`     // Marked published once the job succeeds
+	Platform    string   `json:"platform"`               // "medium", "linkedin", "devto"
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	CoverImage  string   `json:"cover_image,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Subtitle    string   `json:"subtitle,omitempty"`
+	Excerpt     string   `json:"excerpt,omitempty"`
+	Series      string   `json:"series,omitempty"`
+	SeriesOrder int      `json:"series_order,omitempty"`
+	BlogURL     string   `json:"blog_url,omitempty"` // For LinkedIn
 }
 
 // NewPublishPayload helper
@@ -35,86 +50,156 @@ func NewPublishPayload(p PublishPayload) ([]byte, error) {
 
 // PublishService handles the execution of publishing tasks via browser automation.
 type PublishService struct {
-	credsRepo storage.CredentialsRepository
-	breakers  map[string]*breaker.CircuitBreaker
+	credsRepo   storage.CredentialsRepository
+	drafts      *DraftService                // Optional; records published drafts
+	tagMappings storage.TagMappingRepository // Optional; per-platform tag names
+	activity    *ActivityService             // Optional; lists published posts on the dashboard
+	publishLogs storage.PublishLogRepository // Optional; tracks each job's progress
+	limits      *throttle.Throttle           // Optional; per-account platform rate limits
+	breakers    *breaker.Group               // Optional; stops publishing to a platform that keeps failing
 }
 
-func NewPublishService(credsRepo storage.CredentialsRepository) *PublishService {
-	breakers := make(map[string]*breaker.CircuitBreaker)
-	// Initialize breakers for known platforms
-	breakers["medium"] = breaker.NewCircuitBreakerWithName("medium", 3, 1*time.Minute)
-	breakers["devto"] = breaker.NewCircuitBreakerWithName("devto", 3, 1*time.Minute)
-
+func NewPublishService(credsRepo storage.CredentialsRepository, drafts *DraftService, tagMappings storage.TagMappingRepository, activity *ActivityService, publishLogs storage.PublishLogRepository, limits *throttle.Throttle, breakers *breaker.Group) *PublishService {
 	return &PublishService{
-		credsRepo: credsRepo,
-		breakers:  breakers,
+		credsRepo:   credsRepo,
+		drafts:      drafts,
+		tagMappings: tagMappings,
+		activity:    activity,
+		publishLogs: publishLogs,
+		limits:      limits,
+		breakers:    breakers,
 	}
 }
 
-// HandlePublishTask executes the actual publishing logic (Consumer Handler)
-func (s *PublishService) HandlePublishTask(payload []byte) error {
-	ctx := context.Background()
+// NewPublishBreakers builds a circuit breaker per lane platform, shared through
+// Redis when client isn't nil. Errors that say nothing about a platform's health
+// don't trip them: permanent ones (missing credentials, rejected content) and
+// cancellations at shutdown.
+func NewPublishBreakers(client *redis.Client) *breaker.Group {
+	settings := breaker.DefaultSettings
+	settings.Ignore = func(err error) bool {
+		return queue.IsPermanent(err) || errors.Is(err, context.Canceled)
+	}
+	return breaker.NewGroup(settings, client, LanePlatforms...)
+}
 
+// HandlePublishTask executes the actual publishing logic (Consumer Handler).
+// Cancelling ctx aborts the API calls and browser pages of the publish.
+func (s *PublishService) HandlePublishTask(ctx context.Context, payload []byte) (err error) {
 	// 1. Parse Payload
 	var p PublishPayload
 	if err := json.Unmarshal(payload, &p); err != nil {
-		return fmt.Errorf("json.Unmarshal failed: %v", err)
+		return queue.Permanent(fmt.Errorf("json.Unmarshal failed: %v", err))
 	}
 
 	log.Printf("Processing publish task for platform: %s, title: %s", p.Platform, p.Title)
 
-	// 2. Fetch Credentials
-	credsMap, err := s.fetchCredentials(ctx, p.UserID, p.Platform)
+	// The publish log shows the latest attempt; claiming it keeps two workers off the same job
+	record, err := s.claimPublish(ctx, p.JobID)
 	if err != nil {
+		return err
+	}
+	if record != nil && record.Status == domain.PublishSucceeded {
+		log.Printf("⏭️ Job %s already published to %s: %s", p.JobID, p.Platform, record.ExternalURL)
+		return nil
+	}
+	// A failed browser run saves its screenshot, DOM, console and timeline under the job's attempt
+	artifacts := browser.NewArtifacts(artifactsKey(p.JobID, record))
+	ctx = browser.WithArtifacts(ctx, artifacts)
+	released := false
+	defer func() {
+		if err != nil && !released {
+			s.trackPublish(context.WithoutCancel(ctx), p.JobID, domain.PublishFailed, "", err.Error())
+			s.linkArtifacts(context.WithoutCancel(ctx), p.JobID, record, artifacts.URL())
+		}
+	}()
+
+	// Checked before the job spends anything: a platform we can't post to fails
+	// without a credentials lookup or the account's token
+	if err := publishable(p.Platform); err != nil {
+		return err
+	}
+
+	// Resolved after the claim, so a job with no usable account fails in its publish log too
+	credsMap, account, err := s.fetchCredentials(ctx, p.CredentialsWorkspace(), p.Platform, p.AccountID)
+	switch {
+	case err == nil:
+	case p.AccountID != "" || p.CredentialsWorkspace() != DefaultUserID():
+		// The environment's credentials are the operator's own: a named account or a
+		// shared workspace never falls back to them
+		if errors.Is(err, errNoCredentials) {
+			return queue.Permanent(err)
+		}
+		return fmt.Errorf("failed to fetch credentials: %w", err)
+	default:
 		log.Printf("Warning: Failed to fetch credentials from DB: %v. Falling back to Env.", err)
 		credsMap = make(map[string]string)
 	}
 
+	// An earlier attempt may have published the post without us hearing back; posting
+	// again would put it up twice, so a retry that can't check waits until it can
+	if record != nil && record.Attempts > 1 {
+		url, err := s.findPublishedCopy(ctx, p, credsMap, account, record.CreatedAt)
+		if _, limited := throttle.RetryAfter(err); limited {
+			// Waits like a post over the limit does
+			released = s.releasePublish(context.WithoutCancel(ctx), p.JobID)
+			return deferIfLimited(err)
+		}
+		if err != nil {
+			return fmt.Errorf("duplicate check failed: %w", err)
+		}
+		if url != "" {
+			log.Printf("♻️ Job %s was already published to %s: %s", p.JobID, p.Platform, url)
+			s.recordPublished(context.WithoutCancel(ctx), p, url)
+			return nil
+		}
+	}
+
+	// Only a post that is really going out spends the account's token. One over
+	// the limit hands its claim back and waits, neither failed nor counted.
+	if err := s.limits.Take(ctx, p.Platform, account, throttle.Post); err != nil {
+		released = s.releasePublish(context.WithoutCancel(ctx), p.JobID)
+		return deferIfLimited(err)
+	}
+
 	// 3. Execute Automation based on Platform
-	var url string
+	var url string // Empty when the browser flow can't tell where the post landed
+	meta := s.postMetadata(ctx, p)
 
 	switch p.Platform {
 	case "medium":
-		cb, ok := s.breakers["medium"]
-		if !ok {
-			cb = breaker.NewCircuitBreakerWithName("medium", 3, 1*time.Minute)
-		}
-
-		err = cb.Execute(func() error {
+		err = s.breakers.Get("medium").Execute(ctx, func() error {
 			uid := getCredential(credsMap, "uid", "MEDIUM_UID")
 			sid := getCredential(credsMap, "sid", "MEDIUM_SID")
 			xsrf := getCredential(credsMap, "xsrf", "MEDIUM_XSRF")
 
 			if uid == "" || sid == "" {
-				return fmt.Errorf("medium credentials missing")
+				return queue.Permanent(fmt.Errorf("medium credentials missing"))
 			}
-			return browser.PostToMediumWithTags(uid, sid, xsrf, p.Title, p.Content, p.Tags, p.CoverImage)
+			url, err = browser.PostToMediumWithTags(ctx, uid, sid, xsrf, p.Title, p.Content, meta, p.CoverImage)
+			return permanentIfExpired(err)
 		})
-		url = "https://medium.com/me/stories/public" // Placeholder
 
 	case "devto":
-		cb, ok := s.breakers["devto"]
-		if !ok {
-			cb = breaker.NewCircuitBreakerWithName("devto", 3, 1*time.Minute)
-		}
+		err = s.breakers.Get("devto").Execute(ctx, func() error {
+			// An API key skips the browser entirely
+			if apiKey := getCredential(credsMap, "api_key", "DEVTO_API_KEY"); apiKey != "" {
+				url, err = browser.NewDevtoAPIClient(apiKey).Publish(ctx, p.Title, p.Content, p.CoverImage, meta)
+				return err
+			}
 
-		err = cb.Execute(func() error {
 			token := getCredential(credsMap, "remember_user_token", "DEVTO_SESSION_TOKEN")
 			if token == "" {
 				token = getCredential(credsMap, "token", "DEVTO_SESSION_TOKEN") // Legacy fallback
 			}
 			if token == "" {
-				return fmt.Errorf("devto credentials missing")
+				return queue.Permanent(fmt.Errorf("devto credentials missing"))
 			}
-			return browser.PostToDevToWithCookie(token, p.Title, p.Content, p.CoverImage, p.Tags)
+			return permanentIfExpired(browser.PostToDevToWithCookie(ctx, token, p.Title, p.Content, p.CoverImage, meta))
 		})
-		url = "https://dev.to/dashboard" // Placeholder
-
-	case "linkedin":
-		return fmt.Errorf("linkedin publishing not supported")
 
 	default:
-		return fmt.Errorf("unsupported platform: %s", p.Platform)
+		return publishable(p.Platform)
 	}
 
 	start := time.Now()
@@ -127,26 +212,180 @@ func (s *PublishService) HandlePublishTask(payload []byte) error {
 	metrics.PostPublishDuration.WithLabelValues(p.Platform).Observe(time.Since(start).Seconds())
 
 	log.Printf("✅ Published to %s: %s", p.Platform, url)
+
+	// The post is live; record it even if shutdown has started
+	s.recordPublished(context.WithoutCancel(ctx), p, url)
 	return nil
 }
 
-// Helper to fetch credentials from DB and unmarshal them
-func (s *PublishService) fetchCredentials(ctx context.Context, userID string, platform string) (map[string]string, error) {
-	cred, err := s.credsRepo.GetCredentials(ctx, userID, platform)
+// publishable fails for good on a platform the worker can't post to
+func publishable(platform string) error {
+	switch platform {
+	case "medium", "devto":
+		return nil
+	case "linkedin":
+		return queue.Permanent(fmt.Errorf("linkedin publishing not supported"))
+	default:
+		return queue.Permanent(fmt.Errorf("unsupported platform: %s", platform))
+	}
+}
+
+// permanentIfExpired gives up on a publish whose session cookies have expired;
+// retrying won't help until the user reconnects the account
+func permanentIfExpired(err error) error {
+	if errors.Is(err, browser.ErrSessionExpired) {
+		return queue.Permanent(err)
+	}
+	return err
+}
+
+// recordPublished does the bookkeeping of a live post: the job's publish log,
+// the draft's status and the dashboard entry. Failures are logged, not returned;
+// the post is live, so the job mustn't fail (and retry) over them.
+func (s *PublishService) recordPublished(ctx context.Context, p PublishPayload, url string) {
+	s.trackPublish(ctx, p.JobID, domain.PublishSucceeded, url, "")
+
+	if s.drafts != nil && p.DraftID != "" {
+		detail := fmt.Sprintf("published to %s", p.Platform)
+		if err := s.drafts.MarkPublished(ctx, p.CredentialsWorkspace(), p.UserID, p.DraftID, detail); err != nil {
+			log.Printf("⚠️ Failed to mark draft %s published: %v", p.DraftID, err)
+		}
+	}
+
+	// Runs after MarkPublished so the cache invalidation also covers the draft's own row
+	if s.activity != nil && url != "" {
+		post := domain.UnifiedPost{
+			Platform:    p.Platform,
+			RemoteID:    url, // Same key the activity sync uses, so a later sync updates this row
+			Title:       p.Title,
+			URL:         url,
+			Status:      "published",
+			PublishedAt: time.Now(),
+		}
+		if err := s.activity.UpsertPost(ctx, p.UserID, post); err != nil {
+			log.Printf("⚠️ Failed to add %s post to the dashboard: %v", p.Platform, err)
+		}
+	}
+}
+
+// claimPublish marks the job processing in its publish log and returns the record,
+// or nil for jobs queued without one (or before publish logs existed).
+// Another worker running the job is a retryable error: if that worker dies,
+// its claim goes stale and the retry takes over.
+func (s *PublishService) claimPublish(ctx context.Context, jobID string) (*domain.PublishLog, error) {
+	if s.publishLogs == nil || jobID == "" {
+		return nil, nil
+	}
+	record, err := s.publishLogs.ClaimPublish(ctx, jobID, publishClaimStale)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return nil, nil
+	case errors.Is(err, storage.ErrPublishInProgress):
+		return nil, fmt.Errorf("job %s: %w", jobID, err)
+	case err != nil:
+		// Publishing without the claim could post twice; wait for storage instead
+		return nil, fmt.Errorf("failed to claim publish job: %w", err)
+	}
+	return record, nil
+}
+
+// releasePublish queues the claimed job again without counting the attempt and
+// reports whether the publish log no longer needs its failure recorded
+func (s *PublishService) releasePublish(ctx context.Context, jobID string) bool {
+	if s.publishLogs == nil || jobID == "" {
+		return true
+	}
+	err := s.publishLogs.ReleasePublish(ctx, jobID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("⚠️ Failed to release publish job %s: %v", jobID, err)
+		return false
+	}
+	return true
+}
+
+// trackPublish updates the job's publish log. Jobs queued without one (or
+// before publish logs existed) have nothing to update.
+func (s *PublishService) trackPublish(ctx context.Context, jobID string, status domain.PublishStatus, externalURL string, errorMessage string) {
+	if s.publishLogs == nil || jobID == "" {
+		return
+	}
+	err := s.publishLogs.UpdatePublishStatus(ctx, jobID, status, externalURL, errorMessage)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("⚠️ Failed to update publish log of job %s: %v", jobID, err)
+	}
+}
+
+// linkArtifacts points the job's publish log at the failed attempt's artifacts.
+// The artifacts bucket is private, so they are linked through the admin API,
+// which hands out short-lived download URLs. Artifacts saved to the worker's own
+// disk aren't linked: nobody reading the publish log can open them.
+func (s *PublishService) linkArtifacts(ctx context.Context, jobID string, record *domain.PublishLog, savedTo string) {
+	if s.publishLogs == nil || jobID == "" || record == nil || !strings.HasPrefix(savedTo, "s3://") {
+		return
+	}
+	link := fmt.Sprintf("/api/admin/publish/%s/attempts/%d/artifacts", jobID, record.Attempts)
+	err := s.publishLogs.AttachPublishArtifacts(ctx, jobID, link)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("⚠️ Failed to link the artifacts of job %s: %v", jobID, err)
+	}
+}
+
+// artifactsKey scopes a publish attempt's failure artifacts to the job, one directory per attempt
+func artifactsKey(jobID string, record *domain.PublishLog) string {
+	if jobID == "" {
+		return fmt.Sprintf("publish/unscoped/%d", time.Now().UnixNano())
+	}
+	if record == nil {
+		return "publish/" + jobID
+	}
+	return PublishArtifactsKey(jobID, record.Attempts)
+}
+
+// PublishArtifactsKey is where the failure artifacts of one attempt at a publish job are saved
+func PublishArtifactsKey(jobID string, attempt int) string {
+	return fmt.Sprintf("publish/%s/attempt-%d", jobID, attempt)
+}
+
+// CredentialsWorkspace returns the workspace whose credentials the task should use
+func (p PublishPayload) CredentialsWorkspace() string {
+	if p.WorkspaceID != "" {
+		return p.WorkspaceID
+	}
+	return p.UserID
+}
+
+// Helper to fetch credentials from DB and unmarshal them, along with the account they belong to
+func (s *PublishService) fetchCredentials(ctx context.Context, workspaceID string, platform string, accountID string) (map[string]string, string, error) {
+	var cred *domain.UserCredential
+	var err error
+	if accountID != "" {
+		cred, err = s.credsRepo.GetCredentialsByID(ctx, workspaceID, accountID)
+		if err == nil && cred != nil && cred.Platform != platform {
+			return nil, envAccount, fmt.Errorf("%w: account %s is not a %s account", errNoCredentials, accountID, platform)
+		}
+		if err == nil && cred == nil {
+			return nil, envAccount, fmt.Errorf("%w: workspace %s has no account %s", errNoCredentials, workspaceID, accountID)
+		}
+	} else {
+		cred, err = s.credsRepo.GetCredentials(ctx, workspaceID, platform)
+	}
 	if err != nil {
-		return nil, err
+		return nil, envAccount, err
 	}
 	if cred == nil {
-		return nil, fmt.Errorf("credentials not found")
+		return nil, envAccount, fmt.Errorf("%w: workspace %s has no %s account", errNoCredentials, workspaceID, platform)
 	}
 
 	var credsMap map[string]string
 	if err := json.Unmarshal(cred.Credentials, &credsMap); err != nil {
-		return nil, err
+		return nil, envAccount, err
 	}
-	return credsMap, nil
+	return credsMap, rateAccount(cred), nil
 }
 
+// errNoCredentials means the workspace has no usable account for the job
+var errNoCredentials = errors.New("credentials not found")
+
 // Helper to get credential from map or env
 func getCredential(creds map[string]string, key, envVar string) string {
 	if val, ok := creds[key]; ok && val != "" {
@@ -154,3 +393,25 @@ func getCredential(creds map[string]string, key, envVar string) string {
 	}
 	return os.Getenv(envVar)
 }
+
+// envAccount stands for the account configured in the environment, which
+// credentials fall back to when a workspace has none of its own
+const envAccount = "env"
+
+// rateAccount is the account the platform rate limits of actions with cred count against
+func rateAccount(cred *domain.UserCredential) string {
+	if cred == nil {
+		return envAccount
+	}
+	return cred.ID
+}
+
+// deferIfLimited turns an action refused by the rate limits into a deferred job,
+// which runs again once the account is allowed to act
+func deferIfLimited(err error) error {
+	if wait, ok := throttle.RetryAfter(err); ok {
+		log.Printf("⏳ %v", err)
+		return queue.Defer(wait, err)
+	}
+	return err
+}
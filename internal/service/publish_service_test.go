@@ -8,10 +8,13 @@ import (
 	"testing"
 
 	"postificus/internal/domain"
-	_ "postificus/internal/storage"
+	"postificus/internal/queue"
+	"postificus/internal/storage"
+	"postificus/internal/throttle"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockCredentialsRepository
@@ -36,6 +39,22 @@ func (m *MockCredentialsRepository) DeleteCredentials(ctx context.Context, userI
 	return nil
 }
 
+func (m *MockCredentialsRepository) GetAllCredentials(ctx context.Context, workspaceID string) ([]domain.UserCredential, error) {
+	return nil, nil
+}
+
+func (m *MockCredentialsRepository) GetCredentialsByID(ctx context.Context, workspaceID string, id string) (*domain.UserCredential, error) {
+	args := m.Called(ctx, workspaceID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserCredential), args.Error(1)
+}
+
+func (m *MockCredentialsRepository) DeleteCredentialsByID(ctx context.Context, workspaceID string, id string) error {
+	return nil
+}
+
 // Mock Automation (Swap function)
 // In a real scenario, we'd use an interface. For this test, we assume the browser calls will fail if not mocked or we skip the actual browser part if possible.
 // However, since we can't easily swap package-level functions in Go without them being variables,
@@ -51,7 +70,7 @@ func TestPublishService_HandlePublishTask_CredsError(t *testing.T) {
 	os.Unsetenv("MEDIUM_XSRF")
 
 	mockRepo := new(MockCredentialsRepository)
-	svc := NewPublishService(mockRepo)
+	svc := NewPublishService(mockRepo, nil, nil, nil, nil, nil, nil)
 
 	payload := PublishPayload{
 		UserID:   DefaultUserID(),
@@ -65,7 +84,7 @@ func TestPublishService_HandlePublishTask_CredsError(t *testing.T) {
 	mockRepo.On("GetCredentials", mock.Anything, DefaultUserID(), "medium").Return(nil, errors.New("db error"))
 
 	// Execute
-	err := svc.HandlePublishTask(payloadBytes)
+	err := svc.HandlePublishTask(context.Background(), payloadBytes)
 
 	// Assert
 	assert.Error(t, err)
@@ -81,7 +100,7 @@ func TestPublishService_HandlePublishTask_NoCreds(t *testing.T) {
 	t.Setenv("MEDIUM_XSRF", "")
 
 	mockRepo := new(MockCredentialsRepository)
-	svc := NewPublishService(mockRepo)
+	svc := NewPublishService(mockRepo, nil, nil, nil, nil, nil, nil)
 
 	payload := PublishPayload{
 		UserID:   DefaultUserID(),
@@ -95,7 +114,7 @@ func TestPublishService_HandlePublishTask_NoCreds(t *testing.T) {
 	mockRepo.On("GetCredentials", mock.Anything, DefaultUserID(), "medium").Return(nil, nil)
 
 	// Execute
-	err := svc.HandlePublishTask(payloadBytes)
+	err := svc.HandlePublishTask(context.Background(), payloadBytes)
 	t.Logf("HandlePublishTask returned: %v", err)
 
 	// Assert
@@ -104,3 +123,96 @@ func TestPublishService_HandlePublishTask_NoCreds(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestPublishService_HandlePublishTask_SharedWorkspaceNeverUsesEnvCreds(t *testing.T) {
+	// The operator's own account; publishing with it would post under their name
+	t.Setenv("MEDIUM_UID", "operator")
+	t.Setenv("MEDIUM_SID", "operator")
+	t.Setenv("MEDIUM_XSRF", "operator")
+
+	mockRepo := new(MockCredentialsRepository)
+	svc := NewPublishService(mockRepo, nil, nil, nil, nil, nil, nil)
+	mockRepo.On("GetCredentials", mock.Anything, "team", "medium").Return(nil, nil)
+
+	payload, _ := json.Marshal(PublishPayload{WorkspaceID: "team", UserID: "writer", Platform: "medium", Title: "T", Content: "C"})
+	err := svc.HandlePublishTask(context.Background(), payload)
+
+	assert.True(t, queue.IsPermanent(err), "got %v", err)
+	assert.ErrorContains(t, err, "workspace team has no medium account")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPublishService_HandlePublishTask_MissingAccountFailsPublishLog(t *testing.T) {
+	store := storage.NewMemoryStore()
+	ctx := context.Background()
+	_, err := store.PublishLogs.QueuePublish(ctx, &domain.PublishLog{JobID: "job-1", WorkspaceID: "team", Platform: "medium"},
+		storage.OutboxMessage{Queue: "publish", IdempotencyKey: "job-1", Payload: []byte("{}")})
+	require.NoError(t, err)
+
+	mockRepo := new(MockCredentialsRepository)
+	mockRepo.On("GetCredentials", mock.Anything, "team", "medium").Return(nil, nil)
+	svc := NewPublishService(mockRepo, nil, nil, nil, store.PublishLogs, nil, nil)
+
+	payload, _ := json.Marshal(PublishPayload{JobID: "job-1", WorkspaceID: "team", UserID: "writer", Platform: "medium", Title: "T", Content: "C"})
+	err = svc.HandlePublishTask(ctx, payload)
+	require.True(t, queue.IsPermanent(err), "got %v", err)
+
+	record, err := store.PublishLogs.GetPublishLog(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishFailed, record.Status, "the UI stops polling a failed job")
+	assert.Contains(t, record.Error, "workspace team has no medium account")
+}
+
+func TestPublishService_HandlePublishTask_UnsupportedPlatformSpendsNothing(t *testing.T) {
+	limits := throttle.New(nil, map[string]throttle.PlatformLimits{"linkedin": {PostsPerDay: 1}})
+	mockRepo := new(MockCredentialsRepository) // No expectations: looking up credentials would panic
+	svc := NewPublishService(mockRepo, nil, nil, nil, nil, limits, nil)
+
+	payload, _ := json.Marshal(PublishPayload{UserID: DefaultUserID(), Platform: "linkedin", Title: "T", Content: "C"})
+	err := svc.HandlePublishTask(context.Background(), payload)
+	require.True(t, queue.IsPermanent(err), "got %v", err)
+	assert.ErrorContains(t, err, "linkedin publishing not supported")
+
+	assert.NoError(t, limits.Take(context.Background(), "linkedin", "", throttle.Post), "the day's only post token is still there")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPublishService_LinksOnlyUploadedArtifacts(t *testing.T) {
+	store := storage.NewMemoryStore()
+	ctx := context.Background()
+	entry := &domain.PublishLog{JobID: "job-1", WorkspaceID: "team", Platform: "medium"}
+	_, err := store.PublishLogs.QueuePublish(ctx, entry, storage.OutboxMessage{Queue: "publish", IdempotencyKey: "job-1", Payload: []byte("{}")})
+	require.NoError(t, err)
+	record, err := store.PublishLogs.ClaimPublish(ctx, "job-1", publishClaimStale)
+	require.NoError(t, err)
+	svc := NewPublishService(new(MockCredentialsRepository), nil, nil, nil, store.PublishLogs, nil, nil)
+
+	// On the worker's own disk nobody reading the log could open them
+	svc.linkArtifacts(ctx, "job-1", record, "file:///srv/failure-artifacts/publish/job-1/attempt-1/")
+	logged, err := store.PublishLogs.GetPublishLog(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Empty(t, logged.ArtifactsURL)
+
+	svc.linkArtifacts(ctx, "job-1", record, "s3://postificus-artifacts/publish/job-1/attempt-1/")
+	logged, err = store.PublishLogs.GetPublishLog(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/admin/publish/job-1/attempts/1/artifacts", logged.ArtifactsURL)
+}
+
+func TestPublishService_HandlePublishTask_NamedAccountMustResolve(t *testing.T) {
+	t.Setenv("MEDIUM_UID", "operator")
+	t.Setenv("MEDIUM_SID", "operator")
+	t.Setenv("MEDIUM_XSRF", "operator")
+
+	mockRepo := new(MockCredentialsRepository)
+	svc := NewPublishService(mockRepo, nil, nil, nil, nil, nil, nil)
+	mockRepo.On("GetCredentialsByID", mock.Anything, DefaultUserID(), "gone").Return(nil, nil)
+	mockRepo.On("GetCredentialsByID", mock.Anything, DefaultUserID(), "devto-1").Return(&domain.UserCredential{ID: "devto-1", Platform: "devto"}, nil)
+
+	for account, want := range map[string]string{"gone": "has no account gone", "devto-1": "is not a medium account"} {
+		payload, _ := json.Marshal(PublishPayload{UserID: DefaultUserID(), AccountID: account, Platform: "medium", Title: "T", Content: "C"})
+		err := svc.HandlePublishTask(context.Background(), payload)
+		assert.True(t, queue.IsPermanent(err), "%s: got %v", account, err)
+		assert.ErrorContains(t, err, want)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchQueryLen  = 256
+)
+
+type SearchService struct {
+	repo       storage.SearchRepository
+	workspaces *WorkspaceService
+}
+
+func NewSearchService(repo storage.SearchRepository, workspaces *WorkspaceService) *SearchService {
+	return &SearchService{repo: repo, workspaces: workspaces}
+}
+
+// Search ranks the workspace's drafts and the user's synced posts against query.Text
+func (s *SearchService) Search(ctx context.Context, query storage.SearchQuery) ([]domain.SearchResult, error) {
+	if err := s.workspaces.Authorize(ctx, query.WorkspaceID, query.UserID, domain.PermViewDrafts); err != nil {
+		return nil, err
+	}
+
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, fmt.Errorf("%w: query is required", ErrInvalidInput)
+	}
+	if len(query.Text) > maxSearchQueryLen {
+		return nil, fmt.Errorf("%w: query is longer than %d characters", ErrInvalidInput, maxSearchQueryLen)
+	}
+
+	for i, platform := range query.Platforms {
+		query.Platforms[i] = strings.ToLower(strings.TrimSpace(platform))
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	query.Limit = min(query.Limit, maxSearchLimit)
+	query.Offset = max(query.Offset, 0)
+
+	return s.repo.Search(ctx, query)
+}
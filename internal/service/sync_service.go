@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	automation "postificus/internal/browser"
 	"postificus/internal/domain"
+	"postificus/internal/queue"
+	"postificus/internal/throttle"
 	"time"
 )
 
@@ -15,8 +18,9 @@ const (
 )
 
 type SyncPlatformPayload struct {
-	UserID   string `json:"user_id"`
-	Platform string `json:"platform"`
+	UserID      string `json:"user_id"`
+	WorkspaceID string `json:"workspace_id,omitempty"` // Credentials owner; defaults to the user's personal workspace
+	Platform    string `json:"platform"`
 }
 
 // SyncService handles background synchronization tasks.
@@ -31,15 +35,19 @@ func NewSyncService(activityService *ActivityService) *SyncService {
 }
 
 // HandleSyncPlatformActivity is the handler for the sync task.
-func (s *SyncService) HandleSyncPlatformActivity(payload []byte) error {
-	ctx := context.Background()
+func (s *SyncService) HandleSyncPlatformActivity(ctx context.Context, payload []byte) error {
 	var p SyncPlatformPayload
 	if err := json.Unmarshal(payload, &p); err != nil {
-		return fmt.Errorf("json.Unmarshal failed: %v", err)
+		return queue.Permanent(fmt.Errorf("json.Unmarshal failed: %v", err))
 	}
 
 	log.Printf("🔄 [Worker] Starting sync for User %s - Platform: %s", p.UserID, p.Platform)
 
+	workspaceID := p.WorkspaceID
+	if workspaceID == "" {
+		workspaceID = p.UserID
+	}
+
 	var posts []domain.UnifiedPost
 	var err error
 
@@ -47,7 +55,7 @@ func (s *SyncService) HandleSyncPlatformActivity(payload []byte) error {
 	switch p.Platform {
 	case "medium":
 		var mediumPosts []automation.MediumPost // Explicit declaration
-		mediumPosts, err = s.activityService.FetchLiveMediumActivity(ctx, p.UserID, 20)
+		mediumPosts, err = s.activityService.FetchLiveMediumActivity(ctx, workspaceID, 20)
 		if err == nil {
 			for _, mp := range mediumPosts {
 				posts = append(posts, domain.UnifiedPost{
@@ -64,7 +72,7 @@ func (s *SyncService) HandleSyncPlatformActivity(payload []byte) error {
 		}
 	case "devto":
 		var devtoPosts []automation.DevtoPost // Explicit declaration
-		devtoPosts, err = s.activityService.FetchLiveDevtoActivity(ctx, p.UserID, 20)
+		devtoPosts, err = s.activityService.FetchLiveDevtoActivity(ctx, workspaceID, 20)
 		if err == nil {
 			for _, dp := range devtoPosts {
 				views := 0
@@ -105,9 +113,13 @@ func (s *SyncService) HandleSyncPlatformActivity(payload []byte) error {
 			err = fmt.Errorf("devto fetch failed: %w", err)
 		}
 	default:
-		return fmt.Errorf("unknown platform: %s", p.Platform)
+		return queue.Permanent(fmt.Errorf("unknown platform: %s", p.Platform))
 	}
 
+	if errors.Is(err, throttle.ErrLimited) {
+		// The account has scraped enough for now; sync once it may again
+		return deferIfLimited(err)
+	}
 	if err != nil {
 		log.Printf("❌ [Worker] Sync failed for %s: %v", p.Platform, err)
 		return err
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+)
+
+// TagService manages how a workspace's tags are renamed per platform
+type TagService struct {
+	repo       storage.TagMappingRepository
+	workspaces *WorkspaceService
+}
+
+func NewTagService(repo storage.TagMappingRepository, workspaces *WorkspaceService) *TagService {
+	return &TagService{repo: repo, workspaces: workspaces}
+}
+
+func (s *TagService) ListMappings(ctx context.Context, workspaceID, userID, platform string) ([]domain.TagMapping, error) {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTagMappings(ctx, workspaceID, strings.ToLower(strings.TrimSpace(platform)))
+}
+
+// SaveMapping publishes tag as platformTag on the platform from now on
+func (s *TagService) SaveMapping(ctx context.Context, userID string, mapping domain.TagMapping) (*domain.TagMapping, error) {
+	if err := s.workspaces.Authorize(ctx, mapping.WorkspaceID, userID, domain.PermEditDrafts); err != nil {
+		return nil, err
+	}
+
+	mapping.Platform = strings.ToLower(strings.TrimSpace(mapping.Platform))
+	if _, ok := platformTagLimits[mapping.Platform]; !ok {
+		return nil, fmt.Errorf("%w: unsupported platform %q", ErrInvalidInput, mapping.Platform)
+	}
+	tags := domain.NormalizeTags([]string{mapping.Tag, mapping.PlatformTag})
+	switch {
+	case strings.TrimSpace(mapping.Tag) == "" || strings.TrimSpace(mapping.PlatformTag) == "":
+		return nil, fmt.Errorf("%w: tag and platform_tag are required", ErrInvalidInput)
+	case len(tags) == 1:
+		return nil, fmt.Errorf("%w: tag is mapped to itself", ErrInvalidInput)
+	}
+	mapping.Tag, mapping.PlatformTag = tags[0], tags[1]
+
+	if err := s.repo.SaveTagMapping(ctx, mapping); err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (s *TagService) DeleteMapping(ctx context.Context, workspaceID, userID, platform, tag string) error {
+	if err := s.workspaces.Authorize(ctx, workspaceID, userID, domain.PermEditDrafts); err != nil {
+		return err
+	}
+	normalized := domain.NormalizeTags([]string{tag})
+	if len(normalized) == 0 {
+		return fmt.Errorf("%w: tag is required", ErrInvalidInput)
+	}
+	return s.repo.DeleteTagMapping(ctx, workspaceID, strings.ToLower(strings.TrimSpace(platform)), normalized[0])
+}
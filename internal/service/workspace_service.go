@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"postificus/internal/domain"
+	"postificus/internal/storage"
+)
+
+var (
+	ErrForbidden    = errors.New("forbidden")
+	ErrInvalidRole  = errors.New("invalid role")
+	ErrLastOwner    = errors.New("workspace must keep at least one owner")
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// WorkspaceService manages workspaces, memberships and permission checks.
+type WorkspaceService struct {
+	workspaceRepo storage.WorkspaceRepository
+}
+
+func NewWorkspaceService(workspaceRepo storage.WorkspaceRepository) *WorkspaceService {
+	return &WorkspaceService{workspaceRepo: workspaceRepo}
+}
+
+// RoleOf returns the user's role in a workspace, or "" when they are not a member.
+// A user's personal workspace (ID == user ID) always resolves to owner.
+func (s *WorkspaceService) RoleOf(ctx context.Context, workspaceID string, userID string) (domain.Role, error) {
+	if workspaceID == userID {
+		return domain.RoleOwner, nil
+	}
+
+	m, err := s.workspaceRepo.GetMembership(ctx, workspaceID, userID)
+	if err != nil {
+		return "", err
+	}
+	if m == nil {
+		return "", nil
+	}
+	return m.Role, nil
+}
+
+// Authorize returns ErrForbidden unless the user's role grants the permission
+func (s *WorkspaceService) Authorize(ctx context.Context, workspaceID string, userID string, perm domain.Permission) error {
+	role, err := s.RoleOf(ctx, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !role.Can(perm) {
+		return fmt.Errorf("%w: %s requires %s", ErrForbidden, workspaceID, perm)
+	}
+	return nil
+}
+
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, userID string, name string) (*domain.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: workspace name required", ErrInvalidInput)
+	}
+
+	workspace := &domain.Workspace{Name: name}
+	if err := s.workspaceRepo.CreateWorkspace(ctx, workspace, userID); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (s *WorkspaceService) ListWorkspaces(ctx context.Context, userID string) ([]domain.Workspace, error) {
+	return s.workspaceRepo.ListWorkspaces(ctx, userID)
+}
+
+func (s *WorkspaceService) ListMembers(ctx context.Context, workspaceID string, userID string) ([]domain.Membership, error) {
+	if err := s.Authorize(ctx, workspaceID, userID, domain.PermViewDrafts); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListMembers(ctx, workspaceID)
+}
+
+// SetMemberRole adds a member or changes their role (owners only)
+func (s *WorkspaceService) SetMemberRole(ctx context.Context, workspaceID string, actorID string, memberID string, role domain.Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+	if err := s.Authorize(ctx, workspaceID, actorID, domain.PermManageMembers); err != nil {
+		return err
+	}
+	if role != domain.RoleOwner {
+		if err := s.ensureAnotherOwner(ctx, workspaceID, memberID); err != nil {
+			return err
+		}
+	}
+
+	return s.workspaceRepo.SaveMember(ctx, &domain.Membership{
+		WorkspaceID: workspaceID,
+		UserID:      memberID,
+		Role:        role,
+	})
+}
+
+// RemoveMember removes a member (owners only, or a member leaving on their own)
+func (s *WorkspaceService) RemoveMember(ctx context.Context, workspaceID string, actorID string, memberID string) error {
+	if actorID != memberID {
+		if err := s.Authorize(ctx, workspaceID, actorID, domain.PermManageMembers); err != nil {
+			return err
+		}
+	}
+	if err := s.ensureAnotherOwner(ctx, workspaceID, memberID); err != nil {
+		return err
+	}
+	return s.workspaceRepo.RemoveMember(ctx, workspaceID, memberID)
+}
+
+// ensureAnotherOwner prevents demoting or removing the last owner of a workspace
+func (s *WorkspaceService) ensureAnotherOwner(ctx context.Context, workspaceID string, memberID string) error {
+	members, err := s.workspaceRepo.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	isOwner := false
+	owners := 0
+	for _, m := range members {
+		if m.Role == domain.RoleOwner {
+			owners++
+			if m.UserID == memberID {
+				isOwner = true
+			}
+		}
+	}
+	if isOwner && owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"postificus/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWorkspaceRepository
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) CreateWorkspace(ctx context.Context, workspace *domain.Workspace, ownerID string) error {
+	return m.Called(ctx, workspace, ownerID).Error(0)
+}
+
+func (m *MockWorkspaceRepository) ListWorkspaces(ctx context.Context, userID string) ([]domain.Workspace, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) GetMembership(ctx context.Context, workspaceID string, userID string) (*domain.Membership, error) {
+	args := m.Called(ctx, workspaceID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Membership), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]domain.Membership, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).([]domain.Membership), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) SaveMember(ctx context.Context, member *domain.Membership) error {
+	return m.Called(ctx, member).Error(0)
+}
+
+func (m *MockWorkspaceRepository) RemoveMember(ctx context.Context, workspaceID string, userID string) error {
+	return m.Called(ctx, workspaceID, userID).Error(0)
+}
+
+func TestWorkspaceService_Authorize_ByRole(t *testing.T) {
+	mockRepo := new(MockWorkspaceRepository)
+	svc := NewWorkspaceService(mockRepo)
+
+	mockRepo.On("GetMembership", mock.Anything, "team", "viewer").Return(&domain.Membership{Role: domain.RoleViewer}, nil)
+	mockRepo.On("GetMembership", mock.Anything, "team", "writer").Return(&domain.Membership{Role: domain.RoleWriter}, nil)
+	mockRepo.On("GetMembership", mock.Anything, "team", "stranger").Return(nil, nil)
+
+	ctx := context.Background()
+	assert.NoError(t, svc.Authorize(ctx, "team", "viewer", domain.PermViewDrafts))
+	assert.True(t, errors.Is(svc.Authorize(ctx, "team", "viewer", domain.PermEditDrafts), ErrForbidden))
+	assert.NoError(t, svc.Authorize(ctx, "team", "writer", domain.PermPublish))
+	assert.True(t, errors.Is(svc.Authorize(ctx, "team", "writer", domain.PermManageCredentials), ErrForbidden))
+	assert.True(t, errors.Is(svc.Authorize(ctx, "team", "stranger", domain.PermViewDrafts), ErrForbidden))
+
+	// Personal workspace never hits the repository
+	assert.NoError(t, svc.Authorize(ctx, "solo", "solo", domain.PermManageMembers))
+}
+
+func TestWorkspaceService_SetMemberRole_KeepsLastOwner(t *testing.T) {
+	mockRepo := new(MockWorkspaceRepository)
+	svc := NewWorkspaceService(mockRepo)
+
+	mockRepo.On("GetMembership", mock.Anything, "team", "alice").Return(&domain.Membership{Role: domain.RoleOwner}, nil)
+	mockRepo.On("ListMembers", mock.Anything, "team").Return([]domain.Membership{
+		{WorkspaceID: "team", UserID: "alice", Role: domain.RoleOwner},
+		{WorkspaceID: "team", UserID: "bob", Role: domain.RoleWriter},
+	}, nil)
+
+	err := svc.SetMemberRole(context.Background(), "team", "alice", "alice", domain.RoleEditor)
+	assert.ErrorIs(t, err, ErrLastOwner)
+
+	err = svc.SetMemberRole(context.Background(), "team", "alice", "bob", "admin")
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	mockRepo.AssertNotCalled(t, "SaveMember", mock.Anything, mock.Anything)
+}
@@ -8,39 +8,56 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// CredentialsRepository defines the interface for credential storage
+// DefaultAccountName is used when credentials don't carry an account_name
+const DefaultAccountName = "default"
+
+// CredentialsRepository defines the interface for credential storage.
+// Credentials are owned by a workspace, which may hold several accounts per platform.
 type CredentialsRepository interface {
-	SaveCredentials(ctx context.Context, userID string, platform string, creds map[string]string) error
-	GetCredentials(ctx context.Context, userID string, platform string) (*domain.UserCredential, error)
-	DeleteCredentials(ctx context.Context, userID string, platform string) error
+	SaveCredentials(ctx context.Context, workspaceID string, platform string, creds map[string]string) error
+	GetCredentials(ctx context.Context, workspaceID string, platform string) (*domain.UserCredential, error)
+	DeleteCredentials(ctx context.Context, workspaceID string, platform string) error
+	GetAllCredentials(ctx context.Context, workspaceID string) ([]domain.UserCredential, error)
+	GetCredentialsByID(ctx context.Context, workspaceID string, id string) (*domain.UserCredential, error)
+	DeleteCredentialsByID(ctx context.Context, workspaceID string, id string) error
 }
 
 // PostgresCredentialsRepository implements CredentialsRepository
 type PostgresCredentialsRepository struct {
+	db *pgxpool.Pool
 }
 
 // NewCredentialsRepository creates a new instance
-func NewCredentialsRepository() *PostgresCredentialsRepository {
-	return &PostgresCredentialsRepository{}
+func NewCredentialsRepository(db *pgxpool.Pool) *PostgresCredentialsRepository {
+	return &PostgresCredentialsRepository{db: db}
+}
+
+// AccountNameFor returns the account key used to tell apart several accounts on one platform
+func AccountNameFor(creds map[string]string) string {
+	if name := creds["account_name"]; name != "" {
+		return name
+	}
+	return DefaultAccountName
 }
 
-// SaveCredentials upserts user credentials into the database
-func (r *PostgresCredentialsRepository) SaveCredentials(ctx context.Context, userID string, platform string, creds map[string]string) error {
+// SaveCredentials upserts credentials for one platform account of a workspace
+func (r *PostgresCredentialsRepository) SaveCredentials(ctx context.Context, workspaceID string, platform string, creds map[string]string) error {
 	credsJSON, err := json.Marshal(creds)
 	if err != nil {
 		return fmt.Errorf("failed to marshal credentials: %w", err)
 	}
 
 	query := `
-		INSERT INTO user_credentials (user_id, platform, credentials, updated_at)
-		VALUES ($1, $2, $3, NOW())
-		ON CONFLICT (user_id, platform) 
-		DO UPDATE SET credentials = $3, updated_at = NOW();
+		INSERT INTO user_credentials (workspace_id, platform, account_name, credentials, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (workspace_id, platform, account_name)
+		DO UPDATE SET credentials = EXCLUDED.credentials, updated_at = NOW();
 	`
 
-	_, err = DB.Exec(ctx, query, userID, platform, credsJSON)
+	_, err = r.db.Exec(ctx, query, workspaceID, platform, AccountNameFor(creds), credsJSON)
 	if err != nil {
 		return fmt.Errorf("failed to execute save query: %w", err)
 	}
@@ -48,19 +65,73 @@ func (r *PostgresCredentialsRepository) SaveCredentials(ctx context.Context, use
 	return nil
 }
 
-func (r *PostgresCredentialsRepository) DeleteCredentials(ctx context.Context, userID string, platform string) error {
-	_, err := DB.Exec(ctx, `DELETE FROM user_credentials WHERE user_id = $1 AND platform = $2`, userID, platform)
+// DeleteCredentials disconnects every account of a platform
+func (r *PostgresCredentialsRepository) DeleteCredentials(ctx context.Context, workspaceID string, platform string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM user_credentials WHERE workspace_id = $1 AND platform = $2`, workspaceID, platform)
+	return err
+}
+
+// DeleteCredentialsByID disconnects a single account
+func (r *PostgresCredentialsRepository) DeleteCredentialsByID(ctx context.Context, workspaceID string, id string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM user_credentials WHERE workspace_id = $1 AND id = $2`, workspaceID, id)
 	return err
 }
 
-func (r *PostgresCredentialsRepository) GetCredentials(ctx context.Context, userID string, platform string) (*domain.UserCredential, error) {
-	var credsJSON []byte
-	var updatedAt time.Time
+// GetCredentials returns the most recently updated account for a platform
+func (r *PostgresCredentialsRepository) GetCredentials(ctx context.Context, workspaceID string, platform string) (*domain.UserCredential, error) {
+	query := `
+		SELECT id, workspace_id, platform, account_name, credentials, updated_at
+		FROM user_credentials
+		WHERE workspace_id = $1 AND platform = $2
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`
+	return scanCredential(r.db.QueryRow(ctx, query, workspaceID, platform))
+}
+
+// GetCredentialsByID returns a specific account
+func (r *PostgresCredentialsRepository) GetCredentialsByID(ctx context.Context, workspaceID string, id string) (*domain.UserCredential, error) {
+	query := `
+		SELECT id, workspace_id, platform, account_name, credentials, updated_at
+		FROM user_credentials
+		WHERE workspace_id = $1 AND id = $2
+	`
+	return scanCredential(r.db.QueryRow(ctx, query, workspaceID, id))
+}
+
+// GetAllCredentials lists every connected account of a workspace
+func (r *PostgresCredentialsRepository) GetAllCredentials(ctx context.Context, workspaceID string) ([]domain.UserCredential, error) {
+	query := `
+		SELECT id, workspace_id, platform, account_name, credentials, updated_at
+		FROM user_credentials
+		WHERE workspace_id = $1
+		ORDER BY platform, updated_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	defer rows.Close()
+
+	creds := []domain.UserCredential{}
+	for rows.Next() {
+		cred, err := scanCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, *cred)
+	}
+	return creds, rows.Err()
+}
 
-	// We select UpdatedAt just to fill the struct properly
-	query := `SELECT credentials, updated_at FROM user_credentials WHERE user_id = $1 AND platform = $2`
+func scanCredential(row pgx.Row) (*domain.UserCredential, error) {
+	var (
+		cred      domain.UserCredential
+		credsJSON []byte
+		updatedAt time.Time
+	)
 
-	err := DB.QueryRow(ctx, query, userID, platform).Scan(&credsJSON, &updatedAt)
+	err := row.Scan(&cred.ID, &cred.WorkspaceID, &cred.Platform, &cred.AccountName, &credsJSON, &updatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil // Not found is not an error in this context, just nil
@@ -68,10 +139,7 @@ func (r *PostgresCredentialsRepository) GetCredentials(ctx context.Context, user
 		return nil, fmt.Errorf("failed to fetch credentials: %w", err)
 	}
 
-	return &domain.UserCredential{
-		UserID:      userID,
-		Platform:    platform,
-		Credentials: json.RawMessage(credsJSON),
-		UpdatedAt:   updatedAt,
-	}, nil
+	cred.Credentials = json.RawMessage(credsJSON)
+	cred.UpdatedAt = updatedAt
+	return &cred, nil
 }
@@ -2,7 +2,7 @@ package storage
 
 import (
 	"context"
-	_ "embed"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -13,10 +13,39 @@ import (
 
 var DB *pgxpool.Pool
 
-//go:embed schema.sql
-var schemaSQL string
+// ErrNotFound is returned when a scoped lookup matches no row
+var ErrNotFound = errors.New("not found")
 
+// ErrVersionConflict is returned when a conditional write loses to a newer version
+var ErrVersionConflict = errors.New("version conflict")
+
+// InitDB connects and applies pending migrations.
+// Set DB_AUTO_MIGRATE=false to leave migrations to the `migrate` subcommand.
 func InitDB() error {
+	if err := ConnectDB(); err != nil {
+		return err
+	}
+	if os.Getenv("DB_AUTO_MIGRATE") == "false" {
+		log.Println("⏭️ Skipping migrations (DB_AUTO_MIGRATE=false)")
+		return nil
+	}
+
+	migrator, err := NewMigrator(DB)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	log.Printf("✅ Schema up to date (%d migrations applied)", len(applied))
+	return nil
+}
+
+// ConnectDB opens the connection pool without touching the schema
+func ConnectDB() error {
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
 		return fmt.Errorf("DATABASE_URL is not set")
@@ -46,14 +75,6 @@ func InitDB() error {
 	}
 
 	log.Println("✅ Connected to PostgreSQL")
-
-	// Auto-Migrate
-	if _, err := DB.Exec(ctx, schemaSQL); err != nil {
-		log.Printf("Warning: Failed to apply schema: %v", err)
-	} else {
-		log.Println("✅ Schema applied successfully")
-	}
-
 	return nil
 }
 
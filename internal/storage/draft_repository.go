@@ -4,45 +4,96 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"postificus/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// AnyVersion makes SaveDraft overwrite regardless of the stored version
+const AnyVersion int64 = -1
+
 type DraftRepository interface {
-	SaveDraft(ctx context.Context, draft *domain.Draft) error
-	GetDraft(ctx context.Context, id string, userID string) (*domain.Draft, error)
+	// SaveDraft upserts the draft if its stored version equals expectedVersion (or AnyVersion)
+	// and sets draft.Version to the new version. A stale version returns ErrVersionConflict.
+	SaveDraft(ctx context.Context, draft *domain.Draft, expectedVersion int64) error
+	GetDraft(ctx context.Context, id string, workspaceID string) (*domain.Draft, error)
 	UpdateDashboardCache(ctx context.Context, draft *domain.Draft) error
+	UpdateWorkflow(ctx context.Context, draft *domain.Draft) error
+	// ListDrafts returns a page of drafts (without content) and the total number of matches
+	ListDrafts(ctx context.Context, filter DraftFilter) ([]domain.Draft, int, error)
+	// TrashDraft soft-deletes a draft and removes it from the dashboard
+	TrashDraft(ctx context.Context, id string, workspaceID string) error
+	// UntrashDraft brings a draft back from the trash
+	UntrashDraft(ctx context.Context, id string, workspaceID string) error
+	// ListDueScheduled returns up to limit scheduled drafts (without content) of
+	// every workspace whose time had come by before, the earliest first
+	ListDueScheduled(ctx context.Context, before time.Time, limit int) ([]domain.Draft, error)
 }
 
-type PostgresDraftRepository struct{}
+type PostgresDraftRepository struct {
+	db *pgxpool.Pool
+}
 
-func NewDraftRepository() *PostgresDraftRepository {
-	return &PostgresDraftRepository{}
+func NewDraftRepository(db *pgxpool.Pool) *PostgresDraftRepository {
+	return &PostgresDraftRepository{db: db}
 }
 
-func (r *PostgresDraftRepository) SaveDraft(ctx context.Context, draft *domain.Draft) error {
+func (r *PostgresDraftRepository) SaveDraft(ctx context.Context, draft *domain.Draft, expectedVersion int64) error {
 	publishTargetsJSON, err := json.Marshal(draft.PublishTargets)
 	if err != nil {
 		return fmt.Errorf("failed to marshal publish targets: %w", err)
 	}
 
+	// The WHERE clause keeps a draft from being overwritten through another workspace
+	// or from a stale copy
 	query := `
-		INSERT INTO drafts (id, user_id, title, content, cover_image, publish_targets, last_saved_at, is_published)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), FALSE)
+		INSERT INTO drafts (id, workspace_id, user_id, title, content, cover_image, publish_targets, tags,
+			subtitle, excerpt, series_name, series_order, last_saved_at, is_published, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $9, $10, $11, NULLIF($12, ''), NULLIF($13, 0), NOW(), FALSE, 1)
 		ON CONFLICT (id) DO UPDATE SET
 			title = EXCLUDED.title,
 			content = EXCLUDED.content,
 			cover_image = EXCLUDED.cover_image,
 			publish_targets = EXCLUDED.publish_targets,
-			last_saved_at = NOW();
+			tags = EXCLUDED.tags,
+			subtitle = EXCLUDED.subtitle,
+			excerpt = EXCLUDED.excerpt,
+			series_name = EXCLUDED.series_name,
+			series_order = EXCLUDED.series_order,
+			last_saved_at = NOW(),
+			version = drafts.version + 1
+		WHERE drafts.workspace_id = EXCLUDED.workspace_id
+			AND drafts.deleted_at IS NULL
+			AND ($8 < 0 OR drafts.version = $8)
+		RETURNING version;
 	`
 
-	_, err = DB.Exec(ctx, query, draft.ID, draft.UserID, draft.Title, draft.Content, draft.CoverImage, publishTargetsJSON)
-	if err != nil {
+	tags := draft.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	err = r.db.QueryRow(ctx, query, draft.ID, draft.WorkspaceID, draft.UserID, draft.Title, draft.Content, draft.CoverImage, publishTargetsJSON, expectedVersion, tags,
+		draft.Subtitle, draft.Excerpt, draft.Series, draft.SeriesOrder).
+		Scan(&draft.Version)
+	if err == nil {
+		return nil
+	}
+	if err != pgx.ErrNoRows {
 		return fmt.Errorf("failed to execute save query: %w", err)
 	}
-	return nil
+
+	// Nothing written: tell a stale version apart from a draft owned by another workspace or trashed
+	var workspaceID string
+	var trashed bool
+	err = r.db.QueryRow(ctx, `SELECT workspace_id, deleted_at IS NOT NULL FROM drafts WHERE id = $1`, draft.ID).Scan(&workspaceID, &trashed)
+	if err != nil || workspaceID != draft.WorkspaceID || trashed {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
 }
 
 func (r *PostgresDraftRepository) UpdateDashboardCache(ctx context.Context, draft *domain.Draft) error {
@@ -57,48 +108,259 @@ func (r *PostgresDraftRepository) UpdateDashboardCache(ctx context.Context, draf
             published_at = EXCLUDED.published_at,
             last_synced_at = NOW();
     `
+	status := string(draft.Status)
+	if status == "" {
+		status = string(domain.StatusDraft)
+	}
 	// Platform is "postificus" for local drafts
-	_, err := DB.Exec(ctx, unifiedQuery, draft.UserID, "postificus", draft.ID, draft.Title, fmt.Sprintf("/editor?draft=%s", draft.ID), "draft")
+	_, err := r.db.Exec(ctx, unifiedQuery, draft.UserID, "postificus", draft.ID, draft.Title, fmt.Sprintf("/editor?draft=%s", draft.ID), status)
 	if err != nil {
 		return fmt.Errorf("failed to update dashboard cache: %w", err)
 	}
 	return nil
 }
 
-func (r *PostgresDraftRepository) GetDraft(ctx context.Context, id string, userID string) (*domain.Draft, error) {
-	query := `
-		SELECT title, content, cover_image, publish_targets, last_saved_at, is_published
-		FROM drafts
-		WHERE id = $1 AND user_id = $2
-	`
+// draftColumns is the SELECT list read by scanDraft; content is optional for listings
+func draftColumns(withContent bool) string {
+	content := "content"
+	if !withContent {
+		content = "''"
+	}
+	return `id, workspace_id, user_id, title, ` + content + `, cover_image, publish_targets, last_saved_at, is_published,
+		status, COALESCE(reviewer_id::text, ''), scheduled_at, version, tags, deleted_at,
+		COALESCE(subtitle, ''), COALESCE(excerpt, ''), COALESCE(series_name, ''), COALESCE(series_order, 0)`
+}
 
+func scanDraft(row pgx.Row) (*domain.Draft, error) {
 	var (
-		title          string
-		content        string
-		coverImage     string
+		draft          domain.Draft
+		title          *string
+		content        *string
+		coverImage     *string
 		publishTargets []byte
-		lastSavedAt    time.Time
-		isPublished    bool
+		lastSavedAt    *time.Time
+		status         string
 	)
 
-	err := DB.QueryRow(ctx, query, id, userID).Scan(&title, &content, &coverImage, &publishTargets, &lastSavedAt, &isPublished)
+	err := row.Scan(&draft.ID, &draft.WorkspaceID, &draft.UserID, &title, &content, &coverImage, &publishTargets, &lastSavedAt, &draft.IsPublished,
+		&status, &draft.ReviewerID, &draft.ScheduledAt, &draft.Version, &draft.Tags, &draft.DeletedAt,
+		&draft.Subtitle, &draft.Excerpt, &draft.Series, &draft.SeriesOrder)
 	if err != nil {
 		return nil, err
 	}
 
-	targets := []string{}
+	if title != nil {
+		draft.Title = *title
+	}
+	if content != nil {
+		draft.Content = *content
+	}
+	if coverImage != nil {
+		draft.CoverImage = *coverImage
+	}
+	if lastSavedAt != nil {
+		draft.LastSavedAt = *lastSavedAt
+	}
+	draft.PublishTargets = []string{}
 	if len(publishTargets) > 0 {
-		_ = json.Unmarshal(publishTargets, &targets)
-	}
-
-	return &domain.Draft{
-		ID:             id,
-		UserID:         userID,
-		Title:          title,
-		Content:        content,
-		CoverImage:     coverImage,
-		PublishTargets: targets,
-		LastSavedAt:    lastSavedAt,
-		IsPublished:    isPublished,
-	}, nil
+		_ = json.Unmarshal(publishTargets, &draft.PublishTargets)
+	}
+	if draft.Tags == nil {
+		draft.Tags = []string{}
+	}
+	draft.Status = domain.DraftStatus(status)
+	return &draft, nil
+}
+
+func (r *PostgresDraftRepository) GetDraft(ctx context.Context, id string, workspaceID string) (*domain.Draft, error) {
+	query := `SELECT ` + draftColumns(true) + `
+		FROM drafts
+		WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+
+	draft, err := scanDraft(r.db.QueryRow(ctx, query, id, workspaceID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return draft, nil
+}
+
+// UpdateWorkflow persists the editorial state of a draft (status, reviewer, schedule)
+func (r *PostgresDraftRepository) UpdateWorkflow(ctx context.Context, draft *domain.Draft) error {
+	query := `
+		UPDATE drafts
+		SET status = $3,
+			reviewer_id = NULLIF($4, '')::uuid,
+			scheduled_at = $5,
+			is_published = $6
+		WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, draft.ID, draft.WorkspaceID, string(draft.Status), draft.ReviewerID, draft.ScheduledAt, draft.IsPublished)
+	if err != nil {
+		return fmt.Errorf("failed to update workflow: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresDraftRepository) ListDueScheduled(ctx context.Context, before time.Time, limit int) ([]domain.Draft, error) {
+	query := `SELECT ` + draftColumns(false) + `
+		FROM drafts
+		WHERE status = $1 AND scheduled_at <= $2 AND deleted_at IS NULL
+		ORDER BY scheduled_at, id
+		LIMIT $3`
+
+	rows, err := r.db.Query(ctx, query, string(domain.StatusScheduled), before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled drafts: %w", err)
+	}
+	defer rows.Close()
+
+	drafts := []domain.Draft{}
+	for rows.Next() {
+		draft, err := scanDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		drafts = append(drafts, *draft)
+	}
+	return drafts, rows.Err()
+}
+
+// DraftFilter selects and orders drafts for listing
+type DraftFilter struct {
+	WorkspaceID   string
+	Statuses      []domain.DraftStatus
+	Target        string // Publish target platform, e.g. "medium"
+	Tag           string
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Trashed       bool   // List the trash instead of live drafts
+	Sort          string // One of DraftSortColumns' keys
+	Ascending     bool
+	Limit         int
+	Offset        int
+}
+
+// DraftSortColumns maps the public sort keys to columns
+var DraftSortColumns = map[string]string{
+	"updated": "last_saved_at",
+	"title":   "title",
+	"status":  "status",
+	"deleted": "deleted_at",
+}
+
+func (r *PostgresDraftRepository) ListDrafts(ctx context.Context, filter DraftFilter) ([]domain.Draft, int, error) {
+	conditions := []string{"workspace_id = $1"}
+	args := []interface{}{filter.WorkspaceID}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Trashed {
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	} else {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		conditions = append(conditions, "status = ANY("+arg(statuses)+")")
+	}
+	if filter.Target != "" {
+		conditions = append(conditions, "publish_targets ? "+arg(filter.Target))
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, arg(filter.Tag)+" = ANY(tags)")
+	}
+	if filter.UpdatedAfter != nil {
+		conditions = append(conditions, "last_saved_at >= "+arg(*filter.UpdatedAfter))
+	}
+	if filter.UpdatedBefore != nil {
+		conditions = append(conditions, "last_saved_at < "+arg(*filter.UpdatedBefore))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM drafts WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count drafts: %w", err)
+	}
+
+	column, ok := DraftSortColumns[filter.Sort]
+	if !ok {
+		column = DraftSortColumns["updated"]
+	}
+	direction := "DESC NULLS LAST"
+	if filter.Ascending {
+		direction = "ASC NULLS LAST"
+	}
+
+	query := `SELECT ` + draftColumns(false) + `
+		FROM drafts
+		WHERE ` + where + `
+		ORDER BY ` + column + ` ` + direction + `, id
+		LIMIT ` + arg(filter.Limit) + ` OFFSET ` + arg(filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list drafts: %w", err)
+	}
+	defer rows.Close()
+
+	drafts := []domain.Draft{}
+	for rows.Next() {
+		draft, err := scanDraft(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		drafts = append(drafts, *draft)
+	}
+	return drafts, total, rows.Err()
+}
+
+func (r *PostgresDraftRepository) TrashDraft(ctx context.Context, id string, workspaceID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE drafts SET deleted_at = NOW()
+		WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL
+	`, id, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to trash draft: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	// Local drafts are mirrored into the dashboard under the "postificus" platform
+	if _, err := tx.Exec(ctx, `DELETE FROM unified_posts WHERE platform = 'postificus' AND remote_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to remove draft from dashboard: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresDraftRepository) UntrashDraft(ctx context.Context, id string, workspaceID string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE drafts SET deleted_at = NULL
+		WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NOT NULL
+	`, id, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to restore draft: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
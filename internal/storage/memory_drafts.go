@@ -0,0 +1,506 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Drafts
+
+type MemoryDraftRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryDraftRepository) SaveDraft(ctx context.Context, draft *domain.Draft, expectedVersion int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, exists := r.db.drafts[draft.ID]
+	if exists {
+		if stored.WorkspaceID != draft.WorkspaceID || stored.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if expectedVersion >= 0 && stored.Version != expectedVersion {
+			return ErrVersionConflict
+		}
+		stored.Version++
+	} else {
+		stored = domain.Draft{
+			ID:          draft.ID,
+			WorkspaceID: draft.WorkspaceID,
+			UserID:      draft.UserID,
+			Status:      domain.StatusDraft,
+			Version:     1,
+		}
+	}
+
+	stored.Title = draft.Title
+	stored.Content = draft.Content
+	stored.CoverImage = draft.CoverImage
+	stored.PublishTargets = cloneStrings(draft.PublishTargets)
+	stored.Tags = cloneStrings(draft.Tags)
+	stored.Subtitle = draft.Subtitle
+	stored.Excerpt = draft.Excerpt
+	stored.Series = draft.Series
+	stored.SeriesOrder = draft.SeriesOrder
+	stored.LastSavedAt = time.Now().UTC()
+	r.db.drafts[draft.ID] = stored
+
+	draft.Version = stored.Version
+	return nil
+}
+
+func (r *MemoryDraftRepository) GetDraft(ctx context.Context, id string, workspaceID string) (*domain.Draft, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	draft, ok := r.db.drafts[id]
+	if !ok || draft.WorkspaceID != workspaceID || draft.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return cloneDraft(draft), nil
+}
+
+func (r *MemoryDraftRepository) UpdateDashboardCache(ctx context.Context, draft *domain.Draft) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	status := string(draft.Status)
+	if status == "" {
+		status = string(domain.StatusDraft)
+	}
+	key := postKey{draft.UserID, LocalPlatform, draft.ID}
+	post := r.db.posts[key].post
+	post.Platform = LocalPlatform
+	post.RemoteID = draft.ID
+	post.Title = draft.Title
+	post.URL = fmt.Sprintf("/editor?draft=%s", draft.ID)
+	post.Status = status
+	post.PublishedAt = time.Now().UTC()
+	r.db.posts[key] = memoryPost{post: post, lastSyncedAt: time.Now().UTC()}
+	return nil
+}
+
+func (r *MemoryDraftRepository) UpdateWorkflow(ctx context.Context, draft *domain.Draft) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.drafts[draft.ID]
+	if !ok || stored.WorkspaceID != draft.WorkspaceID || stored.DeletedAt != nil {
+		return ErrNotFound
+	}
+	stored.Status = draft.Status
+	stored.ReviewerID = draft.ReviewerID
+	stored.ScheduledAt = draft.ScheduledAt
+	stored.IsPublished = draft.IsPublished
+	r.db.drafts[draft.ID] = stored
+	return nil
+}
+
+func (r *MemoryDraftRepository) ListDrafts(ctx context.Context, filter DraftFilter) ([]domain.Draft, int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var matches []domain.Draft
+	for _, d := range r.db.drafts {
+		if d.WorkspaceID != filter.WorkspaceID || (d.DeletedAt != nil) != filter.Trashed {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !containsStatus(filter.Statuses, d.Status) {
+			continue
+		}
+		if filter.Target != "" && !containsString(d.PublishTargets, filter.Target) {
+			continue
+		}
+		if filter.Tag != "" && !containsString(d.Tags, filter.Tag) {
+			continue
+		}
+		if filter.UpdatedAfter != nil && d.LastSavedAt.Before(*filter.UpdatedAfter) {
+			continue
+		}
+		if filter.UpdatedBefore != nil && !d.LastSavedAt.Before(*filter.UpdatedBefore) {
+			continue
+		}
+		listed := cloneDraft(d)
+		listed.Content = ""
+		matches = append(matches, *listed)
+	}
+
+	less := draftLess(filter.Sort)
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if less(a, b) == less(b, a) {
+			return a.ID < b.ID
+		}
+		if filter.Ascending {
+			return less(a, b)
+		}
+		return less(b, a)
+	})
+
+	total := len(matches)
+	drafts := []domain.Draft{}
+	if filter.Offset < total {
+		drafts = append(drafts, matches[filter.Offset:min(filter.Offset+filter.Limit, total)]...)
+	}
+	return drafts, total, nil
+}
+
+func (r *MemoryDraftRepository) ListDueScheduled(ctx context.Context, before time.Time, limit int) ([]domain.Draft, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	drafts := []domain.Draft{}
+	for _, d := range r.db.drafts {
+		if d.Status != domain.StatusScheduled || d.ScheduledAt == nil || d.ScheduledAt.After(before) || d.DeletedAt != nil {
+			continue
+		}
+		listed := cloneDraft(d)
+		listed.Content = ""
+		drafts = append(drafts, *listed)
+	}
+	sort.Slice(drafts, func(i, j int) bool {
+		a, b := drafts[i], drafts[j]
+		if !a.ScheduledAt.Equal(*b.ScheduledAt) {
+			return a.ScheduledAt.Before(*b.ScheduledAt)
+		}
+		return a.ID < b.ID
+	})
+	return drafts[:min(limit, len(drafts))], nil
+}
+
+// draftLess orders drafts by one of DraftSortColumns' keys
+func draftLess(sortKey string) func(a, b domain.Draft) bool {
+	switch sortKey {
+	case "title":
+		return func(a, b domain.Draft) bool { return a.Title < b.Title }
+	case "status":
+		return func(a, b domain.Draft) bool { return a.Status < b.Status }
+	case "deleted":
+		return func(a, b domain.Draft) bool {
+			return a.DeletedAt != nil && b.DeletedAt != nil && a.DeletedAt.Before(*b.DeletedAt)
+		}
+	default:
+		return func(a, b domain.Draft) bool { return a.LastSavedAt.Before(b.LastSavedAt) }
+	}
+}
+
+func (r *MemoryDraftRepository) TrashDraft(ctx context.Context, id string, workspaceID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	draft, ok := r.db.drafts[id]
+	if !ok || draft.WorkspaceID != workspaceID || draft.DeletedAt != nil {
+		return ErrNotFound
+	}
+	now := time.Now().UTC()
+	draft.DeletedAt = &now
+	r.db.drafts[id] = draft
+
+	for key := range r.db.posts {
+		if key.platform == LocalPlatform && key.remoteID == id {
+			delete(r.db.posts, key)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryDraftRepository) UntrashDraft(ctx context.Context, id string, workspaceID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	draft, ok := r.db.drafts[id]
+	if !ok || draft.WorkspaceID != workspaceID || draft.DeletedAt == nil {
+		return ErrNotFound
+	}
+	draft.DeletedAt = nil
+	r.db.drafts[id] = draft
+	return nil
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStatus(values []domain.DraftStatus, want domain.DraftStatus) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Posts
+
+type MemoryPostRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryPostRepository) UpsertPost(ctx context.Context, userID string, post domain.UnifiedPost) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	post.PublishTargets = nil
+	r.db.posts[postKey{userID, post.Platform, post.RemoteID}] = memoryPost{post: post, lastSyncedAt: time.Now().UTC()}
+	return nil
+}
+
+func (r *MemoryPostRepository) ListPosts(ctx context.Context, userID string, limit int) ([]domain.UnifiedPost, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var records []memoryPost
+	for key, record := range r.db.posts {
+		if key.userID != userID {
+			continue
+		}
+		if key.platform == LocalPlatform {
+			if draft, ok := r.db.drafts[key.remoteID]; ok && draft.UserID == userID {
+				record.post.PublishTargets = cloneStrings(draft.PublishTargets)
+			}
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.post.PublishedAt.Equal(b.post.PublishedAt) {
+			return a.post.PublishedAt.After(b.post.PublishedAt)
+		}
+		return a.lastSyncedAt.After(b.lastSyncedAt)
+	})
+
+	var posts []domain.UnifiedPost
+	for _, record := range records[:min(limit, len(records))] {
+		posts = append(posts, record.post)
+	}
+	return posts, nil
+}
+
+// Search
+
+type MemorySearchRepository struct {
+	db *memoryDB
+}
+
+func (r *MemorySearchRepository) Search(ctx context.Context, query SearchQuery) ([]domain.SearchResult, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var candidates []searchCandidate
+	if query.wantsPlatform(LocalPlatform) {
+		for _, d := range r.db.drafts {
+			if d.WorkspaceID != query.WorkspaceID || d.DeletedAt != nil || !query.wantsStatus(string(d.Status)) {
+				continue
+			}
+			candidates = append(candidates, searchCandidate{
+				result: domain.SearchResult{
+					Kind:      "draft",
+					ID:        d.ID,
+					Platform:  LocalPlatform,
+					Title:     d.Title,
+					Status:    string(d.Status),
+					URL:       "/editor?draft=" + d.ID,
+					UpdatedAt: d.LastSavedAt,
+				},
+				body: d.Content,
+			})
+		}
+	}
+	for key, record := range r.db.posts {
+		if key.userID != query.UserID || key.platform == LocalPlatform || !query.wantsPlatform(key.platform) || !query.wantsStatus(record.post.Status) {
+			continue
+		}
+		updatedAt := record.post.PublishedAt
+		if updatedAt.IsZero() {
+			updatedAt = record.lastSyncedAt
+		}
+		candidates = append(candidates, searchCandidate{result: domain.SearchResult{
+			Kind:      "post",
+			ID:        record.post.RemoteID,
+			Platform:  key.platform,
+			Title:     record.post.Title,
+			Status:    record.post.Status,
+			URL:       record.post.URL,
+			UpdatedAt: updatedAt,
+		}})
+	}
+	return matchSearch(query, candidates), nil
+}
+
+// Reviews
+
+type MemoryReviewRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryReviewRepository) AddComment(ctx context.Context, comment *domain.ReviewComment) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	comment.ID = uuid.NewString()
+	comment.CreatedAt = time.Now().UTC()
+	r.db.comments[comment.DraftID] = append(r.db.comments[comment.DraftID], *comment)
+	return nil
+}
+
+func (r *MemoryReviewRepository) ListComments(ctx context.Context, draftID string) ([]domain.ReviewComment, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	comments := append([]domain.ReviewComment{}, r.db.comments[draftID]...)
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].RangeStart < comments[j].RangeStart })
+	return comments, nil
+}
+
+func (r *MemoryReviewRepository) ResolveComment(ctx context.Context, draftID string, commentID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for i, c := range r.db.comments[draftID] {
+		if c.ID == commentID {
+			r.db.comments[draftID][i].Resolved = true
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryReviewRepository) AddAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.nextAuditID++
+	entry.ID = r.db.nextAuditID
+	entry.CreatedAt = time.Now().UTC()
+	r.db.audit = append(r.db.audit, *entry)
+	return nil
+}
+
+func (r *MemoryReviewRepository) ListAuditEntries(ctx context.Context, draftID string) ([]domain.AuditEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	entries := []domain.AuditEntry{}
+	for _, e := range r.db.audit {
+		if e.DraftID == draftID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// Revisions
+
+type MemoryRevisionRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryRevisionRepository) AddRevision(ctx context.Context, rev *domain.Revision) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now().UTC()
+	if _, ok := r.db.blobs[rev.Hash]; !ok {
+		r.db.blobs[rev.Hash] = memoryBlob{title: rev.Title, content: rev.Content, coverImage: rev.CoverImage, createdAt: now}
+	}
+	r.db.nextRevisionID++
+	rev.ID = r.db.nextRevisionID
+	rev.CreatedAt = now
+
+	stored := *rev
+	stored.Title, stored.Content, stored.CoverImage = "", "", ""
+	r.db.revisions = append(r.db.revisions, stored)
+	return nil
+}
+
+// withBlob fills in a revision's snapshot; content is optional for listings
+func (r *MemoryRevisionRepository) withBlob(rev domain.Revision, withContent bool) domain.Revision {
+	blob := r.db.blobs[rev.Hash]
+	rev.Title = blob.title
+	if withContent {
+		rev.Content = blob.content
+		rev.CoverImage = blob.coverImage
+	}
+	return rev
+}
+
+func (r *MemoryRevisionRepository) LatestRevision(ctx context.Context, draftID string) (*domain.Revision, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for i := len(r.db.revisions) - 1; i >= 0; i-- {
+		if r.db.revisions[i].DraftID == draftID {
+			rev := r.withBlob(r.db.revisions[i], false)
+			return &rev, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryRevisionRepository) ListRevisions(ctx context.Context, draftID string) ([]domain.Revision, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	revisions := []domain.Revision{}
+	for i := len(r.db.revisions) - 1; i >= 0; i-- {
+		if r.db.revisions[i].DraftID == draftID {
+			revisions = append(revisions, r.withBlob(r.db.revisions[i], false))
+		}
+	}
+	return revisions, nil
+}
+
+func (r *MemoryRevisionRepository) GetRevision(ctx context.Context, draftID string, id int64) (*domain.Revision, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, rev := range r.db.revisions {
+		if rev.ID == id && rev.DraftID == draftID {
+			full := r.withBlob(rev, true)
+			return &full, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryRevisionRepository) PruneAutosaves(ctx context.Context, olderThan time.Time) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	latest := make(map[string]int64)
+	for _, rev := range r.db.revisions {
+		latest[rev.DraftID] = rev.ID
+	}
+
+	var kept []domain.Revision
+	var pruned int64
+	referenced := make(map[string]bool)
+	for _, rev := range r.db.revisions {
+		if rev.Kind == domain.RevisionAutosave && rev.CreatedAt.Before(olderThan) && rev.ID != latest[rev.DraftID] {
+			pruned++
+			continue
+		}
+		kept = append(kept, rev)
+		referenced[rev.Hash] = true
+	}
+	r.db.revisions = kept
+
+	for hash, blob := range r.db.blobs {
+		if !referenced[hash] && blob.createdAt.Before(olderThan) {
+			delete(r.db.blobs, hash)
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"postificus/internal/domain"
+)
+
+type memoryOutboxMessage struct {
+	OutboxMessage
+	availableAt time.Time
+	sentAt      *time.Time
+}
+
+// Outbox
+
+type MemoryOutboxRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryOutboxRepository) Add(ctx context.Context, messages ...OutboxMessage) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.addOutboxMessages(messages)
+}
+
+// addOutboxMessages stores messages, all or none; the caller holds the lock
+func (db *memoryDB) addOutboxMessages(messages []OutboxMessage) error {
+	keys := make(map[string]bool, len(db.outbox)+len(messages))
+	for _, m := range db.outbox {
+		keys[m.IdempotencyKey] = true
+	}
+	for _, m := range messages {
+		if keys[m.IdempotencyKey] {
+			return fmt.Errorf("failed to add outbox message: duplicate idempotency key %q", m.IdempotencyKey)
+		}
+		keys[m.IdempotencyKey] = true
+	}
+
+	now := time.Now().UTC()
+	for _, m := range messages {
+		db.nextOutboxID++
+		m.ID = db.nextOutboxID
+		m.Payload = append([]byte(nil), m.Payload...)
+		m.Attempts = 0
+		m.LastError = ""
+		m.CreatedAt = now
+		db.outbox = append(db.outbox, memoryOutboxMessage{OutboxMessage: m, availableAt: now})
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxMessage, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now().UTC()
+	messages := []OutboxMessage{}
+	for i := range r.db.outbox {
+		if len(messages) >= limit {
+			break
+		}
+		m := &r.db.outbox[i]
+		if m.sentAt != nil || m.availableAt.After(now) {
+			continue
+		}
+		m.availableAt = now.Add(lease)
+		m.Attempts++
+		claimed := m.OutboxMessage
+		claimed.Payload = append([]byte(nil), m.Payload...)
+		messages = append(messages, claimed)
+	}
+	return messages, nil
+}
+
+func (r *MemoryOutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if m := r.db.outboxMessage(id); m != nil {
+		now := time.Now().UTC()
+		m.sentAt = &now
+		m.LastError = ""
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) Release(ctx context.Context, id int64, cause string, retryIn time.Duration) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if m := r.db.outboxMessage(id); m != nil && m.sentAt == nil {
+		m.LastError = cause
+		m.availableAt = time.Now().UTC().Add(retryIn)
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) PurgeSent(ctx context.Context, sentBefore time.Time) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	kept := r.db.outbox[:0]
+	var purged int64
+	for _, m := range r.db.outbox {
+		if m.sentAt != nil && m.sentAt.Before(sentBefore) {
+			purged++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	r.db.outbox = kept
+	return purged, nil
+}
+
+func (db *memoryDB) outboxMessage(id int64) *memoryOutboxMessage {
+	for i := range db.outbox {
+		if db.outbox[i].ID == id {
+			return &db.outbox[i]
+		}
+	}
+	return nil
+}
+
+// Publish logs
+
+type MemoryPublishLogRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryPublishLogRepository) QueuePublish(ctx context.Context, entry *domain.PublishLog, message OutboxMessage) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.publishLogs[entry.JobID]
+	if ok && existing.Status != domain.PublishFailed {
+		*entry = existing
+		return false, nil
+	}
+
+	if ok {
+		// Requeue: the earlier run's message makes room for the new one
+		kept := r.db.outbox[:0]
+		for _, m := range r.db.outbox {
+			if m.IdempotencyKey != message.IdempotencyKey {
+				kept = append(kept, m)
+			}
+		}
+		r.db.outbox = kept
+	}
+	if err := r.db.addOutboxMessages([]OutboxMessage{message}); err != nil {
+		return false, err
+	}
+
+	now := time.Now().UTC()
+	if ok {
+		existing.Status = domain.PublishQueued
+		existing.Error = ""
+		existing.UpdatedAt = now
+		*entry = existing
+	} else {
+		r.db.nextPublishLogID++
+		entry.ID = r.db.nextPublishLogID
+		entry.Status = domain.PublishQueued
+		entry.Attempts = 0
+		entry.CreatedAt = now
+		entry.UpdatedAt = now
+	}
+	r.db.publishLogs[entry.JobID] = *entry
+	return true, nil
+}
+
+func (r *MemoryPublishLogRepository) ClaimPublish(ctx context.Context, jobID string, staleAfter time.Duration) (*domain.PublishLog, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	entry, ok := r.db.publishLogs[jobID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	now := time.Now().UTC()
+	claimable := entry.Status == domain.PublishQueued || entry.Status == domain.PublishFailed ||
+		(entry.Status == domain.PublishProcessing && entry.UpdatedAt.Before(now.Add(-staleAfter)))
+	if !claimable {
+		return claimOutcome(&entry)
+	}
+
+	entry.Status = domain.PublishProcessing
+	entry.Attempts++
+	entry.UpdatedAt = now
+	r.db.publishLogs[jobID] = entry
+	return &entry, nil
+}
+
+func (r *MemoryPublishLogRepository) ReleasePublish(ctx context.Context, jobID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	entry, ok := r.db.publishLogs[jobID]
+	if !ok || entry.Status != domain.PublishProcessing {
+		return ErrNotFound
+	}
+	entry.Status = domain.PublishQueued
+	entry.Attempts = max(entry.Attempts-1, 0)
+	entry.UpdatedAt = time.Now().UTC()
+	r.db.publishLogs[jobID] = entry
+	return nil
+}
+
+func (r *MemoryPublishLogRepository) UpdatePublishStatus(ctx context.Context, jobID string, status domain.PublishStatus, externalURL string, errorMessage string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	entry, ok := r.db.publishLogs[jobID]
+	if !ok {
+		return ErrNotFound
+	}
+	entry.Status = status
+	entry.ExternalURL = externalURL
+	entry.Error = errorMessage
+	entry.UpdatedAt = time.Now().UTC()
+	r.db.publishLogs[jobID] = entry
+	return nil
+}
+
+func (r *MemoryPublishLogRepository) AttachPublishArtifacts(ctx context.Context, jobID string, artifactsURL string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	entry, ok := r.db.publishLogs[jobID]
+	if !ok {
+		return ErrNotFound
+	}
+	entry.ArtifactsURL = artifactsURL
+	r.db.publishLogs[jobID] = entry
+	return nil
+}
+
+func (r *MemoryPublishLogRepository) GetPublishLog(ctx context.Context, jobID string) (*domain.PublishLog, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	entry, ok := r.db.publishLogs[jobID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &entry, nil
+}
@@ -0,0 +1,370 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// demoUserID mirrors the user seeded by the baseline migration
+const demoUserID = "00000000-0000-0000-0000-000000000001"
+
+// memoryDB is the state shared by the in-memory repositories.
+// One lock guards everything, which keeps cross-table writes (trash, dashboard cache) atomic.
+type memoryDB struct {
+	mu sync.RWMutex
+
+	credentials map[string]domain.UserCredential // by ID
+	drafts      map[string]domain.Draft          // by ID
+	posts       map[postKey]memoryPost
+	profiles    map[string]domain.Profile // by user ID
+	comments    map[string][]domain.ReviewComment
+	audit       []domain.AuditEntry
+	blobs       map[string]memoryBlob // by hash
+	revisions   []domain.Revision     // metadata only, ascending ID
+	tagMappings map[tagMappingKey]string
+	workspaces  map[string]domain.Workspace
+	members     map[string]map[string]domain.Membership // workspace -> user
+	outbox      []memoryOutboxMessage                   // ascending ID
+	publishLogs map[string]domain.PublishLog            // by job ID
+
+	nextAuditID      int64
+	nextRevisionID   int64
+	nextOutboxID     int64
+	nextPublishLogID int64
+}
+
+type postKey struct {
+	userID, platform, remoteID string
+}
+
+type memoryPost struct {
+	post         domain.UnifiedPost
+	lastSyncedAt time.Time
+}
+
+type memoryBlob struct {
+	title, content, coverImage string
+	createdAt                  time.Time
+}
+
+type tagMappingKey struct {
+	workspaceID, platform, tag string
+}
+
+// NewMemoryStore returns a store that keeps everything in process memory.
+// Nothing survives a restart, and API and worker processes don't share it.
+func NewMemoryStore() *Store {
+	db := &memoryDB{
+		credentials: make(map[string]domain.UserCredential),
+		drafts:      make(map[string]domain.Draft),
+		posts:       make(map[postKey]memoryPost),
+		profiles:    make(map[string]domain.Profile),
+		comments:    make(map[string][]domain.ReviewComment),
+		blobs:       make(map[string]memoryBlob),
+		tagMappings: make(map[tagMappingKey]string),
+		workspaces:  make(map[string]domain.Workspace),
+		members:     make(map[string]map[string]domain.Membership),
+		publishLogs: make(map[string]domain.PublishLog),
+	}
+
+	// Same seed as Postgres: the demo user owns a personal workspace sharing their ID
+	now := time.Now().UTC()
+	db.workspaces[demoUserID] = domain.Workspace{ID: demoUserID, Name: "demo@postificus.local", CreatedAt: now}
+	db.members[demoUserID] = map[string]domain.Membership{
+		demoUserID: {WorkspaceID: demoUserID, UserID: demoUserID, Role: domain.RoleOwner, CreatedAt: now},
+	}
+
+	return &Store{
+		Backend:     BackendMemory,
+		Credentials: &MemoryCredentialsRepository{db: db},
+		Drafts:      &MemoryDraftRepository{db: db},
+		Outbox:      &MemoryOutboxRepository{db: db},
+		Posts:       &MemoryPostRepository{db: db},
+		Profiles:    &MemoryProfileRepository{db: db},
+		PublishLogs: &MemoryPublishLogRepository{db: db},
+		Reviews:     &MemoryReviewRepository{db: db},
+		Revisions:   &MemoryRevisionRepository{db: db},
+		Search:      &MemorySearchRepository{db: db},
+		TagMappings: &MemoryTagMappingRepository{db: db},
+		Workspaces:  &MemoryWorkspaceRepository{db: db},
+	}
+}
+
+func cloneStrings(values []string) []string {
+	return append([]string{}, values...)
+}
+
+// cloneDraft copies the draft so callers can't mutate stored slices
+func cloneDraft(d domain.Draft) *domain.Draft {
+	d.PublishTargets = cloneStrings(d.PublishTargets)
+	d.Tags = cloneStrings(d.Tags)
+	return &d
+}
+
+// Credentials
+
+type MemoryCredentialsRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryCredentialsRepository) SaveCredentials(ctx context.Context, workspaceID string, platform string, creds map[string]string) error {
+	credsJSON, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	account := AccountNameFor(creds)
+	for id, cred := range r.db.credentials {
+		if cred.WorkspaceID == workspaceID && cred.Platform == platform && cred.AccountName == account {
+			cred.Credentials = credsJSON
+			cred.UpdatedAt = time.Now().UTC()
+			r.db.credentials[id] = cred
+			return nil
+		}
+	}
+	id := uuid.NewString()
+	r.db.credentials[id] = domain.UserCredential{
+		ID:          id,
+		WorkspaceID: workspaceID,
+		Platform:    platform,
+		AccountName: account,
+		Credentials: credsJSON,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	return nil
+}
+
+func (r *MemoryCredentialsRepository) GetCredentials(ctx context.Context, workspaceID string, platform string) (*domain.UserCredential, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var latest *domain.UserCredential
+	for _, cred := range r.db.credentials {
+		if cred.WorkspaceID == workspaceID && cred.Platform == platform && (latest == nil || cred.UpdatedAt.After(latest.UpdatedAt)) {
+			c := cred
+			latest = &c
+		}
+	}
+	return latest, nil
+}
+
+func (r *MemoryCredentialsRepository) DeleteCredentials(ctx context.Context, workspaceID string, platform string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, cred := range r.db.credentials {
+		if cred.WorkspaceID == workspaceID && cred.Platform == platform {
+			delete(r.db.credentials, id)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryCredentialsRepository) GetAllCredentials(ctx context.Context, workspaceID string) ([]domain.UserCredential, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	creds := []domain.UserCredential{}
+	for _, cred := range r.db.credentials {
+		if cred.WorkspaceID == workspaceID {
+			creds = append(creds, cred)
+		}
+	}
+	sort.Slice(creds, func(i, j int) bool {
+		if creds[i].Platform != creds[j].Platform {
+			return creds[i].Platform < creds[j].Platform
+		}
+		return creds[i].UpdatedAt.After(creds[j].UpdatedAt)
+	})
+	return creds, nil
+}
+
+func (r *MemoryCredentialsRepository) GetCredentialsByID(ctx context.Context, workspaceID string, id string) (*domain.UserCredential, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	cred, ok := r.db.credentials[id]
+	if !ok || cred.WorkspaceID != workspaceID {
+		return nil, nil
+	}
+	return &cred, nil
+}
+
+func (r *MemoryCredentialsRepository) DeleteCredentialsByID(ctx context.Context, workspaceID string, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if cred, ok := r.db.credentials[id]; ok && cred.WorkspaceID == workspaceID {
+		delete(r.db.credentials, id)
+	}
+	return nil
+}
+
+// Profiles
+
+type MemoryProfileRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryProfileRepository) GetProfile(ctx context.Context, userID string) (*domain.Profile, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	profile, ok := r.db.profiles[userID]
+	if !ok {
+		return &domain.Profile{UserID: userID, Skills: []string{}}, nil
+	}
+	profile.Skills = cloneStrings(profile.Skills)
+	return &profile, nil
+}
+
+func (r *MemoryProfileRepository) SaveProfile(ctx context.Context, profile *domain.Profile) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored := *profile
+	stored.Skills = cloneStrings(profile.Skills)
+	r.db.profiles[profile.UserID] = stored
+	return nil
+}
+
+// Workspaces
+
+type MemoryWorkspaceRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryWorkspaceRepository) CreateWorkspace(ctx context.Context, workspace *domain.Workspace, ownerID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	workspace.ID = uuid.NewString()
+	workspace.CreatedAt = time.Now().UTC()
+	workspace.Role = domain.RoleOwner
+	r.db.workspaces[workspace.ID] = domain.Workspace{ID: workspace.ID, Name: workspace.Name, CreatedAt: workspace.CreatedAt}
+	r.db.members[workspace.ID] = map[string]domain.Membership{
+		ownerID: {WorkspaceID: workspace.ID, UserID: ownerID, Role: domain.RoleOwner, CreatedAt: workspace.CreatedAt},
+	}
+	return nil
+}
+
+func (r *MemoryWorkspaceRepository) ListWorkspaces(ctx context.Context, userID string) ([]domain.Workspace, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	workspaces := []domain.Workspace{}
+	for id, members := range r.db.members {
+		if m, ok := members[userID]; ok {
+			w := r.db.workspaces[id]
+			w.Role = m.Role
+			workspaces = append(workspaces, w)
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].CreatedAt.Before(workspaces[j].CreatedAt) })
+	return workspaces, nil
+}
+
+func (r *MemoryWorkspaceRepository) GetMembership(ctx context.Context, workspaceID string, userID string) (*domain.Membership, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	m, ok := r.db.members[workspaceID][userID]
+	if !ok {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+func (r *MemoryWorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]domain.Membership, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	members := []domain.Membership{}
+	for _, m := range r.db.members[workspaceID] {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].CreatedAt.Before(members[j].CreatedAt) })
+	return members, nil
+}
+
+func (r *MemoryWorkspaceRepository) SaveMember(ctx context.Context, member *domain.Membership) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	members, ok := r.db.members[member.WorkspaceID]
+	if !ok {
+		members = make(map[string]domain.Membership)
+		r.db.members[member.WorkspaceID] = members
+	}
+	stored, ok := members[member.UserID]
+	if !ok {
+		stored = domain.Membership{WorkspaceID: member.WorkspaceID, UserID: member.UserID, CreatedAt: time.Now().UTC()}
+	}
+	stored.Role = member.Role
+	members[member.UserID] = stored
+	return nil
+}
+
+func (r *MemoryWorkspaceRepository) RemoveMember(ctx context.Context, workspaceID string, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	delete(r.db.members[workspaceID], userID)
+	return nil
+}
+
+// Tag mappings
+
+type MemoryTagMappingRepository struct {
+	db *memoryDB
+}
+
+func (r *MemoryTagMappingRepository) ListTagMappings(ctx context.Context, workspaceID string, platform string) ([]domain.TagMapping, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	mappings := []domain.TagMapping{}
+	for key, platformTag := range r.db.tagMappings {
+		if key.workspaceID == workspaceID && (platform == "" || key.platform == platform) {
+			mappings = append(mappings, domain.TagMapping{WorkspaceID: workspaceID, Platform: key.platform, Tag: key.tag, PlatformTag: platformTag})
+		}
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].Platform != mappings[j].Platform {
+			return mappings[i].Platform < mappings[j].Platform
+		}
+		return mappings[i].Tag < mappings[j].Tag
+	})
+	return mappings, nil
+}
+
+func (r *MemoryTagMappingRepository) SaveTagMapping(ctx context.Context, mapping domain.TagMapping) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.tagMappings[tagMappingKey{mapping.WorkspaceID, mapping.Platform, mapping.Tag}] = mapping.PlatformTag
+	return nil
+}
+
+func (r *MemoryTagMappingRepository) DeleteTagMapping(ctx context.Context, workspaceID, platform, tag string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := tagMappingKey{workspaceID, platform, tag}
+	if _, ok := r.db.tagMappings[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.db.tagMappings, key)
+	return nil
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := LoadMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migrations must be numbered without gaps")
+		assert.NotEmpty(t, m.Down, "migration %d_%s needs a down file", m.Version, m.Name)
+	}
+}
+
+func TestLoadMigrations_Validation(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+	migrations, err := loadMigrations(fstest.MapFS{
+		"m/0002_second.up.sql":  file("B"),
+		"m/0001_first.up.sql":   file("A"),
+		"m/0001_first.down.sql": file("a"),
+	}, "m")
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "first", Up: "A", Down: "a"},
+		{Version: 2, Name: "second", Up: "B"},
+	}, migrations)
+
+	_, err = loadMigrations(fstest.MapFS{"m/0001_first.down.sql": file("a")}, "m")
+	assert.ErrorContains(t, err, "no up file")
+
+	_, err = loadMigrations(fstest.MapFS{"m/0001_a.up.sql": file("A"), "m/0001_b.down.sql": file("b")}, "m")
+	assert.ErrorContains(t, err, "two names")
+
+	_, err = loadMigrations(fstest.MapFS{"m/first.sql": file("A")}, "m")
+	assert.ErrorContains(t, err, "unexpected migration file name")
+}
+
+func TestSQLiteMigrator_UpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	db, err := openSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := NewSQLiteMigrator(db)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(migrator.migrations))
+
+	again, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, again, "applied migrations run once")
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	latest := migrator.migrations[len(migrator.migrations)-1]
+	assert.Equal(t, latest.Version, reverted[0].Version)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(migrator.migrations))
+	for _, status := range statuses {
+		assert.Equal(t, status.Version != latest.Version, status.AppliedAt != nil, "migration %d", status.Version)
+	}
+}
+
+func TestSQLiteMigrator_ConcurrentUpAppliesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// Separate handles stand in for the API and the worker booting together
+	const processes = 4
+	counts := make([]int, processes)
+	errs := make([]error, processes)
+	var wg sync.WaitGroup
+	for i := range processes {
+		db, err := openSQLite(path)
+		require.NoError(t, err)
+		defer db.Close()
+		migrator, err := NewSQLiteMigrator(db)
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			applied, err := migrator.Up(context.Background())
+			counts[i], errs[i] = len(applied), err
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range processes {
+		require.NoError(t, errs[i])
+		total += counts[i]
+	}
+	migrations, err := loadMigrations(sqliteMigrationFiles, "migrations/sqlite")
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), total, "every migration is applied by exactly one process")
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	migrator, err := NewMigrator(testPostgres(t))
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(migrator.migrations))
+
+	again, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, again, "applied migrations run once")
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	latest := migrator.migrations[len(migrator.migrations)-1]
+	assert.Equal(t, latest.Version, reverted[0].Version)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(migrator.migrations))
+	for _, status := range statuses {
+		assert.Equal(t, status.Version != latest.Version, status.AppliedAt != nil, "migration %d", status.Version)
+	}
+
+	// Every down file undoes its migration, so the whole chain rolls back and forward again
+	reverted, err = migrator.Down(ctx, len(migrator.migrations))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(migrator.migrations)-1)
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrator.migrations))
+}
+
+func TestMigrator_ConcurrentUpAppliesOnce(t *testing.T) {
+	db := testPostgres(t)
+
+	// Separate pools stand in for the API and the worker booting together
+	const processes = 4
+	counts := make([]int, processes)
+	errs := make([]error, processes)
+	var wg sync.WaitGroup
+	for i := range processes {
+		pool, err := pgxpool.NewWithConfig(context.Background(), db.Config())
+		require.NoError(t, err)
+		defer pool.Close()
+		migrator, err := NewMigrator(pool)
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			applied, err := migrator.Up(context.Background())
+			counts[i], errs[i] = len(applied), err
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range processes {
+		require.NoError(t, errs[i])
+		total += counts[i]
+	}
+	migrations, err := LoadMigrations()
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), total, "every migration is applied by exactly one process")
+}
@@ -0,0 +1,14 @@
+-- Drops everything the baseline created, dependents first
+DROP TABLE IF EXISTS unified_posts;
+DROP TABLE IF EXISTS user_credentials;
+DROP TABLE IF EXISTS publish_logs;
+DROP TABLE IF EXISTS draft_revisions;
+DROP TABLE IF EXISTS draft_revision_blobs;
+DROP TABLE IF EXISTS platform_tag_mappings;
+DROP TABLE IF EXISTS draft_audit_log;
+DROP TABLE IF EXISTS draft_review_comments;
+DROP TABLE IF EXISTS drafts;
+DROP TABLE IF EXISTS user_details;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+DROP TABLE IF EXISTS users;
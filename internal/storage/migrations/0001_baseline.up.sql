@@ -0,0 +1,255 @@
+-- Baseline: the schema as it stood when versioned migrations were introduced.
+-- It stays idempotent so databases created by the old boot-time schema.sql adopt it as-is.
+-- Every later change goes into a new numbered migration.
+
+-- Users and Auth
+CREATE EXTENSION IF NOT EXISTS "pgcrypto";
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL
+);
+
+-- Seed a default user for MVP flows (id=1)
+INSERT INTO users (id, email, password_hash)
+VALUES ('00000000-0000-0000-0000-000000000001', 'demo@postificus.local', 'demo')
+ON CONFLICT DO NOTHING;
+
+-- Workspaces (Shared ownership of drafts and credentials)
+CREATE TABLE IF NOT EXISTS workspaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id),
+    role VARCHAR(20) NOT NULL, -- 'owner', 'editor', 'writer', 'viewer'
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+-- Every user gets a personal workspace sharing their user id
+INSERT INTO workspaces (id, name)
+SELECT id, email FROM users
+ON CONFLICT DO NOTHING;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, id, 'owner' FROM users
+ON CONFLICT DO NOTHING;
+
+-- User Profile Details
+CREATE TABLE IF NOT EXISTS user_details (
+    user_id UUID PRIMARY KEY REFERENCES users(id),
+    full_name VARCHAR(255),
+    username VARCHAR(100),
+    headline VARCHAR(255),
+    bio TEXT,
+    location VARCHAR(255),
+    website TEXT,
+    public_email VARCHAR(255),
+    skills JSONB,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- The "Drafts" table (Hot edits)
+CREATE TABLE IF NOT EXISTS drafts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID REFERENCES workspaces(id),
+    user_id UUID REFERENCES users(id), -- Author
+    title VARCHAR(255),
+    content TEXT, -- Markdown
+    cover_image TEXT,
+    publish_targets JSONB,
+    last_saved_at TIMESTAMP,
+    is_published BOOLEAN DEFAULT FALSE
+);
+
+-- Ensure column exists for older databases
+ALTER TABLE drafts
+    ADD COLUMN IF NOT EXISTS publish_targets JSONB;
+
+ALTER TABLE drafts
+    ADD COLUMN IF NOT EXISTS cover_image TEXT;
+
+ALTER TABLE drafts
+    ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id);
+
+UPDATE drafts SET workspace_id = user_id WHERE workspace_id IS NULL;
+
+-- Editorial workflow ('draft', 'in_review', 'approved', 'scheduled', 'published')
+ALTER TABLE drafts
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'draft';
+
+ALTER TABLE drafts
+    ADD COLUMN IF NOT EXISTS reviewer_id UUID REFERENCES users(id);
+
+ALTER TABLE drafts
+    ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+
+UPDATE drafts SET status = 'published' WHERE is_published AND status = 'draft';
+
+-- Review comments anchored to a character range of the content
+CREATE TABLE IF NOT EXISTS draft_review_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    draft_id UUID REFERENCES drafts(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id),
+    body TEXT NOT NULL,
+    range_start INT NOT NULL DEFAULT 0,
+    range_end INT NOT NULL DEFAULT 0,
+    quote TEXT,
+    resolved BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS draft_review_comments_draft_idx ON draft_review_comments (draft_id);
+
+-- Workflow audit log (who moved what)
+CREATE TABLE IF NOT EXISTS draft_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    draft_id UUID REFERENCES drafts(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id),
+    action VARCHAR(50) NOT NULL, -- 'transition', 'assign_reviewer'
+    from_status VARCHAR(20),
+    to_status VARCHAR(20),
+    detail TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS draft_audit_log_draft_idx ON draft_audit_log (draft_id, created_at);
+
+-- Optimistic concurrency: bumped on every content save, exposed as the draft's ETag
+ALTER TABLE drafts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- Listing filters and soft-delete (trash)
+ALTER TABLE drafts ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE drafts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS drafts_workspace_saved_idx ON drafts (workspace_id, last_saved_at DESC);
+CREATE INDEX IF NOT EXISTS drafts_tags_idx ON drafts USING GIN (tags);
+
+-- Publishing metadata
+ALTER TABLE drafts ADD COLUMN IF NOT EXISTS subtitle TEXT;
+ALTER TABLE drafts ADD COLUMN IF NOT EXISTS excerpt TEXT;
+ALTER TABLE drafts ADD COLUMN IF NOT EXISTS series_name TEXT;
+ALTER TABLE drafts ADD COLUMN IF NOT EXISTS series_order INT;
+
+-- Per-platform tag names, e.g. our "golang" is "go" on Dev.to
+CREATE TABLE IF NOT EXISTS platform_tag_mappings (
+    workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    tag VARCHAR(100) NOT NULL,
+    platform_tag VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, platform, tag)
+);
+
+-- Draft revision history. Snapshot bodies are content-addressed (sha256) and
+-- shared between revisions; revisions themselves are never updated.
+CREATE TABLE IF NOT EXISTS draft_revision_blobs (
+    hash CHAR(64) PRIMARY KEY,
+    title TEXT,
+    content TEXT,
+    cover_image TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS draft_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    draft_id UUID REFERENCES drafts(id) ON DELETE CASCADE,
+    blob_hash CHAR(64) NOT NULL REFERENCES draft_revision_blobs(hash),
+    author_id UUID REFERENCES users(id),
+    kind VARCHAR(20) NOT NULL DEFAULT 'autosave', -- 'autosave', 'restore', 'publish'
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS draft_revisions_draft_idx ON draft_revisions (draft_id, id DESC);
+CREATE INDEX IF NOT EXISTS draft_revisions_blob_idx ON draft_revisions (blob_hash);
+
+-- The "Publish Logs" (Audit Trail)
+CREATE TABLE IF NOT EXISTS publish_logs (
+    id SERIAL PRIMARY KEY,
+    draft_id UUID REFERENCES drafts(id),
+    platform VARCHAR(50), -- 'medium', 'linkedin'
+    status VARCHAR(20),   -- 'queued', 'processing', 'success', 'failed'
+    external_url TEXT,
+    error_message TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- User Credentials (Encrypted/Stored for Automation)
+-- Scoped to a workspace; several accounts per platform are allowed.
+CREATE TABLE IF NOT EXISTS user_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id), -- Legacy owner column
+    platform VARCHAR(50), -- 'medium', 'linkedin', 'devto'
+    account_name VARCHAR(255) NOT NULL DEFAULT 'default',
+    credentials JSONB,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Upgrade older databases keyed by (user_id, platform)
+ALTER TABLE user_credentials
+    ADD COLUMN IF NOT EXISTS id UUID DEFAULT gen_random_uuid();
+
+ALTER TABLE user_credentials
+    ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE user_credentials
+    ADD COLUMN IF NOT EXISTS account_name VARCHAR(255) NOT NULL DEFAULT 'default';
+
+UPDATE user_credentials
+SET workspace_id = user_id,
+    account_name = COALESCE(NULLIF(credentials->>'account_name', ''), 'default')
+WHERE workspace_id IS NULL;
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.key_column_usage
+        WHERE table_name = 'user_credentials'
+          AND constraint_name = 'user_credentials_pkey'
+          AND column_name = 'user_id'
+    ) THEN
+        ALTER TABLE user_credentials DROP CONSTRAINT user_credentials_pkey;
+        ALTER TABLE user_credentials ADD PRIMARY KEY (id);
+    END IF;
+END $$;
+
+ALTER TABLE user_credentials
+    ALTER COLUMN user_id DROP NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_credentials_account_idx
+    ON user_credentials (workspace_id, platform, account_name);
+
+-- Unified Posts (Synced Activity Cache)
+CREATE TABLE IF NOT EXISTS unified_posts (
+    id SERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id),
+    platform VARCHAR(50) NOT NULL, -- 'medium', 'devto'
+    remote_id VARCHAR(255) NOT NULL, -- URL or slug to identify uniqueness
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    status VARCHAR(50), -- 'published', 'draft'
+    views INT DEFAULT 0,
+    reactions INT DEFAULT 0,
+    comments INT DEFAULT 0,
+    published_at TIMESTAMP,
+    last_synced_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(user_id, platform, remote_id)
+);
+
+-- Full-text search. Generated columns keep the vectors current on every write;
+-- draft bodies are HTML from the editor, so tags are stripped before indexing.
+ALTER TABLE drafts ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector('english', regexp_replace(COALESCE(content, ''), '<[^>]+>', ' ', 'g')), 'B')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS drafts_search_idx ON drafts USING GIN (search_vector);
+
+ALTER TABLE unified_posts ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', COALESCE(title, ''))) STORED;
+CREATE INDEX IF NOT EXISTS unified_posts_search_idx ON unified_posts USING GIN (search_vector);
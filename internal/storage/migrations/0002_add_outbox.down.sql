@@ -0,0 +1,6 @@
+ALTER TABLE publish_logs
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS workspace_id,
+    DROP COLUMN IF EXISTS job_id;
+
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,21 @@
+-- Transactional outbox: jobs are written here in the same transaction as the
+-- rows they belong to, and a relay moves them onto the job queue.
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    queue TEXT NOT NULL,
+    payload BYTEA NOT NULL,
+    idempotency_key TEXT NOT NULL UNIQUE, -- Sent as the message ID so workers can drop redeliveries
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP NOT NULL DEFAULT NOW(), -- Claimed rows are leased by pushing this forward
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (available_at) WHERE sent_at IS NULL;
+
+-- Publish logs become the job record: one row per publish job, keyed by its idempotency key
+ALTER TABLE publish_logs
+    ADD COLUMN IF NOT EXISTS job_id TEXT UNIQUE,
+    ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW();
@@ -0,0 +1 @@
+ALTER TABLE publish_logs DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,3 @@
+-- Counts how often workers started a publish job, so a retry knows to look for
+-- a post an earlier attempt may already have created
+ALTER TABLE publish_logs ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS job_type,
+    DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,5 @@
+-- Jobs of several types share a platform's lane, so the outbox keeps each
+-- message's type and priority for the queue
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS job_type TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
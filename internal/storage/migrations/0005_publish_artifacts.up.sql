@@ -0,0 +1,3 @@
+-- Where the last failed attempt's screenshot, DOM snapshot, console log and
+-- step timeline were saved, for debugging the browser automation
+ALTER TABLE publish_logs ADD COLUMN IF NOT EXISTS artifacts_url TEXT;
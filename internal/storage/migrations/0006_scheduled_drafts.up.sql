@@ -0,0 +1,3 @@
+-- The scheduler looks for scheduled drafts whose time has come
+CREATE INDEX IF NOT EXISTS idx_drafts_scheduled ON drafts (scheduled_at)
+    WHERE status = 'scheduled' AND deleted_at IS NULL;
@@ -0,0 +1,143 @@
+-- SQLite schema for single-user and local setups; mirrors the Postgres baseline.
+-- IDs are TEXT UUIDs generated by the application, JSON columns are TEXT and
+-- timestamps are stored in the driver's "sqlite" time format.
+
+CREATE TABLE workspaces (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE workspace_members (
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+-- The demo user's personal workspace, as seeded in Postgres
+INSERT INTO workspaces (id, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'demo@postificus.local', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+VALUES ('00000000-0000-0000-0000-000000000001', '00000000-0000-0000-0000-000000000001', 'owner', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+
+CREATE TABLE user_details (
+    user_id TEXT PRIMARY KEY,
+    full_name TEXT NOT NULL DEFAULT '',
+    username TEXT NOT NULL DEFAULT '',
+    headline TEXT NOT NULL DEFAULT '',
+    bio TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    website TEXT NOT NULL DEFAULT '',
+    public_email TEXT NOT NULL DEFAULT '',
+    skills TEXT NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE drafts (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL,
+    user_id TEXT,
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    cover_image TEXT NOT NULL DEFAULT '',
+    publish_targets TEXT NOT NULL DEFAULT '[]',
+    tags TEXT NOT NULL DEFAULT '[]',
+    subtitle TEXT NOT NULL DEFAULT '',
+    excerpt TEXT NOT NULL DEFAULT '',
+    series_name TEXT NOT NULL DEFAULT '',
+    series_order INTEGER NOT NULL DEFAULT 0,
+    last_saved_at TIMESTAMP,
+    is_published BOOLEAN NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'draft',
+    reviewer_id TEXT NOT NULL DEFAULT '',
+    scheduled_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX drafts_workspace_saved_idx ON drafts (workspace_id, last_saved_at DESC);
+
+CREATE TABLE draft_review_comments (
+    id TEXT PRIMARY KEY,
+    draft_id TEXT NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+    author_id TEXT NOT NULL,
+    body TEXT NOT NULL,
+    range_start INTEGER NOT NULL DEFAULT 0,
+    range_end INTEGER NOT NULL DEFAULT 0,
+    quote TEXT NOT NULL DEFAULT '',
+    resolved BOOLEAN NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX draft_review_comments_draft_idx ON draft_review_comments (draft_id);
+
+CREATE TABLE draft_audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    draft_id TEXT NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status TEXT NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX draft_audit_log_draft_idx ON draft_audit_log (draft_id, created_at);
+
+CREATE TABLE platform_tag_mappings (
+    workspace_id TEXT NOT NULL,
+    platform TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    platform_tag TEXT NOT NULL,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (workspace_id, platform, tag)
+);
+
+CREATE TABLE draft_revision_blobs (
+    hash TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    cover_image TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE draft_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    draft_id TEXT NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+    blob_hash TEXT NOT NULL REFERENCES draft_revision_blobs(hash),
+    author_id TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX draft_revisions_draft_idx ON draft_revisions (draft_id, id DESC);
+CREATE INDEX draft_revisions_blob_idx ON draft_revisions (blob_hash);
+
+CREATE TABLE user_credentials (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL,
+    platform TEXT NOT NULL,
+    account_name TEXT NOT NULL DEFAULT 'default',
+    credentials TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (workspace_id, platform, account_name)
+);
+
+CREATE TABLE unified_posts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL,
+    platform TEXT NOT NULL,
+    remote_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT '',
+    views INTEGER NOT NULL DEFAULT 0,
+    reactions INTEGER NOT NULL DEFAULT 0,
+    comments INTEGER NOT NULL DEFAULT 0,
+    published_at TIMESTAMP,
+    last_synced_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, platform, remote_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS publish_logs;
+DROP TABLE IF EXISTS outbox;
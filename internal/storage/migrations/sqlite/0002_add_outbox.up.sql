@@ -0,0 +1,27 @@
+-- Transactional outbox and publish job records; see the Postgres migration
+CREATE TABLE outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    queue TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    idempotency_key TEXT NOT NULL UNIQUE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (available_at) WHERE sent_at IS NULL;
+
+CREATE TABLE publish_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT UNIQUE,
+    workspace_id TEXT REFERENCES workspaces(id) ON DELETE CASCADE,
+    draft_id TEXT,
+    platform TEXT NOT NULL,
+    status TEXT NOT NULL,
+    external_url TEXT,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
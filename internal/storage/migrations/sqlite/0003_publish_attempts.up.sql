@@ -0,0 +1 @@
+ALTER TABLE publish_logs ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE outbox DROP COLUMN priority;
+ALTER TABLE outbox DROP COLUMN job_type;
@@ -0,0 +1,2 @@
+ALTER TABLE outbox ADD COLUMN job_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE outbox ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
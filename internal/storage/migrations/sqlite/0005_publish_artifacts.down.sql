@@ -0,0 +1 @@
+ALTER TABLE publish_logs DROP COLUMN artifacts_url;
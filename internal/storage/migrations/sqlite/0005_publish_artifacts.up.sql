@@ -0,0 +1 @@
+ALTER TABLE publish_logs ADD COLUMN artifacts_url TEXT;
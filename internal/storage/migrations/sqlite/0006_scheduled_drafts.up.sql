@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_drafts_scheduled ON drafts (scheduled_at)
+    WHERE status = 'scheduled' AND deleted_at IS NULL;
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OutboxMessage is a job waiting in the outbox to be put on the queue
+type OutboxMessage struct {
+	ID             int64
+	Queue          string
+	Type           string // Job type on a shared queue; empty if the queue's own
+	Priority       uint8
+	Payload        []byte
+	IdempotencyKey string // Unique; sent as the queue message ID
+	Attempts       int    // Times the message has been claimed
+	LastError      string
+	CreatedAt      time.Time
+}
+
+// OutboxRepository is the transactional outbox. Repositories that write a job
+// together with their own rows add it in the same transaction; a relay claims
+// pending messages, enqueues them and marks them sent.
+type OutboxRepository interface {
+	// Add stores messages in one transaction
+	Add(ctx context.Context, messages ...OutboxMessage) error
+	// Claim leases up to limit pending messages to the caller. A message that is
+	// neither marked sent nor released before the lease runs out is claimed again.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxMessage, error)
+	MarkSent(ctx context.Context, id int64) error
+	// Release gives a claimed message back after a failed send; it is due again after retryIn
+	Release(ctx context.Context, id int64, cause string, retryIn time.Duration) error
+	// PurgeSent deletes messages sent before the cutoff
+	PurgeSent(ctx context.Context, sentBefore time.Time) (int64, error)
+}
+
+type PostgresOutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(db *pgxpool.Pool) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{db: db}
+}
+
+func (r *PostgresOutboxRepository) Add(ctx context.Context, messages ...OutboxMessage) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := addOutboxMessages(ctx, tx, messages); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// addOutboxMessages writes messages inside a caller's transaction
+func addOutboxMessages(ctx context.Context, tx pgx.Tx, messages []OutboxMessage) error {
+	for _, m := range messages {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO outbox (queue, job_type, priority, payload, idempotency_key, available_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		`, m.Queue, m.Type, int16(m.Priority), m.Payload, m.IdempotencyKey)
+		if err != nil {
+			return fmt.Errorf("failed to add outbox message: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *PostgresOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxMessage, error) {
+	// SKIP LOCKED lets several API instances relay side by side without claiming the same rows
+	rows, err := r.db.Query(ctx, `
+		UPDATE outbox SET available_at = NOW() + $2 * INTERVAL '1 millisecond', attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE sent_at IS NULL AND available_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, queue, job_type, priority, payload, idempotency_key, attempts, COALESCE(last_error, ''), created_at
+	`, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []OutboxMessage{}
+	for rows.Next() {
+		var m OutboxMessage
+		var priority int16
+		if err := rows.Scan(&m.ID, &m.Queue, &m.Type, &priority, &m.Payload, &m.IdempotencyKey, &m.Attempts, &m.LastError, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		m.Priority = uint8(priority)
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING doesn't keep the subquery's order
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+func (r *PostgresOutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, `UPDATE outbox SET sent_at = NOW(), last_error = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message sent: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresOutboxRepository) Release(ctx context.Context, id int64, cause string, retryIn time.Duration) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE outbox SET last_error = $2, available_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id = $1 AND sent_at IS NULL
+	`, id, cause, retryIn.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to release outbox message: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresOutboxRepository) PurgeSent(ctx context.Context, sentBefore time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM outbox WHERE sent_at < $1`, sentBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
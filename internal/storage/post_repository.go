@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostRepository caches posts synced from the platforms (and local drafts) for the dashboard
+type PostRepository interface {
+	UpsertPost(ctx context.Context, userID string, post domain.UnifiedPost) error
+	// ListPosts returns a user's posts, newest first; local drafts carry their publish targets
+	ListPosts(ctx context.Context, userID string, limit int) ([]domain.UnifiedPost, error)
+}
+
+type PostgresPostRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostRepository(db *pgxpool.Pool) *PostgresPostRepository {
+	return &PostgresPostRepository{db: db}
+}
+
+func (r *PostgresPostRepository) UpsertPost(ctx context.Context, userID string, post domain.UnifiedPost) error {
+	query := `
+		INSERT INTO unified_posts (user_id, platform, remote_id, title, url, status, views, reactions, comments, published_at, last_synced_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		ON CONFLICT (user_id, platform, remote_id) 
+		DO UPDATE SET 
+			title = EXCLUDED.title,
+			url = EXCLUDED.url,
+			status = EXCLUDED.status,
+			views = EXCLUDED.views,
+			reactions = EXCLUDED.reactions,
+			comments = EXCLUDED.comments,
+			published_at = EXCLUDED.published_at,
+			last_synced_at = NOW();
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		userID,
+		post.Platform,
+		post.RemoteID,
+		post.Title,
+		post.URL,
+		post.Status,
+		post.Views,
+		post.Reactions,
+		post.Comments,
+		post.PublishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert post: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPostRepository) ListPosts(ctx context.Context, userID string, limit int) ([]domain.UnifiedPost, error) {
+	query := `
+		SELECT
+			up.platform,
+			up.remote_id,
+			up.title,
+			up.url,
+			up.status,
+			up.views,
+			up.reactions,
+			up.comments,
+			up.published_at,
+			d.publish_targets
+		FROM unified_posts up
+		LEFT JOIN drafts d
+			ON up.platform = 'postificus'
+			AND d.id::text = up.remote_id
+			AND d.user_id = up.user_id
+		WHERE up.user_id = $1
+		ORDER BY up.published_at DESC NULLS LAST, up.last_synced_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []domain.UnifiedPost
+	for rows.Next() {
+		var p domain.UnifiedPost
+		var pubAt *time.Time // Handle nullable timestamp
+		var publishTargets []byte
+
+		err := rows.Scan(&p.Platform, &p.RemoteID, &p.Title, &p.URL, &p.Status, &p.Views, &p.Reactions, &p.Comments, &pubAt, &publishTargets)
+		if err != nil {
+			continue // Skip malformed rows
+		}
+
+		if pubAt != nil {
+			p.PublishedAt = *pubAt
+		}
+		if len(publishTargets) > 0 {
+			_ = json.Unmarshal(publishTargets, &p.PublishTargets)
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
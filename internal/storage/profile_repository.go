@@ -8,6 +8,7 @@ import (
 	"postificus/internal/domain"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ProfileRepository interface {
@@ -15,10 +16,12 @@ type ProfileRepository interface {
 	SaveProfile(ctx context.Context, profile *domain.Profile) error
 }
 
-type PostgresProfileRepository struct{}
+type PostgresProfileRepository struct {
+	db *pgxpool.Pool
+}
 
-func NewProfileRepository() *PostgresProfileRepository {
-	return &PostgresProfileRepository{}
+func NewProfileRepository(db *pgxpool.Pool) *PostgresProfileRepository {
+	return &PostgresProfileRepository{db: db}
 }
 
 func (r *PostgresProfileRepository) GetProfile(ctx context.Context, userID string) (*domain.Profile, error) {
@@ -39,7 +42,7 @@ func (r *PostgresProfileRepository) GetProfile(ctx context.Context, userID strin
 		skillsJSON  []byte
 	)
 
-	err := DB.QueryRow(ctx, query, userID).Scan(
+	err := r.db.QueryRow(ctx, query, userID).Scan(
 		&fullName,
 		&username,
 		&headline,
@@ -98,7 +101,7 @@ func (r *PostgresProfileRepository) SaveProfile(ctx context.Context, profile *do
 			updated_at = NOW()
 	`
 
-	_, err = DB.Exec(
+	_, err = r.db.Exec(
 		ctx,
 		query,
 		profile.UserID,
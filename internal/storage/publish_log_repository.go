@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrPublishInProgress means another worker is running the publish job right now
+var ErrPublishInProgress = errors.New("publish already in progress")
+
+// PublishLogRepository keeps one record per publish job, keyed by its idempotency key
+type PublishLogRepository interface {
+	// QueuePublish records a queued publish job and adds the outbox message that
+	// will run it, in one transaction: either both exist or neither does.
+	// A job that already has a record is only queued again if it failed; otherwise
+	// entry is filled with the existing record and queued is false.
+	QueuePublish(ctx context.Context, entry *domain.PublishLog, message OutboxMessage) (queued bool, err error)
+	// ClaimPublish marks the job processing for the calling worker and counts the attempt.
+	// A job that already succeeded is returned unchanged; one another worker started
+	// less than staleAfter ago returns ErrPublishInProgress; ErrNotFound if there is no record.
+	ClaimPublish(ctx context.Context, jobID string, staleAfter time.Duration) (*domain.PublishLog, error)
+	// ReleasePublish hands back a claim the worker didn't act on: the job is queued
+	// again and the attempt uncounted. ErrNotFound if the job isn't being processed.
+	ReleasePublish(ctx context.Context, jobID string) error
+	// UpdatePublishStatus records the job's progress; ErrNotFound if the job has no record
+	UpdatePublishStatus(ctx context.Context, jobID string, status domain.PublishStatus, externalURL string, errorMessage string) error
+	// AttachPublishArtifacts links the debugging trail of a failed attempt; ErrNotFound if the job has no record
+	AttachPublishArtifacts(ctx context.Context, jobID string, artifactsURL string) error
+	GetPublishLog(ctx context.Context, jobID string) (*domain.PublishLog, error)
+}
+
+// claimOutcome turns a record that couldn't be claimed into ClaimPublish's answer
+func claimOutcome(entry *domain.PublishLog) (*domain.PublishLog, error) {
+	if entry.Status == domain.PublishSucceeded {
+		return entry, nil
+	}
+	return nil, ErrPublishInProgress
+}
+
+type PostgresPublishLogRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPublishLogRepository(db *pgxpool.Pool) *PostgresPublishLogRepository {
+	return &PostgresPublishLogRepository{db: db}
+}
+
+const publishLogColumns = `id, job_id, COALESCE(workspace_id::text, ''), COALESCE(draft_id::text, ''), platform, status,
+	COALESCE(external_url, ''), COALESCE(error_message, ''), attempts, COALESCE(artifacts_url, ''), created_at, updated_at`
+
+func scanPublishLog(row pgx.Row, entry *domain.PublishLog) error {
+	var status string
+	err := row.Scan(&entry.ID, &entry.JobID, &entry.WorkspaceID, &entry.DraftID, &entry.Platform, &status,
+		&entry.ExternalURL, &entry.Error, &entry.Attempts, &entry.ArtifactsURL, &entry.CreatedAt, &entry.UpdatedAt)
+	entry.Status = domain.PublishStatus(status)
+	return err
+}
+
+func (r *PostgresPublishLogRepository) QueuePublish(ctx context.Context, entry *domain.PublishLog, message OutboxMessage) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = scanPublishLog(tx.QueryRow(ctx, `
+		INSERT INTO publish_logs (job_id, workspace_id, draft_id, platform, status, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, NOW(), NOW())
+		ON CONFLICT (job_id) DO NOTHING
+		RETURNING `+publishLogColumns,
+		entry.JobID, entry.WorkspaceID, entry.DraftID, entry.Platform, string(domain.PublishQueued)), entry)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The job exists already; lock it so a concurrent request can't queue it twice
+		err = scanPublishLog(tx.QueryRow(ctx, `SELECT `+publishLogColumns+` FROM publish_logs WHERE job_id = $1 FOR UPDATE`, entry.JobID), entry)
+		if err != nil {
+			return false, fmt.Errorf("failed to read publish job: %w", err)
+		}
+		if entry.Status != domain.PublishFailed {
+			return false, tx.Commit(ctx)
+		}
+
+		err = scanPublishLog(tx.QueryRow(ctx, `
+			UPDATE publish_logs SET status = $2, error_message = NULL, updated_at = NOW()
+			WHERE job_id = $1
+			RETURNING `+publishLogColumns, entry.JobID, string(domain.PublishQueued)), entry)
+		if err != nil {
+			return false, fmt.Errorf("failed to requeue publish job: %w", err)
+		}
+		// The earlier run's message was sent long ago; make room for the new one
+		if _, err := tx.Exec(ctx, `DELETE FROM outbox WHERE idempotency_key = $1`, message.IdempotencyKey); err != nil {
+			return false, fmt.Errorf("failed to requeue publish job: %w", err)
+		}
+	} else if err != nil {
+		return false, fmt.Errorf("failed to record publish job: %w", err)
+	}
+
+	if err := addOutboxMessages(ctx, tx, []OutboxMessage{message}); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+func (r *PostgresPublishLogRepository) ClaimPublish(ctx context.Context, jobID string, staleAfter time.Duration) (*domain.PublishLog, error) {
+	var entry domain.PublishLog
+	err := scanPublishLog(r.db.QueryRow(ctx, `
+		UPDATE publish_logs SET status = $2, attempts = attempts + 1, updated_at = NOW()
+		WHERE job_id = $1
+			AND (status IN ($3, $4) OR (status = $2 AND updated_at < NOW() - $5 * INTERVAL '1 millisecond'))
+		RETURNING `+publishLogColumns,
+		jobID, string(domain.PublishProcessing), string(domain.PublishQueued), string(domain.PublishFailed), staleAfter.Milliseconds()), &entry)
+	if err == nil {
+		return &entry, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to claim publish job: %w", err)
+	}
+
+	existing, err := r.GetPublishLog(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return claimOutcome(existing)
+}
+
+func (r *PostgresPublishLogRepository) UpdatePublishStatus(ctx context.Context, jobID string, status domain.PublishStatus, externalURL string, errorMessage string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE publish_logs SET status = $2, external_url = NULLIF($3, ''), error_message = NULLIF($4, ''), updated_at = NOW()
+		WHERE job_id = $1
+	`, jobID, string(status), externalURL, errorMessage)
+	if err != nil {
+		return fmt.Errorf("failed to update publish job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPublishLogRepository) ReleasePublish(ctx context.Context, jobID string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE publish_logs SET status = $2, attempts = GREATEST(attempts - 1, 0), updated_at = NOW()
+		WHERE job_id = $1 AND status = $3
+	`, jobID, string(domain.PublishQueued), string(domain.PublishProcessing))
+	if err != nil {
+		return fmt.Errorf("failed to release publish job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPublishLogRepository) AttachPublishArtifacts(ctx context.Context, jobID string, artifactsURL string) error {
+	tag, err := r.db.Exec(ctx, `UPDATE publish_logs SET artifacts_url = NULLIF($2, '') WHERE job_id = $1`, jobID, artifactsURL)
+	if err != nil {
+		return fmt.Errorf("failed to link publish artifacts: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPublishLogRepository) GetPublishLog(ctx context.Context, jobID string) (*domain.PublishLog, error) {
+	var entry domain.PublishLog
+	err := scanPublishLog(r.db.QueryRow(ctx, `SELECT `+publishLogColumns+` FROM publish_logs WHERE job_id = $1`, jobID), &entry)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publish job: %w", err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"postificus/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReviewRepository stores review comments and the workflow audit log
+type ReviewRepository interface {
+	AddComment(ctx context.Context, comment *domain.ReviewComment) error
+	ListComments(ctx context.Context, draftID string) ([]domain.ReviewComment, error)
+	ResolveComment(ctx context.Context, draftID string, commentID string) error
+	AddAuditEntry(ctx context.Context, entry *domain.AuditEntry) error
+	ListAuditEntries(ctx context.Context, draftID string) ([]domain.AuditEntry, error)
+}
+
+type PostgresReviewRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReviewRepository(db *pgxpool.Pool) *PostgresReviewRepository {
+	return &PostgresReviewRepository{db: db}
+}
+
+func (r *PostgresReviewRepository) AddComment(ctx context.Context, comment *domain.ReviewComment) error {
+	query := `
+		INSERT INTO draft_review_comments (draft_id, author_id, body, range_start, range_end, quote, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		comment.DraftID,
+		comment.AuthorID,
+		comment.Body,
+		comment.RangeStart,
+		comment.RangeEnd,
+		comment.Quote,
+	).Scan(&comment.ID, &comment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add comment: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresReviewRepository) ListComments(ctx context.Context, draftID string) ([]domain.ReviewComment, error) {
+	query := `
+		SELECT id, author_id, body, range_start, range_end, COALESCE(quote, ''), resolved, created_at
+		FROM draft_review_comments
+		WHERE draft_id = $1
+		ORDER BY range_start, created_at
+	`
+	rows, err := r.db.Query(ctx, query, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []domain.ReviewComment{}
+	for rows.Next() {
+		c := domain.ReviewComment{DraftID: draftID}
+		if err := rows.Scan(&c.ID, &c.AuthorID, &c.Body, &c.RangeStart, &c.RangeEnd, &c.Quote, &c.Resolved, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+func (r *PostgresReviewRepository) ResolveComment(ctx context.Context, draftID string, commentID string) error {
+	tag, err := r.db.Exec(ctx, `UPDATE draft_review_comments SET resolved = TRUE WHERE draft_id = $1 AND id = $2`, draftID, commentID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresReviewRepository) AddAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO draft_audit_log (draft_id, actor_id, action, from_status, to_status, detail, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, NOW())
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		entry.DraftID,
+		entry.ActorID,
+		entry.Action,
+		string(entry.FromStatus),
+		string(entry.ToStatus),
+		entry.Detail,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresReviewRepository) ListAuditEntries(ctx context.Context, draftID string) ([]domain.AuditEntry, error) {
+	query := `
+		SELECT id, actor_id, action, COALESCE(from_status, ''), COALESCE(to_status, ''), COALESCE(detail, ''), created_at
+		FROM draft_audit_log
+		WHERE draft_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := r.db.Query(ctx, query, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		e := domain.AuditEntry{DraftID: draftID}
+		var from, to string
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &from, &to, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		e.FromStatus = domain.DraftStatus(from)
+		e.ToStatus = domain.DraftStatus(to)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RevisionRepository stores immutable, content-addressed draft revisions
+type RevisionRepository interface {
+	// AddRevision stores the snapshot blob (deduplicated by hash) and appends a revision
+	AddRevision(ctx context.Context, rev *domain.Revision) error
+	// LatestRevision returns the newest revision of a draft, or nil if it has none
+	LatestRevision(ctx context.Context, draftID string) (*domain.Revision, error)
+	// ListRevisions returns revision metadata (without content), newest first
+	ListRevisions(ctx context.Context, draftID string) ([]domain.Revision, error)
+	GetRevision(ctx context.Context, draftID string, id int64) (*domain.Revision, error)
+	// PruneAutosaves deletes autosave revisions older than the cutoff, keeping each draft's latest revision
+	PruneAutosaves(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+type PostgresRevisionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRevisionRepository(db *pgxpool.Pool) *PostgresRevisionRepository {
+	return &PostgresRevisionRepository{db: db}
+}
+
+func (r *PostgresRevisionRepository) AddRevision(ctx context.Context, rev *domain.Revision) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO draft_revision_blobs (hash, title, content, cover_image, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (hash) DO NOTHING
+	`, rev.Hash, rev.Title, rev.Content, rev.CoverImage)
+	if err != nil {
+		return fmt.Errorf("failed to store revision blob: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO draft_revisions (draft_id, blob_hash, author_id, kind, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at
+	`, rev.DraftID, rev.Hash, rev.AuthorID, string(rev.Kind)).Scan(&rev.ID, &rev.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add revision: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRevisionRepository) LatestRevision(ctx context.Context, draftID string) (*domain.Revision, error) {
+	query := `
+		SELECT r.id, r.blob_hash, COALESCE(r.author_id::text, ''), r.kind, COALESCE(b.title, ''), r.created_at
+		FROM draft_revisions r
+		JOIN draft_revision_blobs b ON b.hash = r.blob_hash
+		WHERE r.draft_id = $1
+		ORDER BY r.id DESC
+		LIMIT 1
+	`
+	rev := domain.Revision{DraftID: draftID}
+	var kind string
+	err := r.db.QueryRow(ctx, query, draftID).Scan(&rev.ID, &rev.Hash, &rev.AuthorID, &kind, &rev.Title, &rev.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest revision: %w", err)
+	}
+	rev.Kind = domain.RevisionKind(kind)
+	return &rev, nil
+}
+
+func (r *PostgresRevisionRepository) ListRevisions(ctx context.Context, draftID string) ([]domain.Revision, error) {
+	query := `
+		SELECT r.id, r.blob_hash, COALESCE(r.author_id::text, ''), r.kind, COALESCE(b.title, ''), r.created_at
+		FROM draft_revisions r
+		JOIN draft_revision_blobs b ON b.hash = r.blob_hash
+		WHERE r.draft_id = $1
+		ORDER BY r.id DESC
+	`
+	rows, err := r.db.Query(ctx, query, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []domain.Revision{}
+	for rows.Next() {
+		rev := domain.Revision{DraftID: draftID}
+		var kind string
+		if err := rows.Scan(&rev.ID, &rev.Hash, &rev.AuthorID, &kind, &rev.Title, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		rev.Kind = domain.RevisionKind(kind)
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *PostgresRevisionRepository) GetRevision(ctx context.Context, draftID string, id int64) (*domain.Revision, error) {
+	query := `
+		SELECT r.blob_hash, COALESCE(r.author_id::text, ''), r.kind,
+		       COALESCE(b.title, ''), COALESCE(b.content, ''), COALESCE(b.cover_image, ''), r.created_at
+		FROM draft_revisions r
+		JOIN draft_revision_blobs b ON b.hash = r.blob_hash
+		WHERE r.draft_id = $1 AND r.id = $2
+	`
+	rev := domain.Revision{ID: id, DraftID: draftID}
+	var kind string
+	err := r.db.QueryRow(ctx, query, draftID, id).Scan(&rev.Hash, &rev.AuthorID, &kind, &rev.Title, &rev.Content, &rev.CoverImage, &rev.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	rev.Kind = domain.RevisionKind(kind)
+	return &rev, nil
+}
+
+func (r *PostgresRevisionRepository) PruneAutosaves(ctx context.Context, olderThan time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM draft_revisions r
+		WHERE r.kind = 'autosave'
+		  AND r.created_at < $1
+		  AND r.id <> (SELECT MAX(id) FROM draft_revisions latest WHERE latest.draft_id = r.draft_id)
+	`, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune revisions: %w", err)
+	}
+
+	// Only drop old orphans so a blob being re-referenced by a concurrent save survives
+	_, err = r.db.Exec(ctx, `
+		DELETE FROM draft_revision_blobs b
+		WHERE b.created_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM draft_revisions r WHERE r.blob_hash = b.hash)
+	`, olderThan)
+	if err != nil {
+		return tag.RowsAffected(), fmt.Errorf("failed to prune revision blobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
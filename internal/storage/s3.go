@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"mime/multipart"
@@ -18,6 +20,14 @@ var S3Client *minio.Client
 var S3Bucket string
 var S3PublicURL string
 
+// ArtifactsBucket keeps the failure artifacts of browser runs. Unlike the
+// uploads bucket it must stay private: screenshots and DOM snapshots show
+// logged-in sessions. Empty means artifacts aren't uploaded at all.
+var ArtifactsBucket string
+
+// ErrNoArtifactsBucket means ARTIFACTS_BUCKET isn't configured
+var ErrNoArtifactsBucket = errors.New("artifacts bucket not configured")
+
 func InitS3() error {
 	endpoint := os.Getenv("S3_ENDPOINT")
 	accessKey := os.Getenv("S3_ACCESS_KEY")
@@ -29,6 +39,12 @@ func InitS3() error {
 	// For Supabase/AWS, we construct a public URL base
 	// S3_PUBLIC_URL can be set explicitly, or we try to guess
 	S3PublicURL = os.Getenv("S3_PUBLIC_URL")
+	ArtifactsBucket = os.Getenv("ARTIFACTS_BUCKET")
+	if ArtifactsBucket != "" && ArtifactsBucket == S3Bucket {
+		// The uploads bucket serves its objects publicly
+		log.Printf("⚠️ ARTIFACTS_BUCKET is the public uploads bucket; failure artifacts stay local")
+		ArtifactsBucket = ""
+	}
 
 	if endpoint == "" || accessKey == "" || secretKey == "" {
 		return fmt.Errorf("S3 configuration missing")
@@ -68,6 +84,20 @@ func InitS3() error {
 		}
 	}
 
+	// New buckets are private; the artifacts bucket never gets a public policy
+	if ArtifactsBucket != "" {
+		exists, errBucket := S3Client.BucketExists(ctx, ArtifactsBucket)
+		if errBucket != nil {
+			log.Printf("⚠️ Check bucket exists error: %v", errBucket)
+		} else if !exists {
+			if err := S3Client.MakeBucket(ctx, ArtifactsBucket, minio.MakeBucketOptions{}); err != nil {
+				log.Printf("⚠️ Failed to create bucket %s: %v", ArtifactsBucket, err)
+			} else {
+				log.Printf("✅ Created bucket: %s", ArtifactsBucket)
+			}
+		}
+	}
+
 	log.Printf("✅ S3 Client initialized for endpoint: %s", endpoint)
 	return nil
 }
@@ -116,3 +146,42 @@ func UploadFile(ctx context.Context, file *multipart.FileHeader) (string, error)
 		return fmt.Sprintf("https://%s/%s/%s", S3Client.EndpointURL().Host, S3Bucket, info.Key), nil
 	}
 }
+
+// PutArtifact stores data under key in the artifacts bucket and returns its s3:// location
+func PutArtifact(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	if S3Client == nil || ArtifactsBucket == "" {
+		return "", ErrNoArtifactsBucket
+	}
+	_, err := S3Client.PutObject(ctx, ArtifactsBucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("S3 upload failed: %w", err)
+	}
+	return fmt.Sprintf("s3://%s/%s", ArtifactsBucket, key), nil
+}
+
+// PresignArtifacts returns a download URL, valid for expiry, for each file
+// saved under prefix in the artifacts bucket, by file name; ErrNotFound if
+// there are none.
+func PresignArtifacts(ctx context.Context, prefix string, expiry time.Duration) (map[string]string, error) {
+	if S3Client == nil || ArtifactsBucket == "" {
+		return nil, ErrNoArtifactsBucket
+	}
+	prefix = strings.Trim(prefix, "/") + "/"
+	urls := make(map[string]string)
+	for object := range S3Client.ListObjects(ctx, ArtifactsBucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("S3 list failed: %w", object.Err)
+		}
+		url, err := S3Client.PresignedGetObject(ctx, ArtifactsBucket, object.Key, expiry, nil)
+		if err != nil {
+			return nil, fmt.Errorf("S3 presign failed: %w", err)
+		}
+		urls[strings.TrimPrefix(object.Key, prefix)] = url.String()
+	}
+	if len(urls) == 0 {
+		return nil, ErrNotFound
+	}
+	return urls, nil
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LocalPlatform is the pseudo-platform local drafts are listed under
+const LocalPlatform = "postificus"
+
+// SearchQuery is a full-text query over a workspace's drafts and a user's synced posts
+type SearchQuery struct {
+	Text        string // websearch syntax: "quoted phrases", -exclusions, OR
+	WorkspaceID string
+	UserID      string
+	Platforms   []string // Empty means all; LocalPlatform selects drafts
+	Statuses    []string
+	Limit       int
+	Offset      int
+}
+
+type SearchRepository interface {
+	Search(ctx context.Context, query SearchQuery) ([]domain.SearchResult, error)
+}
+
+type PostgresSearchRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSearchRepository(db *pgxpool.Pool) *PostgresSearchRepository {
+	return &PostgresSearchRepository{db: db}
+}
+
+// Every backend marks matches in plain text with these control characters;
+// markupSnippet then escapes the text and turns them into <mark> tags
+const (
+	markStart = "\x02"
+	markStop  = "\x03"
+)
+
+var (
+	markReplacer      = strings.NewReplacer(markStart, "<mark>", markStop, "</mark>")
+	stripMarkReplacer = strings.NewReplacer(markStart, "", markStop, "")
+)
+
+// plainText turns a draft's HTML into the text snippets are cut from:
+// tags dropped, then entities decoded, so "&lt;b&gt;" reads "<b>"
+func plainText(body string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(stripMarkReplacer.Replace(body), " "))
+}
+
+// markupSnippet HTML-escapes marked plain text and turns its marks into <mark> tags
+func markupSnippet(marked string) string {
+	return markReplacer.Replace(html.EscapeString(marked))
+}
+
+// headlineOptions controls ts_headline snippets
+const headlineOptions = `StartSel="` + markStart + `", StopSel="` + markStop + `", MaxFragments=2, MaxWords=25, MinWords=8, FragmentDelimiter=" … "`
+
+// sqlStripMarks removes the mark characters from a text column (chr(2) and chr(3), markStart and markStop)
+func sqlStripMarks(column string) string {
+	return "replace(replace(" + column + ", chr(2), ''), chr(3), '')"
+}
+
+func (r *PostgresSearchRepository) Search(ctx context.Context, query SearchQuery) ([]domain.SearchResult, error) {
+	args := []interface{}{query.Text}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var branches []string
+
+	if query.wantsPlatform(LocalPlatform) {
+		conditions := []string{"d.workspace_id = " + arg(query.WorkspaceID), "d.deleted_at IS NULL", "d.search_vector @@ q.query"}
+		if len(query.Statuses) > 0 {
+			conditions = append(conditions, "d.status = ANY("+arg(query.Statuses)+")")
+		}
+		branches = append(branches, `
+			SELECT 'draft' AS kind, d.id::text AS id, '`+LocalPlatform+`' AS platform, COALESCE(d.title, '') AS title,
+				COALESCE(d.status, '') AS status, '/editor?draft=' || d.id AS url,
+				`+sqlStripMarks("regexp_replace(COALESCE(d.content, ''), '<[^>]+>', ' ', 'g')")+` AS body,
+				ts_rank_cd(d.search_vector, q.query) AS rank, d.last_saved_at AS updated_at
+			FROM drafts d, q
+			WHERE `+strings.Join(conditions, " AND "))
+	}
+
+	postConditions := []string{"up.user_id = " + arg(query.UserID), "up.platform <> '" + LocalPlatform + "'", "up.search_vector @@ q.query"}
+	if len(query.Platforms) > 0 {
+		postConditions = append(postConditions, "up.platform = ANY("+arg(query.Platforms)+")")
+	}
+	if len(query.Statuses) > 0 {
+		postConditions = append(postConditions, "up.status = ANY("+arg(query.Statuses)+")")
+	}
+	branches = append(branches, `
+		SELECT 'post' AS kind, up.remote_id AS id, up.platform, up.title,
+			COALESCE(up.status, '') AS status, up.url, '' AS body,
+			ts_rank_cd(up.search_vector, q.query) AS rank, COALESCE(up.published_at, up.last_synced_at) AS updated_at
+		FROM unified_posts up, q
+		WHERE `+strings.Join(postConditions, " AND "))
+
+	// Rank and page first, then build headlines only for the rows returned. ts_headline
+	// returns its input verbatim apart from the marks; bodies still hold their entities,
+	// which are decoded before everything is escaped the way the other backends do it
+	sql := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query),
+		hits AS (
+			` + strings.Join(branches, "\n\t\t\tUNION ALL\n") + `
+			ORDER BY rank DESC, updated_at DESC NULLS LAST
+			LIMIT ` + arg(query.Limit) + ` OFFSET ` + arg(query.Offset) + `
+		)
+		SELECT hits.kind, hits.id, hits.platform, hits.title,
+			ts_headline('english', ` + sqlStripMarks("hits.title") + `, q.query, ` + arg(headlineOptions) + `),
+			CASE WHEN hits.body = '' THEN '' ELSE ts_headline('english', hits.body, q.query, ` + arg(headlineOptions) + `) END,
+			hits.status, hits.url, hits.rank, hits.updated_at
+		FROM hits, q
+		ORDER BY hits.rank DESC, hits.updated_at DESC NULLS LAST
+	`
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	results := []domain.SearchResult{}
+	for rows.Next() {
+		var res domain.SearchResult
+		var rank float32
+		var updatedAt *time.Time
+		if err := rows.Scan(&res.Kind, &res.ID, &res.Platform, &res.Title, &res.TitleHighlight, &res.Snippet,
+			&res.Status, &res.URL, &rank, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		res.TitleHighlight = markupSnippet(res.TitleHighlight)
+		res.Snippet = markupSnippet(html.UnescapeString(res.Snippet))
+		res.Rank = float64(rank)
+		if updatedAt != nil {
+			res.UpdatedAt = *updatedAt
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,635 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Drafts
+
+type SQLiteDraftRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteDraftRepository) SaveDraft(ctx context.Context, draft *domain.Draft, expectedVersion int64) error {
+	publishTargets, err := json.Marshal(nonNilStrings(draft.PublishTargets))
+	if err != nil {
+		return fmt.Errorf("failed to marshal publish targets: %w", err)
+	}
+	tags, err := json.Marshal(nonNilStrings(draft.Tags))
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	// Same guards as Postgres: no writes across workspaces, into the trash or from a stale copy
+	query := `
+		INSERT INTO drafts (id, workspace_id, user_id, title, content, cover_image, publish_targets, tags,
+			subtitle, excerpt, series_name, series_order, last_saved_at, is_published, version)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?9, ?10, ?11, ?12, ?13, ?14, 0, 1)
+		ON CONFLICT (id) DO UPDATE SET
+			title = excluded.title,
+			content = excluded.content,
+			cover_image = excluded.cover_image,
+			publish_targets = excluded.publish_targets,
+			tags = excluded.tags,
+			subtitle = excluded.subtitle,
+			excerpt = excluded.excerpt,
+			series_name = excluded.series_name,
+			series_order = excluded.series_order,
+			last_saved_at = excluded.last_saved_at,
+			version = drafts.version + 1
+		WHERE drafts.workspace_id = excluded.workspace_id
+			AND drafts.deleted_at IS NULL
+			AND (?8 < 0 OR drafts.version = ?8)
+		RETURNING version
+	`
+	err = r.db.QueryRowContext(ctx, query, draft.ID, draft.WorkspaceID, draft.UserID, draft.Title, draft.Content, draft.CoverImage,
+		string(publishTargets), expectedVersion, string(tags), draft.Subtitle, draft.Excerpt, draft.Series, draft.SeriesOrder, sqliteNow()).
+		Scan(&draft.Version)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to execute save query: %w", err)
+	}
+
+	var workspaceID string
+	var deletedAt *time.Time
+	err = r.db.QueryRowContext(ctx, `SELECT workspace_id, deleted_at FROM drafts WHERE id = ?`, draft.ID).Scan(&workspaceID, &deletedAt)
+	if err != nil || workspaceID != draft.WorkspaceID || deletedAt != nil {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func (r *SQLiteDraftRepository) UpdateDashboardCache(ctx context.Context, draft *domain.Draft) error {
+	query := `
+		INSERT INTO unified_posts (user_id, platform, remote_id, title, url, status, published_at, last_synced_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?7)
+		ON CONFLICT (user_id, platform, remote_id)
+		DO UPDATE SET
+			title = excluded.title,
+			url = excluded.url,
+			status = excluded.status,
+			published_at = excluded.published_at,
+			last_synced_at = excluded.last_synced_at
+	`
+	status := string(draft.Status)
+	if status == "" {
+		status = string(domain.StatusDraft)
+	}
+	_, err := r.db.ExecContext(ctx, query, draft.UserID, LocalPlatform, draft.ID, draft.Title, fmt.Sprintf("/editor?draft=%s", draft.ID), status, sqliteNow())
+	if err != nil {
+		return fmt.Errorf("failed to update dashboard cache: %w", err)
+	}
+	return nil
+}
+
+// sqliteDraftColumns is the SELECT list read by scanSQLiteDraft
+func sqliteDraftColumns(withContent bool) string {
+	content := "content"
+	if !withContent {
+		content = "''"
+	}
+	return `id, workspace_id, COALESCE(user_id, ''), title, ` + content + `, cover_image, publish_targets, last_saved_at, is_published,
+		status, reviewer_id, scheduled_at, version, tags, deleted_at, subtitle, excerpt, series_name, series_order`
+}
+
+func scanSQLiteDraft(row sqlRow) (*domain.Draft, error) {
+	var draft domain.Draft
+	var publishTargets, tags string
+	var lastSavedAt *time.Time
+	err := row.Scan(&draft.ID, &draft.WorkspaceID, &draft.UserID, &draft.Title, &draft.Content, &draft.CoverImage, &publishTargets,
+		&lastSavedAt, &draft.IsPublished, &draft.Status, &draft.ReviewerID, &draft.ScheduledAt, &draft.Version, &tags, &draft.DeletedAt,
+		&draft.Subtitle, &draft.Excerpt, &draft.Series, &draft.SeriesOrder)
+	if err != nil {
+		return nil, err
+	}
+	if lastSavedAt != nil {
+		draft.LastSavedAt = *lastSavedAt
+	}
+	draft.PublishTargets = []string{}
+	_ = json.Unmarshal([]byte(publishTargets), &draft.PublishTargets)
+	draft.Tags = []string{}
+	_ = json.Unmarshal([]byte(tags), &draft.Tags)
+	return &draft, nil
+}
+
+func (r *SQLiteDraftRepository) GetDraft(ctx context.Context, id string, workspaceID string) (*domain.Draft, error) {
+	query := `SELECT ` + sqliteDraftColumns(true) + ` FROM drafts WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL`
+	draft, err := scanSQLiteDraft(r.db.QueryRowContext(ctx, query, id, workspaceID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return draft, err
+}
+
+func (r *SQLiteDraftRepository) UpdateWorkflow(ctx context.Context, draft *domain.Draft) error {
+	query := `
+		UPDATE drafts
+		SET status = ?, reviewer_id = ?, scheduled_at = ?, is_published = ?
+		WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
+	`
+	var scheduledAt any
+	if draft.ScheduledAt != nil {
+		scheduledAt = draft.ScheduledAt.UTC()
+	}
+	result, err := r.db.ExecContext(ctx, query, string(draft.Status), draft.ReviewerID, scheduledAt, draft.IsPublished, draft.ID, draft.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to update workflow: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteDraftRepository) ListDrafts(ctx context.Context, filter DraftFilter) ([]domain.Draft, int, error) {
+	conditions := []string{"workspace_id = ?"}
+	args := []any{filter.WorkspaceID}
+
+	if filter.Trashed {
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	} else {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			placeholders[i] = "?"
+			args = append(args, string(status))
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.Target != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM json_each(drafts.publish_targets) WHERE value = ?)")
+		args = append(args, filter.Target)
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM json_each(drafts.tags) WHERE value = ?)")
+		args = append(args, filter.Tag)
+	}
+	if filter.UpdatedAfter != nil {
+		conditions = append(conditions, "last_saved_at >= ?")
+		args = append(args, filter.UpdatedAfter.UTC())
+	}
+	if filter.UpdatedBefore != nil {
+		conditions = append(conditions, "last_saved_at < ?")
+		args = append(args, filter.UpdatedBefore.UTC())
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM drafts WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count drafts: %w", err)
+	}
+
+	column, ok := DraftSortColumns[filter.Sort]
+	if !ok {
+		column = DraftSortColumns["updated"]
+	}
+	direction := "DESC NULLS LAST"
+	if filter.Ascending {
+		direction = "ASC NULLS LAST"
+	}
+
+	query := `SELECT ` + sqliteDraftColumns(false) + `
+		FROM drafts
+		WHERE ` + where + `
+		ORDER BY ` + column + ` ` + direction + `, id
+		LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list drafts: %w", err)
+	}
+	defer rows.Close()
+
+	drafts := []domain.Draft{}
+	for rows.Next() {
+		draft, err := scanSQLiteDraft(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		drafts = append(drafts, *draft)
+	}
+	return drafts, total, rows.Err()
+}
+
+func (r *SQLiteDraftRepository) ListDueScheduled(ctx context.Context, before time.Time, limit int) ([]domain.Draft, error) {
+	query := `SELECT ` + sqliteDraftColumns(false) + `
+		FROM drafts
+		WHERE status = ? AND scheduled_at <= ? AND deleted_at IS NULL
+		ORDER BY scheduled_at, id
+		LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, string(domain.StatusScheduled), before.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled drafts: %w", err)
+	}
+	defer rows.Close()
+
+	drafts := []domain.Draft{}
+	for rows.Next() {
+		draft, err := scanSQLiteDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		drafts = append(drafts, *draft)
+	}
+	return drafts, rows.Err()
+}
+
+func (r *SQLiteDraftRepository) TrashDraft(ctx context.Context, id string, workspaceID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE drafts SET deleted_at = ? WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL`, sqliteNow(), id, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to trash draft: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM unified_posts WHERE platform = ? AND remote_id = ?`, LocalPlatform, id); err != nil {
+		return fmt.Errorf("failed to remove draft from dashboard: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteDraftRepository) UntrashDraft(ctx context.Context, id string, workspaceID string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE drafts SET deleted_at = NULL WHERE id = ? AND workspace_id = ? AND deleted_at IS NOT NULL`, id, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to restore draft: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Posts
+
+type SQLitePostRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLitePostRepository) UpsertPost(ctx context.Context, userID string, post domain.UnifiedPost) error {
+	query := `
+		INSERT INTO unified_posts (user_id, platform, remote_id, title, url, status, views, reactions, comments, published_at, last_synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, platform, remote_id)
+		DO UPDATE SET
+			title = excluded.title,
+			url = excluded.url,
+			status = excluded.status,
+			views = excluded.views,
+			reactions = excluded.reactions,
+			comments = excluded.comments,
+			published_at = excluded.published_at,
+			last_synced_at = excluded.last_synced_at
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, post.Platform, post.RemoteID, post.Title, post.URL, post.Status,
+		post.Views, post.Reactions, post.Comments, post.PublishedAt.UTC(), sqliteNow())
+	if err != nil {
+		return fmt.Errorf("failed to upsert post: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLitePostRepository) ListPosts(ctx context.Context, userID string, limit int) ([]domain.UnifiedPost, error) {
+	query := `
+		SELECT up.platform, up.remote_id, up.title, up.url, up.status, up.views, up.reactions, up.comments,
+			up.published_at, d.publish_targets
+		FROM unified_posts up
+		LEFT JOIN drafts d
+			ON up.platform = ?
+			AND d.id = up.remote_id
+			AND d.user_id = up.user_id
+		WHERE up.user_id = ?
+		ORDER BY up.published_at DESC NULLS LAST, up.last_synced_at DESC
+		LIMIT ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, LocalPlatform, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []domain.UnifiedPost
+	for rows.Next() {
+		var p domain.UnifiedPost
+		var publishedAt *time.Time
+		var publishTargets *string
+		if err := rows.Scan(&p.Platform, &p.RemoteID, &p.Title, &p.URL, &p.Status, &p.Views, &p.Reactions, &p.Comments, &publishedAt, &publishTargets); err != nil {
+			continue // Skip malformed rows
+		}
+		if publishedAt != nil {
+			p.PublishedAt = *publishedAt
+		}
+		if publishTargets != nil {
+			_ = json.Unmarshal([]byte(*publishTargets), &p.PublishTargets)
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
+
+// Search
+
+// SQLiteSearchRepository ranks candidates in Go; fine for the single-user sizes SQLite serves
+type SQLiteSearchRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteSearchRepository) Search(ctx context.Context, query SearchQuery) ([]domain.SearchResult, error) {
+	var candidates []searchCandidate
+
+	if query.wantsPlatform(LocalPlatform) {
+		rows, err := r.db.QueryContext(ctx, `
+			SELECT id, title, content, status, last_saved_at
+			FROM drafts
+			WHERE workspace_id = ? AND deleted_at IS NULL
+		`, query.WorkspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search drafts: %w", err)
+		}
+		for rows.Next() {
+			var c searchCandidate
+			var updatedAt *time.Time
+			if err := rows.Scan(&c.result.ID, &c.result.Title, &c.body, &c.result.Status, &updatedAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan draft: %w", err)
+			}
+			if !query.wantsStatus(c.result.Status) {
+				continue
+			}
+			c.result.Kind = "draft"
+			c.result.Platform = LocalPlatform
+			c.result.URL = "/editor?draft=" + c.result.ID
+			if updatedAt != nil {
+				c.result.UpdatedAt = *updatedAt
+			}
+			candidates = append(candidates, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT remote_id, platform, title, status, url, published_at, last_synced_at
+		FROM unified_posts
+		WHERE user_id = ? AND platform <> ?
+	`, query.UserID, LocalPlatform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search posts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c := searchCandidate{result: domain.SearchResult{Kind: "post"}}
+		var publishedAt *time.Time
+		if err := rows.Scan(&c.result.ID, &c.result.Platform, &c.result.Title, &c.result.Status, &c.result.URL, &publishedAt, &c.result.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		if !query.wantsPlatform(c.result.Platform) || !query.wantsStatus(c.result.Status) {
+			continue
+		}
+		if publishedAt != nil {
+			c.result.UpdatedAt = *publishedAt
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matchSearch(query, candidates), nil
+}
+
+// Reviews
+
+type SQLiteReviewRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteReviewRepository) AddComment(ctx context.Context, comment *domain.ReviewComment) error {
+	comment.ID = uuid.NewString()
+	comment.CreatedAt = sqliteNow()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO draft_review_comments (id, draft_id, author_id, body, range_start, range_end, quote, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, comment.ID, comment.DraftID, comment.AuthorID, comment.Body, comment.RangeStart, comment.RangeEnd, comment.Quote, comment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add comment: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteReviewRepository) ListComments(ctx context.Context, draftID string) ([]domain.ReviewComment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, author_id, body, range_start, range_end, quote, resolved, created_at
+		FROM draft_review_comments
+		WHERE draft_id = ?
+		ORDER BY range_start, created_at
+	`, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []domain.ReviewComment{}
+	for rows.Next() {
+		c := domain.ReviewComment{DraftID: draftID}
+		if err := rows.Scan(&c.ID, &c.AuthorID, &c.Body, &c.RangeStart, &c.RangeEnd, &c.Quote, &c.Resolved, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+func (r *SQLiteReviewRepository) ResolveComment(ctx context.Context, draftID string, commentID string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE draft_review_comments SET resolved = 1 WHERE draft_id = ? AND id = ?`, draftID, commentID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve comment: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteReviewRepository) AddAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	entry.CreatedAt = sqliteNow()
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO draft_audit_log (draft_id, actor_id, action, from_status, to_status, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, entry.DraftID, entry.ActorID, entry.Action, string(entry.FromStatus), string(entry.ToStatus), entry.Detail, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteReviewRepository) ListAuditEntries(ctx context.Context, draftID string) ([]domain.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, actor_id, action, from_status, to_status, detail, created_at
+		FROM draft_audit_log
+		WHERE draft_id = ?
+		ORDER BY created_at, id
+	`, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		e := domain.AuditEntry{DraftID: draftID}
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.FromStatus, &e.ToStatus, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Revisions
+
+type SQLiteRevisionRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteRevisionRepository) AddRevision(ctx context.Context, rev *domain.Revision) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rev.CreatedAt = sqliteNow()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO draft_revision_blobs (hash, title, content, cover_image, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (hash) DO NOTHING
+	`, rev.Hash, rev.Title, rev.Content, rev.CoverImage, rev.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store revision blob: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO draft_revisions (draft_id, blob_hash, author_id, kind, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`, rev.DraftID, rev.Hash, rev.AuthorID, string(rev.Kind), rev.CreatedAt).Scan(&rev.ID)
+	if err != nil {
+		return fmt.Errorf("failed to add revision: %w", err)
+	}
+	return tx.Commit()
+}
+
+const sqliteRevisionColumns = `r.id, r.blob_hash, r.author_id, r.kind, b.title, r.created_at`
+
+func (r *SQLiteRevisionRepository) LatestRevision(ctx context.Context, draftID string) (*domain.Revision, error) {
+	rev := domain.Revision{DraftID: draftID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+sqliteRevisionColumns+`
+		FROM draft_revisions r
+		JOIN draft_revision_blobs b ON b.hash = r.blob_hash
+		WHERE r.draft_id = ?
+		ORDER BY r.id DESC
+		LIMIT 1
+	`, draftID).Scan(&rev.ID, &rev.Hash, &rev.AuthorID, &rev.Kind, &rev.Title, &rev.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest revision: %w", err)
+	}
+	return &rev, nil
+}
+
+func (r *SQLiteRevisionRepository) ListRevisions(ctx context.Context, draftID string) ([]domain.Revision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sqliteRevisionColumns+`
+		FROM draft_revisions r
+		JOIN draft_revision_blobs b ON b.hash = r.blob_hash
+		WHERE r.draft_id = ?
+		ORDER BY r.id DESC
+	`, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []domain.Revision{}
+	for rows.Next() {
+		rev := domain.Revision{DraftID: draftID}
+		if err := rows.Scan(&rev.ID, &rev.Hash, &rev.AuthorID, &rev.Kind, &rev.Title, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *SQLiteRevisionRepository) GetRevision(ctx context.Context, draftID string, id int64) (*domain.Revision, error) {
+	rev := domain.Revision{ID: id, DraftID: draftID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT r.blob_hash, r.author_id, r.kind, b.title, b.content, b.cover_image, r.created_at
+		FROM draft_revisions r
+		JOIN draft_revision_blobs b ON b.hash = r.blob_hash
+		WHERE r.draft_id = ? AND r.id = ?
+	`, draftID, id).Scan(&rev.Hash, &rev.AuthorID, &rev.Kind, &rev.Title, &rev.Content, &rev.CoverImage, &rev.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return &rev, nil
+}
+
+func (r *SQLiteRevisionRepository) PruneAutosaves(ctx context.Context, olderThan time.Time) (int64, error) {
+	olderThan = olderThan.UTC()
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM draft_revisions
+		WHERE kind = 'autosave'
+		  AND created_at < ?
+		  AND id <> (SELECT MAX(latest.id) FROM draft_revisions latest WHERE latest.draft_id = draft_revisions.draft_id)
+	`, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune revisions: %w", err)
+	}
+	pruned, _ := result.RowsAffected()
+
+	_, err = r.db.ExecContext(ctx, `
+		DELETE FROM draft_revision_blobs
+		WHERE created_at < ?
+		  AND NOT EXISTS (SELECT 1 FROM draft_revisions r WHERE r.blob_hash = draft_revision_blobs.hash)
+	`, olderThan)
+	if err != nil {
+		return pruned, fmt.Errorf("failed to prune revision blobs: %w", err)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"postificus/internal/domain"
+)
+
+// Outbox
+
+type SQLiteOutboxRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteOutboxRepository) Add(ctx context.Context, messages ...OutboxMessage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := addSQLiteOutboxMessages(ctx, tx, messages); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addSQLiteOutboxMessages writes messages inside a caller's transaction
+func addSQLiteOutboxMessages(ctx context.Context, tx *sql.Tx, messages []OutboxMessage) error {
+	now := sqliteNow()
+	for _, m := range messages {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO outbox (queue, job_type, priority, payload, idempotency_key, available_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, m.Queue, m.Type, int(m.Priority), m.Payload, m.IdempotencyKey, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to add outbox message: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *SQLiteOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxMessage, error) {
+	// One statement, so concurrent relays (SQLite serializes writers) never claim the same row
+	now := sqliteNow()
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE outbox SET available_at = ?, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE sent_at IS NULL AND available_at <= ?
+			ORDER BY id
+			LIMIT ?
+		)
+		RETURNING id, queue, job_type, priority, payload, idempotency_key, attempts, COALESCE(last_error, ''), created_at
+	`, now.Add(lease), now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []OutboxMessage{}
+	for rows.Next() {
+		var m OutboxMessage
+		var priority int
+		if err := rows.Scan(&m.ID, &m.Queue, &m.Type, &priority, &m.Payload, &m.IdempotencyKey, &m.Attempts, &m.LastError, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		m.Priority = uint8(priority)
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+func (r *SQLiteOutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE outbox SET sent_at = ?, last_error = NULL WHERE id = ?`, sqliteNow(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message sent: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteOutboxRepository) Release(ctx context.Context, id int64, cause string, retryIn time.Duration) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE outbox SET last_error = ?, available_at = ? WHERE id = ? AND sent_at IS NULL`,
+		cause, sqliteNow().Add(retryIn), id)
+	if err != nil {
+		return fmt.Errorf("failed to release outbox message: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteOutboxRepository) PurgeSent(ctx context.Context, sentBefore time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM outbox WHERE sent_at < ?`, sentBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Publish logs
+
+type SQLitePublishLogRepository struct {
+	db *sql.DB
+}
+
+const sqlitePublishLogColumns = `id, job_id, COALESCE(workspace_id, ''), COALESCE(draft_id, ''), platform, status,
+	COALESCE(external_url, ''), COALESCE(error_message, ''), attempts, COALESCE(artifacts_url, ''), created_at, updated_at`
+
+func scanSQLitePublishLog(row sqlRow, entry *domain.PublishLog) error {
+	var status string
+	err := row.Scan(&entry.ID, &entry.JobID, &entry.WorkspaceID, &entry.DraftID, &entry.Platform, &status,
+		&entry.ExternalURL, &entry.Error, &entry.Attempts, &entry.ArtifactsURL, &entry.CreatedAt, &entry.UpdatedAt)
+	entry.Status = domain.PublishStatus(status)
+	return err
+}
+
+func (r *SQLitePublishLogRepository) QueuePublish(ctx context.Context, entry *domain.PublishLog, message OutboxMessage) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := sqliteNow()
+	err = scanSQLitePublishLog(tx.QueryRowContext(ctx, `
+		INSERT INTO publish_logs (job_id, workspace_id, draft_id, platform, status, created_at, updated_at)
+		VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?)
+		ON CONFLICT (job_id) DO NOTHING
+		RETURNING `+sqlitePublishLogColumns,
+		entry.JobID, entry.WorkspaceID, entry.DraftID, entry.Platform, string(domain.PublishQueued), now, now), entry)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The job exists already; SQLite's single writer keeps this transaction to itself
+		err = scanSQLitePublishLog(tx.QueryRowContext(ctx, `SELECT `+sqlitePublishLogColumns+` FROM publish_logs WHERE job_id = ?`, entry.JobID), entry)
+		if err != nil {
+			return false, fmt.Errorf("failed to read publish job: %w", err)
+		}
+		if entry.Status != domain.PublishFailed {
+			return false, tx.Commit()
+		}
+
+		err = scanSQLitePublishLog(tx.QueryRowContext(ctx, `
+			UPDATE publish_logs SET status = ?, error_message = NULL, updated_at = ?
+			WHERE job_id = ?
+			RETURNING `+sqlitePublishLogColumns, string(domain.PublishQueued), now, entry.JobID), entry)
+		if err != nil {
+			return false, fmt.Errorf("failed to requeue publish job: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM outbox WHERE idempotency_key = ?`, message.IdempotencyKey); err != nil {
+			return false, fmt.Errorf("failed to requeue publish job: %w", err)
+		}
+	} else if err != nil {
+		return false, fmt.Errorf("failed to record publish job: %w", err)
+	}
+
+	if err := addSQLiteOutboxMessages(ctx, tx, []OutboxMessage{message}); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *SQLitePublishLogRepository) ClaimPublish(ctx context.Context, jobID string, staleAfter time.Duration) (*domain.PublishLog, error) {
+	now := sqliteNow()
+	var entry domain.PublishLog
+	err := scanSQLitePublishLog(r.db.QueryRowContext(ctx, `
+		UPDATE publish_logs SET status = ?, attempts = attempts + 1, updated_at = ?
+		WHERE job_id = ?
+			AND (status IN (?, ?) OR (status = ? AND updated_at < ?))
+		RETURNING `+sqlitePublishLogColumns,
+		string(domain.PublishProcessing), now, jobID, string(domain.PublishQueued), string(domain.PublishFailed),
+		string(domain.PublishProcessing), now.Add(-staleAfter)), &entry)
+	if err == nil {
+		return &entry, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to claim publish job: %w", err)
+	}
+
+	existing, err := r.GetPublishLog(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return claimOutcome(existing)
+}
+
+func (r *SQLitePublishLogRepository) UpdatePublishStatus(ctx context.Context, jobID string, status domain.PublishStatus, externalURL string, errorMessage string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE publish_logs SET status = ?, external_url = NULLIF(?, ''), error_message = NULLIF(?, ''), updated_at = ?
+		WHERE job_id = ?
+	`, string(status), externalURL, errorMessage, sqliteNow(), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to update publish job: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLitePublishLogRepository) ReleasePublish(ctx context.Context, jobID string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE publish_logs SET status = ?, attempts = MAX(attempts - 1, 0), updated_at = ?
+		WHERE job_id = ? AND status = ?
+	`, string(domain.PublishQueued), sqliteNow(), jobID, string(domain.PublishProcessing))
+	if err != nil {
+		return fmt.Errorf("failed to release publish job: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLitePublishLogRepository) AttachPublishArtifacts(ctx context.Context, jobID string, artifactsURL string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE publish_logs SET artifacts_url = NULLIF(?, '') WHERE job_id = ?`, artifactsURL, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to link publish artifacts: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLitePublishLogRepository) GetPublishLog(ctx context.Context, jobID string) (*domain.PublishLog, error) {
+	var entry domain.PublishLog
+	err := scanSQLitePublishLog(r.db.QueryRowContext(ctx, `SELECT `+sqlitePublishLogColumns+` FROM publish_logs WHERE job_id = ?`, jobID), &entry)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publish job: %w", err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,393 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite" // Pure-Go driver, so the binaries still build with CGO_ENABLED=0
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrationFiles embed.FS
+
+// OpenSQLiteStore opens (or creates) the database file at path and migrates it.
+// WAL mode lets the API and a worker share the file.
+func OpenSQLiteStore(path string) (*Store, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := migrateSQLite(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	log.Printf("✅ Using SQLite database at %s", path)
+
+	store := NewSQLiteStore(db)
+	store.close = func() { db.Close() }
+	return store, nil
+}
+
+// openSQLite opens the database file at path without touching the schema
+func openSQLite(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open sqlite database: %w", err)
+	}
+	return db, nil
+}
+
+// NewSQLiteStore serves every repository from an already migrated database
+func NewSQLiteStore(db *sql.DB) *Store {
+	return &Store{
+		Backend:     BackendSQLite,
+		Credentials: &SQLiteCredentialsRepository{db: db},
+		Drafts:      &SQLiteDraftRepository{db: db},
+		Outbox:      &SQLiteOutboxRepository{db: db},
+		Posts:       &SQLitePostRepository{db: db},
+		Profiles:    &SQLiteProfileRepository{db: db},
+		PublishLogs: &SQLitePublishLogRepository{db: db},
+		Reviews:     &SQLiteReviewRepository{db: db},
+		Revisions:   &SQLiteRevisionRepository{db: db},
+		Search:      &SQLiteSearchRepository{db: db},
+		TagMappings: &SQLiteTagMappingRepository{db: db},
+		Workspaces:  &SQLiteWorkspaceRepository{db: db},
+	}
+}
+
+// migrateSQLite applies pending SQLite migrations
+func migrateSQLite(ctx context.Context, db *sql.DB) error {
+	migrator, err := NewSQLiteMigrator(db)
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up(ctx)
+	return err
+}
+
+// sqliteNow is the write timestamp; UTC keeps the stored strings sortable
+func sqliteNow() time.Time {
+	return time.Now().UTC()
+}
+
+// Credentials
+
+type SQLiteCredentialsRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteCredentialsRepository) SaveCredentials(ctx context.Context, workspaceID string, platform string, creds map[string]string) error {
+	credsJSON, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	query := `
+		INSERT INTO user_credentials (id, workspace_id, platform, account_name, credentials, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (workspace_id, platform, account_name)
+		DO UPDATE SET credentials = excluded.credentials, updated_at = excluded.updated_at
+	`
+	_, err = r.db.ExecContext(ctx, query, uuid.NewString(), workspaceID, platform, AccountNameFor(creds), string(credsJSON), sqliteNow())
+	if err != nil {
+		return fmt.Errorf("failed to execute save query: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteCredentialsRepository) DeleteCredentials(ctx context.Context, workspaceID string, platform string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_credentials WHERE workspace_id = ? AND platform = ?`, workspaceID, platform)
+	return err
+}
+
+func (r *SQLiteCredentialsRepository) DeleteCredentialsByID(ctx context.Context, workspaceID string, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_credentials WHERE workspace_id = ? AND id = ?`, workspaceID, id)
+	return err
+}
+
+func (r *SQLiteCredentialsRepository) GetCredentials(ctx context.Context, workspaceID string, platform string) (*domain.UserCredential, error) {
+	query := `
+		SELECT id, workspace_id, platform, account_name, credentials, updated_at
+		FROM user_credentials
+		WHERE workspace_id = ? AND platform = ?
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`
+	return scanSQLiteCredential(r.db.QueryRowContext(ctx, query, workspaceID, platform))
+}
+
+func (r *SQLiteCredentialsRepository) GetCredentialsByID(ctx context.Context, workspaceID string, id string) (*domain.UserCredential, error) {
+	query := `
+		SELECT id, workspace_id, platform, account_name, credentials, updated_at
+		FROM user_credentials
+		WHERE workspace_id = ? AND id = ?
+	`
+	return scanSQLiteCredential(r.db.QueryRowContext(ctx, query, workspaceID, id))
+}
+
+func (r *SQLiteCredentialsRepository) GetAllCredentials(ctx context.Context, workspaceID string) ([]domain.UserCredential, error) {
+	query := `
+		SELECT id, workspace_id, platform, account_name, credentials, updated_at
+		FROM user_credentials
+		WHERE workspace_id = ?
+		ORDER BY platform, updated_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	defer rows.Close()
+
+	creds := []domain.UserCredential{}
+	for rows.Next() {
+		cred, err := scanSQLiteCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, *cred)
+	}
+	return creds, rows.Err()
+}
+
+// sqlRow is satisfied by *sql.Row and *sql.Rows
+type sqlRow interface {
+	Scan(dest ...any) error
+}
+
+func scanSQLiteCredential(row sqlRow) (*domain.UserCredential, error) {
+	var cred domain.UserCredential
+	var credsJSON string
+	err := row.Scan(&cred.ID, &cred.WorkspaceID, &cred.Platform, &cred.AccountName, &credsJSON, &cred.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch credentials: %w", err)
+	}
+	cred.Credentials = json.RawMessage(credsJSON)
+	return &cred, nil
+}
+
+// Profiles
+
+type SQLiteProfileRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteProfileRepository) GetProfile(ctx context.Context, userID string) (*domain.Profile, error) {
+	query := `
+		SELECT full_name, username, headline, bio, location, website, public_email, skills
+		FROM user_details
+		WHERE user_id = ?
+	`
+	profile := domain.Profile{UserID: userID, Skills: []string{}}
+	var skillsJSON string
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&profile.FullName, &profile.Username, &profile.Headline, &profile.Bio,
+		&profile.Location, &profile.Website, &profile.PublicEmail, &skillsJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &profile, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profile: %w", err)
+	}
+	_ = json.Unmarshal([]byte(skillsJSON), &profile.Skills)
+	return &profile, nil
+}
+
+func (r *SQLiteProfileRepository) SaveProfile(ctx context.Context, profile *domain.Profile) error {
+	skillsJSON, err := json.Marshal(profile.Skills)
+	if err != nil {
+		return fmt.Errorf("failed to encode skills: %w", err)
+	}
+
+	query := `
+		INSERT INTO user_details (
+			user_id, full_name, username, headline, bio, location, website, public_email, skills, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			full_name = excluded.full_name,
+			username = excluded.username,
+			headline = excluded.headline,
+			bio = excluded.bio,
+			location = excluded.location,
+			website = excluded.website,
+			public_email = excluded.public_email,
+			skills = excluded.skills,
+			updated_at = excluded.updated_at
+	`
+	_, err = r.db.ExecContext(ctx, query, profile.UserID, profile.FullName, profile.Username, profile.Headline, profile.Bio,
+		profile.Location, profile.Website, profile.PublicEmail, string(skillsJSON), sqliteNow())
+	if err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	return nil
+}
+
+// Workspaces
+
+type SQLiteWorkspaceRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteWorkspaceRepository) CreateWorkspace(ctx context.Context, workspace *domain.Workspace, ownerID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	workspace.ID = uuid.NewString()
+	workspace.CreatedAt = sqliteNow()
+	if _, err := tx.ExecContext(ctx, `INSERT INTO workspaces (id, name, created_at) VALUES (?, ?, ?)`, workspace.ID, workspace.Name, workspace.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		workspace.ID, ownerID, string(domain.RoleOwner), workspace.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add owner: %w", err)
+	}
+
+	workspace.Role = domain.RoleOwner
+	return tx.Commit()
+}
+
+func (r *SQLiteWorkspaceRepository) ListWorkspaces(ctx context.Context, userID string) ([]domain.Workspace, error) {
+	query := `
+		SELECT w.id, w.name, m.role, w.created_at
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = ?
+		ORDER BY w.created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []domain.Workspace{}
+	for rows.Next() {
+		var w domain.Workspace
+		if err := rows.Scan(&w.ID, &w.Name, &w.Role, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, rows.Err()
+}
+
+func (r *SQLiteWorkspaceRepository) GetMembership(ctx context.Context, workspaceID string, userID string) (*domain.Membership, error) {
+	m := domain.Membership{WorkspaceID: workspaceID, UserID: userID}
+	err := r.db.QueryRowContext(ctx, `SELECT role, created_at FROM workspace_members WHERE workspace_id = ? AND user_id = ?`,
+		workspaceID, userID).Scan(&m.Role, &m.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch membership: %w", err)
+	}
+	return &m, nil
+}
+
+func (r *SQLiteWorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]domain.Membership, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id, role, created_at FROM workspace_members WHERE workspace_id = ? ORDER BY created_at`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []domain.Membership{}
+	for rows.Next() {
+		m := domain.Membership{WorkspaceID: workspaceID}
+		if err := rows.Scan(&m.UserID, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (r *SQLiteWorkspaceRepository) SaveMember(ctx context.Context, member *domain.Membership) error {
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = excluded.role
+	`
+	if _, err := r.db.ExecContext(ctx, query, member.WorkspaceID, member.UserID, string(member.Role), sqliteNow()); err != nil {
+		return fmt.Errorf("failed to save member: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteWorkspaceRepository) RemoveMember(ctx context.Context, workspaceID string, userID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ?`, workspaceID, userID)
+	return err
+}
+
+// Tag mappings
+
+type SQLiteTagMappingRepository struct {
+	db *sql.DB
+}
+
+func (r *SQLiteTagMappingRepository) ListTagMappings(ctx context.Context, workspaceID string, platform string) ([]domain.TagMapping, error) {
+	query := `
+		SELECT platform, tag, platform_tag
+		FROM platform_tag_mappings
+		WHERE workspace_id = ? AND (? = '' OR platform = ?)
+		ORDER BY platform, tag
+	`
+	rows, err := r.db.QueryContext(ctx, query, workspaceID, platform, platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag mappings: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := []domain.TagMapping{}
+	for rows.Next() {
+		m := domain.TagMapping{WorkspaceID: workspaceID}
+		if err := rows.Scan(&m.Platform, &m.Tag, &m.PlatformTag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag mapping: %w", err)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+func (r *SQLiteTagMappingRepository) SaveTagMapping(ctx context.Context, mapping domain.TagMapping) error {
+	query := `
+		INSERT INTO platform_tag_mappings (workspace_id, platform, tag, platform_tag, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (workspace_id, platform, tag)
+		DO UPDATE SET platform_tag = excluded.platform_tag, updated_at = excluded.updated_at
+	`
+	if _, err := r.db.ExecContext(ctx, query, mapping.WorkspaceID, mapping.Platform, mapping.Tag, mapping.PlatformTag, sqliteNow()); err != nil {
+		return fmt.Errorf("failed to save tag mapping: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteTagMappingRepository) DeleteTagMapping(ctx context.Context, workspaceID, platform, tag string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM platform_tag_mappings WHERE workspace_id = ? AND platform = ? AND tag = ?`, workspaceID, platform, tag)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag mapping: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
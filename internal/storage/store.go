@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
+)
+
+// Store bundles the repositories of one storage backend.
+// Services receive the repositories they need from it at construction.
+type Store struct {
+	Backend     string
+	Credentials CredentialsRepository
+	Drafts      DraftRepository
+	Outbox      OutboxRepository
+	Posts       PostRepository
+	Profiles    ProfileRepository
+	PublishLogs PublishLogRepository
+	Reviews     ReviewRepository
+	Revisions   RevisionRepository
+	Search      SearchRepository
+	TagMappings TagMappingRepository
+	Workspaces  WorkspaceRepository
+
+	close func()
+}
+
+// NewPostgresStore serves every repository from the given pool
+func NewPostgresStore(db *pgxpool.Pool) *Store {
+	return &Store{
+		Backend:     BackendPostgres,
+		Credentials: NewCredentialsRepository(db),
+		Drafts:      NewDraftRepository(db),
+		Outbox:      NewOutboxRepository(db),
+		Posts:       NewPostRepository(db),
+		Profiles:    NewProfileRepository(db),
+		PublishLogs: NewPublishLogRepository(db),
+		Reviews:     NewReviewRepository(db),
+		Revisions:   NewRevisionRepository(db),
+		Search:      NewSearchRepository(db),
+		TagMappings: NewTagMappingRepository(db),
+		Workspaces:  NewWorkspaceRepository(db),
+	}
+}
+
+// OpenStore opens the backend named by STORAGE_BACKEND:
+//
+//   - postgres (default): DATABASE_URL, migrated on boot
+//   - sqlite: a single file at SQLITE_PATH (default postificus.db), for single-user setups
+//   - memory: nothing persists; for tests and demos
+func OpenStore() (*Store, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", BackendPostgres:
+		if err := InitDB(); err != nil {
+			return nil, err
+		}
+		store := NewPostgresStore(DB)
+		store.close = CloseDB
+		return store, nil
+	case BackendSQLite:
+		return OpenSQLiteStore(sqlitePath())
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q (want postgres, sqlite or memory)", backend)
+	}
+}
+
+// OpenMigrator connects to the database STORAGE_BACKEND selects, as OpenStore
+// does, but leaves the schema alone; the migrate command drives it. Call close
+// when done.
+func OpenMigrator() (migrator SchemaMigrator, close func(), err error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", BackendPostgres:
+		if err := ConnectDB(); err != nil {
+			return nil, nil, err
+		}
+		pg, err := NewMigrator(DB)
+		if err != nil {
+			CloseDB()
+			return nil, nil, err
+		}
+		return pg, CloseDB, nil
+	case BackendSQLite:
+		db, err := openSQLite(sqlitePath())
+		if err != nil {
+			return nil, nil, err
+		}
+		lite, err := NewSQLiteMigrator(db)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return lite, func() { db.Close() }, nil
+	case BackendMemory:
+		return nil, nil, fmt.Errorf("STORAGE_BACKEND=memory has no schema to migrate")
+	default:
+		return nil, nil, fmt.Errorf("unknown STORAGE_BACKEND %q (want postgres, sqlite or memory)", backend)
+	}
+}
+
+// sqlitePath is the SQLite database file, SQLITE_PATH or postificus.db
+func sqlitePath() string {
+	if path := os.Getenv("SQLITE_PATH"); path != "" {
+		return path
+	}
+	return "postificus.db"
+}
+
+// Close releases the backend's connections
+func (s *Store) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
@@ -0,0 +1,452 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"postificus/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Postgres keys drafts and workspaces by UUID, so the fixtures do too
+const (
+	testDraftID      = "00000000-0000-0000-0000-0000000000d1"
+	otherWorkspaceID = "00000000-0000-0000-0000-0000000000ff"
+)
+
+// The memory and SQLite backends must behave like Postgres; each case runs against
+// both, and against Postgres itself when TEST_DATABASE_URL is set (always in CI)
+func testStores(t *testing.T) map[string]*Store {
+	sqlite, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(sqlite.Close)
+
+	stores := map[string]*Store{
+		BackendMemory: NewMemoryStore(),
+		BackendSQLite: sqlite,
+	}
+	if os.Getenv("TEST_DATABASE_URL") != "" || os.Getenv("CI") != "" {
+		db := testPostgres(t)
+		migrator, err := NewMigrator(db)
+		require.NoError(t, err)
+		_, err = migrator.Up(context.Background())
+		require.NoError(t, err)
+		stores[BackendPostgres] = NewPostgresStore(db)
+	}
+	return stores
+}
+
+// testPostgres creates a scratch database on the server at TEST_DATABASE_URL,
+// dropped when the test ends. The test is skipped when the variable is unset,
+// and fails if that happens in CI, which runs against a real Postgres.
+func testPostgres(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal("TEST_DATABASE_URL must be set in CI (see docker-compose.test.yml)")
+		}
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	admin, err := pgx.Connect(ctx, url)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close(context.Background()) })
+	name := "postificus_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err = admin.Exec(ctx, `CREATE DATABASE `+name)
+	require.NoError(t, err)
+
+	config, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	config.ConnConfig.Database = name
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		if _, err := admin.Exec(context.Background(), `DROP DATABASE IF EXISTS `+name+` WITH (FORCE)`); err != nil {
+			t.Logf("failed to drop %s: %v", name, err)
+		}
+	})
+	return db
+}
+
+func TestStore_DraftLifecycle(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			draft := &domain.Draft{
+				ID: testDraftID, WorkspaceID: demoUserID, UserID: demoUserID,
+				Title: "Hello", Content: "<p>World</p>", PublishTargets: []string{"medium"}, Tags: []string{"go"},
+				Series: "Basics", SeriesOrder: 2,
+			}
+			require.NoError(t, store.Drafts.SaveDraft(ctx, draft, AnyVersion))
+			assert.Equal(t, int64(1), draft.Version)
+
+			got, err := store.Drafts.GetDraft(ctx, testDraftID, demoUserID)
+			require.NoError(t, err)
+			assert.Equal(t, "<p>World</p>", got.Content)
+			assert.Equal(t, []string{"medium"}, got.PublishTargets)
+			assert.Equal(t, []string{"go"}, got.Tags)
+			assert.Equal(t, "Basics", got.Series)
+			assert.Equal(t, 2, got.SeriesOrder)
+
+			_, err = store.Drafts.GetDraft(ctx, testDraftID, otherWorkspaceID)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			draft.Title = "Hello again"
+			require.NoError(t, store.Drafts.SaveDraft(ctx, draft, 1))
+			assert.Equal(t, int64(2), draft.Version)
+			assert.ErrorIs(t, store.Drafts.SaveDraft(ctx, draft, 1), ErrVersionConflict)
+
+			draft.Status = domain.StatusInReview
+			draft.ReviewerID = demoUserID
+			require.NoError(t, store.Drafts.UpdateWorkflow(ctx, draft))
+			got, err = store.Drafts.GetDraft(ctx, testDraftID, demoUserID)
+			require.NoError(t, err)
+			assert.Equal(t, domain.StatusInReview, got.Status)
+			assert.Equal(t, demoUserID, got.ReviewerID)
+
+			require.NoError(t, store.Drafts.UpdateDashboardCache(ctx, draft))
+			posts, err := store.Posts.ListPosts(ctx, demoUserID, 10)
+			require.NoError(t, err)
+			require.Len(t, posts, 1)
+			assert.Equal(t, []string{"medium"}, posts[0].PublishTargets)
+
+			require.NoError(t, store.Drafts.TrashDraft(ctx, testDraftID, demoUserID))
+			_, err = store.Drafts.GetDraft(ctx, testDraftID, demoUserID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.ErrorIs(t, store.Drafts.SaveDraft(ctx, draft, AnyVersion), ErrNotFound)
+			posts, err = store.Posts.ListPosts(ctx, demoUserID, 10)
+			require.NoError(t, err)
+			assert.Empty(t, posts)
+
+			trashed, total, err := store.Drafts.ListDrafts(ctx, DraftFilter{WorkspaceID: demoUserID, Trashed: true, Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, 1, total)
+			require.Len(t, trashed, 1)
+			assert.Empty(t, trashed[0].Content, "listings leave out content")
+
+			require.NoError(t, store.Drafts.UntrashDraft(ctx, testDraftID, demoUserID))
+			assert.ErrorIs(t, store.Drafts.UntrashDraft(ctx, testDraftID, demoUserID), ErrNotFound)
+		})
+	}
+}
+
+func TestStore_ListDraftsFilters(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, d := range []domain.Draft{
+				{ID: "00000000-0000-0000-0000-00000000000a", Title: "Alpha", PublishTargets: []string{"medium"}, Tags: []string{"go"}},
+				{ID: "00000000-0000-0000-0000-00000000000b", Title: "Bravo", PublishTargets: []string{"devto"}, Tags: []string{"rust"}},
+				{ID: "00000000-0000-0000-0000-00000000000c", Title: "Charlie", PublishTargets: []string{"medium", "devto"}, Tags: []string{"go", "web"}},
+			} {
+				d.WorkspaceID, d.UserID = demoUserID, demoUserID
+				require.NoError(t, store.Drafts.SaveDraft(ctx, &d, AnyVersion))
+			}
+
+			drafts, total, err := store.Drafts.ListDrafts(ctx, DraftFilter{WorkspaceID: demoUserID, Target: "medium", Tag: "go", Sort: "title", Ascending: true, Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, 2, total)
+			require.Len(t, drafts, 2)
+			assert.Equal(t, "Alpha", drafts[0].Title)
+			assert.Equal(t, "Charlie", drafts[1].Title)
+
+			drafts, total, err = store.Drafts.ListDrafts(ctx, DraftFilter{WorkspaceID: demoUserID, Sort: "title", Limit: 1, Offset: 1})
+			require.NoError(t, err)
+			assert.Equal(t, 3, total)
+			require.Len(t, drafts, 1)
+			assert.Equal(t, "Bravo", drafts[0].Title)
+		})
+	}
+}
+
+func TestStore_Search(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			draft := &domain.Draft{ID: testDraftID, WorkspaceID: demoUserID, UserID: demoUserID, Title: "Go generics", Content: "<p>Type parameters in practice</p>"}
+			require.NoError(t, store.Drafts.SaveDraft(ctx, draft, AnyVersion))
+			require.NoError(t, store.Posts.UpsertPost(ctx, demoUserID, domain.UnifiedPost{
+				Platform: "devto", RemoteID: "42", Title: "Rust traits", URL: "https://dev.to/x", Status: "published", PublishedAt: time.Now(),
+			}))
+
+			results, err := store.Search.Search(ctx, SearchQuery{Text: "generics", WorkspaceID: demoUserID, UserID: demoUserID, Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "draft", results[0].Kind)
+			assert.Equal(t, "Go <mark>generics</mark>", results[0].TitleHighlight)
+
+			results, err = store.Search.Search(ctx, SearchQuery{Text: "generics OR traits", WorkspaceID: demoUserID, UserID: demoUserID, Limit: 10})
+			require.NoError(t, err)
+			assert.Len(t, results, 2)
+
+			results, err = store.Search.Search(ctx, SearchQuery{Text: "generics -practice", WorkspaceID: demoUserID, UserID: demoUserID, Limit: 10})
+			require.NoError(t, err)
+			assert.Empty(t, results)
+
+			// A stray '<' survives tag stripping and must come back escaped
+			draft.Content = "<p>Constraints</p> compare a<b safely"
+			require.NoError(t, store.Drafts.SaveDraft(ctx, draft, AnyVersion))
+			results, err = store.Search.Search(ctx, SearchQuery{Text: "constraints", WorkspaceID: demoUserID, UserID: demoUserID, Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Contains(t, results[0].Snippet, "a&lt;b")
+			assert.NotContains(t, results[0].Snippet, "a<b")
+
+			// Tags are dropped and entities decoded once, then everything is escaped:
+			// every backend shows the text as the editor does, never as markup
+			draft.Content = "<p>Escaping &lt;script&gt; tags &amp; entities</p><script>alert(1)</script>"
+			require.NoError(t, store.Drafts.SaveDraft(ctx, draft, AnyVersion))
+			results, err = store.Search.Search(ctx, SearchQuery{Text: "escaping", WorkspaceID: demoUserID, UserID: demoUserID, Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			snippet := results[0].Snippet
+			assert.Contains(t, snippet, "<mark>Escaping</mark> &lt;script&gt; tags &amp; entities")
+			assert.NotContains(t, snippet, "<script")
+			assert.NotContains(t, snippet, "&amp;lt;")
+			assert.NotContains(t, snippet, "&amp;amp;")
+		})
+	}
+}
+
+func TestStore_Revisions(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			draft := &domain.Draft{ID: testDraftID, WorkspaceID: demoUserID, UserID: demoUserID, Title: "T"}
+			require.NoError(t, store.Drafts.SaveDraft(ctx, draft, AnyVersion))
+
+			latest, err := store.Revisions.LatestRevision(ctx, testDraftID)
+			require.NoError(t, err)
+			assert.Nil(t, latest)
+
+			for _, content := range []string{"one", "two", "one"} {
+				rev := &domain.Revision{DraftID: testDraftID, Hash: domain.RevisionHash("T", content, ""), AuthorID: demoUserID, Kind: domain.RevisionAutosave, Title: "T", Content: content}
+				require.NoError(t, store.Revisions.AddRevision(ctx, rev))
+			}
+
+			revisions, err := store.Revisions.ListRevisions(ctx, testDraftID)
+			require.NoError(t, err)
+			require.Len(t, revisions, 3)
+			assert.Greater(t, revisions[0].ID, revisions[1].ID, "newest first")
+
+			rev, err := store.Revisions.GetRevision(ctx, testDraftID, revisions[1].ID)
+			require.NoError(t, err)
+			assert.Equal(t, "two", rev.Content)
+
+			pruned, err := store.Revisions.PruneAutosaves(ctx, time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), pruned, "the latest revision is always kept")
+
+			latest, err = store.Revisions.LatestRevision(ctx, testDraftID)
+			require.NoError(t, err)
+			require.NotNil(t, latest)
+			rev, err = store.Revisions.GetRevision(ctx, testDraftID, latest.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "one", rev.Content)
+		})
+	}
+}
+
+func TestStore_WorkspacesAndTagMappings(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			ws := &domain.Workspace{Name: "Team"}
+			require.NoError(t, store.Workspaces.CreateWorkspace(ctx, ws, demoUserID))
+			require.NotEmpty(t, ws.ID)
+
+			workspaces, err := store.Workspaces.ListWorkspaces(ctx, demoUserID)
+			require.NoError(t, err)
+			assert.Len(t, workspaces, 2)
+
+			member, err := store.Workspaces.GetMembership(ctx, ws.ID, demoUserID)
+			require.NoError(t, err)
+			require.NotNil(t, member)
+			assert.Equal(t, domain.RoleOwner, member.Role)
+
+			mapping := domain.TagMapping{WorkspaceID: ws.ID, Platform: "devto", Tag: "golang", PlatformTag: "go"}
+			require.NoError(t, store.TagMappings.SaveTagMapping(ctx, mapping))
+			mapping.PlatformTag = "golang"
+			require.NoError(t, store.TagMappings.SaveTagMapping(ctx, mapping))
+
+			mappings, err := store.TagMappings.ListTagMappings(ctx, ws.ID, "devto")
+			require.NoError(t, err)
+			assert.Equal(t, []domain.TagMapping{mapping}, mappings)
+
+			require.NoError(t, store.TagMappings.DeleteTagMapping(ctx, ws.ID, "devto", "golang"))
+			mappings, err = store.TagMappings.ListTagMappings(ctx, ws.ID, "")
+			require.NoError(t, err)
+			assert.Empty(t, mappings)
+		})
+	}
+}
+
+func TestStore_Outbox(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			require.NoError(t, store.Outbox.Add(ctx,
+				OutboxMessage{Queue: "sync", Type: "sync:one", Priority: 5, Payload: []byte(`{"n":1}`), IdempotencyKey: "k1"},
+				OutboxMessage{Queue: "sync", Payload: []byte(`{"n":2}`), IdempotencyKey: "k2"},
+			))
+			// A duplicate key rolls back the whole batch
+			assert.Error(t, store.Outbox.Add(ctx,
+				OutboxMessage{Queue: "sync", Payload: []byte(`{}`), IdempotencyKey: "k3"},
+				OutboxMessage{Queue: "sync", Payload: []byte(`{}`), IdempotencyKey: "k1"},
+			))
+
+			claimed, err := store.Outbox.Claim(ctx, 10, time.Minute)
+			require.NoError(t, err)
+			require.Len(t, claimed, 2)
+			assert.Equal(t, "k1", claimed[0].IdempotencyKey)
+			assert.Equal(t, []byte(`{"n":1}`), claimed[0].Payload)
+			assert.Equal(t, "sync:one", claimed[0].Type)
+			assert.Equal(t, uint8(5), claimed[0].Priority)
+			assert.Equal(t, 1, claimed[0].Attempts)
+
+			// Leased messages aren't handed out twice
+			again, err := store.Outbox.Claim(ctx, 10, time.Minute)
+			require.NoError(t, err)
+			assert.Empty(t, again)
+
+			require.NoError(t, store.Outbox.MarkSent(ctx, claimed[0].ID))
+			require.NoError(t, store.Outbox.Release(ctx, claimed[1].ID, "broker down", 0))
+			again, err = store.Outbox.Claim(ctx, 10, time.Minute)
+			require.NoError(t, err)
+			require.Len(t, again, 1)
+			assert.Equal(t, "k2", again[0].IdempotencyKey)
+			assert.Equal(t, 2, again[0].Attempts)
+			assert.Equal(t, "broker down", again[0].LastError)
+
+			purged, err := store.Outbox.PurgeSent(ctx, time.Now().Add(time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), purged)
+		})
+	}
+}
+
+func TestStore_PublishLogs(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			entry := &domain.PublishLog{JobID: "job-1", WorkspaceID: demoUserID, Platform: "devto"}
+			queued, err := store.PublishLogs.QueuePublish(ctx, entry, OutboxMessage{Queue: "publish", Payload: []byte(`{}`), IdempotencyKey: "job-1"})
+			require.NoError(t, err)
+			assert.True(t, queued)
+			assert.Equal(t, domain.PublishQueued, entry.Status)
+
+			// The job record and its outbox message are written together, or not at all
+			_, err = store.PublishLogs.QueuePublish(ctx, &domain.PublishLog{JobID: "job-2", WorkspaceID: demoUserID, Platform: "devto"},
+				OutboxMessage{Queue: "publish", Payload: []byte(`{}`), IdempotencyKey: "job-1"})
+			require.Error(t, err)
+			_, err = store.PublishLogs.GetPublishLog(ctx, "job-2")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			claimed, err := store.Outbox.Claim(ctx, 10, time.Minute)
+			require.NoError(t, err)
+			require.Len(t, claimed, 1)
+			assert.Equal(t, "job-1", claimed[0].IdempotencyKey)
+
+			require.NoError(t, store.PublishLogs.UpdatePublishStatus(ctx, "job-1", domain.PublishSucceeded, "https://dev.to/a", ""))
+			got, err := store.PublishLogs.GetPublishLog(ctx, "job-1")
+			require.NoError(t, err)
+			assert.Equal(t, domain.PublishSucceeded, got.Status)
+			assert.Equal(t, "https://dev.to/a", got.ExternalURL)
+			assert.Equal(t, "devto", got.Platform)
+			assert.Empty(t, got.DraftID)
+
+			assert.ErrorIs(t, store.PublishLogs.UpdatePublishStatus(ctx, "missing", domain.PublishFailed, "", "x"), ErrNotFound)
+
+			require.NoError(t, store.PublishLogs.AttachPublishArtifacts(ctx, "job-1", "/api/admin/publish/job-1/attempts/1/artifacts"))
+			got, err = store.PublishLogs.GetPublishLog(ctx, "job-1")
+			require.NoError(t, err)
+			assert.Equal(t, "/api/admin/publish/job-1/attempts/1/artifacts", got.ArtifactsURL)
+			assert.ErrorIs(t, store.PublishLogs.AttachPublishArtifacts(ctx, "missing", "s3://uploads/x/"), ErrNotFound)
+		})
+	}
+}
+
+func TestStore_PublishLogsAreIdempotent(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			message := OutboxMessage{Queue: "publish", Payload: []byte(`{}`), IdempotencyKey: "job-1"}
+			queue := func() (*domain.PublishLog, bool) {
+				entry := &domain.PublishLog{JobID: "job-1", WorkspaceID: demoUserID, Platform: "devto"}
+				queued, err := store.PublishLogs.QueuePublish(ctx, entry, message)
+				require.NoError(t, err)
+				return entry, queued
+			}
+			_, queued := queue()
+			require.True(t, queued)
+
+			// Asking again while the job is pending returns it instead of queueing a copy
+			entry, queued := queue()
+			assert.False(t, queued)
+			assert.Equal(t, domain.PublishQueued, entry.Status)
+
+			claimed, err := store.PublishLogs.ClaimPublish(ctx, "job-1", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, domain.PublishProcessing, claimed.Status)
+			assert.Equal(t, 1, claimed.Attempts)
+
+			// A worker that claimed the job but didn't run it hands it back uncounted
+			require.NoError(t, store.PublishLogs.ReleasePublish(ctx, "job-1"))
+			released, err := store.PublishLogs.GetPublishLog(ctx, "job-1")
+			require.NoError(t, err)
+			assert.Equal(t, domain.PublishQueued, released.Status)
+			assert.Zero(t, released.Attempts)
+			assert.ErrorIs(t, store.PublishLogs.ReleasePublish(ctx, "job-1"), ErrNotFound, "only a claimed job can be released")
+			claimed, err = store.PublishLogs.ClaimPublish(ctx, "job-1", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, 1, claimed.Attempts)
+
+			// A second worker backs off while the first one's claim is fresh, and takes over once it's stale
+			_, err = store.PublishLogs.ClaimPublish(ctx, "job-1", time.Minute)
+			assert.ErrorIs(t, err, ErrPublishInProgress)
+			time.Sleep(10 * time.Millisecond)
+			claimed, err = store.PublishLogs.ClaimPublish(ctx, "job-1", time.Millisecond)
+			require.NoError(t, err)
+			assert.Equal(t, 2, claimed.Attempts)
+
+			// A failed job is queued again, with a fresh outbox message under the same key
+			require.NoError(t, store.PublishLogs.UpdatePublishStatus(ctx, "job-1", domain.PublishFailed, "", "boom"))
+			entry, queued = queue()
+			assert.True(t, queued)
+			assert.Equal(t, domain.PublishQueued, entry.Status)
+			assert.Empty(t, entry.Error)
+			assert.Equal(t, 2, entry.Attempts)
+			pending, err := store.Outbox.Claim(ctx, 10, time.Minute)
+			require.NoError(t, err)
+			require.Len(t, pending, 1)
+			assert.Equal(t, "job-1", pending[0].IdempotencyKey)
+
+			// Once it succeeded, claiming hands back the result rather than running it again
+			_, err = store.PublishLogs.ClaimPublish(ctx, "job-1", time.Minute)
+			require.NoError(t, err)
+			require.NoError(t, store.PublishLogs.UpdatePublishStatus(ctx, "job-1", domain.PublishSucceeded, "https://dev.to/a", ""))
+			done, err := store.PublishLogs.ClaimPublish(ctx, "job-1", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, domain.PublishSucceeded, done.Status)
+			assert.Equal(t, 3, done.Attempts)
+			entry, queued = queue()
+			assert.False(t, queued)
+			assert.Equal(t, "https://dev.to/a", entry.ExternalURL)
+
+			_, err = store.PublishLogs.ClaimPublish(ctx, "missing", time.Minute)
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
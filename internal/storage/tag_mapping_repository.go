@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"postificus/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TagMappingRepository stores per-platform tag names for a workspace
+type TagMappingRepository interface {
+	// ListTagMappings returns all mappings, or only one platform's when platform is set
+	ListTagMappings(ctx context.Context, workspaceID string, platform string) ([]domain.TagMapping, error)
+	SaveTagMapping(ctx context.Context, mapping domain.TagMapping) error
+	DeleteTagMapping(ctx context.Context, workspaceID, platform, tag string) error
+}
+
+type PostgresTagMappingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTagMappingRepository(db *pgxpool.Pool) *PostgresTagMappingRepository {
+	return &PostgresTagMappingRepository{db: db}
+}
+
+func (r *PostgresTagMappingRepository) ListTagMappings(ctx context.Context, workspaceID string, platform string) ([]domain.TagMapping, error) {
+	query := `
+		SELECT platform, tag, platform_tag
+		FROM platform_tag_mappings
+		WHERE workspace_id = $1 AND ($2 = '' OR platform = $2)
+		ORDER BY platform, tag
+	`
+	rows, err := r.db.Query(ctx, query, workspaceID, platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag mappings: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := []domain.TagMapping{}
+	for rows.Next() {
+		m := domain.TagMapping{WorkspaceID: workspaceID}
+		if err := rows.Scan(&m.Platform, &m.Tag, &m.PlatformTag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag mapping: %w", err)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+func (r *PostgresTagMappingRepository) SaveTagMapping(ctx context.Context, mapping domain.TagMapping) error {
+	query := `
+		INSERT INTO platform_tag_mappings (workspace_id, platform, tag, platform_tag, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (workspace_id, platform, tag)
+		DO UPDATE SET platform_tag = EXCLUDED.platform_tag, updated_at = NOW()
+	`
+	_, err := r.db.Exec(ctx, query, mapping.WorkspaceID, mapping.Platform, mapping.Tag, mapping.PlatformTag)
+	if err != nil {
+		return fmt.Errorf("failed to save tag mapping: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresTagMappingRepository) DeleteTagMapping(ctx context.Context, workspaceID, platform, tag string) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM platform_tag_mappings WHERE workspace_id = $1 AND platform = $2 AND tag = $3`, workspaceID, platform, tag)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag mapping: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
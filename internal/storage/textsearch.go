@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"postificus/internal/domain"
+)
+
+// textQuery is a parsed websearch-style query for the backends without Postgres
+// full-text search: "quoted phrases", -exclusions and OR between groups.
+// Matching is case-insensitive substring matching without stemming.
+type textQuery struct {
+	groups [][]textTerm // A document matches when any group matches
+}
+
+type textTerm struct {
+	text    string
+	exclude bool
+}
+
+var (
+	queryToken = regexp.MustCompile(`-?"[^"]*"|\S+`)
+	htmlTag    = regexp.MustCompile(`<[^>]+>`)
+)
+
+func parseTextQuery(raw string) textQuery {
+	var q textQuery
+	var group []textTerm
+	for _, token := range queryToken.FindAllString(raw, -1) {
+		if strings.EqualFold(token, "or") {
+			if len(group) > 0 {
+				q.groups = append(q.groups, group)
+			}
+			group = nil
+			continue
+		}
+		term := textTerm{}
+		if strings.HasPrefix(token, "-") {
+			term.exclude = true
+			token = token[1:]
+		}
+		term.text = strings.ToLower(strings.TrimSpace(strings.Trim(token, `"`)))
+		if term.text != "" {
+			group = append(group, term)
+		}
+	}
+	if len(group) > 0 {
+		q.groups = append(q.groups, group)
+	}
+	return q
+}
+
+// rank scores a document; zero means no match. Title hits weigh more than body hits.
+func (q textQuery) rank(title, body string) float64 {
+	title, body = strings.ToLower(title), strings.ToLower(body)
+	best := 0.0
+	for _, group := range q.groups {
+		score, matched := 0.0, true
+		for _, term := range group {
+			hits := 2*strings.Count(title, term.text) + strings.Count(body, term.text)
+			if term.exclude {
+				if hits > 0 {
+					matched = false
+				}
+				continue
+			}
+			if hits == 0 {
+				matched = false
+			}
+			score += float64(hits)
+		}
+		if matched && score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// terms returns the words to highlight
+func (q textQuery) terms() []string {
+	var terms []string
+	for _, group := range q.groups {
+		for _, term := range group {
+			if !term.exclude {
+				terms = append(terms, term.text)
+			}
+		}
+	}
+	// Longest first so overlapping terms highlight the longer match
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	return terms
+}
+
+// highlight HTML-escapes text and wraps every term occurrence in <mark>
+func (q textQuery) highlight(text string) string {
+	return markupSnippet(q.mark(stripMarkReplacer.Replace(text)))
+}
+
+// mark wraps every term occurrence in markStart and markStop
+func (q textQuery) mark(text string) string {
+	terms := q.terms()
+	if len(terms) == 0 {
+		return text
+	}
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Case folding changed byte offsets; fall back to plain text
+		return text
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		matched := ""
+		for _, term := range terms {
+			if strings.HasPrefix(lower[i:], term) {
+				matched = term
+				break
+			}
+		}
+		if matched == "" {
+			j := i + 1
+			for j < len(text) && !isTermStart(lower, j, terms) {
+				j++
+			}
+			b.WriteString(text[i:j])
+			i = j
+			continue
+		}
+		b.WriteString(markStart)
+		b.WriteString(text[i : i+len(matched)])
+		b.WriteString(markStop)
+		i += len(matched)
+	}
+	return b.String()
+}
+
+func isTermStart(lower string, i int, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(lower[i:], term) {
+			return true
+		}
+	}
+	return false
+}
+
+// snippet picks about 25 words of body around the first match, highlighted
+func (q textQuery) snippet(body string) string {
+	words := strings.FieldsFunc(plainText(body), unicode.IsSpace)
+	if len(words) == 0 {
+		return ""
+	}
+
+	first := 0
+	terms := q.terms()
+search:
+	for i, word := range words {
+		for _, term := range terms {
+			if strings.Contains(strings.ToLower(word), strings.Fields(term)[0]) {
+				first = i
+				break search
+			}
+		}
+	}
+
+	const window = 25
+	start := max(first-window/3, 0)
+	end := min(start+window, len(words))
+	text := strings.Join(words[start:end], " ")
+	if start > 0 {
+		text = "… " + text
+	}
+	if end < len(words) {
+		text += " …"
+	}
+	return q.highlight(text)
+}
+
+// searchCandidate is a draft or post considered by matchSearch
+type searchCandidate struct {
+	result domain.SearchResult
+	body   string
+}
+
+// matchSearch ranks, orders and pages candidates the way the Postgres search does
+func matchSearch(query SearchQuery, candidates []searchCandidate) []domain.SearchResult {
+	q := parseTextQuery(query.Text)
+
+	var hits []domain.SearchResult
+	for _, c := range candidates {
+		rank := q.rank(c.result.Title, htmlTag.ReplaceAllString(c.body, " "))
+		if rank == 0 {
+			continue
+		}
+		res := c.result
+		res.Rank = rank
+		res.TitleHighlight = q.highlight(res.Title)
+		if c.body != "" {
+			res.Snippet = q.snippet(c.body)
+		}
+		hits = append(hits, res)
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].UpdatedAt.After(hits[j].UpdatedAt)
+	})
+
+	results := []domain.SearchResult{}
+	if query.Offset < len(hits) {
+		results = append(results, hits[query.Offset:min(query.Offset+query.Limit, len(hits))]...)
+	}
+	return results
+}
+
+// wantsPlatform reports whether a search includes the platform
+func (q SearchQuery) wantsPlatform(platform string) bool {
+	if len(q.Platforms) == 0 {
+		return true
+	}
+	for _, p := range q.Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsStatus reports whether a search includes the status
+func (q SearchQuery) wantsStatus(status string) bool {
+	if len(q.Statuses) == 0 {
+		return true
+	}
+	for _, s := range q.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"postificus/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WorkspaceRepository interface {
+	CreateWorkspace(ctx context.Context, workspace *domain.Workspace, ownerID string) error
+	ListWorkspaces(ctx context.Context, userID string) ([]domain.Workspace, error)
+	GetMembership(ctx context.Context, workspaceID string, userID string) (*domain.Membership, error)
+	ListMembers(ctx context.Context, workspaceID string) ([]domain.Membership, error)
+	SaveMember(ctx context.Context, member *domain.Membership) error
+	RemoveMember(ctx context.Context, workspaceID string, userID string) error
+}
+
+type PostgresWorkspaceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkspaceRepository(db *pgxpool.Pool) *PostgresWorkspaceRepository {
+	return &PostgresWorkspaceRepository{db: db}
+}
+
+// CreateWorkspace inserts the workspace and its first owner atomically
+func (r *PostgresWorkspaceRepository) CreateWorkspace(ctx context.Context, workspace *domain.Workspace, ownerID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO workspaces (name, created_at) VALUES ($1, NOW()) RETURNING id, created_at`,
+		workspace.Name,
+	).Scan(&workspace.ID, &workspace.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES ($1, $2, $3, NOW())`,
+		workspace.ID, ownerID, string(domain.RoleOwner),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add owner: %w", err)
+	}
+
+	workspace.Role = domain.RoleOwner
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresWorkspaceRepository) ListWorkspaces(ctx context.Context, userID string) ([]domain.Workspace, error) {
+	query := `
+		SELECT w.id, w.name, m.role, w.created_at
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.created_at
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []domain.Workspace{}
+	for rows.Next() {
+		var w domain.Workspace
+		var role string
+		if err := rows.Scan(&w.ID, &w.Name, &role, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		w.Role = domain.Role(role)
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, rows.Err()
+}
+
+// GetMembership returns nil when the user is not a member
+func (r *PostgresWorkspaceRepository) GetMembership(ctx context.Context, workspaceID string, userID string) (*domain.Membership, error) {
+	query := `
+		SELECT role, created_at
+		FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2
+	`
+	m := domain.Membership{WorkspaceID: workspaceID, UserID: userID}
+	var role string
+	err := r.db.QueryRow(ctx, query, workspaceID, userID).Scan(&role, &m.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch membership: %w", err)
+	}
+	m.Role = domain.Role(role)
+	return &m, nil
+}
+
+func (r *PostgresWorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]domain.Membership, error) {
+	query := `
+		SELECT user_id, role, created_at
+		FROM workspace_members
+		WHERE workspace_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []domain.Membership{}
+	for rows.Next() {
+		m := domain.Membership{WorkspaceID: workspaceID}
+		var role string
+		if err := rows.Scan(&m.UserID, &role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		m.Role = domain.Role(role)
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// SaveMember adds a member or changes their role
+func (r *PostgresWorkspaceRepository) SaveMember(ctx context.Context, member *domain.Membership) error {
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := r.db.Exec(ctx, query, member.WorkspaceID, member.UserID, string(member.Role))
+	if err != nil {
+		return fmt.Errorf("failed to save member: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresWorkspaceRepository) RemoveMember(ctx context.Context, workspaceID string, userID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+	return err
+}
@@ -0,0 +1,139 @@
+// Package textdiff implements line-based diffs of Markdown drafts.
+package textdiff
+
+import "strings"
+
+// OpKind is the kind of a single line edit
+type OpKind int
+
+const (
+	OpEqual OpKind = iota
+	OpDelete
+	OpInsert
+)
+
+// Edit is one line of an edit script.
+// OldPos and NewPos are the 0-based line cursors in each text before the edit applies.
+type Edit struct {
+	Kind   OpKind
+	OldPos int
+	NewPos int
+	Text   string
+}
+
+// Lines splits text into lines without their trailing newline
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Diff returns the shortest edit script turning a into b (Myers' algorithm).
+// Memory grows with the square of the number of differing lines, which is fine for drafts.
+func Diff(a, b []string) []Edit {
+	// Trim the common prefix and suffix; most autosaves touch a single paragraph
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, Edit{Kind: OpEqual, OldPos: i, NewPos: i, Text: a[i]})
+	}
+	for _, e := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		e.OldPos += prefix
+		e.NewPos += prefix
+		edits = append(edits, e)
+	}
+	for i := 0; i < suffix; i++ {
+		oldPos, newPos := len(a)-suffix+i, len(b)-suffix+i
+		edits = append(edits, Edit{Kind: OpEqual, OldPos: oldPos, NewPos: newPos, Text: a[oldPos]})
+	}
+	return edits
+}
+
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace[d] holds v[-d-1 .. d+1] as it was at the start of round d
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // Right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(trace [][]int, a, b []string) []Edit {
+	x, y := len(a), len(b)
+	var reversed []Edit
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		at := func(k int) int { return trace[d][k+d+1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, Edit{Kind: OpEqual, OldPos: x, NewPos: y, Text: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, Edit{Kind: OpInsert, OldPos: prevX, NewPos: prevY, Text: b[prevY]})
+			} else {
+				reversed = append(reversed, Edit{Kind: OpDelete, OldPos: prevX, NewPos: prevY, Text: a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	edits := make([]Edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
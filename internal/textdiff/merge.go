@@ -0,0 +1,152 @@
+package textdiff
+
+import "strings"
+
+// Conflict is a region both sides changed differently.
+// BaseStart and BaseEnd are the 0-based line range [start, end) in the base text.
+type Conflict struct {
+	BaseStart int      `json:"base_start"`
+	BaseEnd   int      `json:"base_end"`
+	Ours      []string `json:"ours"`
+	Theirs    []string `json:"theirs"`
+}
+
+// hunk replaces base lines [start, end) with lines
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+func hunks(edits []Edit) []hunk {
+	var out []hunk
+	var current *hunk
+	for _, e := range edits {
+		if e.Kind == OpEqual {
+			current = nil
+			continue
+		}
+		if current == nil {
+			out = append(out, hunk{start: e.OldPos, end: e.OldPos})
+			current = &out[len(out)-1]
+		}
+		if e.Kind == OpDelete {
+			current.end = e.OldPos + 1
+		} else {
+			current.lines = append(current.lines, e.Text)
+		}
+	}
+	return out
+}
+
+// apply rewrites base[start:end] with the given hunks, which must lie inside the range
+func apply(base []string, start, end int, hs []hunk) []string {
+	var out []string
+	pos := start
+	for _, h := range hs {
+		out = append(out, base[pos:h.start]...)
+		out = append(out, h.lines...)
+		pos = h.end
+	}
+	return append(out, base[pos:end]...)
+}
+
+// Merge3 merges two edits of a common base line by line.
+// Changes that touch or overlap the same base lines conflict unless both sides made
+// the same change; conflicting regions are written with git-style markers and reported.
+func Merge3(base, ours, theirs string) (string, []Conflict) {
+	baseLines := Lines(base)
+	oursHunks := hunks(Diff(baseLines, Lines(ours)))
+	theirsHunks := hunks(Diff(baseLines, Lines(theirs)))
+
+	var out []string
+	var conflicts []Conflict
+	pos, i, j := 0, 0, 0
+
+	for i < len(oursHunks) || j < len(theirsHunks) {
+		// Start a region at the earliest pending hunk, then absorb everything touching it
+		var start int
+		switch {
+		case j >= len(theirsHunks):
+			start = oursHunks[i].start
+		case i >= len(oursHunks):
+			start = theirsHunks[j].start
+		default:
+			start = min(oursHunks[i].start, theirsHunks[j].start)
+		}
+		end := start
+
+		var fromOurs, fromTheirs []hunk
+		for grew := true; grew; {
+			grew = false
+			if i < len(oursHunks) && oursHunks[i].start <= end {
+				fromOurs = append(fromOurs, oursHunks[i])
+				end = max(end, oursHunks[i].end)
+				i++
+				grew = true
+			}
+			if j < len(theirsHunks) && theirsHunks[j].start <= end {
+				fromTheirs = append(fromTheirs, theirsHunks[j])
+				end = max(end, theirsHunks[j].end)
+				j++
+				grew = true
+			}
+		}
+
+		out = append(out, baseLines[pos:start]...)
+		switch {
+		case len(fromTheirs) == 0:
+			out = append(out, apply(baseLines, start, end, fromOurs)...)
+		case len(fromOurs) == 0:
+			out = append(out, apply(baseLines, start, end, fromTheirs)...)
+		default:
+			mine := apply(baseLines, start, end, fromOurs)
+			other := apply(baseLines, start, end, fromTheirs)
+			if strings.Join(mine, "\n") == strings.Join(other, "\n") {
+				out = append(out, mine...)
+				break
+			}
+			conflicts = append(conflicts, Conflict{BaseStart: start, BaseEnd: end, Ours: mine, Theirs: other})
+			out = append(out, "<<<<<<< ours")
+			out = append(out, mine...)
+			out = append(out, "=======")
+			out = append(out, other...)
+			out = append(out, ">>>>>>> theirs")
+		}
+		pos = end
+	}
+	out = append(out, baseLines[pos:]...)
+
+	if len(out) == 0 {
+		return "", conflicts
+	}
+	merged := strings.Join(out, "\n")
+	if trailingNewline(base, ours, theirs) {
+		merged += "\n"
+	}
+	return merged, conflicts
+}
+
+// trailingNewline keeps the base's final newline unless one side changed it
+func trailingNewline(base, ours, theirs string) bool {
+	has := func(s string) bool { return strings.HasSuffix(s, "\n") }
+	switch {
+	case has(ours) != has(base):
+		return has(ours)
+	case has(theirs) != has(base):
+		return has(theirs)
+	default:
+		return has(base)
+	}
+}
+
+// MergeLine merges a single-line field such as a title; ok is false when both sides changed it differently.
+func MergeLine(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, true
+	case ours == base:
+		return theirs, true
+	default:
+		return ours, false
+	}
+}
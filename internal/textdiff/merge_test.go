@@ -0,0 +1,44 @@
+package textdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge3_NonOverlappingEdits(t *testing.T) {
+	base := "# Title\n\nFirst paragraph.\n\nSecond paragraph.\n\nThird paragraph.\n"
+	ours := "# Title\n\nFirst paragraph, reworded.\n\nSecond paragraph.\n\nThird paragraph.\n"
+	theirs := "# Title\n\nFirst paragraph.\n\nSecond paragraph.\n\nThird paragraph.\n\nA new ending.\n"
+
+	merged, conflicts := Merge3(base, ours, theirs)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, "# Title\n\nFirst paragraph, reworded.\n\nSecond paragraph.\n\nThird paragraph.\n\nA new ending.\n", merged)
+}
+
+func TestMerge3_SameChangeOnBothSides(t *testing.T) {
+	merged, conflicts := Merge3("a\nb\nc\n", "a\nB\nc\n", "a\nB\nc\n")
+	assert.Empty(t, conflicts)
+	assert.Equal(t, "a\nB\nc\n", merged)
+}
+
+func TestMerge3_OverlappingEditsConflict(t *testing.T) {
+	merged, conflicts := Merge3("a\nb\nc\n", "a\nmine\nc\n", "a\ntheirs\nc\n")
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, Conflict{BaseStart: 1, BaseEnd: 2, Ours: []string{"mine"}, Theirs: []string{"theirs"}}, conflicts[0])
+	assert.Equal(t, "a\n<<<<<<< ours\nmine\n=======\ntheirs\n>>>>>>> theirs\nc\n", merged)
+}
+
+func TestMerge3_AdjacentEditsConflict(t *testing.T) {
+	_, conflicts := Merge3("a\nb\nc\n", "a\nB\nc\n", "a\nb\nC\n")
+	assert.Len(t, conflicts, 1)
+}
+
+func TestMergeLine(t *testing.T) {
+	title, ok := MergeLine("Old", "Old", "New")
+	assert.True(t, ok)
+	assert.Equal(t, "New", title)
+
+	_, ok = MergeLine("Old", "Mine", "Theirs")
+	assert.False(t, ok)
+}
@@ -0,0 +1,81 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each hunk
+const DefaultContext = 3
+
+// Unified renders the difference between two texts in unified diff format.
+// It returns an empty string when the texts are identical.
+func Unified(fromName, toName, from, to string, context int) string {
+	edits := Diff(Lines(from), Lines(to))
+
+	var changes []int
+	for i, e := range edits {
+		if e.Kind != OpEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+
+	for i := 0; i < len(changes); {
+		// Merge changes whose unchanged gap fits inside both hunks' context
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context+1 {
+			j++
+		}
+
+		start := max(changes[i]-context, 0)
+		end := min(changes[j]+context+1, len(edits))
+		writeHunk(&b, edits[start:end])
+		i = j + 1
+	}
+	return b.String()
+}
+
+func writeHunk(b *strings.Builder, hunk []Edit) {
+	oldCount, newCount := 0, 0
+	for _, e := range hunk {
+		if e.Kind != OpInsert {
+			oldCount++
+		}
+		if e.Kind != OpDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(hunk[0].OldPos, oldCount), hunkRange(hunk[0].NewPos, newCount))
+	for _, e := range hunk {
+		switch e.Kind {
+		case OpEqual:
+			b.WriteString(" ")
+		case OpDelete:
+			b.WriteString("-")
+		case OpInsert:
+			b.WriteString("+")
+		}
+		b.WriteString(e.Text)
+		b.WriteString("\n")
+	}
+}
+
+// hunkRange formats a 0-based position as a 1-based "start,count" range.
+// Empty ranges point at the line before the hunk, as GNU diff does.
+func hunkRange(pos, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", pos)
+	case 1:
+		return fmt.Sprintf("%d", pos+1)
+	default:
+		return fmt.Sprintf("%d,%d", pos+1, count)
+	}
+}
@@ -0,0 +1,64 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified_Identical(t *testing.T) {
+	assert.Equal(t, "", Unified("a", "b", "one\ntwo\n", "one\ntwo\n", DefaultContext))
+}
+
+func TestUnified_SingleChange(t *testing.T) {
+	from := "# Title\n\nIntro\nBody\nOutro\n"
+	to := "# Title\n\nIntro\nBetter body\nOutro\n"
+
+	expected := strings.Join([]string{
+		"--- r1",
+		"+++ r2",
+		"@@ -2,4 +2,4 @@",
+		" ",
+		" Intro",
+		"-Body",
+		"+Better body",
+		" Outro",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, Unified("r1", "r2", from, to, 2))
+}
+
+func TestUnified_SeparateHunksAndEmptyRanges(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	to := "new\na\nb\nc\nd\ne\nf\ng\n"
+
+	expected := strings.Join([]string{
+		"--- from",
+		"+++ to",
+		"@@ -0,0 +1 @@",
+		"+new",
+		"@@ -8 +8,0 @@",
+		"-h",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, Unified("from", "to", from, to, 0))
+}
+
+func TestDiff_IsMinimal(t *testing.T) {
+	a := Lines("a\nb\nc\na\nb\nb\na")
+	b := Lines("c\nb\na\nb\na\nc")
+
+	changes := 0
+	var rebuilt []string
+	for _, e := range Diff(a, b) {
+		if e.Kind != OpEqual {
+			changes++
+		}
+		if e.Kind != OpDelete {
+			rebuilt = append(rebuilt, e.Text)
+		}
+	}
+	assert.Equal(t, 5, changes)
+	assert.Equal(t, b, rebuilt)
+}
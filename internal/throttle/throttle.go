@@ -0,0 +1,271 @@
+// Package throttle rate-limits what the workers do on each platform account,
+// so publishing and scraping stay below the levels that get accounts and IPs banned.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"postificus/internal/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// limit is a token bucket: bursts of up to Count actions, refilled evenly at Count per Per
+type limit struct {
+	Count int
+	Per   time.Duration
+}
+
+// interval is how long one token takes to come back
+func (l limit) interval() time.Duration {
+	return l.Per / time.Duration(l.Count)
+}
+
+// PlatformLimits cap what a single account does on a platform; zero is no limit
+type PlatformLimits struct {
+	PostsPerDay       int
+	RequestsPerMinute int
+}
+
+// DefaultLimits apply to platforms the environment doesn't configure
+var DefaultLimits = map[string]PlatformLimits{
+	"medium": {PostsPerDay: 10, RequestsPerMinute: 20},
+	"devto":  {PostsPerDay: 20, RequestsPerMinute: 30},
+}
+
+// Action is what a worker is about to do on a platform
+type Action int
+
+const (
+	Request Action = iota // A scrape, lookup or any other call against the platform
+	Post                  // Publishing a post; it counts as a request as well
+)
+
+func (a Action) String() string {
+	if a == Post {
+		return "post"
+	}
+	return "request"
+}
+
+// ErrLimited matches every LimitedError
+var ErrLimited = errors.New("platform rate limit reached")
+
+// LimitedError is returned for an action an account's limits don't allow yet
+type LimitedError struct {
+	Platform string
+	Action   Action
+	Wait     time.Duration // Until the action is allowed
+}
+
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("%s %s rate limit reached; allowed again in %s", e.Platform, e.Action, e.Wait.Round(time.Second))
+}
+
+func (e *LimitedError) Is(target error) bool { return target == ErrLimited }
+
+// RetryAfter returns how long the action err refused must wait, if err is a LimitedError
+func RetryAfter(err error) (time.Duration, bool) {
+	var limited *LimitedError
+	if errors.As(err, &limited) {
+		return limited.Wait, true
+	}
+	return 0, false
+}
+
+// Throttle keeps a token bucket per platform account and limit. The buckets live
+// in Redis when a client is given, so every worker draws from the same ones, and
+// in process memory otherwise (or while Redis can't be reached).
+type Throttle struct {
+	client *redis.Client
+	limits map[string]PlatformLimits
+	now    func() time.Time
+
+	mu    sync.Mutex
+	local map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+	full   time.Time // When it has refilled; forgotten after that
+}
+
+func New(client *redis.Client, limits map[string]PlatformLimits) *Throttle {
+	return &Throttle{client: client, limits: limits, now: time.Now, local: make(map[string]*bucket)}
+}
+
+// Take takes a token for the action from each of the account's buckets: all of
+// them or, returning a LimitedError, none. A nil Throttle allows everything.
+func (t *Throttle) Take(ctx context.Context, platform string, account string, action Action) error {
+	if t == nil {
+		return nil
+	}
+	limits := t.limits[platform]
+	keys := make([]string, 0, 2)
+	buckets := make([]limit, 0, 2)
+	prefix := "throttle:" + platform + ":" + account
+	if action == Post && limits.PostsPerDay > 0 {
+		keys = append(keys, prefix+":posts")
+		buckets = append(buckets, limit{Count: limits.PostsPerDay, Per: 24 * time.Hour})
+	}
+	if limits.RequestsPerMinute > 0 {
+		keys = append(keys, prefix+":requests")
+		buckets = append(buckets, limit{Count: limits.RequestsPerMinute, Per: time.Minute})
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	wait, err := t.takeShared(ctx, keys, buckets)
+	if err != nil {
+		log.Printf("⚠️ Rate limit check failed, using this process's buckets: %v", err)
+		wait = t.takeLocal(keys, buckets)
+	}
+	if wait > 0 {
+		metrics.PlatformRateLimited.WithLabelValues(platform, action.String()).Inc()
+		return &LimitedError{Platform: platform, Action: action, Wait: wait}
+	}
+	return nil
+}
+
+// takeScript refills the buckets in KEYS, then takes a token from each if all
+// have one. ARGV holds the time (Unix millis), then the capacity and refill
+// interval (millis) of every bucket. It returns 0, or the millis until all have a token.
+var takeScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local tokens = {}
+local wait = 0
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[2 * i])
+	local interval = tonumber(ARGV[2 * i + 1])
+	local state = redis.call('HMGET', key, 'tokens', 'at')
+	local available = tonumber(state[1]) or capacity
+	local at = tonumber(state[2]) or now
+	available = math.min(capacity, available + math.max(0, now - at) / interval)
+	tokens[i] = available
+	if available < 1 then
+		wait = math.max(wait, math.ceil((1 - available) * interval))
+	end
+end
+if wait > 0 then
+	return wait
+end
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[2 * i])
+	local interval = tonumber(ARGV[2 * i + 1])
+	redis.call('HSET', key, 'tokens', tostring(tokens[i] - 1), 'at', ARGV[1])
+	-- A bucket that has filled up again is the same as no bucket
+	redis.call('PEXPIRE', key, math.ceil(capacity * interval))
+end
+return 0
+`)
+
+func (t *Throttle) takeShared(ctx context.Context, keys []string, buckets []limit) (time.Duration, error) {
+	if t.client == nil {
+		return t.takeLocal(keys, buckets), nil
+	}
+	args := []interface{}{t.now().UnixMilli()}
+	for _, l := range buckets {
+		args = append(args, l.Count, max(l.interval().Milliseconds(), 1))
+	}
+	wait, err := takeScript.Run(ctx, t.client, keys, args...).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// takeLocal is takeScript on the process's own buckets
+func (t *Throttle) takeLocal(keys []string, buckets []limit) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	refilled := make([]float64, len(keys))
+	var wait time.Duration
+	for i, key := range keys {
+		capacity := float64(buckets[i].Count)
+		interval := buckets[i].interval()
+		available := capacity
+		if b, ok := t.local[key]; ok {
+			available = math.Min(capacity, b.tokens+float64(now.Sub(b.at))/float64(interval))
+		}
+		refilled[i] = available
+		if available < 1 {
+			wait = max(wait, time.Duration(math.Ceil((1-available)*float64(interval))))
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for key, b := range t.local {
+		if now.After(b.full) {
+			delete(t.local, key)
+		}
+	}
+	for i, key := range keys {
+		tokens := refilled[i] - 1
+		full := now.Add(time.Duration((float64(buckets[i].Count) - tokens) * float64(buckets[i].interval())))
+		t.local[key] = &bucket{tokens: tokens, at: now, full: full}
+	}
+	return 0
+}
+
+// LimitsFromEnv returns DefaultLimits overridden by PLATFORM_POSTS_PER_DAY and
+// PLATFORM_REQUESTS_PER_MINUTE, both per platform such as "medium=10,devto=20"
+func LimitsFromEnv() (map[string]PlatformLimits, error) {
+	limits := make(map[string]PlatformLimits, len(DefaultLimits))
+	for platform, l := range DefaultLimits {
+		limits[platform] = l
+	}
+	posts, err := parsePerPlatform(os.Getenv("PLATFORM_POSTS_PER_DAY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PLATFORM_POSTS_PER_DAY: %w", err)
+	}
+	for platform, n := range posts {
+		l := limits[platform]
+		l.PostsPerDay = n
+		limits[platform] = l
+	}
+	requests, err := parsePerPlatform(os.Getenv("PLATFORM_REQUESTS_PER_MINUTE"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PLATFORM_REQUESTS_PER_MINUTE: %w", err)
+	}
+	for platform, n := range requests {
+		l := limits[platform]
+		l.RequestsPerMinute = n
+		limits[platform] = l
+	}
+	return limits, nil
+}
+
+// parsePerPlatform reads a setting such as "medium=10,devto=20"
+func parsePerPlatform(spec string) (map[string]int, error) {
+	values := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		platform, raw, ok := strings.Cut(entry, "=")
+		platform = strings.TrimSpace(platform)
+		if !ok || platform == "" {
+			return nil, fmt.Errorf("invalid entry %q (want platform=limit)", entry)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid limit for %s: %q", platform, raw)
+		}
+		values[platform] = n
+	}
+	return values, nil
+}
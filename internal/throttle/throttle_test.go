@@ -0,0 +1,147 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock is a settable clock shared by the throttle and, through miniredis, its keys' TTLs
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+	mr  *miniredis.Miniredis
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	if c.mr != nil {
+		c.mr.FastForward(d)
+	}
+}
+
+// testThrottle is a throttle on a clock the test moves
+type testThrottle struct {
+	*Throttle
+	clock *testClock
+}
+
+// testThrottles returns a Redis-backed and a process-local throttle with the limits
+func testThrottles(t *testing.T, limits map[string]PlatformLimits) map[string]testThrottle {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	throttles := map[string]testThrottle{
+		"redis": {Throttle: New(client, limits), clock: &testClock{now: time.Unix(1_700_000_000, 0), mr: mr}},
+		"local": {Throttle: New(nil, limits), clock: &testClock{now: time.Unix(1_700_000_000, 0)}},
+	}
+	for _, th := range throttles {
+		th.now = th.clock.Now
+	}
+	return throttles
+}
+
+func TestThrottle_PostsPerDay(t *testing.T) {
+	for name, th := range testThrottles(t, map[string]PlatformLimits{"devto": {PostsPerDay: 2}}) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			require.NoError(t, th.Take(ctx, "devto", "acct-1", Post))
+			require.NoError(t, th.Take(ctx, "devto", "acct-1", Post))
+
+			err := th.Take(ctx, "devto", "acct-1", Post)
+			require.ErrorIs(t, err, ErrLimited)
+			wait, ok := RetryAfter(fmt.Errorf("publish: %w", err))
+			require.True(t, ok)
+			assert.Equal(t, 12*time.Hour, wait, "one post comes back every 24h/2")
+
+			// Other accounts and scrapes have their own buckets
+			assert.NoError(t, th.Take(ctx, "devto", "acct-2", Post))
+			assert.NoError(t, th.Take(ctx, "devto", "acct-1", Request))
+
+			th.clock.Advance(12 * time.Hour)
+			assert.NoError(t, th.Take(ctx, "devto", "acct-1", Post))
+			assert.ErrorIs(t, th.Take(ctx, "devto", "acct-1", Post), ErrLimited)
+		})
+	}
+}
+
+func TestThrottle_PostsCountAsRequests(t *testing.T) {
+	for name, th := range testThrottles(t, map[string]PlatformLimits{"medium": {PostsPerDay: 10, RequestsPerMinute: 2}}) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			require.NoError(t, th.Take(ctx, "medium", "acct", Post))
+			require.NoError(t, th.Take(ctx, "medium", "acct", Request))
+
+			// Out of requests: the post is refused and its post token isn't taken
+			err := th.Take(ctx, "medium", "acct", Post)
+			var limited *LimitedError
+			require.True(t, errors.As(err, &limited))
+			assert.Equal(t, 30*time.Second, limited.Wait)
+
+			th.clock.Advance(30 * time.Second)
+			for range 9 {
+				require.NoError(t, th.Take(ctx, "medium", "acct", Post))
+				th.clock.Advance(30 * time.Second)
+			}
+			assert.ErrorIs(t, th.Take(ctx, "medium", "acct", Post), ErrLimited, "ten posts a day, not eleven")
+		})
+	}
+}
+
+func TestThrottle_Unlimited(t *testing.T) {
+	th := New(nil, map[string]PlatformLimits{"devto": {PostsPerDay: 0, RequestsPerMinute: 1}})
+	ctx := context.Background()
+	for range 5 {
+		assert.NoError(t, th.Take(ctx, "linkedin", "acct", Post), "platforms without limits")
+	}
+	assert.NoError(t, th.Take(ctx, "devto", "acct", Post))
+	assert.ErrorIs(t, th.Take(ctx, "devto", "acct", Request), ErrLimited)
+
+	var none *Throttle
+	assert.NoError(t, none.Take(ctx, "devto", "acct", Post))
+}
+
+func TestThrottle_FallsBackWhenRedisIsDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	mr.Close()
+
+	th := New(client, map[string]PlatformLimits{"devto": {PostsPerDay: 1}})
+	ctx := context.Background()
+	require.NoError(t, th.Take(ctx, "devto", "acct", Post))
+	assert.ErrorIs(t, th.Take(ctx, "devto", "acct", Post), ErrLimited)
+}
+
+func TestLimitsFromEnv(t *testing.T) {
+	t.Setenv("PLATFORM_POSTS_PER_DAY", "medium=3, hashnode=5")
+	t.Setenv("PLATFORM_REQUESTS_PER_MINUTE", "devto=0")
+	limits, err := LimitsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, PlatformLimits{PostsPerDay: 3, RequestsPerMinute: DefaultLimits["medium"].RequestsPerMinute}, limits["medium"])
+	assert.Equal(t, PlatformLimits{PostsPerDay: DefaultLimits["devto"].PostsPerDay}, limits["devto"])
+	assert.Equal(t, PlatformLimits{PostsPerDay: 5}, limits["hashnode"])
+
+	for _, invalid := range []string{"medium", "medium=lots", "=3", "devto=-1"} {
+		t.Setenv("PLATFORM_POSTS_PER_DAY", invalid)
+		_, err := LimitsFromEnv()
+		assert.Error(t, err, invalid)
+	}
+}